@@ -0,0 +1,21 @@
+package adapter
+
+import (
+	"context"
+	"time"
+)
+
+// CollabBroker 实时协作消息代理，负责多实例间的事件广播与在线状态共享
+// 消息与在线状态均为不透明的字节序列，由业务层负责编解码
+type CollabBroker interface {
+	// Publish 向导图的协作频道广播消息，所有实例的订阅者（包括自身）都会收到
+	Publish(ctx context.Context, mapID string, payload []byte) error
+	// Subscribe 订阅导图的协作频道，返回时订阅已生效；ctx 取消后退订并关闭 channel
+	Subscribe(ctx context.Context, mapID string) (<-chan []byte, error)
+	// SavePresence 保存连接的在线状态，ttl 内未刷新的在线状态自动失效
+	SavePresence(ctx context.Context, mapID, connID string, payload []byte, ttl time.Duration) error
+	// DeletePresence 删除连接的在线状态
+	DeletePresence(ctx context.Context, mapID, connID string) error
+	// ListPresence 获取导图的全部在线状态，可能包含已过期但尚未清理的条目
+	ListPresence(ctx context.Context, mapID string) ([][]byte, error)
+}
@@ -16,4 +16,16 @@ type COSService interface {
 	// contentType: 文件类型，如 "image/jpeg"
 	// 返回: 完整URL
 	UploadFile(ctx context.Context, resourcePath string, fileData []byte, contentType string) (string, error)
+
+	// CopyFile 在COS内复制文件
+	// srcPath: 源文件路径，dstPath: 目标路径
+	// 返回: 目标文件的完整URL
+	CopyFile(ctx context.Context, srcPath, dstPath string) (string, error)
+
+	// DeleteFile 删除COS中的文件，文件不存在时不返回错误
+	DeleteFile(ctx context.Context, resourcePath string) error
+
+	// DeleteDirectory 删除COS中以 prefix 为前缀的所有文件
+	// prefix: 目录路径，如 "user/123/maps/456/"，必须以 "/" 结尾
+	DeleteDirectory(ctx context.Context, prefix string) error
 }
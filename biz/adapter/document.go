@@ -0,0 +1,17 @@
+package adapter
+
+import (
+	"context"
+
+	"forge/biz/entity"
+)
+
+// DocumentExporter 思维导图文档导出接口，按节点层级生成结构化文档
+type DocumentExporter interface {
+	// ExportDOCX 导出为 Word 文档，节点层级对应标题级别
+	ExportDOCX(ctx context.Context, mindMap *entity.MindMap) ([]byte, error)
+	// ExportPPTX 导出为演示文稿，每个一级分支一页
+	ExportPPTX(ctx context.Context, mindMap *entity.MindMap) ([]byte, error)
+	// ExportPDF 导出为 PDF 讲义
+	ExportPDF(ctx context.Context, mindMap *entity.MindMap) ([]byte, error)
+}
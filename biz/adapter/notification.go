@@ -0,0 +1,32 @@
+package adapter
+
+import "context"
+
+// MindMapInvitation 思维导图协作邀请内容
+type MindMapInvitation struct {
+	InviterName string // 邀请人用户名
+	MapID       string
+	MapTitle    string
+	Role        string // 被授予的角色：editor、viewer
+}
+
+// MindMapMention 思维导图评论中的@提醒内容
+type MindMapMention struct {
+	AuthorName string // 评论人用户名
+	MapID      string
+	MapTitle   string
+	NodeText   string // 评论所在节点的文本
+	Content    string // 评论内容
+}
+
+// NotificationService 业务通知服务接口，支持邮件与短信
+type NotificationService interface {
+	// SendMindMapInviteEmail 发送思维导图协作邀请邮件
+	SendMindMapInviteEmail(ctx context.Context, email string, invitation *MindMapInvitation) error
+	// SendMindMapInviteSMS 发送思维导图协作邀请短信
+	SendMindMapInviteSMS(ctx context.Context, phone string, invitation *MindMapInvitation) error
+	// SendMindMapMentionEmail 发送评论@提醒邮件
+	SendMindMapMentionEmail(ctx context.Context, email string, mention *MindMapMention) error
+	// SendMindMapMentionSMS 发送评论@提醒短信
+	SendMindMapMentionSMS(ctx context.Context, phone string, mention *MindMapMention) error
+}
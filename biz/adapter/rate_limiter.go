@@ -0,0 +1,17 @@
+package adapter
+
+import (
+	"context"
+	"time"
+)
+
+// RateLimiter 固定窗口计数器，用于接口限流与失败次数锁定
+// 计数在首次计数后的 window 时长内有效，过期后重新计数
+type RateLimiter interface {
+	// Allow 将 key 的计数加一，返回加一后的计数是否未超过 limit
+	Allow(ctx context.Context, key string, limit int64, window time.Duration) (bool, error)
+	// Count 返回 key 在当前窗口内的计数，不存在时为 0
+	Count(ctx context.Context, key string) (int64, error)
+	// Release 将 key 的计数减一，不改变过期时间，计数不存在或已为 0 时不做处理
+	Release(ctx context.Context, key string) error
+}
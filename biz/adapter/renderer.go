@@ -0,0 +1,22 @@
+package adapter
+
+import (
+	"context"
+
+	"forge/biz/entity"
+)
+
+// RenderOptions 位图渲染选项
+type RenderOptions struct {
+	Scale     float64 // 缩放倍数，<=0 时为 1
+	MaxWidth  int     // 输出图片最大宽度，0 表示不限制，超出时等比缩小
+	MaxHeight int     // 输出图片最大高度，0 表示不限制，超出时等比缩小
+}
+
+// MindMapRenderer 思维导图服务端渲染接口，根据导图布局类型排版并输出图片
+type MindMapRenderer interface {
+	// RenderSVG 渲染为 SVG 矢量图
+	RenderSVG(ctx context.Context, mindMap *entity.MindMap) ([]byte, error)
+	// RenderPNG 渲染为 PNG 位图
+	RenderPNG(ctx context.Context, mindMap *entity.MindMap, opts RenderOptions) ([]byte, error)
+}
@@ -25,30 +25,93 @@ var (
 )
 
 type AiChatService struct {
-	aiChatRepo repo.AiChatRepo
-	einoServer repo.EinoServer
+	aiChatRepo  repo.AiChatRepo
+	einoServer  repo.EinoServer
+	mindMapRepo repo.IMindMapRepo
 }
 
-func NewAiChatService(aiChatRepo repo.AiChatRepo, einoServer repo.EinoServer) *AiChatService {
-	return &AiChatService{aiChatRepo: aiChatRepo, einoServer: einoServer}
+func NewAiChatService(aiChatRepo repo.AiChatRepo, einoServer repo.EinoServer, mindMapRepo repo.IMindMapRepo) *AiChatService {
+	return &AiChatService{aiChatRepo: aiChatRepo, einoServer: einoServer, mindMapRepo: mindMapRepo}
 }
 
-func (a *AiChatService) ProcessUserMessage(ctx context.Context, req *types.ProcessUserMessageParams) (types.AgentResponse, error) {
+// checkMapAccess 校验用户是导图的协作成员 非成员视为导图不存在
+func (a *AiChatService) checkMapAccess(ctx context.Context, mapID, userID string) error {
+	if mapID == "" {
+		return MAP_ID_NOT_NULL
+	}
+	member, err := a.mindMapRepo.GetMindMapMember(ctx, mapID, userID)
+	if err != nil {
+		return err
+	}
+	if member == nil {
+		zlog.CtxWarnf(ctx, "用户不是导图协作成员 mapID:%s userID:%s", mapID, userID)
+		return MIND_MAP_NOT_EXIST
+	}
+	return nil
+}
+
+// getOwnConversation 获取当前用户自己的会话 会话仅创建者可见 且创建者需仍可访问会话所属导图
+func (a *AiChatService) getOwnConversation(ctx context.Context, conversationID string, checkMap bool) (*entity.Conversation, error) {
 	user, ok := entity.GetUser(ctx)
 	if !ok {
 		zlog.CtxErrorf(ctx, "未能从上下文中获取用户信息")
-		return types.AgentResponse{}, AI_CHAT_PERMISSION_DENIED
+		return nil, AI_CHAT_PERMISSION_DENIED
+	}
+
+	conversation, err := a.aiChatRepo.GetConversation(ctx, conversationID)
+	if err != nil {
+		return nil, err
+	}
+	if conversation.UserID != user.UserID {
+		zlog.CtxWarnf(ctx, "无权访问他人会话 conversationID:%s userID:%s", conversationID, user.UserID)
+		return nil, AI_CHAT_PERMISSION_DENIED
+	}
+
+	if checkMap {
+		if err := a.checkMapAccess(ctx, conversation.MapID, user.UserID); err != nil {
+			return nil, err
+		}
 	}
+	return conversation, nil
+}
+
+func (a *AiChatService) ProcessUserMessage(ctx context.Context, req *types.ProcessUserMessageParams) (types.AgentResponse, error) {
+	return a.processUserMessage(ctx, req, a.einoServer.SendMessage)
+}
+
+// ProcessUserMessageStream 流式处理用户消息 事件推送完毕后与非流式相同地保存聊天记录
+func (a *AiChatService) ProcessUserMessageStream(ctx context.Context, req *types.ProcessUserMessageParams, onEvent func(types.AgentEvent)) (types.AgentResponse, error) {
+	return a.processUserMessage(ctx, req, func(ctx context.Context, messages []*entity.Message) (types.AgentResponse, error) {
+		conversation, _ := entity.GetConversation(ctx)
+		return a.einoServer.SendMessageStream(ctx, messages, func(event types.AgentEvent) {
+			//工具调用后推送完整导图JSON
+			if event.MapData != nil {
+				mapJson, err := replacePromptMapRoot(conversation.MapData, event.MapData)
+				if err != nil {
+					zlog.CtxErrorf(ctx, "序列化工具调用后的导图失败 %v", err)
+				} else {
+					event.Content = mapJson
+				}
+			}
+			onEvent(event)
+		})
+	})
+}
 
-	conversation, err := a.aiChatRepo.GetConversation(ctx, req.ConversationID, user.UserID)
+// processUserMessage 组装上下文 调用send获取ai回复 并保存本轮聊天记录
+func (a *AiChatService) processUserMessage(ctx context.Context, req *types.ProcessUserMessageParams,
+	send func(ctx context.Context, messages []*entity.Message) (types.AgentResponse, error)) (types.AgentResponse, error) {
+	conversation, err := a.getOwnConversation(ctx, req.ConversationID, true)
 	if err != nil {
 		return types.AgentResponse{}, err
 	}
+	//读取导图当前版本作为对话上下文
+	if err := a.loadMapContext(ctx, conversation); err != nil {
+		return types.AgentResponse{}, err
+	}
 	//将数据写入ctx
 	ctx = entity.WithConversation(ctx, conversation)
 
-	//更新导图数据
-	conversation.UpdateMapData(req.MapData)
 	//更新导图提示词
 	conversation.ProcessSystemPrompt()
 
@@ -56,16 +119,24 @@ func (a *AiChatService) ProcessUserMessage(ctx context.Context, req *types.Proce
 	conversation.AddMessage(req.Message, entity.USER, "", nil)
 
 	//调用ai 返回ai消息
-	aiMsg, err := a.einoServer.SendMessage(ctx, conversation.Messages)
+	aiMsg, err := send(ctx, conversation.Messages)
 	if err != nil {
 		return types.AgentResponse{}, err
 	}
 
-	//添加ai消息
-	conversation.AddMessage(aiMsg.Content, entity.ASSISTANT, "", aiMsg.ToolCalls)
-	if aiMsg.NewMapJson != "" {
-		conversation.AddMessage(aiMsg.NewMapJson, entity.TOOL, aiMsg.ToolCallID, nil)
+	//添加ai消息 包括工具调用与工具结果
+	for _, msg := range aiMsg.Messages {
+		conversation.AddMessage(msg.Content, string(msg.Role), msg.ToolCallID, msg.ToolCalls)
 	}
+	if aiMsg.NewMapData != nil {
+		aiMsg.NewMapJson, err = replacePromptMapRoot(conversation.MapData, aiMsg.NewMapData)
+		if err != nil {
+			return types.AgentResponse{}, err
+		}
+		//计算ai修改前后的导图差异 供前端高亮
+		aiMsg.Diff = diffMapJson(ctx, conversation.MapData, aiMsg.NewMapJson)
+	}
+	aiMsg.MapVersion = conversation.MapVersion
 
 	//更新会话聊天记录
 	err = a.aiChatRepo.UpdateConversationMessage(ctx, conversation)
@@ -83,10 +154,17 @@ func (a *AiChatService) SaveNewConversation(ctx context.Context, req *types.Save
 		return "", AI_CHAT_PERMISSION_DENIED
 	}
 
-	conversation, err := entity.NewConversation(user.UserID, req.MapID, req.Title, req.MapData)
+	if err := a.checkMapAccess(ctx, req.MapID, user.UserID); err != nil {
+		return "", err
+	}
+
+	conversation, err := entity.NewConversation(user.UserID, req.MapID, req.Title)
 	if err != nil {
 		return "", err
 	}
+	if err := a.loadMapContext(ctx, conversation); err != nil {
+		return "", err
+	}
 	//初始化系统提示词
 	conversation.ProcessSystemPrompt()
 
@@ -104,6 +182,10 @@ func (a *AiChatService) GetConversationList(ctx context.Context, req *types.GetC
 		return nil, AI_CHAT_PERMISSION_DENIED
 	}
 
+	if err := a.checkMapAccess(ctx, req.MapID, user.UserID); err != nil {
+		return nil, err
+	}
+
 	conversationList, err := a.aiChatRepo.GetMapAllConversation(ctx, req.MapID, user.UserID)
 	if err != nil {
 		return nil, err
@@ -113,13 +195,12 @@ func (a *AiChatService) GetConversationList(ctx context.Context, req *types.GetC
 }
 
 func (a *AiChatService) DelConversation(ctx context.Context, req *types.DelConversationParams) error {
-	user, ok := entity.GetUser(ctx)
-	if !ok {
-		zlog.CtxErrorf(ctx, "未能从上下文中获取用户信息")
-		return AI_CHAT_PERMISSION_DENIED
+	//失去导图访问权限后仍允许删除自己的会话
+	if _, err := a.getOwnConversation(ctx, req.ConversationID, false); err != nil {
+		return err
 	}
 
-	err := a.aiChatRepo.DeleteConversation(ctx, req.ConversationID, user.UserID)
+	err := a.aiChatRepo.DeleteConversation(ctx, req.ConversationID)
 	if err != nil {
 		return err
 	}
@@ -128,13 +209,7 @@ func (a *AiChatService) DelConversation(ctx context.Context, req *types.DelConve
 }
 
 func (a *AiChatService) GetConversation(ctx context.Context, req *types.GetConversationParams) (*entity.Conversation, error) {
-	user, ok := entity.GetUser(ctx)
-	if !ok {
-		zlog.CtxErrorf(ctx, "未能从上下文中获取用户信息")
-		return nil, AI_CHAT_PERMISSION_DENIED
-	}
-
-	conversation, err := a.aiChatRepo.GetConversation(ctx, req.ConversationID, user.UserID)
+	conversation, err := a.getOwnConversation(ctx, req.ConversationID, true)
 	if err != nil {
 		return nil, err
 	}
@@ -143,13 +218,7 @@ func (a *AiChatService) GetConversation(ctx context.Context, req *types.GetConve
 }
 
 func (a *AiChatService) UpdateConversationTitle(ctx context.Context, req *types.UpdateConversationTitleParams) error {
-	user, ok := entity.GetUser(ctx)
-	if !ok {
-		zlog.CtxErrorf(ctx, "未能从上下文中获取用户信息")
-		return AI_CHAT_PERMISSION_DENIED
-	}
-
-	conversation, err := a.aiChatRepo.GetConversation(ctx, req.ConversationID, user.UserID)
+	conversation, err := a.getOwnConversation(ctx, req.ConversationID, true)
 	if err != nil {
 		return err
 	}
@@ -298,6 +367,44 @@ func (a *AiChatService) GenerateMindMapPro(ctx context.Context, req *types.Gener
 	return batch, generationResults, conversations, nil
 }
 
+// diffMapJson 计算修改前后导图JSON的结构差异 任一导图无法解析时返回nil
+func diffMapJson(ctx context.Context, oldMapJson, newMapJson string) *entity.MindMapDiff {
+	oldRoot, err := parseMapRoot(oldMapJson)
+	if err != nil {
+		zlog.CtxWarnf(ctx, "解析修改前的导图JSON失败: %v", err)
+		return nil
+	}
+	newRoot, err := parseMapRoot(newMapJson)
+	if err != nil {
+		zlog.CtxWarnf(ctx, "解析ai返回的导图JSON失败: %v", err)
+		return nil
+	}
+	return entity.DiffMindMapData(oldRoot, newRoot)
+}
+
+// parseMapRoot 解析导图JSON中的根节点 兼容完整导图（含root字段）与仅有根节点两种格式
+func parseMapRoot(mapJson string) (*entity.MindMapData, error) {
+	raw := []byte(extractFirstJSONObject(mapJson))
+	var mindMap struct {
+		Root *entity.MindMapData `json:"root"`
+	}
+	if err := json.Unmarshal(raw, &mindMap); err != nil {
+		return nil, err
+	}
+	if mindMap.Root != nil {
+		return mindMap.Root, nil
+	}
+
+	var root entity.MindMapData
+	if err := json.Unmarshal(raw, &root); err != nil {
+		return nil, err
+	}
+	if root.Data.Text == "" && len(root.Children) == 0 {
+		return nil, errors.New("导图JSON中缺少根节点")
+	}
+	return &root, nil
+}
+
 // extractJSONFromResult 根据策略从AI生成结果中提取JSON
 func extractJSONFromResult(result string, strategy int) string {
 	if strategy == 1 {
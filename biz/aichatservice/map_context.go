@@ -0,0 +1,100 @@
+package aichatservice
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"forge/biz/entity"
+	"forge/pkg/log/zlog"
+)
+
+// promptMindMap 注入提示词的导图JSON 字段与前端导图格式一致
+type promptMindMap struct {
+	MapID  string     `json:"mapId"`
+	UserID string     `json:"userId"`
+	Title  string     `json:"title"`
+	Desc   string     `json:"desc"`
+	Layout string     `json:"layout"`
+	Root   promptNode `json:"root"`
+}
+
+type promptNode struct {
+	Data     promptNodeData `json:"data"`
+	Children []promptNode   `json:"children"`
+}
+
+type promptNodeData struct {
+	UID             string   `json:"uid"`
+	Text            string   `json:"text"`
+	Note            string   `json:"note,omitempty"`
+	Hyperlink       string   `json:"hyperlink,omitempty"`
+	HyperlinkTitle  string   `json:"hyperlinkTitle,omitempty"`
+	Tags            []string `json:"tags,omitempty"`
+	Color           string   `json:"color,omitempty"`
+	BackgroundColor string   `json:"backgroundColor,omitempty"`
+	Icon            string   `json:"icon,omitempty"`
+	Priority        int      `json:"priority,omitempty"`
+	Collapsed       bool     `json:"collapsed,omitempty"`
+	Attachments     []string `json:"attachments,omitempty"`
+}
+
+// loadMapContext 从导图仓储读取会话所属导图的当前版本作为对话上下文 不使用客户端提交的导图数据
+// 调用方需先校验用户可访问该导图
+func (a *AiChatService) loadMapContext(ctx context.Context, conversation *entity.Conversation) error {
+	mindMap, err := a.mindMapRepo.GetMindMap(ctx, conversation.MapID)
+	if err != nil {
+		return err
+	}
+	if mindMap == nil {
+		zlog.CtxWarnf(ctx, "会话所属导图不存在或已移入回收站 mapID:%s", conversation.MapID)
+		return MIND_MAP_NOT_EXIST
+	}
+
+	mapJson, err := marshalPromptMindMap(mindMap)
+	if err != nil {
+		return err
+	}
+	conversation.UpdateMapData(mapJson, mindMap.Version)
+	return nil
+}
+
+// marshalPromptMindMap 将导图序列化为提示词中使用的JSON
+func marshalPromptMindMap(mindMap *entity.MindMap) (string, error) {
+	data, err := json.Marshal(&promptMindMap{
+		MapID:  mindMap.MapID,
+		UserID: mindMap.UserID,
+		Title:  mindMap.Title,
+		Desc:   mindMap.Desc,
+		Layout: mindMap.Layout,
+		Root:   newPromptNode(&mindMap.Data),
+	})
+	if err != nil {
+		return "", fmt.Errorf("序列化导图失败: %w", err)
+	}
+	return string(data), nil
+}
+
+// replacePromptMapRoot 用ai修改后的节点树替换提示词导图JSON中的根节点 其余字段保持不变
+func replacePromptMapRoot(mapJson string, root *entity.MindMapData) (string, error) {
+	var mindMap promptMindMap
+	if err := json.Unmarshal([]byte(mapJson), &mindMap); err != nil {
+		return "", fmt.Errorf("解析导图失败: %w", err)
+	}
+	mindMap.Root = newPromptNode(root)
+	data, err := json.Marshal(&mindMap)
+	if err != nil {
+		return "", fmt.Errorf("序列化导图失败: %w", err)
+	}
+	return string(data), nil
+}
+
+func newPromptNode(node *entity.MindMapData) promptNode {
+	children := make([]promptNode, 0, len(node.Children))
+	for i := range node.Children {
+		children = append(children, newPromptNode(&node.Children[i]))
+	}
+	return promptNode{
+		Data:     promptNodeData(node.Data),
+		Children: children,
+	}
+}
@@ -24,6 +24,7 @@ type Message struct {
 	ToolCallID string            `json:"tool_call_id"`
 	ToolCalls  []schema.ToolCall `json:"tool_calls"`
 	Timestamp  time.Time         `json:"timestamp"`
+	MapVersion int64             `json:"map_version,omitempty"` // 该消息所基于的导图版本
 }
 
 type Conversation struct {
@@ -31,13 +32,14 @@ type Conversation struct {
 	UserID         string
 	MapID          string
 	Title          string
-	MapData        string
+	MapData        string // 当前轮对话使用的导图JSON，由服务端从导图仓储读取，不持久化
+	MapVersion     int64  // MapData 对应的导图版本
 	Messages       []*Message
 	CreatedAt      time.Time
 	UpdatedAt      time.Time
 }
 
-func NewConversation(userID, mapID, title, mapData string) (*Conversation, error) {
+func NewConversation(userID, mapID, title string) (*Conversation, error) {
 	newID, err := util.GenerateStringID()
 	if err != nil {
 		return nil, err
@@ -48,7 +50,6 @@ func NewConversation(userID, mapID, title, mapData string) (*Conversation, error
 	messages := make([]*Message, 0)
 
 	return &Conversation{
-		MapData:        mapData,
 		ConversationID: newID,
 		UserID:         userID,
 		MapID:          mapID,
@@ -68,6 +69,7 @@ func (c *Conversation) AddMessage(content, role, ToolCallID string, ToolCalls []
 		ToolCallID: ToolCallID,
 		ToolCalls:  ToolCalls,
 		Timestamp:  now,
+		MapVersion: c.MapVersion,
 	}
 
 	c.Messages = append(c.Messages, message)
@@ -79,22 +81,24 @@ func (c *Conversation) UpdateTitle(title string) {
 	c.Title = title
 }
 
-func (c *Conversation) UpdateMapData(mapData string) {
+func (c *Conversation) UpdateMapData(mapData string, version int64) {
 	c.MapData = mapData
+	c.MapVersion = version
 }
 
 // 处理系统提示词
 func (c *Conversation) ProcessSystemPrompt() {
-	version := len(c.Messages)
+	version := c.MapVersion
 
 	text := fmt.Sprintf(configs.Config().GetAiChatConfig().SystemPrompt, version, version, c.MapData)
 	if len(c.Messages) == 0 {
 		c.AddMessage(text, SYSTEM, "", nil)
 	} else {
 		c.Messages[0] = &Message{
-			Content:   text,
-			Role:      SYSTEM,
-			Timestamp: time.Now(),
+			Content:    text,
+			Role:       SYSTEM,
+			Timestamp:  time.Now(),
+			MapVersion: version,
 		}
 	}
 }
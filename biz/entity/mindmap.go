@@ -3,7 +3,10 @@ package entity
 import (
 	"context"
 	"errors"
+	"net/url"
+	"regexp"
 	"time"
+	"unicode/utf8"
 
 	"forge/pkg/log/zlog"
 
@@ -21,13 +24,32 @@ type MindMap struct {
 	CreatedAt time.Time
 	UpdatedAt time.Time
 	DeletedAt *time.Time
-	// Version   int64 // TODO: 版本字段用于乐观锁，前期注释
+	Version   int64  // 版本号，用于乐观锁，每次更新递增
+	Thumbnail string // 缩略图地址
+	Role      string // 当前用户在导图中的协作角色，仅查询时填充
+	// 当前用户对导图的整理信息，仅查询时填充
+	FolderID string
+	Tags     []string
+	Starred  bool
+	Pinned   bool
+	// 派生导图的来源，直接创建或导入的导图为空
+	Provenance *MindMapProvenance
 }
 
 // NodeData 节点数据值对象
 type NodeData struct {
-	Text string
-	// 可扩展其他节点属性，如颜色、图标等
+	UID             string   // 节点唯一标识，由服务端分配，改名、移动后保持不变
+	Text            string   // 节点文本
+	Note            string   // 备注（markdown）
+	Hyperlink       string   // 超链接地址
+	HyperlinkTitle  string   // 超链接标题
+	Tags            []string // 标签
+	Color           string   // 文字颜色，#RGB 或 #RRGGBB
+	BackgroundColor string   // 背景颜色，#RGB 或 #RRGGBB
+	Icon            string   // 图标标识
+	Priority        int      // 优先级，0 表示未设置
+	Collapsed       bool     // 是否折叠子节点
+	Attachments     []string // 附件标识，附件文件与元数据单独存储
 }
 
 // MindMapData 思维导图数据值对象 - 递归树结构
@@ -65,13 +87,89 @@ func (m *MindMap) Validate() error {
 	if m.Layout == "" {
 		return ErrInvalidLayout
 	}
+	return m.ValidateTree(TreeValidationRulesFromConfig())
+}
+
+// Validate 校验节点属性
+func (n *NodeData) Validate() error {
+	if utf8.RuneCountInString(n.Note) > MaxNodeNoteLength {
+		return ErrNodeNoteTooLong
+	}
+	if n.Hyperlink != "" {
+		if len(n.Hyperlink) > MaxNodeHyperlinkLength {
+			return ErrNodeInvalidHyperlink
+		}
+		u, err := url.Parse(n.Hyperlink)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https" && u.Scheme != "mailto") {
+			return ErrNodeInvalidHyperlink
+		}
+	}
+	if utf8.RuneCountInString(n.HyperlinkTitle) > MaxNodeHyperlinkTitleLength {
+		return ErrNodeHyperlinkTitleTooLong
+	}
+	if len(n.Tags) > MaxNodeTags {
+		return ErrNodeTooManyTags
+	}
+	for _, tag := range n.Tags {
+		if tag == "" || utf8.RuneCountInString(tag) > MaxNodeTagLength {
+			return ErrNodeInvalidTag
+		}
+	}
+	if !isValidNodeColor(n.Color) || !isValidNodeColor(n.BackgroundColor) {
+		return ErrNodeInvalidColor
+	}
+	if len(n.Icon) > MaxNodeIconLength {
+		return ErrNodeInvalidIcon
+	}
+	if n.Priority < 0 || n.Priority > MaxNodePriority {
+		return ErrNodeInvalidPriority
+	}
+	if len(n.Attachments) > MaxNodeAttachments {
+		return ErrNodeTooManyAttachments
+	}
+	for _, attachmentID := range n.Attachments {
+		if attachmentID == "" || len(attachmentID) > MaxNodeAttachmentIDLength {
+			return ErrNodeInvalidAttachment
+		}
+	}
 	return nil
 }
 
+// 节点颜色仅支持十六进制格式，空值表示使用默认样式
+var nodeColorPattern = regexp.MustCompile(`^#([0-9a-fA-F]{3}|[0-9a-fA-F]{6})$`)
+
+func isValidNodeColor(color string) bool {
+	return color == "" || nodeColorPattern.MatchString(color)
+}
+
+// 节点属性限制
+const (
+	MaxNodeNoteLength           = 5000
+	MaxNodeHyperlinkLength      = 2048
+	MaxNodeHyperlinkTitleLength = 100
+	MaxNodeTags                 = 10
+	MaxNodeTagLength            = 20
+	MaxNodeIconLength           = 50
+	MaxNodePriority             = 9
+	MaxNodeAttachments          = 20
+	MaxNodeAttachmentIDLength   = 64
+)
+
 // 错误定义
 var (
 	ErrInvalidTitle  = errors.New("标题不能为空")
 	ErrTitleTooLong  = errors.New("标题长度不能超过100字符")
 	ErrDescTooLong   = errors.New("描述长度不能超过500字符")
 	ErrInvalidLayout = errors.New("布局类型不能为空")
+
+	ErrNodeNoteTooLong           = errors.New("节点备注长度不能超过5000字符")
+	ErrNodeInvalidHyperlink      = errors.New("节点超链接无效，仅支持http、https、mailto")
+	ErrNodeHyperlinkTitleTooLong = errors.New("节点超链接标题长度不能超过100字符")
+	ErrNodeTooManyTags           = errors.New("节点标签数量不能超过10个")
+	ErrNodeInvalidTag            = errors.New("节点标签不能为空且长度不能超过20字符")
+	ErrNodeInvalidColor          = errors.New("节点颜色格式无效，仅支持#RGB或#RRGGBB")
+	ErrNodeInvalidIcon           = errors.New("节点图标标识长度不能超过50字符")
+	ErrNodeInvalidPriority       = errors.New("节点优先级必须在0-9之间")
+	ErrNodeTooManyAttachments    = errors.New("节点附件数量不能超过20个")
+	ErrNodeInvalidAttachment     = errors.New("节点附件标识无效")
 )
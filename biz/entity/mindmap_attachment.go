@@ -0,0 +1,129 @@
+package entity
+
+import (
+	"time"
+
+	"forge/infra/configs"
+)
+
+// 附件类型
+const (
+	AttachmentKindImage    = "image"    // 图片
+	AttachmentKindPDF      = "pdf"      // PDF 文档
+	AttachmentKindDocument = "document" // Office 文档
+	AttachmentKindText     = "text"     // 纯文本、Markdown、CSV
+)
+
+// DefaultAttachmentQuota 每个用户的附件存储配额默认值（1GB）
+const DefaultAttachmentQuota int64 = 1 << 30
+
+// MindMapAttachment 导图节点上的附件，文件存储在 COS 中，节点数据通过附件标识引用
+type MindMapAttachment struct {
+	AttachmentID string
+	MapID        string
+	NodeID       string // 上传时所属的节点，节点删除后附件仍保留，恢复历史版本时可继续引用
+	UserID       string // 上传者，附件大小计入上传者的存储配额
+	FileName     string // 原始文件名
+	ContentType  string
+	Kind         string
+	Size         int64  // 文件大小（字节）
+	ObjectKey    string // COS 存储路径
+	URL          string // 访问地址
+	CreatedAt    time.Time
+}
+
+// ClearAttachments 清空所有节点的附件引用
+// 附件归属于上传时的导图，复制到其他导图或模板的节点不再引用原导图的附件
+func (d *MindMapData) ClearAttachments() {
+	d.Data.Attachments = nil
+	for i := range d.Children {
+		d.Children[i].ClearAttachments()
+	}
+}
+
+// AddAttachment 在节点上追加附件引用
+func (n *NodeData) AddAttachment(attachmentID string) error {
+	if len(n.Attachments) >= MaxNodeAttachments {
+		return ErrNodeTooManyAttachments
+	}
+	n.Attachments = append(n.Attachments, attachmentID)
+	return nil
+}
+
+// RemoveAttachment 移除树中所有节点对附件的引用，返回是否有节点被修改
+func (d *MindMapData) RemoveAttachment(attachmentID string) bool {
+	changed := false
+	for i, id := range d.Data.Attachments {
+		if id == attachmentID {
+			d.Data.Attachments = append(d.Data.Attachments[:i:i], d.Data.Attachments[i+1:]...)
+			changed = true
+			break
+		}
+	}
+	for i := range d.Children {
+		changed = d.Children[i].RemoveAttachment(attachmentID) || changed
+	}
+	return changed
+}
+
+// RetainAttachments 移除树中不满足 keep 的附件引用，返回是否有节点被修改
+func (d *MindMapData) RetainAttachments(keep func(attachmentID string) bool) bool {
+	changed := false
+	if len(d.Data.Attachments) > 0 {
+		kept := d.Data.Attachments[:0:0]
+		for _, id := range d.Data.Attachments {
+			if keep(id) {
+				kept = append(kept, id)
+			}
+		}
+		if len(kept) != len(d.Data.Attachments) {
+			d.Data.Attachments = kept
+			changed = true
+		}
+	}
+	for i := range d.Children {
+		changed = d.Children[i].RetainAttachments(keep) || changed
+	}
+	return changed
+}
+
+// RemapAttachments 按 ids 将树中的附件引用替换为新的附件标识，不在 ids 中的引用保持不变
+func (d *MindMapData) RemapAttachments(ids map[string]string) {
+	if len(d.Data.Attachments) > 0 {
+		remapped := make([]string, len(d.Data.Attachments))
+		for i, id := range d.Data.Attachments {
+			if newID, ok := ids[id]; ok {
+				id = newID
+			}
+			remapped[i] = id
+		}
+		d.Data.Attachments = remapped
+	}
+	for i := range d.Children {
+		d.Children[i].RemapAttachments(ids)
+	}
+}
+
+// AttachmentIDs 树中所有节点引用的附件标识
+func (d *MindMapData) AttachmentIDs() map[string]struct{} {
+	ids := make(map[string]struct{})
+	var walk func(node *MindMapData)
+	walk = func(node *MindMapData) {
+		for _, id := range node.Data.Attachments {
+			ids[id] = struct{}{}
+		}
+		for i := range node.Children {
+			walk(&node.Children[i])
+		}
+	}
+	walk(d)
+	return ids
+}
+
+// AttachmentQuotaFromConfig 从配置读取每个用户的附件存储配额，未配置时使用默认值
+func AttachmentQuotaFromConfig() int64 {
+	if quota := configs.Config().GetMindMapConfig().AttachmentQuota; quota > 0 {
+		return quota
+	}
+	return DefaultAttachmentQuota
+}
@@ -0,0 +1,229 @@
+package entity
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// 实时协作事件类型
+const (
+	CollabEventOp     = "op"     // 节点操作已生效
+	CollabEventJoin   = "join"   // 协作者加入
+	CollabEventLeave  = "leave"  // 协作者离开
+	CollabEventCursor = "cursor" // 协作者光标所在节点变化
+	CollabEventReload = "reload" // 导图通过协作以外的方式被修改，需重新加载
+)
+
+// CollabPresence 实时协作中的在线状态，每个连接一条
+type CollabPresence struct {
+	ConnID       string
+	UserID       string
+	UserName     string
+	Avatar       string
+	Role         string
+	CursorNodeID string // 光标所在节点标识，为空表示未选中节点
+	JoinedAt     time.Time
+	UpdatedAt    time.Time
+}
+
+// CollabEvent 实时协作事件，通过消息通道广播给同一导图的所有连接
+type CollabEvent struct {
+	Type     string
+	MapID    string
+	ConnID   string           // 事件来源连接
+	UserID   string           // 事件来源用户
+	Version  int64            // op/reload 事件对应的导图版本
+	Ops      []MindMapPatchOp // op 事件中已变换并生效的操作
+	Presence *CollabPresence  // join/leave/cursor 事件中的在线状态
+}
+
+// 错误定义
+var (
+	ErrPatchConflict = errors.New("节点已被其他协作者修改")
+)
+
+// RebasePatchOps 将基于 base 版本生成的操作变换到 current 版本上执行，返回变换后的操作与执行结果
+// 协作操作必须通过节点标识定位，插入位置与排序按 base 中的兄弟节点换算，以保留协作者的意图：
+//   - 目标节点已被删除的 delete 视为已生效并丢弃
+//   - 改名以后提交者为准
+//   - 其他操作的目标节点已不存在时返回 ErrPatchConflict
+//
+// 新增的子树在 current 中重新分配重复的节点标识，返回的操作可直接广播给其他协作者
+func RebasePatchOps(base, current MindMapData, ops []MindMapPatchOp) ([]MindMapPatchOp, MindMapData, error) {
+	clientDoc := base.Clone()
+	doc := current.Clone()
+	rebased := make([]MindMapPatchOp, 0, len(ops))
+	for i, op := range ops {
+		if op.NodeID == "" || (op.Op == PatchOpMove && op.ParentID == "") {
+			return nil, MindMapData{}, fmt.Errorf("ops[%d] %s: %w: 协作操作必须指定节点标识", i, op.Op, ErrInvalidPatchOp)
+		}
+
+		transformed, skip, err := rebasePatchOp(&clientDoc, &doc, op)
+		if err != nil {
+			return nil, MindMapData{}, fmt.Errorf("ops[%d] %s: %w", i, op.Op, err)
+		}
+
+		// 操作需要在协作者所见的文档上有效，后续操作基于前面操作执行后的结构
+		// 新增子树使用分配后的标识，使后续操作中的兄弟节点能在两侧对应
+		if transformed.Node != nil {
+			op.Node = transformed.Node
+		}
+		if err := clientDoc.applyPatchOp(op); err != nil {
+			return nil, MindMapData{}, fmt.Errorf("ops[%d] %s: %w", i, op.Op, err)
+		}
+		if skip {
+			continue
+		}
+		if err := doc.applyPatchOp(transformed); err != nil {
+			return nil, MindMapData{}, fmt.Errorf("ops[%d] %s: %w: %w", i, op.Op, ErrPatchConflict, err)
+		}
+		rebased = append(rebased, transformed)
+	}
+	return rebased, doc, nil
+}
+
+// rebasePatchOp 变换单个操作，skip 表示操作在 doc 上已无需执行
+func rebasePatchOp(clientDoc, doc *MindMapData, op MindMapPatchOp) (MindMapPatchOp, bool, error) {
+	transformed := MindMapPatchOp{Op: op.Op, NodeID: op.NodeID, ParentID: op.ParentID, Text: op.Text}
+	if _, ok := doc.FindNodePath(op.NodeID); !ok {
+		if op.Op == PatchOpDelete {
+			return transformed, true, nil
+		}
+		return transformed, false, fmt.Errorf("%w: 节点 %s 不存在", ErrPatchConflict, op.NodeID)
+	}
+
+	switch op.Op {
+	case PatchOpAddChild:
+		if op.Node == nil {
+			return transformed, false, fmt.Errorf("%w: 缺少新增节点", ErrInvalidPatchOp)
+		}
+		index, err := rebaseIndex(clientDoc, doc, op.NodeID, "", op.Index)
+		if err != nil {
+			return transformed, false, err
+		}
+		node := op.Node.Clone()
+		seen := make(map[string]struct{})
+		doc.collectNodeIDs(seen)
+		if _, err := node.ensureNodeIDs(seen); err != nil {
+			return transformed, false, err
+		}
+		transformed.Index = index
+		transformed.Node = &node
+
+	case PatchOpMove:
+		if _, ok := doc.FindNodePath(op.ParentID); !ok {
+			return transformed, false, fmt.Errorf("%w: 节点 %s 不存在", ErrPatchConflict, op.ParentID)
+		}
+		index, err := rebaseIndex(clientDoc, doc, op.ParentID, op.NodeID, op.Index)
+		if err != nil {
+			return transformed, false, err
+		}
+		transformed.Index = index
+
+	case PatchOpReorder:
+		order, err := rebaseOrder(clientDoc, doc, op.NodeID, op.Order)
+		if err != nil {
+			return transformed, false, err
+		}
+		transformed.Order = order
+	}
+	return transformed, false, nil
+}
+
+// rebaseIndex 将 clientDoc 中 parentID 下的插入位置换算为 doc 中的位置
+// 以插入位置前一个兄弟节点为锚点，锚点已不在该父节点下时按原位置截断；moving 为移动中的节点，不参与计算
+func rebaseIndex(clientDoc, doc *MindMapData, parentID, moving string, index *int) (*int, error) {
+	if index == nil {
+		return nil, nil
+	}
+	clientSiblings, err := childIDs(clientDoc, parentID, moving)
+	if err != nil {
+		return nil, err
+	}
+	if *index < 0 || *index > len(clientSiblings) {
+		return nil, fmt.Errorf("%w: 插入位置 %d 越界", ErrInvalidPatchOp, *index)
+	}
+	siblings, err := childIDs(doc, parentID, moving)
+	if err != nil {
+		return nil, err
+	}
+
+	pos := min(*index, len(siblings))
+	if *index == 0 {
+		pos = 0
+	} else if anchor := indexOf(siblings, clientSiblings[*index-1]); anchor >= 0 {
+		pos = anchor + 1
+	}
+	return &pos, nil
+}
+
+// rebaseOrder 将 clientDoc 中 parentID 子节点的排列换算为 doc 中的排列
+// 期间新增的子节点保持原有相对顺序排在末尾，已删除的子节点忽略
+func rebaseOrder(clientDoc, doc *MindMapData, parentID string, order []int) ([]int, error) {
+	clientChildren, err := childIDs(clientDoc, parentID, "")
+	if err != nil {
+		return nil, err
+	}
+	if len(order) != len(clientChildren) {
+		return nil, fmt.Errorf("%w: 排序长度与子节点数量不一致", ErrInvalidPatchOp)
+	}
+	children, err := childIDs(doc, parentID, "")
+	if err != nil {
+		return nil, err
+	}
+
+	placed := make([]bool, len(children))
+	rebased := make([]int, 0, len(children))
+	for _, idx := range order {
+		if idx < 0 || idx >= len(clientChildren) {
+			return nil, fmt.Errorf("%w: 排序 %v 不是有效的排列", ErrInvalidPatchOp, order)
+		}
+		if pos := indexOf(children, clientChildren[idx]); pos >= 0 && !placed[pos] {
+			placed[pos] = true
+			rebased = append(rebased, pos)
+		}
+	}
+	for pos := range children {
+		if !placed[pos] {
+			rebased = append(rebased, pos)
+		}
+	}
+	return rebased, nil
+}
+
+// childIDs 返回节点的子节点标识列表，exclude 对应的子节点不计入
+func childIDs(d *MindMapData, parentID, exclude string) ([]string, error) {
+	path, ok := d.FindNodePath(parentID)
+	if !ok {
+		return nil, fmt.Errorf("%w: 节点 %s 不存在", ErrInvalidPatchOp, parentID)
+	}
+	parent, err := d.nodeAt(path)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]string, 0, len(parent.Children))
+	for _, child := range parent.Children {
+		if child.Data.UID != exclude {
+			ids = append(ids, child.Data.UID)
+		}
+	}
+	return ids, nil
+}
+
+func indexOf(ids []string, id string) int {
+	for i, v := range ids {
+		if v == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// collectNodeIDs 收集子树中所有节点标识
+func (d *MindMapData) collectNodeIDs(seen map[string]struct{}) {
+	seen[d.Data.UID] = struct{}{}
+	for i := range d.Children {
+		d.Children[i].collectNodeIDs(seen)
+	}
+}
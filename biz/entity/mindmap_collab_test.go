@@ -0,0 +1,268 @@
+package entity
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"forge/pkg/log/zlog"
+	"forge/util"
+
+	"go.uber.org/zap"
+)
+
+// collabBase r(a(a1 a2) b(b1) c)，节点文本与标识相同
+func collabBase() MindMapData {
+	node := func(uid string, children ...MindMapData) MindMapData {
+		return MindMapData{Data: NodeData{UID: uid, Text: uid}, Children: children}
+	}
+	return node("r",
+		node("a", node("a1"), node("a2")),
+		node("b", node("b1")),
+		node("c"),
+	)
+}
+
+// outline 以文本输出树结构，如 r(a(a1 a2) b)
+func outline(d MindMapData) string {
+	if len(d.Children) == 0 {
+		return d.Data.Text
+	}
+	parts := make([]string, 0, len(d.Children))
+	for _, child := range d.Children {
+		parts = append(parts, outline(child))
+	}
+	return d.Data.Text + "(" + strings.Join(parts, " ") + ")"
+}
+
+func addOp(parentID, uid string, index *int) MindMapPatchOp {
+	return MindMapPatchOp{Op: PatchOpAddChild, NodeID: parentID, Index: index, Node: &MindMapData{Data: NodeData{UID: uid, Text: uid}}}
+}
+
+func moveOp(nodeID, parentID string, index *int) MindMapPatchOp {
+	return MindMapPatchOp{Op: PatchOpMove, NodeID: nodeID, ParentID: parentID, Index: index}
+}
+
+func renameOp(nodeID, text string) MindMapPatchOp {
+	return MindMapPatchOp{Op: PatchOpRename, NodeID: nodeID, Text: &text}
+}
+
+func deleteOp(nodeID string) MindMapPatchOp {
+	return MindMapPatchOp{Op: PatchOpDelete, NodeID: nodeID}
+}
+
+func intPtr(v int) *int {
+	return &v
+}
+
+// TestRebasePatchOps 两个协作者基于同一版本并发提交，first 先生效，second 变换到 first 之后的版本上执行
+func TestRebasePatchOps(t *testing.T) {
+	zlog.InitLogger(zap.NewNop())
+	if err := util.InitSnowflake(1); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		first    []MindMapPatchOp
+		second   []MindMapPatchOp
+		want     string
+		wantErr  error
+		commutes bool // 交换提交顺序后结果相同
+	}{
+		{
+			name:   "add/add same position",
+			first:  []MindMapPatchOp{addOp("a", "x", intPtr(0))},
+			second: []MindMapPatchOp{addOp("a", "y", intPtr(0))},
+			want:   "r(a(y x a1 a2) b(b1) c)",
+		},
+		{
+			name:   "add/add after same anchor",
+			first:  []MindMapPatchOp{addOp("a", "x", intPtr(1))},
+			second: []MindMapPatchOp{addOp("a", "y", intPtr(1))},
+			want:   "r(a(a1 y x a2) b(b1) c)",
+		},
+		{
+			name:     "add/add different parents",
+			first:    []MindMapPatchOp{addOp("a", "x", nil)},
+			second:   []MindMapPatchOp{addOp("b", "y", intPtr(0))},
+			want:     "r(a(a1 a2 x) b(y b1) c)",
+			commutes: true,
+		},
+		{
+			name:   "add/add duplicate uid is re-keyed",
+			first:  []MindMapPatchOp{addOp("a", "x", nil)},
+			second: []MindMapPatchOp{addOp("b", "x", nil)},
+			want:   "r(a(a1 a2 x) b(b1 x) c)",
+		},
+		{
+			name:   "add after anchor moved away",
+			first:  []MindMapPatchOp{moveOp("a1", "c", nil)},
+			second: []MindMapPatchOp{addOp("a", "y", intPtr(1))},
+			want:   "r(a(a2 y) b(b1) c(a1))",
+		},
+		{
+			name:    "add under deleted parent",
+			first:   []MindMapPatchOp{deleteOp("a")},
+			second:  []MindMapPatchOp{addOp("a", "y", nil)},
+			wantErr: ErrPatchConflict,
+		},
+		{
+			name:     "add/delete different subtrees",
+			first:    []MindMapPatchOp{deleteOp("b")},
+			second:   []MindMapPatchOp{addOp("a", "y", intPtr(0))},
+			want:     "r(a(y a1 a2) c)",
+			commutes: true,
+		},
+		{
+			name:     "delete/delete same node",
+			first:    []MindMapPatchOp{deleteOp("a1")},
+			second:   []MindMapPatchOp{deleteOp("a1")},
+			want:     "r(a(a2) b(b1) c)",
+			commutes: true,
+		},
+		{
+			name:     "delete ancestor/delete descendant",
+			first:    []MindMapPatchOp{deleteOp("a")},
+			second:   []MindMapPatchOp{deleteOp("a1")},
+			want:     "r(b(b1) c)",
+			commutes: true,
+		},
+		{
+			name:    "delete/rename descendant",
+			first:   []MindMapPatchOp{deleteOp("a")},
+			second:  []MindMapPatchOp{renameOp("a1", "z")},
+			wantErr: ErrPatchConflict,
+		},
+		{
+			name:   "rename/rename same node keeps later",
+			first:  []MindMapPatchOp{renameOp("a", "x")},
+			second: []MindMapPatchOp{renameOp("a", "y")},
+			want:   "r(y(a1 a2) b(b1) c)",
+		},
+		{
+			name:     "rename/rename different nodes",
+			first:    []MindMapPatchOp{renameOp("a", "x")},
+			second:   []MindMapPatchOp{renameOp("b", "y")},
+			want:     "r(x(a1 a2) y(b1) c)",
+			commutes: true,
+		},
+		{
+			name:     "rename/move same node",
+			first:    []MindMapPatchOp{renameOp("a1", "x")},
+			second:   []MindMapPatchOp{moveOp("a1", "b", intPtr(0))},
+			want:     "r(a(a2) b(x b1) c)",
+			commutes: true,
+		},
+		{
+			name:   "move/move same node keeps later",
+			first:  []MindMapPatchOp{moveOp("a1", "b", nil)},
+			second: []MindMapPatchOp{moveOp("a1", "c", nil)},
+			want:   "r(a(a2) b(b1) c(a1))",
+		},
+		{
+			name:     "move/move different nodes",
+			first:    []MindMapPatchOp{moveOp("a1", "c", nil)},
+			second:   []MindMapPatchOp{moveOp("b1", "a", intPtr(0))},
+			want:     "r(a(b1 a2) b c(a1))",
+			commutes: true,
+		},
+		{
+			name:    "move into deleted parent",
+			first:   []MindMapPatchOp{deleteOp("b")},
+			second:  []MindMapPatchOp{moveOp("a1", "b", nil)},
+			wantErr: ErrPatchConflict,
+		},
+		{
+			name:    "move deleted node",
+			first:   []MindMapPatchOp{deleteOp("a1")},
+			second:  []MindMapPatchOp{moveOp("a1", "c", nil)},
+			wantErr: ErrPatchConflict,
+		},
+		{
+			name:    "move/move into each other",
+			first:   []MindMapPatchOp{moveOp("b", "a1", nil)},
+			second:  []MindMapPatchOp{moveOp("a", "b", nil)},
+			wantErr: ErrPatchConflict,
+		},
+		{
+			name:   "reorder/add",
+			first:  []MindMapPatchOp{addOp("a", "y", nil)},
+			second: []MindMapPatchOp{{Op: PatchOpReorder, NodeID: "a", Order: []int{1, 0}}},
+			want:   "r(a(a2 a1 y) b(b1) c)",
+		},
+		{
+			name:   "add then move new node in one batch",
+			first:  []MindMapPatchOp{addOp("a", "x", intPtr(0))},
+			second: []MindMapPatchOp{addOp("b", "y", nil), moveOp("y", "a", intPtr(1))},
+			want:   "r(a(x a1 y a2) b(b1) c)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := rebaseConcurrent(t, tt.first, tt.second)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("err = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected err: %v", err)
+			}
+			if outline(got) != tt.want {
+				t.Errorf("result = %s, want %s", outline(got), tt.want)
+			}
+
+			if tt.commutes {
+				swapped, err := rebaseConcurrent(t, tt.second, tt.first)
+				if err != nil {
+					t.Fatalf("swapped: unexpected err: %v", err)
+				}
+				if outline(swapped) != outline(got) {
+					t.Errorf("swapped result = %s, want %s", outline(swapped), outline(got))
+				}
+			}
+		})
+	}
+}
+
+// rebaseConcurrent first 直接在 base 上生效，second 变换后执行
+// 其他协作者在 first 之后的版本上执行广播的操作，结果需与服务端一致，且节点标识不重复
+func rebaseConcurrent(t *testing.T, first, second []MindMapPatchOp) (MindMapData, error) {
+	t.Helper()
+	base := collabBase()
+	current := base.Clone()
+	if err := current.ApplyPatch(first); err != nil {
+		t.Fatalf("apply first: %v", err)
+	}
+
+	rebased, doc, err := RebasePatchOps(base, current, second)
+	if err != nil {
+		return MindMapData{}, err
+	}
+
+	peer := current.Clone()
+	if err := peer.ApplyPatch(rebased); err != nil {
+		t.Fatalf("apply rebased ops on peer: %v", err)
+	}
+	if !reflect.DeepEqual(peer, doc) {
+		t.Errorf("peer diverged: %s, server %s", outline(peer), outline(doc))
+	}
+
+	seen := make(map[string]struct{})
+	var check func(d MindMapData)
+	check = func(d MindMapData) {
+		if _, dup := seen[d.Data.UID]; dup {
+			t.Errorf("duplicate uid %s in %s", d.Data.UID, outline(doc))
+		}
+		seen[d.Data.UID] = struct{}{}
+		for _, child := range d.Children {
+			check(child)
+		}
+	}
+	check(doc)
+	return doc, nil
+}
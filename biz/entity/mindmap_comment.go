@@ -0,0 +1,75 @@
+package entity
+
+import (
+	"errors"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// MindMapComment 导图节点上的评论，不修改导图内容
+// 线程的首条评论 ParentID 为空，回复的 ParentID 为首条评论，回复不再嵌套
+type MindMapComment struct {
+	CommentID  string
+	MapID      string
+	NodeID     string // 评论的节点标识，回复与首条评论相同
+	ParentID   string // 所属线程的首条评论，首条评论为空
+	UserID     string // 作者
+	Content    string
+	Mentions   []string   // 被@的用户ID
+	Resolved   bool       // 线程是否已解决，仅首条评论有效
+	ResolvedBy string     // 解决线程的用户
+	ResolvedAt *time.Time // 解决时间
+	EditedAt   *time.Time // 最后编辑时间，未编辑过为空
+	CreatedAt  time.Time
+
+	// 作者的用户信息，查询时填充
+	UserName string
+	Avatar   string
+}
+
+// MindMapCommentThread 评论线程，回复按时间顺序排列
+type MindMapCommentThread struct {
+	Comment *MindMapComment
+	Replies []*MindMapComment
+}
+
+// 评论限制
+const (
+	MaxMindMapCommentLength   = 2000
+	MaxMindMapCommentMentions = 20
+)
+
+// 错误定义
+var (
+	ErrInvalidCommentContent = errors.New("评论内容不能为空且长度不能超过2000字符")
+	ErrTooManyMentions       = errors.New("单条评论最多@20位用户")
+)
+
+// IsThread 是否为线程的首条评论
+func (c *MindMapComment) IsThread() bool {
+	return c.ParentID == ""
+}
+
+// Validate 校验评论内容与@的用户，去除首尾空白与重复的@
+func (c *MindMapComment) Validate() error {
+	c.Content = strings.TrimSpace(c.Content)
+	if c.Content == "" || utf8.RuneCountInString(c.Content) > MaxMindMapCommentLength {
+		return ErrInvalidCommentContent
+	}
+
+	seen := make(map[string]struct{}, len(c.Mentions))
+	mentions := make([]string, 0, len(c.Mentions))
+	for _, userID := range c.Mentions {
+		if _, dup := seen[userID]; userID == "" || dup {
+			continue
+		}
+		seen[userID] = struct{}{}
+		mentions = append(mentions, userID)
+	}
+	if len(mentions) > MaxMindMapCommentMentions {
+		return ErrTooManyMentions
+	}
+	c.Mentions = mentions
+	return nil
+}
@@ -0,0 +1,275 @@
+package entity
+
+import (
+	"slices"
+	"sort"
+	"strings"
+)
+
+// 节点变更类型
+const (
+	DiffAdded   = "added"   // 新增节点
+	DiffRemoved = "removed" // 删除节点
+	DiffRenamed = "renamed" // 节点文本变化
+	DiffMoved   = "moved"   // 父节点变化或在兄弟节点间的相对顺序变化
+	DiffUpdated = "updated" // 备注、链接、标签、样式、附件等其他属性变化
+)
+
+// MindMapNodeChange 单个节点的变更，同一节点可能同时出现改名、移动与属性变化
+type MindMapNodeChange struct {
+	Type        string
+	NodeID      string // 节点标识，新增节点未分配标识时为空
+	Text        string // 变更后的文本，删除的节点为原文本
+	OldText     string // renamed 时的原文本
+	ParentID    string // 变更后的父节点标识
+	OldParentID string // 变更前的父节点标识
+	Path        []int  // 节点在新树中的路径，删除的节点为空
+	OldPath     []int  // 节点在原树中的路径，新增的节点为空
+}
+
+// MindMapDiffSummary 各类变更的节点数量
+type MindMapDiffSummary struct {
+	Added   int
+	Removed int
+	Renamed int
+	Moved   int
+	Updated int
+}
+
+// MindMapDiff 两棵导图树之间的结构差异
+type MindMapDiff struct {
+	Changes []MindMapNodeChange // 先按新树先序列出新增与修改，再按原树先序列出删除
+	Summary MindMapDiffSummary
+}
+
+// Empty 两棵树是否没有差异
+func (d *MindMapDiff) Empty() bool {
+	return len(d.Changes) == 0
+}
+
+// diffNode 参与比较的节点及其在树中的位置
+type diffNode struct {
+	node   *MindMapData
+	parent *diffNode
+	path   []int
+	match  *diffNode // 另一棵树中匹配的节点
+}
+
+// diffTextKey 按文本匹配时的分组键
+type diffTextKey struct {
+	parent *diffNode
+	text   string
+}
+
+// DiffMindMapData 比较两棵导图树
+// 节点优先按标识匹配；新树中没有标识或标识在原树中不存在的节点（例如 AI 新增的节点），
+// 在父节点已匹配时按文本（忽略首尾空白）匹配原父节点下尚未匹配的子节点，均未匹配的视为新增
+func DiffMindMapData(oldData, newData *MindMapData) *MindMapDiff {
+	oldNodes := flattenDiffNodes(oldData)
+	newNodes := flattenDiffNodes(newData)
+
+	// 按标识匹配，重复的标识只匹配首次出现的节点
+	oldByUID := make(map[string]*diffNode, len(oldNodes))
+	for _, n := range oldNodes {
+		if uid := n.node.Data.UID; uid != "" {
+			if _, dup := oldByUID[uid]; !dup {
+				oldByUID[uid] = n
+			}
+		}
+	}
+	for _, n := range newNodes {
+		if o, ok := oldByUID[n.node.Data.UID]; ok && o.match == nil {
+			n.match, o.match = o, n
+		}
+	}
+
+	// 原树节点按（父节点、文本）分组，同组内保持先序，文本匹配时取组内首个尚未匹配的节点
+	oldByText := make(map[diffTextKey][]*diffNode)
+	for _, o := range oldNodes {
+		if o.parent != nil && o.match == nil {
+			key := diffTextKey{parent: o.parent, text: strings.TrimSpace(o.node.Data.Text)}
+			oldByText[key] = append(oldByText[key], o)
+		}
+	}
+
+	// 先序遍历保证父节点先于子节点完成匹配
+	for _, n := range newNodes {
+		if n.match != nil {
+			continue
+		}
+		if n.parent == nil {
+			if root := oldNodes[0]; root.match == nil {
+				n.match, root.match = root, n
+			}
+			continue
+		}
+		if n.parent.match == nil {
+			continue
+		}
+		key := diffTextKey{parent: n.parent.match, text: strings.TrimSpace(n.node.Data.Text)}
+		candidates := oldByText[key]
+		for len(candidates) > 0 && candidates[0].match != nil {
+			candidates = candidates[1:]
+		}
+		if len(candidates) > 0 {
+			n.match, candidates[0].match = candidates[0], n
+			candidates = candidates[1:]
+		}
+		oldByText[key] = candidates
+	}
+
+	moved := reorderedDiffNodes(newNodes)
+	diff := &MindMapDiff{}
+	for _, n := range newNodes {
+		change := MindMapNodeChange{
+			NodeID:   n.uid(),
+			Text:     n.node.Data.Text,
+			ParentID: n.parentUID(),
+			Path:     n.path,
+		}
+		o := n.match
+		if o == nil {
+			change.Type = DiffAdded
+			diff.add(change)
+			continue
+		}
+		change.OldParentID, change.OldPath = o.parentUID(), o.path
+
+		if n.node.Data.Text != o.node.Data.Text {
+			renamed := change
+			renamed.Type, renamed.OldText = DiffRenamed, o.node.Data.Text
+			diff.add(renamed)
+		}
+		if n.parent != nil && (n.parent.match != o.parent || moved[n]) {
+			change.Type = DiffMoved
+			diff.add(change)
+		}
+		if !sameNodeAttributes(&n.node.Data, &o.node.Data) {
+			change.Type = DiffUpdated
+			diff.add(change)
+		}
+	}
+	for _, o := range oldNodes {
+		if o.match == nil {
+			diff.add(MindMapNodeChange{
+				Type:        DiffRemoved,
+				NodeID:      o.node.Data.UID,
+				Text:        o.node.Data.Text,
+				OldParentID: o.parentUID(),
+				OldPath:     o.path,
+			})
+		}
+	}
+	return diff
+}
+
+func (d *MindMapDiff) add(change MindMapNodeChange) {
+	d.Changes = append(d.Changes, change)
+	switch change.Type {
+	case DiffAdded:
+		d.Summary.Added++
+	case DiffRemoved:
+		d.Summary.Removed++
+	case DiffRenamed:
+		d.Summary.Renamed++
+	case DiffMoved:
+		d.Summary.Moved++
+	case DiffUpdated:
+		d.Summary.Updated++
+	}
+}
+
+// uid 节点标识，新树中未带标识的节点使用匹配到的原节点标识
+func (n *diffNode) uid() string {
+	if n.node.Data.UID == "" && n.match != nil {
+		return n.match.node.Data.UID
+	}
+	return n.node.Data.UID
+}
+
+func (n *diffNode) parentUID() string {
+	if n.parent == nil {
+		return ""
+	}
+	return n.parent.uid()
+}
+
+// flattenDiffNodes 按先序展开整棵树
+func flattenDiffNodes(root *MindMapData) []*diffNode {
+	var nodes []*diffNode
+	var walk func(node *MindMapData, parent *diffNode, path []int)
+	walk = func(node *MindMapData, parent *diffNode, path []int) {
+		n := &diffNode{node: node, parent: parent, path: path}
+		nodes = append(nodes, n)
+		for i := range node.Children {
+			walk(&node.Children[i], n, append(slices.Clone(path), i))
+		}
+	}
+	walk(root, nil, []int{})
+	return nodes
+}
+
+// reorderedDiffNodes 找出父节点未变但相对顺序发生变化的节点
+// 同一父节点下仍保留的子节点中，原顺序的最长递增子序列视为未移动，其余节点视为移动，
+// 因此兄弟节点的增删导致的下标变化不会被当作移动
+func reorderedDiffNodes(newNodes []*diffNode) map[*diffNode]bool {
+	moved := make(map[*diffNode]bool)
+	children := make(map[*diffNode][]*diffNode)
+	for _, n := range newNodes {
+		if n.parent != nil && n.match != nil && n.parent.match != nil && n.parent.match == n.match.parent {
+			children[n.parent] = append(children[n.parent], n)
+		}
+	}
+	for _, siblings := range children {
+		oldIndexes := make([]int, len(siblings))
+		for i, n := range siblings {
+			oldIndexes[i] = n.match.path[len(n.match.path)-1]
+		}
+		kept := longestIncreasing(oldIndexes)
+		for i, n := range siblings {
+			if !kept[i] {
+				moved[n] = true
+			}
+		}
+	}
+	return moved
+}
+
+// longestIncreasing 返回严格递增的最长子序列所包含的下标
+func longestIncreasing(values []int) map[int]bool {
+	tails := make([]int, 0, len(values)) // tails[k] 为长度 k+1 的递增子序列末尾元素的下标
+	prev := make([]int, len(values))
+	for i, v := range values {
+		k := sort.Search(len(tails), func(j int) bool { return values[tails[j]] >= v })
+		prev[i] = -1
+		if k > 0 {
+			prev[i] = tails[k-1]
+		}
+		if k == len(tails) {
+			tails = append(tails, i)
+		} else {
+			tails[k] = i
+		}
+	}
+
+	kept := make(map[int]bool, len(tails))
+	if len(tails) > 0 {
+		for i := tails[len(tails)-1]; i >= 0; i = prev[i] {
+			kept[i] = true
+		}
+	}
+	return kept
+}
+
+// sameNodeAttributes 比较文本与标识以外的节点属性，折叠状态属于界面状态，不视为变化
+func sameNodeAttributes(a, b *NodeData) bool {
+	return a.Note == b.Note &&
+		a.Hyperlink == b.Hyperlink &&
+		a.HyperlinkTitle == b.HyperlinkTitle &&
+		slices.Equal(a.Tags, b.Tags) &&
+		slices.Equal(a.Attachments, b.Attachments) &&
+		a.Color == b.Color &&
+		a.BackgroundColor == b.BackgroundColor &&
+		a.Icon == b.Icon &&
+		a.Priority == b.Priority
+}
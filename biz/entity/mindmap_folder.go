@@ -0,0 +1,73 @@
+package entity
+
+import (
+	"errors"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// MindMapFolder 用户用于整理导图的文件夹，可多级嵌套，每个用户独立
+type MindMapFolder struct {
+	FolderID  string
+	UserID    string
+	ParentID  string // 上级文件夹，顶层为空
+	Name      string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// MindMapTagCount 用户使用的导图标签及对应的导图数量
+type MindMapTagCount struct {
+	Tag   string
+	Count int64
+}
+
+// MindMapRootFolder 表示顶层（未归入任何文件夹）的文件夹标识，用于筛选与移动
+const MindMapRootFolder = "root"
+
+// 文件夹与导图标签限制
+const (
+	MaxMindMapFolderNameLength = 50
+	MaxMindMapFolderDepth      = 10
+	MaxMindMapFolders          = 200
+	MaxMindMapTags             = 20
+	MaxMindMapTagLength        = 20
+)
+
+// 错误定义
+var (
+	ErrInvalidFolderName = errors.New("文件夹名称不能为空且长度不能超过50字符")
+	ErrInvalidMindMapTag = errors.New("导图标签不能为空且长度不能超过20字符")
+	ErrTooManyMindMapTag = errors.New("导图标签数量不能超过20个")
+)
+
+// Validate 校验文件夹
+func (f *MindMapFolder) Validate() error {
+	if f.Name == "" || utf8.RuneCountInString(f.Name) > MaxMindMapFolderNameLength {
+		return ErrInvalidFolderName
+	}
+	return nil
+}
+
+// NormalizeMindMapTags 去除标签首尾空白并去重（不区分大小写，与数据库排序规则一致），保持原有顺序
+func NormalizeMindMapTags(tags []string) ([]string, error) {
+	seen := make(map[string]struct{}, len(tags))
+	normalized := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		tag = strings.TrimSpace(tag)
+		if tag == "" || utf8.RuneCountInString(tag) > MaxMindMapTagLength {
+			return nil, ErrInvalidMindMapTag
+		}
+		key := strings.ToLower(tag)
+		if _, dup := seen[key]; dup {
+			continue
+		}
+		seen[key] = struct{}{}
+		normalized = append(normalized, tag)
+	}
+	if len(normalized) > MaxMindMapTags {
+		return nil, ErrTooManyMindMapTag
+	}
+	return normalized, nil
+}
@@ -0,0 +1,45 @@
+package entity
+
+import "time"
+
+// 思维导图协作角色，权限依次递增
+const (
+	MindMapRoleViewer = "viewer" // 只读
+	MindMapRoleEditor = "editor" // 可编辑导图内容
+	MindMapRoleOwner  = "owner"  // 所有者，可管理协作成员、分享链接及删除导图
+)
+
+var mindMapRoleLevels = map[string]int{
+	MindMapRoleViewer: 1,
+	MindMapRoleEditor: 2,
+	MindMapRoleOwner:  3,
+}
+
+// MindMapMember 思维导图协作成员，所有者同样作为成员记录
+type MindMapMember struct {
+	MapID     string
+	UserID    string
+	Role      string
+	InvitedBy string // 邀请人，所有者为空
+	FolderID  string // 成员将导图归入的文件夹，顶层为空
+	StarredAt *time.Time
+	PinnedAt  *time.Time
+	CreatedAt time.Time
+	UpdatedAt time.Time
+
+	// 成员的用户信息，仅查询成员列表时填充
+	UserName string
+	Avatar   string
+}
+
+// IsValidMindMapRole 是否为合法的协作角色
+func IsValidMindMapRole(role string) bool {
+	_, ok := mindMapRoleLevels[role]
+	return ok
+}
+
+// MindMapRoleAllows 判断角色 role 是否具备 required 角色的权限
+func MindMapRoleAllows(role, required string) bool {
+	level, ok := mindMapRoleLevels[role]
+	return ok && level >= mindMapRoleLevels[required]
+}
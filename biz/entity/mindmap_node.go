@@ -0,0 +1,126 @@
+package entity
+
+import (
+	"forge/util"
+)
+
+// EnsureNodeIDs 为缺少标识或标识重复的节点分配新的唯一标识，返回是否有节点被修改
+// 已有且唯一的标识保持不变，保证节点在改名、移动后仍可被稳定定位
+func (d *MindMapData) EnsureNodeIDs() (bool, error) {
+	return d.ensureNodeIDs(make(map[string]struct{}))
+}
+
+// EnsureNodeIDsFrom 与 EnsureNodeIDs 相同，但标识重复时参照修改前的树 prev 决定由哪个节点保留原标识
+// 重复的标识在 prev 中存在时，由父节点标识与文本和 prev 中该节点最接近的节点保留（父节点相同优先于文本相同），
+// 其余节点视为新传入的节点并分配新标识，保证已有节点的标识不会因客户端传入重复标识而改变；
+// prev 为空或 prev 中不存在该标识时由先序遍历中首次出现的节点保留
+func (d *MindMapData) EnsureNodeIDsFrom(prev *MindMapData) (bool, error) {
+	occurrences := make(map[string][]nodeOccurrence)
+	d.collectOccurrences("", occurrences)
+
+	prevNodes := make(map[string][]nodeOccurrence)
+	if prev != nil {
+		prev.collectOccurrences("", prevNodes)
+	}
+
+	cleared := false
+	for uid, occs := range occurrences {
+		if len(occs) < 2 {
+			continue
+		}
+		keeper := 0
+		if prevOccs, ok := prevNodes[uid]; ok {
+			p := prevOccs[0]
+			best := -1
+			for i, o := range occs {
+				score := 0
+				if o.parentUID == p.parentUID {
+					score += 2
+				}
+				if o.node.Data.Text == p.node.Data.Text {
+					score++
+				}
+				if score > best {
+					best, keeper = score, i
+				}
+			}
+		}
+		// 清空其余节点的标识，由 EnsureNodeIDs 重新分配
+		for i, o := range occs {
+			if i != keeper {
+				o.node.Data.UID = ""
+				cleared = true
+			}
+		}
+	}
+
+	changed, err := d.EnsureNodeIDs()
+	return cleared || changed, err
+}
+
+// nodeOccurrence 节点及其父节点标识
+type nodeOccurrence struct {
+	node      *MindMapData
+	parentUID string
+}
+
+// collectOccurrences 按先序收集带标识的节点
+func (d *MindMapData) collectOccurrences(parentUID string, occurrences map[string][]nodeOccurrence) {
+	if d.Data.UID != "" {
+		occurrences[d.Data.UID] = append(occurrences[d.Data.UID], nodeOccurrence{node: d, parentUID: parentUID})
+	}
+	for i := range d.Children {
+		d.Children[i].collectOccurrences(d.Data.UID, occurrences)
+	}
+}
+
+func (d *MindMapData) ensureNodeIDs(seen map[string]struct{}) (bool, error) {
+	changed := false
+	if _, dup := seen[d.Data.UID]; d.Data.UID == "" || dup {
+		uid, err := util.GenerateStringID()
+		if err != nil {
+			return false, err
+		}
+		d.Data.UID = uid
+		changed = true
+	}
+	seen[d.Data.UID] = struct{}{}
+
+	for i := range d.Children {
+		childChanged, err := d.Children[i].ensureNodeIDs(seen)
+		if err != nil {
+			return false, err
+		}
+		changed = changed || childChanged
+	}
+	return changed, nil
+}
+
+// FindNodePath 根据节点标识查找节点路径，路径为从根节点开始逐层的子节点下标
+func (d *MindMapData) FindNodePath(uid string) ([]int, bool) {
+	if uid == "" {
+		return nil, false
+	}
+	if d.Data.UID == uid {
+		return []int{}, true
+	}
+	for i := range d.Children {
+		if path, ok := d.Children[i].FindNodePath(uid); ok {
+			return append([]int{i}, path...), true
+		}
+	}
+	return nil, false
+}
+
+// FindNode 根据节点标识查找节点
+func (d *MindMapData) FindNode(uid string) (*MindMapData, bool) {
+	path, ok := d.FindNodePath(uid)
+	if !ok {
+		return nil, false
+	}
+	node, err := d.nodeAt(path)
+	if err != nil {
+		return nil, false
+	}
+	return node, true
+}
@@ -0,0 +1,228 @@
+package entity
+
+import (
+	"errors"
+	"fmt"
+)
+
+// 补丁操作类型
+const (
+	PatchOpAddChild = "add_child" // 在父节点下添加子节点
+	PatchOpDelete   = "delete"    // 删除节点及其子树
+	PatchOpMove     = "move"      // 移动节点到新的父节点下
+	PatchOpRename   = "rename"    // 修改节点文本
+	PatchOpReorder  = "reorder"   // 调整子节点顺序
+)
+
+// MindMapPatchOp 思维导图节点级补丁操作
+// 节点优先通过标识定位；未提供标识时通过路径定位，路径为从根节点开始逐层的子节点下标，空路径表示根节点
+// 多个操作按顺序执行，后续操作的路径基于前面操作执行后的树结构
+type MindMapPatchOp struct {
+	Op         string
+	NodeID     string       // 目标节点标识（add_child/reorder 时为父节点），优先于 Path
+	Path       []int        // 目标节点路径（add_child/reorder 时为父节点）
+	ParentID   string       // move 时的新父节点标识，优先于 ParentPath
+	ParentPath []int        // move 时的新父节点路径（基于移动前的树结构）
+	Index      *int         // add_child/move 时的插入位置，为空表示追加到末尾
+	Node       *MindMapData // add_child 时新增的子树
+	Text       *string      // rename 时的新文本
+	Order      []int        // reorder 时子节点的新顺序（原下标的排列）
+}
+
+// 错误定义
+var (
+	ErrInvalidPatchOp = errors.New("无效的节点操作")
+)
+
+// Clone 深拷贝思维导图数据，补丁在副本上执行，失败时不影响原数据
+func (d MindMapData) Clone() MindMapData {
+	clone := MindMapData{Data: d.Data}
+	if d.Data.Tags != nil {
+		clone.Data.Tags = append([]string(nil), d.Data.Tags...)
+	}
+	if d.Data.Attachments != nil {
+		clone.Data.Attachments = append([]string(nil), d.Data.Attachments...)
+	}
+	if d.Children != nil {
+		clone.Children = make([]MindMapData, len(d.Children))
+		for i, child := range d.Children {
+			clone.Children[i] = child.Clone()
+		}
+	}
+	return clone
+}
+
+// ApplyPatch 按顺序执行补丁操作，任意操作失败即返回错误
+// 调用方应在副本上执行，以保证整体原子性
+func (d *MindMapData) ApplyPatch(ops []MindMapPatchOp) error {
+	for i, op := range ops {
+		if err := d.applyPatchOp(op); err != nil {
+			return fmt.Errorf("ops[%d] %s: %w", i, op.Op, err)
+		}
+	}
+	return nil
+}
+
+func (d *MindMapData) applyPatchOp(op MindMapPatchOp) error {
+	// 将节点标识解析为当前树中的路径
+	if op.NodeID != "" {
+		path, ok := d.FindNodePath(op.NodeID)
+		if !ok {
+			return fmt.Errorf("%w: 节点 %s 不存在", ErrInvalidPatchOp, op.NodeID)
+		}
+		op.Path = path
+	}
+	if op.ParentID != "" {
+		path, ok := d.FindNodePath(op.ParentID)
+		if !ok {
+			return fmt.Errorf("%w: 节点 %s 不存在", ErrInvalidPatchOp, op.ParentID)
+		}
+		op.ParentPath = path
+	}
+
+	switch op.Op {
+	case PatchOpAddChild:
+		if op.Node == nil {
+			return fmt.Errorf("%w: 缺少新增节点", ErrInvalidPatchOp)
+		}
+		parent, err := d.nodeAt(op.Path)
+		if err != nil {
+			return err
+		}
+		return parent.insertChild(op.Node.Clone(), op.Index)
+
+	case PatchOpDelete:
+		if len(op.Path) == 0 {
+			return fmt.Errorf("%w: 不能删除根节点", ErrInvalidPatchOp)
+		}
+		_, err := d.detach(op.Path)
+		return err
+
+	case PatchOpMove:
+		if len(op.Path) == 0 {
+			return fmt.Errorf("%w: 不能移动根节点", ErrInvalidPatchOp)
+		}
+		if isPathPrefix(op.Path, op.ParentPath) {
+			return fmt.Errorf("%w: 不能将节点移动到自身或其子节点下", ErrInvalidPatchOp)
+		}
+		// 先校验目标父节点存在，避免摘除后才发现失败
+		if _, err := d.nodeAt(op.ParentPath); err != nil {
+			return err
+		}
+		node, err := d.detach(op.Path)
+		if err != nil {
+			return err
+		}
+		// 摘除节点后，同一父节点下排在其后的兄弟下标前移一位
+		parent, err := d.nodeAt(shiftPathAfterRemoval(op.ParentPath, op.Path))
+		if err != nil {
+			return err
+		}
+		return parent.insertChild(node, op.Index)
+
+	case PatchOpRename:
+		if op.Text == nil {
+			return fmt.Errorf("%w: 缺少节点文本", ErrInvalidPatchOp)
+		}
+		node, err := d.nodeAt(op.Path)
+		if err != nil {
+			return err
+		}
+		node.Data.Text = *op.Text
+		return nil
+
+	case PatchOpReorder:
+		parent, err := d.nodeAt(op.Path)
+		if err != nil {
+			return err
+		}
+		return parent.reorderChildren(op.Order)
+
+	default:
+		return fmt.Errorf("%w: 未知的操作类型 %q", ErrInvalidPatchOp, op.Op)
+	}
+}
+
+// nodeAt 根据路径定位节点
+func (d *MindMapData) nodeAt(path []int) (*MindMapData, error) {
+	node := d
+	for depth, idx := range path {
+		if idx < 0 || idx >= len(node.Children) {
+			return nil, fmt.Errorf("%w: 路径 %v 在第%d层越界", ErrInvalidPatchOp, path, depth)
+		}
+		node = &node.Children[idx]
+	}
+	return node, nil
+}
+
+// detach 从树中摘除路径指向的节点并返回该节点
+func (d *MindMapData) detach(path []int) (MindMapData, error) {
+	parent, err := d.nodeAt(path[:len(path)-1])
+	if err != nil {
+		return MindMapData{}, err
+	}
+	idx := path[len(path)-1]
+	if idx < 0 || idx >= len(parent.Children) {
+		return MindMapData{}, fmt.Errorf("%w: 路径 %v 越界", ErrInvalidPatchOp, path)
+	}
+	node := parent.Children[idx]
+	parent.Children = append(parent.Children[:idx], parent.Children[idx+1:]...)
+	return node, nil
+}
+
+// insertChild 在指定位置插入子节点，index 为空时追加到末尾
+func (d *MindMapData) insertChild(child MindMapData, index *int) error {
+	pos := len(d.Children)
+	if index != nil {
+		pos = *index
+	}
+	if pos < 0 || pos > len(d.Children) {
+		return fmt.Errorf("%w: 插入位置 %d 越界", ErrInvalidPatchOp, pos)
+	}
+	d.Children = append(d.Children, MindMapData{})
+	copy(d.Children[pos+1:], d.Children[pos:])
+	d.Children[pos] = child
+	return nil
+}
+
+// reorderChildren 按给定的原下标排列重新排列子节点
+func (d *MindMapData) reorderChildren(order []int) error {
+	if len(order) != len(d.Children) {
+		return fmt.Errorf("%w: 排序长度与子节点数量不一致", ErrInvalidPatchOp)
+	}
+	seen := make([]bool, len(order))
+	reordered := make([]MindMapData, len(order))
+	for i, idx := range order {
+		if idx < 0 || idx >= len(order) || seen[idx] {
+			return fmt.Errorf("%w: 排序 %v 不是有效的排列", ErrInvalidPatchOp, order)
+		}
+		seen[idx] = true
+		reordered[i] = d.Children[idx]
+	}
+	d.Children = reordered
+	return nil
+}
+
+// isPathPrefix 判断 prefix 是否为 path 的前缀（包括相等）
+func isPathPrefix(prefix, path []int) bool {
+	if len(prefix) > len(path) {
+		return false
+	}
+	for i := range prefix {
+		if prefix[i] != path[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// shiftPathAfterRemoval 计算移除 removed 节点后 path 在新树中的路径
+func shiftPathAfterRemoval(path, removed []int) []int {
+	depth := len(removed) - 1
+	if len(path) <= depth || !isPathPrefix(removed[:depth], path) || path[depth] <= removed[depth] {
+		return path
+	}
+	shifted := append([]int(nil), path...)
+	shifted[depth]--
+	return shifted
+}
@@ -0,0 +1,106 @@
+package entity
+
+import (
+	"errors"
+	"strings"
+)
+
+// 派生导图的来源类型
+const (
+	ProvenanceDuplicate = "duplicate" // 复制整张导图
+	ProvenanceExtract   = "extract"   // 提取子树为新导图
+	ProvenanceMerge     = "merge"     // 合并多张导图
+	ProvenanceTemplate  = "template"  // 基于模板创建
+)
+
+// 合并导图时同层重复节点的处理方式，同一父节点下文本相同（忽略首尾空白）且来自不同导图的节点视为重复
+const (
+	MergeDuplicateKeep  = "keep"  // 全部保留
+	MergeDuplicateSkip  = "skip"  // 只保留先出现的节点及其子树
+	MergeDuplicateMerge = "merge" // 合并为先出现的节点，子节点递归合并
+)
+
+// MindMapProvenance 派生导图的来源记录，创建时写入后不再变化
+type MindMapProvenance struct {
+	Type       string
+	Sources    []MindMapSource // 来源导图，merge 时按合并顺序排列
+	NodeID     string          // extract 时被提取的节点标识
+	TemplateID string          // template 时的模板标识
+}
+
+// MindMapSource 派生时来源导图的快照信息
+type MindMapSource struct {
+	MapID   string
+	Version int64 // 派生时来源导图的版本，可用于查看对应历史版本
+	Title   string
+}
+
+// 错误定义
+var (
+	ErrNodeNotFound          = errors.New("节点不存在")
+	ErrInvalidMergeDuplicate = errors.New("无效的重复节点处理方式")
+)
+
+// NewMindMapSource 根据导图生成来源快照
+func NewMindMapSource(m *MindMap) MindMapSource {
+	return MindMapSource{MapID: m.MapID, Version: m.Version, Title: m.Title}
+}
+
+// Subtree 返回节点标识对应子树的副本
+func (d *MindMapData) Subtree(uid string) (MindMapData, error) {
+	path, ok := d.FindNodePath(uid)
+	if !ok {
+		return MindMapData{}, ErrNodeNotFound
+	}
+	node, err := d.nodeAt(path)
+	if err != nil {
+		return MindMapData{}, err
+	}
+	return node.Clone(), nil
+}
+
+// MergeMindMapData 以 rootText 为新根节点，将多棵树依次作为其子节点合并
+// 重复节点只在不同来源之间判断，同一来源内文本相同的节点保持不变
+func MergeMindMapData(rootText string, trees []MindMapData, duplicate string) (MindMapData, error) {
+	switch duplicate {
+	case "":
+		duplicate = MergeDuplicateKeep
+	case MergeDuplicateKeep, MergeDuplicateSkip, MergeDuplicateMerge:
+	default:
+		return MindMapData{}, ErrInvalidMergeDuplicate
+	}
+
+	root := MindMapData{Data: NodeData{Text: rootText}}
+	for _, tree := range trees {
+		root.Children = mergeChildren(root.Children, []MindMapData{tree.Clone()}, duplicate)
+	}
+	return root, nil
+}
+
+// mergeChildren 将 src 合并到 dst 末尾，只与 dst 中已有的节点比较是否重复
+func mergeChildren(dst, src []MindMapData, duplicate string) []MindMapData {
+	existing := len(dst)
+	for _, node := range src {
+		j := -1
+		if duplicate != MergeDuplicateKeep {
+			j = indexOfText(dst[:existing], node.Data.Text)
+		}
+		switch {
+		case j < 0:
+			dst = append(dst, node)
+		case duplicate == MergeDuplicateMerge:
+			dst[j].Children = mergeChildren(dst[j].Children, node.Children, duplicate)
+		}
+	}
+	return dst
+}
+
+func indexOfText(nodes []MindMapData, text string) int {
+	text = strings.TrimSpace(text)
+	for i := range nodes {
+		if strings.TrimSpace(nodes[i].Data.Text) == text {
+			return i
+		}
+	}
+	return -1
+}
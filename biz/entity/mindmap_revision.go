@@ -0,0 +1,15 @@
+package entity
+
+import "time"
+
+// MindMapRevision 思维导图历史版本 - 每次更新产生一份不可变快照
+type MindMapRevision struct {
+	MapID     string
+	Version   int64  // 快照对应的导图版本号
+	UserID    string // 产生该版本的用户
+	Title     string
+	Desc      string
+	Layout    string
+	Data      MindMapData
+	CreatedAt time.Time
+}
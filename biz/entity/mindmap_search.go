@@ -0,0 +1,47 @@
+package entity
+
+// 搜索命中的字段
+const (
+	SearchFieldTitle = "title"
+	SearchFieldDesc  = "desc"
+	SearchFieldNode  = "node"
+)
+
+// MindMapSearchHit 搜索命中项，Field 为 node 时 NodeUID、Path 指向命中的节点
+type MindMapSearchHit struct {
+	Field   string
+	NodeUID string
+	Path    []int // 从根节点开始逐层的子节点下标，空表示根节点
+	Text    string
+}
+
+// MindMapSearchResult 单个导图的搜索结果，MindMap 不包含导图数据
+type MindMapSearchResult struct {
+	MindMap  *MindMap
+	Hits     []MindMapSearchHit // 按标题、描述、节点先序遍历顺序排列，数量可能被截断
+	HitCount int                // 命中总数
+}
+
+// SearchEntries 生成导图的搜索索引条目：标题、描述以及每个节点的文本
+func (m *MindMap) SearchEntries() []MindMapSearchHit {
+	entries := []MindMapSearchHit{{Field: SearchFieldTitle, Text: m.Title}}
+	if m.Desc != "" {
+		entries = append(entries, MindMapSearchHit{Field: SearchFieldDesc, Text: m.Desc})
+	}
+	return m.Data.appendSearchEntries(entries, []int{})
+}
+
+func (d *MindMapData) appendSearchEntries(entries []MindMapSearchHit, path []int) []MindMapSearchHit {
+	if d.Data.Text != "" {
+		entries = append(entries, MindMapSearchHit{
+			Field:   SearchFieldNode,
+			NodeUID: d.Data.UID,
+			Path:    append([]int{}, path...),
+			Text:    d.Data.Text,
+		})
+	}
+	for i := range d.Children {
+		entries = d.Children[i].appendSearchEntries(entries, append(path, i))
+	}
+	return entries
+}
@@ -0,0 +1,24 @@
+package entity
+
+import "time"
+
+// MindMapShare 思维导图只读分享链接，持有 Token 的任何人均可查看导图
+type MindMapShare struct {
+	ShareID      string
+	MapID        string
+	UserID       string // 创建分享的用户（导图所有者）
+	Token        string
+	PasswordHash string     // 访问密码的 bcrypt 哈希，为空表示无需密码
+	ExpiresAt    *time.Time // 过期时间，为空表示永久有效
+	CreatedAt    time.Time
+}
+
+// HasPassword 是否设置了访问密码
+func (s *MindMapShare) HasPassword() bool {
+	return s.PasswordHash != ""
+}
+
+// IsExpired 分享链接在 now 时是否已过期
+func (s *MindMapShare) IsExpired(now time.Time) bool {
+	return s.ExpiresAt != nil && !now.Before(*s.ExpiresAt)
+}
@@ -0,0 +1,29 @@
+package entity
+
+import "time"
+
+// MindMapTemplate 思维导图模板，系统模板由服务内置，用户模板由用户从已有导图保存
+type MindMapTemplate struct {
+	TemplateID string
+	UserID     string // 创建模板的用户，系统模板为空
+	Name       string
+	Desc       string
+	Category   string // 分类，如 analysis、planning、meeting、reading
+	Layout     string
+	Data       MindMapData // 模板中的节点不包含标识，基于模板创建导图时重新分配
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+// IsSystem 是否为系统模板
+func (t *MindMapTemplate) IsSystem() bool {
+	return t.UserID == ""
+}
+
+// ClearNodeIDs 清除子树中所有节点的标识
+func (d *MindMapData) ClearNodeIDs() {
+	d.Data.UID = ""
+	for i := range d.Children {
+		d.Children[i].ClearNodeIDs()
+	}
+}
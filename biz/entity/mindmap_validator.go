@@ -0,0 +1,137 @@
+package entity
+
+import (
+	"errors"
+	"fmt"
+	"unicode/utf8"
+
+	"forge/infra/configs"
+)
+
+// 导图树结构默认校验规则，未配置时使用
+const (
+	DefaultMaxTreeDepth      = 50
+	DefaultMaxTreeNodes      = 10000
+	DefaultMaxNodeTextLength = 1000
+)
+
+// DefaultAllowedLayouts 默认允许的布局类型
+var DefaultAllowedLayouts = []string{
+	"logicalStructure",
+	"logicalStructureLeft",
+	"mindMap",
+	"organizationStructure",
+	"catalogOrganization",
+	"timeline",
+	"timeline2",
+	"verticalTimeline",
+	"fishbone",
+}
+
+// TreeValidationRules 导图树结构校验规则
+type TreeValidationRules struct {
+	MaxDepth       int      // 最大层级深度，根节点为第1层
+	MaxNodes       int      // 最大节点数量
+	MaxTextLength  int      // 节点文本最大字符数
+	AllowEmptyText bool     // 是否允许空文本节点
+	AllowedLayouts []string // 允许的布局类型
+}
+
+// TreeValidationError 导图树校验错误，携带出错节点路径
+type TreeValidationError struct {
+	Path string // 出错节点路径，如 root.children[0]
+	Err  error
+}
+
+func (e *TreeValidationError) Error() string {
+	return fmt.Sprintf("%s: %v", e.Path, e.Err)
+}
+
+func (e *TreeValidationError) Unwrap() error {
+	return e.Err
+}
+
+// 错误定义
+var (
+	ErrLayoutNotAllowed = errors.New("不支持的布局类型")
+	ErrTreeTooDeep      = errors.New("导图层级过深")
+	ErrTooManyNodes     = errors.New("导图节点数量过多")
+	ErrNodeTextEmpty    = errors.New("节点文本不能为空")
+	ErrNodeTextTooLong  = errors.New("节点文本过长")
+)
+
+// TreeValidationRulesFromConfig 从配置读取校验规则，未配置的项使用默认值
+func TreeValidationRulesFromConfig() TreeValidationRules {
+	cfg := configs.Config().GetMindMapConfig()
+	rules := TreeValidationRules{
+		MaxDepth:       cfg.MaxDepth,
+		MaxNodes:       cfg.MaxNodes,
+		MaxTextLength:  cfg.MaxTextLength,
+		AllowEmptyText: cfg.AllowEmptyText,
+		AllowedLayouts: cfg.AllowedLayouts,
+	}
+	if rules.MaxDepth <= 0 {
+		rules.MaxDepth = DefaultMaxTreeDepth
+	}
+	if rules.MaxNodes <= 0 {
+		rules.MaxNodes = DefaultMaxTreeNodes
+	}
+	if rules.MaxTextLength <= 0 {
+		rules.MaxTextLength = DefaultMaxNodeTextLength
+	}
+	if len(rules.AllowedLayouts) == 0 {
+		rules.AllowedLayouts = DefaultAllowedLayouts
+	}
+	return rules
+}
+
+// ValidateTree 按规则校验布局与整棵导图树，错误中携带出错节点路径
+func (m *MindMap) ValidateTree(rules TreeValidationRules) error {
+	if !isAllowedLayout(m.Layout, rules.AllowedLayouts) {
+		return &TreeValidationError{Path: "layout", Err: fmt.Errorf("%w: %s", ErrLayoutNotAllowed, m.Layout)}
+	}
+	return m.Data.ValidateTree(rules)
+}
+
+// ValidateTree 按规则校验节点树，错误中携带出错节点路径
+func (d *MindMapData) ValidateTree(rules TreeValidationRules) error {
+	count := 0
+	return d.validateTree(rules, "root", 1, &count)
+}
+
+func (d *MindMapData) validateTree(rules TreeValidationRules, path string, depth int, count *int) error {
+	if depth > rules.MaxDepth {
+		return &TreeValidationError{Path: path, Err: fmt.Errorf("%w，最多%d层", ErrTreeTooDeep, rules.MaxDepth)}
+	}
+	*count++
+	if *count > rules.MaxNodes {
+		return &TreeValidationError{Path: path, Err: fmt.Errorf("%w，最多%d个", ErrTooManyNodes, rules.MaxNodes)}
+	}
+
+	if !rules.AllowEmptyText && d.Data.Text == "" {
+		return &TreeValidationError{Path: path, Err: ErrNodeTextEmpty}
+	}
+	if utf8.RuneCountInString(d.Data.Text) > rules.MaxTextLength {
+		return &TreeValidationError{Path: path, Err: fmt.Errorf("%w，最多%d字符", ErrNodeTextTooLong, rules.MaxTextLength)}
+	}
+	if err := d.Data.Validate(); err != nil {
+		return &TreeValidationError{Path: path, Err: err}
+	}
+
+	for i := range d.Children {
+		childPath := fmt.Sprintf("%s.children[%d]", path, i)
+		if err := d.Children[i].validateTree(rules, childPath, depth+1, count); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func isAllowedLayout(layout string, allowed []string) bool {
+	for _, l := range allowed {
+		if l == layout {
+			return true
+		}
+	}
+	return false
+}
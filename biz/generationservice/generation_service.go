@@ -122,7 +122,7 @@ func (g *GenerationService) ExportSFTData(ctx context.Context, startDate, endDat
 	for _, result := range selectedResults {
 
 		// 获取对话记录
-		conversation, err := g.aiChatRepo.GetConversation(ctx, result.ConversationID, userID)
+		conversation, err := g.aiChatRepo.GetConversation(ctx, result.ConversationID)
 		if err != nil {
 			zlog.CtxWarnf(ctx, "获取对话记录失败 conversationID:%s, err:%v", result.ConversationID, err)
 			continue
@@ -276,7 +276,7 @@ func (g *GenerationService) ExportDPOData(ctx context.Context, startDate, endDat
 		pairs := g.generateOptimalDPOPairs(ctx, positiveResults, negativeResults, batchID)
 
 		for _, pair := range pairs {
-			dpoRecord, err := g.buildDPORecord(ctx, pair.positive, pair.negative, userID)
+			dpoRecord, err := g.buildDPORecord(ctx, pair.positive, pair.negative)
 			if err != nil {
 				zlog.CtxWarnf(ctx, "构建DPO记录失败 batchID:%s, positive:%s, negative:%s, err:%v",
 					batchID, pair.positive.ResultID, pair.negative.ResultID, err)
@@ -433,15 +433,16 @@ func (g *GenerationService) calculatePairScore(positive, negative *entity.Genera
 }
 
 // buildDPORecord 构建DPO记录
-func (g *GenerationService) buildDPORecord(ctx context.Context, positive, negative *entity.GenerationResult, userID string) (string, error) {
+// 样本均来自按用户查询的标记结果，对话归属无需再次校验
+func (g *GenerationService) buildDPORecord(ctx context.Context, positive, negative *entity.GenerationResult) (string, error) {
 	// 获取正样本对话
-	positiveConversation, err := g.aiChatRepo.GetConversation(ctx, positive.ConversationID, userID)
+	positiveConversation, err := g.aiChatRepo.GetConversation(ctx, positive.ConversationID)
 	if err != nil {
 		return "", fmt.Errorf("获取正样本对话失败: %w", err)
 	}
 
 	// 获取负样本对话用于校验
-	negativeConversation, err := g.aiChatRepo.GetConversation(ctx, negative.ConversationID, userID)
+	negativeConversation, err := g.aiChatRepo.GetConversation(ctx, negative.ConversationID)
 	if err != nil {
 		return "", fmt.Errorf("获取负样本对话失败: %w", err)
 	}
@@ -635,6 +636,12 @@ func (g *GenerationService) SaveSelectedMindMap(ctx context.Context, resultID st
 		Data:   mindMapData, // 直接使用解析好的结构化数据
 	}
 
+	// AI 输出不可信，保存前执行完整的结构校验
+	if err := mindMap.Validate(); err != nil {
+		zlog.CtxWarnf(ctx, "选中导图校验失败: resultID=%s, err=%v", resultID, err)
+		return nil, fmt.Errorf("导图数据校验失败: %w", err)
+	}
+
 	// 保存到正式导图系统
 	if err := g.mindMapRepo.CreateMindMap(ctx, mindMap); err != nil {
 		return nil, fmt.Errorf("保存导图失败: %w", err)
@@ -0,0 +1,429 @@
+package mindmapservice
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"forge/biz/entity"
+	"forge/biz/repo"
+	"forge/biz/types"
+	"forge/pkg/log/zlog"
+	"forge/util"
+)
+
+var (
+	ErrAttachmentNotFound      = errors.New("附件不存在")
+	ErrUnsupportedAttachment   = errors.New("不支持的附件类型")
+	ErrAttachmentTooLarge      = errors.New("附件过大")
+	ErrAttachmentQuotaExceeded = errors.New("附件存储空间不足")
+)
+
+// 各类附件的大小上限
+var attachmentMaxSizes = map[string]int64{
+	entity.AttachmentKindImage:    10 << 20,
+	entity.AttachmentKindPDF:      50 << 20,
+	entity.AttachmentKindDocument: 50 << 20,
+	entity.AttachmentKindText:     5 << 20,
+}
+
+const (
+	maxAttachmentFileNameLen  = 255
+	maxAttachmentLinkAttempts = 3 // 关联节点时遇到并发修改的最大尝试次数
+)
+
+// attachmentType 允许上传的附件类型，文件内容需与扩展名一致
+type attachmentType struct {
+	contentType string
+	kind        string
+	match       func(data []byte) bool
+}
+
+var (
+	zipMagic = []byte("PK\x03\x04")                                   // docx、xlsx、pptx
+	oleMagic = []byte{0xD0, 0xCF, 0x11, 0xE0, 0xA1, 0xB1, 0x1A, 0xE1} // doc、xls、ppt
+)
+
+func hasMagic(magic []byte) func(data []byte) bool {
+	return func(data []byte) bool { return bytes.HasPrefix(data, magic) }
+}
+
+func isJPEG(data []byte) bool { return bytes.HasPrefix(data, []byte{0xFF, 0xD8, 0xFF}) }
+
+func isWebP(data []byte) bool {
+	return len(data) >= 12 && bytes.HasPrefix(data, []byte("RIFF")) && bytes.Equal(data[8:12], []byte("WEBP"))
+}
+
+func isGIF(data []byte) bool {
+	return bytes.HasPrefix(data, []byte("GIF87a")) || bytes.HasPrefix(data, []byte("GIF89a"))
+}
+
+// isPlainText 合法的 UTF-8 且不包含 NUL 字符
+func isPlainText(data []byte) bool {
+	return utf8.Valid(data) && bytes.IndexByte(data, 0) < 0
+}
+
+// 不支持 SVG、HTML 等可能携带脚本的类型，附件地址直接在浏览器中打开
+var attachmentTypes = map[string]attachmentType{
+	".jpg":  {"image/jpeg", entity.AttachmentKindImage, isJPEG},
+	".jpeg": {"image/jpeg", entity.AttachmentKindImage, isJPEG},
+	".png":  {"image/png", entity.AttachmentKindImage, hasMagic([]byte("\x89PNG\r\n\x1a\n"))},
+	".gif":  {"image/gif", entity.AttachmentKindImage, isGIF},
+	".webp": {"image/webp", entity.AttachmentKindImage, isWebP},
+	".pdf":  {"application/pdf", entity.AttachmentKindPDF, hasMagic([]byte("%PDF-"))},
+	".docx": {"application/vnd.openxmlformats-officedocument.wordprocessingml.document", entity.AttachmentKindDocument, hasMagic(zipMagic)},
+	".xlsx": {"application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", entity.AttachmentKindDocument, hasMagic(zipMagic)},
+	".pptx": {"application/vnd.openxmlformats-officedocument.presentationml.presentation", entity.AttachmentKindDocument, hasMagic(zipMagic)},
+	".doc":  {"application/msword", entity.AttachmentKindDocument, hasMagic(oleMagic)},
+	".xls":  {"application/vnd.ms-excel", entity.AttachmentKindDocument, hasMagic(oleMagic)},
+	".ppt":  {"application/vnd.ms-powerpoint", entity.AttachmentKindDocument, hasMagic(oleMagic)},
+	".txt":  {"text/plain; charset=utf-8", entity.AttachmentKindText, isPlainText},
+	".md":   {"text/markdown; charset=utf-8", entity.AttachmentKindText, isPlainText},
+	".csv":  {"text/csv; charset=utf-8", entity.AttachmentKindText, isPlainText},
+}
+
+// mindMapFileDir 导图在 COS 中的文件目录，附件与缩略图均存放在所有者目录下，彻底删除导图时整体清理
+func mindMapFileDir(ownerID, mapID string) string {
+	return path.Join("user", ownerID, "maps", mapID) + "/"
+}
+
+// ListMindMapAttachments 获取导图的附件，nodeID 不为空时只返回上传到该节点的附件（协作成员均可查看）
+func (s *MindMapServiceImpl) ListMindMapAttachments(ctx context.Context, mapID, nodeID string) ([]*entity.MindMapAttachment, error) {
+	if _, _, err := s.authorize(ctx, mapID, entity.MindMapRoleViewer); err != nil {
+		return nil, err
+	}
+
+	attachments, err := s.mindMapRepo.ListMindMapAttachments(ctx, mapID, nodeID)
+	if err != nil {
+		zlog.CtxErrorf(ctx, "failed to list mindmap attachments: %v", err)
+		return nil, ErrInternalError
+	}
+	return attachments, nil
+}
+
+// UploadMindMapAttachment 上传附件到 COS 并添加到节点（需要编辑权限）
+// 附件大小计入上传者的存储配额，添加到节点会产生导图的新版本
+func (s *MindMapServiceImpl) UploadMindMapAttachment(ctx context.Context, mapID string, req *types.UploadMindMapAttachmentParams) (*entity.MindMapAttachment, error) {
+	user, ok := entity.GetUser(ctx)
+	if !ok {
+		zlog.CtxErrorf(ctx, "failed to get user from context")
+		return nil, ErrPermissionDenied
+	}
+
+	if req == nil || req.File == nil || req.NodeID == "" {
+		zlog.CtxErrorf(ctx, "attachment file and nodeID are required")
+		return nil, ErrInvalidParams
+	}
+
+	mindMap, err := s.getMindMapForRole(ctx, mapID, entity.MindMapRoleEditor)
+	if err != nil {
+		return nil, err // getMindMapForRole已经包含权限验证
+	}
+	node, ok := mindMap.Data.FindNode(req.NodeID)
+	if !ok {
+		zlog.CtxWarnf(ctx, "node not found in mindmap, mapID: %s, nodeID: %s", mapID, req.NodeID)
+		return nil, fmt.Errorf("%w: 节点不存在", ErrInvalidParams)
+	}
+	if len(node.Data.Attachments) >= entity.MaxNodeAttachments {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidParams, entity.ErrNodeTooManyAttachments)
+	}
+
+	// 先按扩展名与声明的大小校验，避免读取不允许的文件
+	fileName := truncateUTF8(strings.TrimSpace(filepath.Base(req.File.Filename)), maxAttachmentFileNameLen)
+	ext := strings.ToLower(filepath.Ext(fileName))
+	fileType, ok := attachmentTypes[ext]
+	if !ok {
+		zlog.CtxWarnf(ctx, "unsupported attachment type: %q", req.File.Filename)
+		return nil, ErrUnsupportedAttachment
+	}
+	maxSize := attachmentMaxSizes[fileType.kind]
+	if req.File.Size > maxSize {
+		zlog.CtxWarnf(ctx, "attachment too large: %d bytes, max: %d", req.File.Size, maxSize)
+		return nil, fmt.Errorf("%w: %s文件不能超过%dMB", ErrAttachmentTooLarge, ext, maxSize>>20)
+	}
+
+	file, err := req.File.Open()
+	if err != nil {
+		zlog.CtxErrorf(ctx, "failed to open attachment file: %v", err)
+		return nil, ErrInternalError
+	}
+	defer file.Close()
+
+	content, err := io.ReadAll(io.LimitReader(file, maxSize+1))
+	if err != nil {
+		zlog.CtxErrorf(ctx, "failed to read attachment file: %v", err)
+		return nil, ErrInternalError
+	}
+	if len(content) == 0 {
+		return nil, fmt.Errorf("%w: 附件内容为空", ErrInvalidParams)
+	}
+	if int64(len(content)) > maxSize {
+		return nil, fmt.Errorf("%w: %s文件不能超过%dMB", ErrAttachmentTooLarge, ext, maxSize>>20)
+	}
+	if !fileType.match(content) {
+		zlog.CtxWarnf(ctx, "attachment content does not match extension: %q", req.File.Filename)
+		return nil, fmt.Errorf("%w: 文件内容与扩展名不符", ErrUnsupportedAttachment)
+	}
+
+	// 并发上传时可能略微超出配额，不做强一致保证
+	used, err := s.mindMapRepo.SumUserAttachmentSize(ctx, user.UserID)
+	if err != nil {
+		zlog.CtxErrorf(ctx, "failed to get attachment usage: %v", err)
+		return nil, ErrInternalError
+	}
+	if quota := entity.AttachmentQuotaFromConfig(); used+int64(len(content)) > quota {
+		zlog.CtxWarnf(ctx, "attachment quota exceeded, userID: %s, used: %d, size: %d, quota: %d", user.UserID, used, len(content), quota)
+		return nil, ErrAttachmentQuotaExceeded
+	}
+
+	attachmentID, err := util.GenerateStringID()
+	if err != nil {
+		zlog.CtxErrorf(ctx, "failed to generate attachment id: %v", err)
+		return nil, ErrInternalError
+	}
+
+	// 存储路径只使用生成的标识与扩展名，原始文件名仅作为元数据保存
+	objectKey := path.Join(mindMapFileDir(mindMap.UserID, mapID), "attachments", attachmentID+ext)
+	url, err := s.cosService.UploadFile(ctx, objectKey, content, fileType.contentType)
+	if err != nil {
+		zlog.CtxErrorf(ctx, "failed to upload attachment, mapID: %s, objectKey: %s, error: %v", mapID, objectKey, err)
+		return nil, ErrInternalError
+	}
+
+	attachment := &entity.MindMapAttachment{
+		AttachmentID: attachmentID,
+		MapID:        mapID,
+		NodeID:       req.NodeID,
+		UserID:       user.UserID,
+		FileName:     fileName,
+		ContentType:  fileType.contentType,
+		Kind:         fileType.kind,
+		Size:         int64(len(content)),
+		ObjectKey:    objectKey,
+		URL:          url,
+	}
+	if err := s.mindMapRepo.CreateMindMapAttachment(ctx, attachment); err != nil {
+		zlog.CtxErrorf(ctx, "failed to create mindmap attachment: %v", err)
+		s.deleteAttachmentFile(ctx, objectKey)
+		return nil, ErrInternalError
+	}
+
+	// 添加到节点失败时撤销上传
+	err = s.updateNodeAttachments(ctx, user.UserID, mapID, func(data *entity.MindMapData) (bool, error) {
+		node, ok := data.FindNode(req.NodeID)
+		if !ok {
+			return false, fmt.Errorf("%w: 节点不存在", ErrInvalidParams)
+		}
+		if err := node.Data.AddAttachment(attachmentID); err != nil {
+			return false, fmt.Errorf("%w: %w", ErrInvalidParams, err)
+		}
+		return true, nil
+	})
+	if err != nil {
+		zlog.CtxWarnf(ctx, "failed to add attachment to node, mapID: %s, nodeID: %s: %v", mapID, req.NodeID, err)
+		if delErr := s.mindMapRepo.DeleteMindMapAttachment(ctx, mapID, attachmentID); delErr != nil {
+			zlog.CtxErrorf(ctx, "failed to rollback mindmap attachment, attachmentID: %s: %v", attachmentID, delErr)
+		}
+		s.deleteAttachmentFile(ctx, objectKey)
+		return nil, err
+	}
+
+	zlog.CtxInfof(ctx, "mindmap attachment uploaded successfully, mapID: %s, nodeID: %s, attachmentID: %s, size: %d", mapID, req.NodeID, attachmentID, attachment.Size)
+	return attachment, nil
+}
+
+// DeleteMindMapAttachment 删除附件，并移除节点对附件的引用（需要编辑权限）
+func (s *MindMapServiceImpl) DeleteMindMapAttachment(ctx context.Context, mapID, attachmentID string) error {
+	user, _, err := s.authorize(ctx, mapID, entity.MindMapRoleEditor)
+	if err != nil {
+		return err
+	}
+
+	attachment, err := s.mindMapRepo.GetMindMapAttachment(ctx, mapID, attachmentID)
+	if err != nil {
+		zlog.CtxErrorf(ctx, "failed to get mindmap attachment: %v", err)
+		return ErrInternalError
+	}
+	if attachment == nil {
+		return ErrAttachmentNotFound
+	}
+
+	// 先移除引用，失败时附件保持可用
+	err = s.updateNodeAttachments(ctx, user.UserID, mapID, func(data *entity.MindMapData) (bool, error) {
+		return data.RemoveAttachment(attachmentID), nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// 并发删除时附件可能已不存在
+	if err := s.mindMapRepo.DeleteMindMapAttachment(ctx, mapID, attachmentID); err != nil && !errors.Is(err, repo.ErrMindMapAttachmentNotFound) {
+		zlog.CtxErrorf(ctx, "failed to delete mindmap attachment: %v", err)
+		return ErrInternalError
+	}
+	s.deleteAttachmentFile(ctx, attachment.ObjectKey)
+
+	zlog.CtxInfof(ctx, "mindmap attachment deleted successfully, mapID: %s, attachmentID: %s, userID: %s", mapID, attachmentID, user.UserID)
+	return nil
+}
+
+// GetAttachmentUsage 获取当前用户的附件存储用量与配额
+func (s *MindMapServiceImpl) GetAttachmentUsage(ctx context.Context) (*types.AttachmentUsage, error) {
+	user, ok := entity.GetUser(ctx)
+	if !ok {
+		zlog.CtxErrorf(ctx, "failed to get user from context")
+		return nil, ErrPermissionDenied
+	}
+
+	used, err := s.mindMapRepo.SumUserAttachmentSize(ctx, user.UserID)
+	if err != nil {
+		zlog.CtxErrorf(ctx, "failed to get attachment usage: %v", err)
+		return nil, ErrInternalError
+	}
+	return &types.AttachmentUsage{
+		Used:  used,
+		Quota: entity.AttachmentQuotaFromConfig(),
+	}, nil
+}
+
+// updateNodeAttachments 在最新的导图数据上修改节点附件引用并保存，遇到并发修改时重新读取后重试
+// update 返回 false 表示无需修改
+func (s *MindMapServiceImpl) updateNodeAttachments(ctx context.Context, userID, mapID string, update func(data *entity.MindMapData) (bool, error)) error {
+	for attempt := 1; ; attempt++ {
+		mindMap, err := s.mindMapRepo.GetMindMap(ctx, mapID)
+		if err != nil {
+			zlog.CtxErrorf(ctx, "failed to get mindmap: %v", err)
+			return ErrInternalError
+		}
+		if mindMap == nil {
+			return ErrMindMapNotFound
+		}
+
+		data := mindMap.Data.Clone()
+		changed, err := update(&data)
+		if err != nil || !changed {
+			return err
+		}
+
+		expectedVersion := mindMap.Version
+		_, err = s.updateMindMap(ctx, &repo.MindMapUpdateInfo{
+			MapID:           mapID,
+			UserID:          userID,
+			Data:            &data,
+			ExpectedVersion: &expectedVersion,
+		})
+		if errors.Is(err, ErrVersionConflict) && attempt < maxAttachmentLinkAttempts {
+			continue
+		}
+		return err
+	}
+}
+
+// retainKnownAttachments 移除待保存数据中不属于该导图的附件引用
+// 客户端提交的附件标识只有在导图的附件记录中存在时才保留；不再被引用的附件不回收，
+// 历史版本仍可能引用它们，附件随导图彻底删除时一并清理
+func (s *MindMapServiceImpl) retainKnownAttachments(ctx context.Context, mapID string, trees ...*entity.MindMapData) error {
+	attachments, err := s.mindMapRepo.ListMindMapAttachments(ctx, mapID, "")
+	if err != nil {
+		zlog.CtxErrorf(ctx, "failed to list mindmap attachments: %v", err)
+		return ErrInternalError
+	}
+
+	known := make(map[string]struct{}, len(attachments))
+	for _, attachment := range attachments {
+		known[attachment.AttachmentID] = struct{}{}
+	}
+	keep := func(attachmentID string) bool {
+		_, ok := known[attachmentID]
+		return ok
+	}
+	for _, tree := range trees {
+		if tree != nil && tree.RetainAttachments(keep) {
+			zlog.CtxWarnf(ctx, "unknown attachment references removed, mapID: %s", mapID)
+		}
+	}
+	return nil
+}
+
+// copyAttachments 将树中引用的来源附件复制到导图 mapID 下并替换节点引用，返回待保存的附件记录
+// 来源中不存在的附件引用被移除，复制的附件计入当前用户的存储配额，失败时删除已复制的文件
+func (s *MindMapServiceImpl) copyAttachments(ctx context.Context, userID, mapID string, data *entity.MindMapData, sources []*entity.MindMapAttachment) ([]*entity.MindMapAttachment, error) {
+	referenced := data.AttachmentIDs()
+	known := make(map[string]*entity.MindMapAttachment, len(referenced))
+	var total int64
+	for _, attachment := range sources {
+		if _, ok := referenced[attachment.AttachmentID]; !ok {
+			continue
+		}
+		if _, dup := known[attachment.AttachmentID]; !dup {
+			known[attachment.AttachmentID] = attachment
+			total += attachment.Size
+		}
+	}
+	data.RetainAttachments(func(attachmentID string) bool {
+		_, ok := known[attachmentID]
+		return ok
+	})
+	if len(known) == 0 {
+		return nil, nil
+	}
+
+	used, err := s.mindMapRepo.SumUserAttachmentSize(ctx, userID)
+	if err != nil {
+		zlog.CtxErrorf(ctx, "failed to get attachment usage: %v", err)
+		return nil, ErrInternalError
+	}
+	if quota := entity.AttachmentQuotaFromConfig(); used+total > quota {
+		zlog.CtxWarnf(ctx, "attachment quota exceeded, userID: %s, used: %d, size: %d, quota: %d", userID, used, total, quota)
+		return nil, ErrAttachmentQuotaExceeded
+	}
+
+	ids := make(map[string]string, len(known))
+	copied := make([]*entity.MindMapAttachment, 0, len(known))
+	for _, source := range known {
+		attachmentID, err := util.GenerateStringID()
+		if err != nil {
+			zlog.CtxErrorf(ctx, "failed to generate attachment id: %v", err)
+			s.deleteAttachmentFiles(ctx, copied)
+			return nil, ErrInternalError
+		}
+		objectKey := path.Join(mindMapFileDir(userID, mapID), "attachments", attachmentID+path.Ext(source.ObjectKey))
+		url, err := s.cosService.CopyFile(ctx, source.ObjectKey, objectKey)
+		if err != nil {
+			zlog.CtxErrorf(ctx, "failed to copy attachment, attachmentID: %s, objectKey: %s, error: %v", source.AttachmentID, objectKey, err)
+			s.deleteAttachmentFiles(ctx, copied)
+			return nil, ErrInternalError
+		}
+
+		attachment := *source
+		attachment.AttachmentID = attachmentID
+		attachment.MapID = mapID
+		attachment.UserID = userID
+		attachment.ObjectKey = objectKey
+		attachment.URL = url
+		copied = append(copied, &attachment)
+		ids[source.AttachmentID] = attachmentID
+	}
+	data.RemapAttachments(ids)
+	return copied, nil
+}
+
+// deleteAttachmentFiles 删除附件文件，失败时仅记录日志
+func (s *MindMapServiceImpl) deleteAttachmentFiles(ctx context.Context, attachments []*entity.MindMapAttachment) {
+	for _, attachment := range attachments {
+		s.deleteAttachmentFile(ctx, attachment.ObjectKey)
+	}
+}
+
+// deleteAttachmentFile 删除 COS 中的附件文件，失败时仅记录日志，残留文件在彻底删除导图时清理
+func (s *MindMapServiceImpl) deleteAttachmentFile(ctx context.Context, objectKey string) {
+	if err := s.cosService.DeleteFile(ctx, objectKey); err != nil {
+		zlog.CtxWarnf(ctx, "failed to delete attachment file, objectKey: %s: %v", objectKey, err)
+	}
+}
@@ -0,0 +1,318 @@
+package mindmapservice
+
+import (
+	"context"
+	"path"
+	"slices"
+	"testing"
+	"time"
+
+	"forge/biz/adapter"
+	"forge/biz/entity"
+	"forge/biz/repo"
+	"forge/biz/types"
+	"forge/pkg/log/zlog"
+	"forge/util"
+
+	"go.uber.org/zap"
+)
+
+// fakeMindMapRepo 内存中的导图仓储，只实现测试用到的方法
+type fakeMindMapRepo struct {
+	repo.IMindMapRepo
+	mindMaps    map[string]*entity.MindMap
+	revisions   map[string][]*entity.MindMapRevision
+	attachments map[string][]*entity.MindMapAttachment
+}
+
+func newFakeMindMapRepo() *fakeMindMapRepo {
+	return &fakeMindMapRepo{
+		mindMaps:    make(map[string]*entity.MindMap),
+		revisions:   make(map[string][]*entity.MindMapRevision),
+		attachments: make(map[string][]*entity.MindMapAttachment),
+	}
+}
+
+// save 写入导图并记录当前版本的快照
+func (r *fakeMindMapRepo) save(mindMap *entity.MindMap) {
+	r.mindMaps[mindMap.MapID] = mindMap
+	r.revisions[mindMap.MapID] = append(r.revisions[mindMap.MapID], &entity.MindMapRevision{
+		MapID:   mindMap.MapID,
+		Version: mindMap.Version,
+		UserID:  mindMap.UserID,
+		Title:   mindMap.Title,
+		Desc:    mindMap.Desc,
+		Layout:  mindMap.Layout,
+		Data:    mindMap.Data.Clone(),
+	})
+}
+
+func (r *fakeMindMapRepo) CreateMindMap(ctx context.Context, mindMap *entity.MindMap) error {
+	mindMap.Version = 1
+	r.save(mindMap)
+	return nil
+}
+
+func (r *fakeMindMapRepo) GetMindMap(ctx context.Context, mapID string) (*entity.MindMap, error) {
+	mindMap, ok := r.mindMaps[mapID]
+	if !ok {
+		return nil, nil
+	}
+	clone := *mindMap
+	clone.Data = mindMap.Data.Clone()
+	return &clone, nil
+}
+
+func (r *fakeMindMapRepo) GetMindMapMember(ctx context.Context, mapID, userID string) (*entity.MindMapMember, error) {
+	mindMap, ok := r.mindMaps[mapID]
+	if !ok || mindMap.UserID != userID {
+		return nil, nil
+	}
+	return &entity.MindMapMember{MapID: mapID, UserID: userID, Role: entity.MindMapRoleOwner}, nil
+}
+
+func (r *fakeMindMapRepo) GetMindMapTags(ctx context.Context, mapID, userID string) ([]string, error) {
+	return nil, nil
+}
+
+func (r *fakeMindMapRepo) GetMindMapRevision(ctx context.Context, mapID string, version int64) (*entity.MindMapRevision, error) {
+	for _, revision := range r.revisions[mapID] {
+		if revision.Version == version {
+			clone := *revision
+			clone.Data = revision.Data.Clone()
+			return &clone, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *fakeMindMapRepo) UpdateMindMap(ctx context.Context, updateInfo *repo.MindMapUpdateInfo) (int64, error) {
+	current, ok := r.mindMaps[updateInfo.MapID]
+	if !ok {
+		return 0, repo.ErrMindMapNotFound
+	}
+	if updateInfo.ExpectedVersion != nil && *updateInfo.ExpectedVersion != current.Version {
+		return 0, repo.ErrMindMapVersionConflict
+	}
+	updated := *current
+	if updateInfo.Title != nil {
+		updated.Title = *updateInfo.Title
+	}
+	if updateInfo.Desc != nil {
+		updated.Desc = *updateInfo.Desc
+	}
+	if updateInfo.Layout != nil {
+		updated.Layout = *updateInfo.Layout
+	}
+	if updateInfo.Data != nil {
+		updated.Data = updateInfo.Data.Clone()
+	}
+	updated.Version++
+	r.save(&updated)
+	return updated.Version, nil
+}
+
+func (r *fakeMindMapRepo) ListMindMapAttachments(ctx context.Context, mapID, nodeID string) ([]*entity.MindMapAttachment, error) {
+	return r.attachments[mapID], nil
+}
+
+func (r *fakeMindMapRepo) CreateMindMapAttachment(ctx context.Context, attachment *entity.MindMapAttachment) error {
+	r.attachments[attachment.MapID] = append(r.attachments[attachment.MapID], attachment)
+	return nil
+}
+
+func (r *fakeMindMapRepo) SumUserAttachmentSize(ctx context.Context, userID string) (int64, error) {
+	var total int64
+	for _, attachments := range r.attachments {
+		for _, attachment := range attachments {
+			if attachment.UserID == userID {
+				total += attachment.Size
+			}
+		}
+	}
+	return total, nil
+}
+
+func (r *fakeMindMapRepo) DeleteMindMapAttachment(ctx context.Context, mapID, attachmentID string) error {
+	attachments := r.attachments[mapID]
+	for i, attachment := range attachments {
+		if attachment.AttachmentID == attachmentID {
+			r.attachments[mapID] = slices.Delete(attachments, i, i+1)
+			return nil
+		}
+	}
+	return repo.ErrMindMapAttachmentNotFound
+}
+
+// fakeCOSService 记录被复制与删除的文件
+type fakeCOSService struct {
+	adapter.COSService
+	copied  map[string]string // 目标路径 -> 源路径
+	deleted []string
+}
+
+func (c *fakeCOSService) CopyFile(ctx context.Context, srcPath, dstPath string) (string, error) {
+	if c.copied == nil {
+		c.copied = make(map[string]string)
+	}
+	c.copied[dstPath] = srcPath
+	return "https://cos.example.com/" + dstPath, nil
+}
+
+func (c *fakeCOSService) DeleteFile(ctx context.Context, resourcePath string) error {
+	c.deleted = append(c.deleted, resourcePath)
+	return nil
+}
+
+// fakeCollabBroker 丢弃广播的协作事件
+type fakeCollabBroker struct {
+	adapter.CollabBroker
+}
+
+func (b *fakeCollabBroker) Publish(ctx context.Context, mapID string, payload []byte) error {
+	return nil
+}
+
+func newTestMindMapService(mindMapRepo *fakeMindMapRepo, cos *fakeCOSService) *MindMapServiceImpl {
+	zlog.InitLogger(zap.NewNop())
+	if err := util.InitSnowflake(1); err != nil {
+		panic(err)
+	}
+	return NewMindMapServiceImpl(mindMapRepo, nil, nil, nil, cos, nil, &fakeCollabBroker{}, nil)
+}
+
+// TestRestoreRevisionKeepsAttachments 删除带附件的节点后恢复之前的版本，附件记录与文件仍然存在
+func TestRestoreRevisionKeepsAttachments(t *testing.T) {
+	mindMapRepo := newFakeMindMapRepo()
+	cos := &fakeCOSService{}
+	s := newTestMindMapService(mindMapRepo, cos)
+	ctx := entity.WithUser(context.Background(), &entity.User{UserID: "u1"})
+
+	mindMapRepo.save(&entity.MindMap{
+		MapID:   "m1",
+		UserID:  "u1",
+		Title:   "导图",
+		Layout:  "mindMap",
+		Version: 1,
+		Data: entity.MindMapData{
+			Data: entity.NodeData{UID: "root", Text: "根"},
+			Children: []entity.MindMapData{
+				{Data: entity.NodeData{UID: "a", Text: "带附件", Attachments: []string{"att1"}}},
+			},
+		},
+	})
+	mindMapRepo.attachments["m1"] = []*entity.MindMapAttachment{{
+		AttachmentID: "att1",
+		MapID:        "m1",
+		NodeID:       "a",
+		UserID:       "u1",
+		ObjectKey:    "user/u1/maps/m1/att1.pdf",
+		CreatedAt:    time.Now().Add(-24 * time.Hour),
+	}}
+
+	// 删除带附件的节点
+	removed := entity.MindMapData{Data: entity.NodeData{UID: "root", Text: "根"}}
+	version := int64(1)
+	newVersion, err := s.UpdateMindMap(ctx, "m1", &types.UpdateMindMapParams{Data: &removed, Version: &version})
+	if err != nil {
+		t.Fatalf("UpdateMindMap: %v", err)
+	}
+
+	// 恢复删除前的版本
+	newVersion, err = s.RestoreMindMapRevision(ctx, "m1", &types.RestoreMindMapRevisionParams{Version: 1, ExpectedVersion: &newVersion})
+	if err != nil {
+		t.Fatalf("RestoreMindMapRevision: %v", err)
+	}
+
+	restored := mindMapRepo.mindMaps["m1"]
+	if restored.Version != newVersion {
+		t.Fatalf("version = %d, want %d", restored.Version, newVersion)
+	}
+	node, ok := restored.Data.FindNode("a")
+	if !ok || !slices.Equal(node.Data.Attachments, []string{"att1"}) {
+		t.Errorf("restored node attachments = %v, want [att1]", node.Data.Attachments)
+	}
+	if len(mindMapRepo.attachments["m1"]) != 1 {
+		t.Errorf("attachment record removed: %v", mindMapRepo.attachments["m1"])
+	}
+	if len(cos.deleted) != 0 {
+		t.Errorf("attachment files deleted: %v", cos.deleted)
+	}
+}
+
+// TestExtractSubtreeCopiesAttachments 提取并移除分支时，分支引用的附件复制到新导图，原导图的附件保留
+func TestExtractSubtreeCopiesAttachments(t *testing.T) {
+	mindMapRepo := newFakeMindMapRepo()
+	cos := &fakeCOSService{}
+	s := newTestMindMapService(mindMapRepo, cos)
+	ctx := entity.WithUser(context.Background(), &entity.User{UserID: "u1"})
+
+	mindMapRepo.save(&entity.MindMap{
+		MapID:   "m1",
+		UserID:  "u1",
+		Title:   "导图",
+		Layout:  "mindMap",
+		Version: 1,
+		Data: entity.MindMapData{
+			Data: entity.NodeData{UID: "root", Text: "根", Attachments: []string{"att0"}},
+			Children: []entity.MindMapData{
+				{
+					Data: entity.NodeData{UID: "a", Text: "分支", Attachments: []string{"att1", "missing"}},
+					Children: []entity.MindMapData{
+						{Data: entity.NodeData{UID: "b", Text: "子节点", Attachments: []string{"att2"}}},
+					},
+				},
+			},
+		},
+	})
+	mindMapRepo.attachments["m1"] = []*entity.MindMapAttachment{
+		{AttachmentID: "att0", MapID: "m1", NodeID: "root", UserID: "u1", Size: 1, ObjectKey: "user/u1/maps/m1/attachments/att0.png"},
+		{AttachmentID: "att1", MapID: "m1", NodeID: "a", UserID: "u1", Size: 2, ObjectKey: "user/u1/maps/m1/attachments/att1.pdf"},
+		{AttachmentID: "att2", MapID: "m1", NodeID: "b", UserID: "u1", Size: 3, ObjectKey: "user/u1/maps/m1/attachments/att2.txt"},
+	}
+
+	mindMap, err := s.ExtractMindMapSubtree(ctx, "m1", &types.ExtractMindMapSubtreeParams{NodeID: "a", RemoveFromSource: true})
+	if err != nil {
+		t.Fatalf("ExtractMindMapSubtree: %v", err)
+	}
+
+	if _, ok := mindMapRepo.mindMaps["m1"].Data.FindNode("a"); ok {
+		t.Errorf("extracted branch still in source")
+	}
+	if len(mindMapRepo.attachments["m1"]) != 3 {
+		t.Errorf("source attachments = %d, want 3", len(mindMapRepo.attachments["m1"]))
+	}
+	if len(cos.deleted) != 0 {
+		t.Errorf("attachment files deleted: %v", cos.deleted)
+	}
+
+	copied := mindMapRepo.attachments[mindMap.MapID]
+	if len(copied) != 2 {
+		t.Fatalf("copied attachments = %d, want 2", len(copied))
+	}
+	byNode := make(map[string]*entity.MindMapAttachment, len(copied))
+	for _, attachment := range copied {
+		if attachment.MapID != mindMap.MapID || attachment.UserID != "u1" {
+			t.Errorf("copied attachment owner = %s/%s", attachment.MapID, attachment.UserID)
+		}
+		if src := cos.copied[attachment.ObjectKey]; src == "" {
+			t.Errorf("attachment file not copied: %s", attachment.ObjectKey)
+		}
+		byNode[attachment.NodeID] = attachment
+	}
+
+	saved := mindMapRepo.mindMaps[mindMap.MapID]
+	for nodeID, want := range map[string]string{"a": "att1", "b": "att2"} {
+		attachment := byNode[nodeID]
+		if attachment == nil || attachment.AttachmentID == want {
+			t.Fatalf("attachment %s not copied with a new id", want)
+		}
+		if cos.copied[attachment.ObjectKey] != "user/u1/maps/m1/attachments/"+want+path.Ext(attachment.ObjectKey) {
+			t.Errorf("attachment %s copied from %s", want, cos.copied[attachment.ObjectKey])
+		}
+		node, ok := saved.Data.FindNode(nodeID)
+		if !ok || !slices.Equal(node.Data.Attachments, []string{attachment.AttachmentID}) {
+			t.Errorf("node %s attachments = %v, want [%s]", nodeID, node.Data.Attachments, attachment.AttachmentID)
+		}
+	}
+}
@@ -0,0 +1,314 @@
+package mindmapservice
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sort"
+	"time"
+
+	"forge/biz/entity"
+	"forge/biz/repo"
+	"forge/biz/types"
+	"forge/pkg/log/zlog"
+	"forge/util"
+)
+
+const (
+	// 在线状态有效期，连接需在有效期内刷新
+	collabPresenceTTL = 90 * time.Second
+	// 保存时遇到并发修改的最大重试次数，每次重试基于最新版本重新变换
+	collabMaxRetries = 5
+	// 节点标识最大长度
+	maxCollabNodeIDLength = 64
+)
+
+// JoinCollab 加入导图的实时协作（协作成员均可加入，仅编辑者及以上可提交操作）
+// 先订阅事件再读取快照，客户端丢弃版本不高于快照版本的操作事件即可保证不遗漏
+func (s *MindMapServiceImpl) JoinCollab(ctx context.Context, mapID string) (*types.CollabSession, error) {
+	user, member, err := s.authorize(ctx, mapID, entity.MindMapRoleViewer)
+	if err != nil {
+		return nil, err
+	}
+
+	connID, err := util.GenerateStringID()
+	if err != nil {
+		zlog.CtxErrorf(ctx, "failed to generate collab conn id: %v", err)
+		return nil, ErrInternalError
+	}
+	now := time.Now()
+	presence := entity.CollabPresence{
+		ConnID:    connID,
+		UserID:    user.UserID,
+		UserName:  user.UserName,
+		Avatar:    user.Avatar,
+		Role:      member.Role,
+		JoinedAt:  now,
+		UpdatedAt: now,
+	}
+
+	events, err := s.collab.join(mapID, presence)
+	if err != nil {
+		zlog.CtxErrorf(ctx, "failed to join collab room: %v", err)
+		return nil, ErrInternalError
+	}
+
+	mindMap, err := s.mindMapRepo.GetMindMap(ctx, mapID)
+	if err != nil || mindMap == nil {
+		s.collab.leave(mapID, connID)
+		if err != nil {
+			zlog.CtxErrorf(ctx, "failed to get mindmap: %v", err)
+			return nil, ErrInternalError
+		}
+		return nil, ErrMindMapNotFound
+	}
+	mindMap.Role = member.Role
+
+	if err := s.saveCollabPresence(ctx, mapID, presence); err != nil {
+		s.collab.leave(mapID, connID)
+		return nil, ErrInternalError
+	}
+	s.publishCollabEvent(ctx, &entity.CollabEvent{Type: entity.CollabEventJoin, MapID: mapID, ConnID: connID, UserID: user.UserID, Presence: &presence})
+
+	presences, err := s.listCollabPresence(ctx, mapID)
+	if err != nil {
+		s.LeaveCollab(ctx, mapID, connID)
+		return nil, ErrInternalError
+	}
+
+	zlog.CtxInfof(ctx, "collab joined, mapID: %s, userID: %s, connID: %s", mapID, user.UserID, connID)
+	return &types.CollabSession{
+		ConnID:   connID,
+		MindMap:  mindMap,
+		Presence: presences,
+		Events:   events,
+	}, nil
+}
+
+// LeaveCollab 离开实时协作，清理在线状态并通知其他协作者
+func (s *MindMapServiceImpl) LeaveCollab(ctx context.Context, mapID, connID string) {
+	// 连接断开后请求上下文可能已取消，清理工作不受其影响
+	ctx = context.WithoutCancel(ctx)
+
+	presence, ok := s.collab.leave(mapID, connID)
+	if !ok {
+		// 连接已因消费过慢被移除，仍需清理在线状态
+		user, _ := entity.GetUser(ctx)
+		presence = entity.CollabPresence{ConnID: connID}
+		if user != nil {
+			presence.UserID = user.UserID
+		}
+	}
+	if err := s.collab.broker.DeletePresence(ctx, mapID, connID); err != nil {
+		zlog.CtxWarnf(ctx, "failed to delete collab presence, mapID: %s, connID: %s: %v", mapID, connID, err)
+	}
+	s.publishCollabEvent(ctx, &entity.CollabEvent{Type: entity.CollabEventLeave, MapID: mapID, ConnID: connID, UserID: presence.UserID, Presence: &presence})
+
+	zlog.CtxInfof(ctx, "collab left, mapID: %s, connID: %s", mapID, connID)
+}
+
+// ApplyCollabOps 提交实时协作操作（需要编辑权限）
+// 操作基于客户端所见的 BaseVersion 生成，服务端将其变换到最新版本后以乐观锁保存，成功后广播给其他协作者
+func (s *MindMapServiceImpl) ApplyCollabOps(ctx context.Context, mapID, connID string, req *types.ApplyCollabOpsParams) (*types.ApplyCollabOpsResult, error) {
+	if req == nil || len(req.Ops) == 0 || len(req.Ops) > maxPatchOps || req.BaseVersion <= 0 {
+		zlog.CtxErrorf(ctx, "invalid collab ops")
+		return nil, ErrInvalidParams
+	}
+	if _, ok := s.collab.presence(mapID, connID); !ok {
+		zlog.CtxWarnf(ctx, "collab connection not found, mapID: %s, connID: %s", mapID, connID)
+		return nil, ErrInvalidParams
+	}
+
+	// 每次提交都重新校验权限，协作期间角色可能被修改
+	user, _, err := s.authorize(ctx, mapID, entity.MindMapRoleEditor)
+	if err != nil {
+		return nil, err
+	}
+
+	for attempt := 0; attempt < collabMaxRetries; attempt++ {
+		current, err := s.mindMapRepo.GetMindMap(ctx, mapID)
+		if err != nil {
+			zlog.CtxErrorf(ctx, "failed to get mindmap: %v", err)
+			return nil, ErrInternalError
+		}
+		if current == nil {
+			return nil, ErrMindMapNotFound
+		}
+		if req.BaseVersion > current.Version {
+			zlog.CtxErrorf(ctx, "collab base version is ahead, mapID: %s, base: %d, current: %d", mapID, req.BaseVersion, current.Version)
+			return nil, ErrInvalidParams
+		}
+
+		base := current.Data
+		if req.BaseVersion < current.Version {
+			revision, err := s.mindMapRepo.GetMindMapRevision(ctx, mapID, req.BaseVersion)
+			if err != nil {
+				zlog.CtxErrorf(ctx, "failed to get mindmap revision: %v", err)
+				return nil, ErrInternalError
+			}
+			if revision == nil {
+				// 基准版本已不可用，客户端需重新同步
+				zlog.CtxWarnf(ctx, "collab base revision not found, mapID: %s, base: %d", mapID, req.BaseVersion)
+				return nil, ErrVersionConflict
+			}
+			base = revision.Data
+		}
+
+		ops, data, err := entity.RebasePatchOps(base, current.Data, req.Ops)
+		if err != nil {
+			zlog.CtxWarnf(ctx, "failed to rebase collab ops, mapID: %s: %v", mapID, err)
+			switch {
+			case errors.Is(err, entity.ErrPatchConflict):
+				return nil, ErrCollabConflict
+			case errors.Is(err, entity.ErrInvalidPatchOp):
+				return nil, ErrInvalidPatch
+			}
+			return nil, ErrInternalError
+		}
+		// 所有操作均已被并发修改覆盖（如删除已删除的节点），无需保存
+		if len(ops) == 0 {
+			return &types.ApplyCollabOpsResult{Version: current.Version}, nil
+		}
+
+		tempMindMap := *current
+		tempMindMap.Data = data
+		if err := tempMindMap.Validate(); err != nil {
+			zlog.CtxErrorf(ctx, "mindmap validation failed after collab ops: %v", err)
+			return nil, newInvalidDataError(err)
+		}
+
+		// 新增的子树与保存的数据一致地移除不属于该导图的附件引用，再广播给其他协作者
+		trees := []*entity.MindMapData{&data}
+		for i := range ops {
+			trees = append(trees, ops[i].Node)
+		}
+		if err := s.retainKnownAttachments(ctx, mapID, trees...); err != nil {
+			return nil, err
+		}
+
+		expectedVersion := current.Version
+		version, err := s.mindMapRepo.UpdateMindMap(ctx, &repo.MindMapUpdateInfo{
+			MapID:           mapID,
+			UserID:          user.UserID,
+			Data:            &data,
+			ExpectedVersion: &expectedVersion,
+		})
+		if err != nil {
+			if errors.Is(err, repo.ErrMindMapVersionConflict) {
+				zlog.CtxInfof(ctx, "collab ops concurrent update, retrying, mapID: %s, attempt: %d", mapID, attempt+1)
+				continue
+			}
+			if errors.Is(err, repo.ErrMindMapNotFound) {
+				return nil, ErrMindMapNotFound
+			}
+			zlog.CtxErrorf(ctx, "failed to update mindmap: %v", err)
+			return nil, ErrInternalError
+		}
+
+		s.publishCollabEvent(ctx, &entity.CollabEvent{Type: entity.CollabEventOp, MapID: mapID, ConnID: connID, UserID: user.UserID, Version: version, Ops: ops})
+
+		zlog.CtxInfof(ctx, "collab ops applied, mapID: %s, userID: %s, version: %d, ops: %d", mapID, user.UserID, version, len(ops))
+		return &types.ApplyCollabOpsResult{Version: version, Ops: ops}, nil
+	}
+
+	zlog.CtxWarnf(ctx, "collab ops retries exhausted, mapID: %s", mapID)
+	return nil, ErrVersionConflict
+}
+
+// UpdateCollabCursor 更新光标所在节点，nodeID 为空表示取消选中
+func (s *MindMapServiceImpl) UpdateCollabCursor(ctx context.Context, mapID, connID, nodeID string) error {
+	if len(nodeID) > maxCollabNodeIDLength {
+		zlog.CtxErrorf(ctx, "invalid cursor node id")
+		return ErrInvalidParams
+	}
+
+	presence, ok := s.collab.updatePresence(mapID, connID, func(p *entity.CollabPresence) {
+		p.CursorNodeID = nodeID
+		p.UpdatedAt = time.Now()
+	})
+	if !ok {
+		zlog.CtxWarnf(ctx, "collab connection not found, mapID: %s, connID: %s", mapID, connID)
+		return ErrInvalidParams
+	}
+
+	if err := s.saveCollabPresence(ctx, mapID, presence); err != nil {
+		return ErrInternalError
+	}
+	s.publishCollabEvent(ctx, &entity.CollabEvent{Type: entity.CollabEventCursor, MapID: mapID, ConnID: connID, UserID: presence.UserID, Presence: &presence})
+	return nil
+}
+
+// TouchCollab 刷新在线状态，并重新校验协作权限，成员被移除后返回错误
+func (s *MindMapServiceImpl) TouchCollab(ctx context.Context, mapID, connID string) error {
+	if _, _, err := s.authorize(ctx, mapID, entity.MindMapRoleViewer); err != nil {
+		return err
+	}
+
+	presence, ok := s.collab.updatePresence(mapID, connID, func(p *entity.CollabPresence) {
+		p.UpdatedAt = time.Now()
+	})
+	if !ok {
+		zlog.CtxWarnf(ctx, "collab connection not found, mapID: %s, connID: %s", mapID, connID)
+		return ErrInvalidParams
+	}
+
+	if err := s.saveCollabPresence(ctx, mapID, presence); err != nil {
+		return ErrInternalError
+	}
+	return nil
+}
+
+func (s *MindMapServiceImpl) saveCollabPresence(ctx context.Context, mapID string, presence entity.CollabPresence) error {
+	payload, err := json.Marshal(presence)
+	if err != nil {
+		zlog.CtxErrorf(ctx, "failed to marshal collab presence: %v", err)
+		return err
+	}
+	if err := s.collab.broker.SavePresence(ctx, mapID, presence.ConnID, payload, collabPresenceTTL); err != nil {
+		zlog.CtxErrorf(ctx, "failed to save collab presence: %v", err)
+		return err
+	}
+	return nil
+}
+
+// listCollabPresence 获取导图的在线状态，按加入时间排序，并清理过期未刷新的条目（如实例异常退出遗留的连接）
+func (s *MindMapServiceImpl) listCollabPresence(ctx context.Context, mapID string) ([]*entity.CollabPresence, error) {
+	payloads, err := s.collab.broker.ListPresence(ctx, mapID)
+	if err != nil {
+		zlog.CtxErrorf(ctx, "failed to list collab presence: %v", err)
+		return nil, err
+	}
+
+	expiredBefore := time.Now().Add(-collabPresenceTTL)
+	presences := make([]*entity.CollabPresence, 0, len(payloads))
+	for _, payload := range payloads {
+		var presence entity.CollabPresence
+		if err := json.Unmarshal(payload, &presence); err != nil {
+			zlog.CtxWarnf(ctx, "failed to unmarshal collab presence: %v", err)
+			continue
+		}
+		if presence.UpdatedAt.Before(expiredBefore) {
+			if err := s.collab.broker.DeletePresence(ctx, mapID, presence.ConnID); err != nil {
+				zlog.CtxWarnf(ctx, "failed to delete expired collab presence: %v", err)
+			}
+			continue
+		}
+		presences = append(presences, &presence)
+	}
+	sort.Slice(presences, func(i, j int) bool {
+		return presences[i].JoinedAt.Before(presences[j].JoinedAt)
+	})
+	return presences, nil
+}
+
+// publishCollabEvent 广播协作事件，失败仅记录日志，客户端可通过版本号发现遗漏并重新同步
+func (s *MindMapServiceImpl) publishCollabEvent(ctx context.Context, event *entity.CollabEvent) {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		zlog.CtxErrorf(ctx, "failed to marshal collab event: %v", err)
+		return
+	}
+	if err := s.collab.broker.Publish(ctx, event.MapID, payload); err != nil {
+		zlog.CtxWarnf(ctx, "failed to publish collab event, mapID: %s, type: %s: %v", event.MapID, event.Type, err)
+	}
+}
@@ -0,0 +1,151 @@
+package mindmapservice
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+
+	"forge/biz/adapter"
+	"forge/biz/entity"
+	"forge/pkg/log/zlog"
+)
+
+// 每个连接待推送事件的缓冲大小，超出时视为消费过慢
+const collabEventBuffer = 64
+
+// collabHub 管理本实例上的实时协作连接
+// 同一导图在本实例上只订阅一次消息频道，收到的事件分发给该导图的所有本地连接
+type collabHub struct {
+	broker adapter.CollabBroker
+	mu     sync.Mutex
+	rooms  map[string]*collabRoom
+}
+
+type collabRoom struct {
+	conns  map[string]*collabConn
+	cancel context.CancelFunc
+}
+
+type collabConn struct {
+	presence entity.CollabPresence
+	events   chan *entity.CollabEvent
+}
+
+func newCollabHub(broker adapter.CollabBroker) *collabHub {
+	return &collabHub{broker: broker, rooms: make(map[string]*collabRoom)}
+}
+
+// join 注册本地连接，导图在本实例上的首个连接负责订阅消息频道
+func (h *collabHub) join(mapID string, presence entity.CollabPresence) (<-chan *entity.CollabEvent, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	room, ok := h.rooms[mapID]
+	if !ok {
+		ctx, cancel := context.WithCancel(context.Background())
+		ch, err := h.broker.Subscribe(ctx, mapID)
+		if err != nil {
+			cancel()
+			return nil, err
+		}
+		room = &collabRoom{conns: make(map[string]*collabConn), cancel: cancel}
+		h.rooms[mapID] = room
+		go h.dispatch(mapID, room, ch)
+	}
+
+	conn := &collabConn{presence: presence, events: make(chan *entity.CollabEvent, collabEventBuffer)}
+	room.conns[presence.ConnID] = conn
+	return conn.events, nil
+}
+
+// leave 注销本地连接，返回连接最后的在线状态；连接不存在（已被移除）时返回 false
+func (h *collabHub) leave(mapID, connID string) (entity.CollabPresence, bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	room, ok := h.rooms[mapID]
+	if !ok {
+		return entity.CollabPresence{}, false
+	}
+	conn, ok := room.conns[connID]
+	if !ok {
+		return entity.CollabPresence{}, false
+	}
+	h.removeLocked(mapID, room, connID)
+	return conn.presence, true
+}
+
+// presence 获取本地连接的在线状态
+func (h *collabHub) presence(mapID, connID string) (entity.CollabPresence, bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if room, ok := h.rooms[mapID]; ok {
+		if conn, ok := room.conns[connID]; ok {
+			return conn.presence, true
+		}
+	}
+	return entity.CollabPresence{}, false
+}
+
+// updatePresence 修改本地连接的在线状态并返回修改后的副本
+func (h *collabHub) updatePresence(mapID, connID string, update func(*entity.CollabPresence)) (entity.CollabPresence, bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if room, ok := h.rooms[mapID]; ok {
+		if conn, ok := room.conns[connID]; ok {
+			update(&conn.presence)
+			return conn.presence, true
+		}
+	}
+	return entity.CollabPresence{}, false
+}
+
+// removeLocked 移除连接并关闭其事件 channel，房间为空时退订消息频道，调用方需持有锁
+func (h *collabHub) removeLocked(mapID string, room *collabRoom, connID string) {
+	if conn, ok := room.conns[connID]; ok {
+		close(conn.events)
+		delete(room.conns, connID)
+	}
+	if len(room.conns) == 0 && h.rooms[mapID] == room {
+		room.cancel()
+		delete(h.rooms, mapID)
+	}
+}
+
+// dispatch 将消息频道中的事件分发给本地连接，不回传给事件来源连接
+// 消费过慢的连接直接移除，客户端重连后重新同步
+func (h *collabHub) dispatch(mapID string, room *collabRoom, ch <-chan []byte) {
+	for payload := range ch {
+		var event entity.CollabEvent
+		if err := json.Unmarshal(payload, &event); err != nil {
+			zlog.Warnf("failed to unmarshal collab event, mapID: %s: %v", mapID, err)
+			continue
+		}
+
+		h.mu.Lock()
+		for connID, conn := range room.conns {
+			if connID == event.ConnID {
+				continue
+			}
+			select {
+			case conn.events <- &event:
+			default:
+				zlog.Warnf("collab connection is too slow, removed, mapID: %s, connID: %s", mapID, connID)
+				h.removeLocked(mapID, room, connID)
+			}
+		}
+		h.mu.Unlock()
+	}
+
+	// 订阅意外中断时断开全部本地连接，由客户端重连
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.rooms[mapID] == room {
+		zlog.Warnf("collab subscription closed unexpectedly, mapID: %s", mapID)
+		for connID := range room.conns {
+			h.removeLocked(mapID, room, connID)
+		}
+	}
+}
@@ -0,0 +1,336 @@
+package mindmapservice
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"forge/biz/adapter"
+	"forge/biz/entity"
+	"forge/biz/repo"
+	"forge/biz/types"
+	"forge/pkg/log/zlog"
+	"forge/util"
+)
+
+// ListMindMapComments 获取导图的评论线程（协作成员均可查看）
+func (s *MindMapServiceImpl) ListMindMapComments(ctx context.Context, mapID string, req *types.ListMindMapCommentsParams) ([]*entity.MindMapCommentThread, error) {
+	if _, err := s.getMindMapForRole(ctx, mapID, entity.MindMapRoleViewer); err != nil {
+		return nil, err // getMindMapForRole已经包含权限验证
+	}
+
+	query := repo.MindMapCommentQuery{MapID: mapID}
+	if req != nil {
+		query.NodeID = req.NodeID
+		query.Resolved = req.Resolved
+	}
+	comments, err := s.mindMapRepo.ListMindMapComments(ctx, query)
+	if err != nil {
+		zlog.CtxErrorf(ctx, "failed to list mindmap comments: %v", err)
+		return nil, ErrInternalError
+	}
+	if err := s.fillCommentAuthors(ctx, comments); err != nil {
+		return nil, err
+	}
+
+	// 评论按创建时间排列，线程的首条评论总是先于其回复
+	threads := make([]*entity.MindMapCommentThread, 0)
+	threadIndex := make(map[string]*entity.MindMapCommentThread)
+	for _, comment := range comments {
+		if comment.IsThread() {
+			thread := &entity.MindMapCommentThread{Comment: comment}
+			threads = append(threads, thread)
+			threadIndex[comment.CommentID] = thread
+			continue
+		}
+		if thread, ok := threadIndex[comment.ParentID]; ok {
+			thread.Replies = append(thread.Replies, comment)
+		}
+	}
+
+	zlog.CtxInfof(ctx, "mindmap comments listed successfully, mapID: %s, threads: %d, comments: %d", mapID, len(threads), len(comments))
+	return threads, nil
+}
+
+// CreateMindMapComment 在节点上发起评论线程或回复已有线程（协作成员均可评论），并通知被@的用户
+func (s *MindMapServiceImpl) CreateMindMapComment(ctx context.Context, mapID string, req *types.CreateMindMapCommentParams) (*entity.MindMapComment, error) {
+	if req == nil || (req.NodeID == "" && req.ParentID == "") {
+		zlog.CtxErrorf(ctx, "nodeID or parentID is required")
+		return nil, ErrInvalidParams
+	}
+
+	mindMap, err := s.getMindMapForRole(ctx, mapID, entity.MindMapRoleViewer)
+	if err != nil {
+		return nil, err // getMindMapForRole已经包含权限验证
+	}
+	user, _ := entity.GetUser(ctx)
+
+	commentID, err := util.GenerateStringID()
+	if err != nil {
+		zlog.CtxErrorf(ctx, "failed to generate comment id: %v", err)
+		return nil, ErrInternalError
+	}
+	comment := &entity.MindMapComment{
+		CommentID: commentID,
+		MapID:     mapID,
+		NodeID:    req.NodeID,
+		UserID:    user.UserID,
+		Content:   req.Content,
+		Mentions:  req.Mentions,
+	}
+
+	if req.ParentID != "" {
+		// 回复不再嵌套，回复某条回复时归入同一线程
+		parent, err := s.getMindMapComment(ctx, mapID, req.ParentID)
+		if err != nil {
+			return nil, err
+		}
+		comment.ParentID = parent.CommentID
+		if !parent.IsThread() {
+			comment.ParentID = parent.ParentID
+		}
+		comment.NodeID = parent.NodeID
+	} else if _, ok := mindMap.Data.FindNode(req.NodeID); !ok {
+		zlog.CtxWarnf(ctx, "comment node not found, mapID: %s, nodeID: %s", mapID, req.NodeID)
+		return nil, fmt.Errorf("%w: 节点不存在", ErrInvalidParams)
+	}
+
+	if err := comment.Validate(); err != nil {
+		zlog.CtxErrorf(ctx, "invalid mindmap comment: %v", err)
+		return nil, fmt.Errorf("%w: %w", ErrInvalidParams, err)
+	}
+	if err := s.checkCommentMentions(ctx, mapID, comment.Mentions); err != nil {
+		return nil, err
+	}
+
+	if err := s.mindMapRepo.CreateMindMapComment(ctx, comment); err != nil {
+		zlog.CtxErrorf(ctx, "failed to create mindmap comment: %v", err)
+		return nil, ErrInternalError
+	}
+	comment.UserName = user.UserName
+	comment.Avatar = user.Avatar
+
+	s.notifyCommentMentions(ctx, mindMap, comment, comment.Mentions)
+
+	zlog.CtxInfof(ctx, "mindmap comment created successfully, mapID: %s, commentID: %s, parentID: %s", mapID, commentID, comment.ParentID)
+	return comment, nil
+}
+
+// UpdateMindMapComment 编辑评论内容（仅作者可编辑），只通知新增的被@用户
+func (s *MindMapServiceImpl) UpdateMindMapComment(ctx context.Context, mapID, commentID string, req *types.UpdateMindMapCommentParams) (*entity.MindMapComment, error) {
+	if req == nil {
+		zlog.CtxErrorf(ctx, "update comment params is required")
+		return nil, ErrInvalidParams
+	}
+
+	mindMap, err := s.getMindMapForRole(ctx, mapID, entity.MindMapRoleViewer)
+	if err != nil {
+		return nil, err // getMindMapForRole已经包含权限验证
+	}
+	user, _ := entity.GetUser(ctx)
+
+	comment, err := s.getMindMapComment(ctx, mapID, commentID)
+	if err != nil {
+		return nil, err
+	}
+	if comment.UserID != user.UserID {
+		zlog.CtxWarnf(ctx, "only author can edit comment, commentID: %s, userID: %s", commentID, user.UserID)
+		return nil, ErrPermissionDenied
+	}
+
+	previous := make(map[string]struct{}, len(comment.Mentions))
+	for _, userID := range comment.Mentions {
+		previous[userID] = struct{}{}
+	}
+
+	comment.Content = req.Content
+	comment.Mentions = req.Mentions
+	if err := comment.Validate(); err != nil {
+		zlog.CtxErrorf(ctx, "invalid mindmap comment: %v", err)
+		return nil, fmt.Errorf("%w: %w", ErrInvalidParams, err)
+	}
+	if err := s.checkCommentMentions(ctx, mapID, comment.Mentions); err != nil {
+		return nil, err
+	}
+
+	if err := s.mindMapRepo.UpdateMindMapComment(ctx, comment); err != nil {
+		if errors.Is(err, repo.ErrMindMapCommentNotFound) {
+			return nil, ErrCommentNotFound
+		}
+		zlog.CtxErrorf(ctx, "failed to update mindmap comment: %v", err)
+		return nil, ErrInternalError
+	}
+	comment.UserName = user.UserName
+	comment.Avatar = user.Avatar
+
+	added := make([]string, 0, len(comment.Mentions))
+	for _, userID := range comment.Mentions {
+		if _, ok := previous[userID]; !ok {
+			added = append(added, userID)
+		}
+	}
+	s.notifyCommentMentions(ctx, mindMap, comment, added)
+
+	zlog.CtxInfof(ctx, "mindmap comment updated successfully, mapID: %s, commentID: %s", mapID, commentID)
+	return comment, nil
+}
+
+// DeleteMindMapComment 删除评论（作者或导图所有者可删除），删除线程首条评论时一并删除其回复
+func (s *MindMapServiceImpl) DeleteMindMapComment(ctx context.Context, mapID, commentID string) error {
+	mindMap, err := s.getMindMapForRole(ctx, mapID, entity.MindMapRoleViewer)
+	if err != nil {
+		return err // getMindMapForRole已经包含权限验证
+	}
+	user, _ := entity.GetUser(ctx)
+
+	comment, err := s.getMindMapComment(ctx, mapID, commentID)
+	if err != nil {
+		return err
+	}
+	if comment.UserID != user.UserID && mindMap.Role != entity.MindMapRoleOwner {
+		zlog.CtxWarnf(ctx, "only author or owner can delete comment, commentID: %s, userID: %s", commentID, user.UserID)
+		return ErrPermissionDenied
+	}
+
+	if err := s.mindMapRepo.DeleteMindMapComment(ctx, mapID, commentID); err != nil {
+		if errors.Is(err, repo.ErrMindMapCommentNotFound) {
+			return ErrCommentNotFound
+		}
+		zlog.CtxErrorf(ctx, "failed to delete mindmap comment: %v", err)
+		return ErrInternalError
+	}
+
+	zlog.CtxInfof(ctx, "mindmap comment deleted successfully, mapID: %s, commentID: %s, operator: %s", mapID, commentID, user.UserID)
+	return nil
+}
+
+// ResolveMindMapComment 将评论线程标记为已解决或重新打开（线程发起人或拥有编辑权限的成员可操作）
+func (s *MindMapServiceImpl) ResolveMindMapComment(ctx context.Context, mapID, commentID string, resolved bool) error {
+	mindMap, err := s.getMindMapForRole(ctx, mapID, entity.MindMapRoleViewer)
+	if err != nil {
+		return err // getMindMapForRole已经包含权限验证
+	}
+	user, _ := entity.GetUser(ctx)
+
+	comment, err := s.getMindMapComment(ctx, mapID, commentID)
+	if err != nil {
+		return err
+	}
+	if !comment.IsThread() {
+		zlog.CtxWarnf(ctx, "only thread can be resolved, commentID: %s", commentID)
+		return ErrInvalidParams
+	}
+	if comment.UserID != user.UserID && !entity.MindMapRoleAllows(mindMap.Role, entity.MindMapRoleEditor) {
+		zlog.CtxWarnf(ctx, "comment resolve permission denied, commentID: %s, userID: %s, role: %s", commentID, user.UserID, mindMap.Role)
+		return ErrPermissionDenied
+	}
+
+	if comment.Resolved == resolved {
+		return nil
+	}
+	if err := s.mindMapRepo.SetMindMapCommentResolved(ctx, mapID, commentID, user.UserID, resolved); err != nil {
+		zlog.CtxErrorf(ctx, "failed to set mindmap comment resolved: %v", err)
+		return ErrInternalError
+	}
+
+	zlog.CtxInfof(ctx, "mindmap comment resolved updated successfully, mapID: %s, commentID: %s, resolved: %t", mapID, commentID, resolved)
+	return nil
+}
+
+// getMindMapComment 获取导图中的评论，不存在时返回 ErrCommentNotFound
+func (s *MindMapServiceImpl) getMindMapComment(ctx context.Context, mapID, commentID string) (*entity.MindMapComment, error) {
+	if commentID == "" {
+		zlog.CtxErrorf(ctx, "commentID is required")
+		return nil, ErrInvalidParams
+	}
+
+	comment, err := s.mindMapRepo.GetMindMapComment(ctx, mapID, commentID)
+	if err != nil {
+		zlog.CtxErrorf(ctx, "failed to get mindmap comment: %v", err)
+		return nil, ErrInternalError
+	}
+	if comment == nil {
+		zlog.CtxWarnf(ctx, "mindmap comment not found, mapID: %s, commentID: %s", mapID, commentID)
+		return nil, ErrCommentNotFound
+	}
+	return comment, nil
+}
+
+// checkCommentMentions 校验被@的用户均为导图协作成员，评论只对协作成员可见
+func (s *MindMapServiceImpl) checkCommentMentions(ctx context.Context, mapID string, mentions []string) error {
+	for _, userID := range mentions {
+		member, err := s.mindMapRepo.GetMindMapMember(ctx, mapID, userID)
+		if err != nil {
+			zlog.CtxErrorf(ctx, "failed to get mindmap member: %v", err)
+			return ErrInternalError
+		}
+		if member == nil {
+			zlog.CtxWarnf(ctx, "mentioned user is not a member, mapID: %s, userID: %s", mapID, userID)
+			return fmt.Errorf("%w: 被@的用户不是导图协作成员", ErrInvalidParams)
+		}
+	}
+	return nil
+}
+
+// fillCommentAuthors 填充评论作者的用户信息，用户已注销时保持为空
+func (s *MindMapServiceImpl) fillCommentAuthors(ctx context.Context, comments []*entity.MindMapComment) error {
+	authors := make(map[string]*entity.User)
+	for _, comment := range comments {
+		author, ok := authors[comment.UserID]
+		if !ok {
+			var err error
+			author, err = s.userRepo.GetUser(ctx, repo.NewUserQueryByID(comment.UserID))
+			if err != nil {
+				zlog.CtxErrorf(ctx, "failed to get comment author, userID: %s: %v", comment.UserID, err)
+				return ErrInternalError
+			}
+			authors[comment.UserID] = author
+		}
+		if author != nil {
+			comment.UserName = author.UserName
+			comment.Avatar = author.Avatar
+		}
+	}
+	return nil
+}
+
+// notifyCommentMentions 异步通知被@的用户（不包含作者本人），有邮箱时发送邮件，否则发送短信，失败仅记录日志
+func (s *MindMapServiceImpl) notifyCommentMentions(ctx context.Context, mindMap *entity.MindMap, comment *entity.MindMapComment, userIDs []string) {
+	recipients := make([]string, 0, len(userIDs))
+	for _, userID := range userIDs {
+		if userID != comment.UserID {
+			recipients = append(recipients, userID)
+		}
+	}
+	if len(recipients) == 0 {
+		return
+	}
+
+	mention := &adapter.MindMapMention{
+		AuthorName: comment.UserName,
+		MapID:      mindMap.MapID,
+		MapTitle:   mindMap.Title,
+		Content:    comment.Content,
+	}
+	if node, ok := mindMap.Data.FindNode(comment.NodeID); ok {
+		mention.NodeText = node.Data.Text
+	}
+
+	go func(ctx context.Context) {
+		for _, userID := range recipients {
+			recipient, err := s.userRepo.GetUser(ctx, repo.NewUserQueryByID(userID))
+			if err != nil || recipient == nil {
+				zlog.CtxWarnf(ctx, "failed to get mentioned user, userID: %s: %v", userID, err)
+				continue
+			}
+			if recipient.Email != "" {
+				err = s.notifier.SendMindMapMentionEmail(ctx, recipient.Email, mention)
+			} else if recipient.Phone != "" {
+				err = s.notifier.SendMindMapMentionSMS(ctx, recipient.Phone, mention)
+			}
+			if err != nil {
+				zlog.CtxWarnf(ctx, "failed to send mindmap mention, mapID: %s, userID: %s: %v", mention.MapID, userID, err)
+			}
+		}
+	}(context.WithoutCancel(ctx))
+}
@@ -0,0 +1,221 @@
+package mindmapservice
+
+import (
+	"context"
+	"strings"
+
+	"forge/biz/entity"
+	"forge/biz/types"
+	"forge/pkg/log/zlog"
+)
+
+const (
+	duplicateTitleSuffix = " - 副本"
+	maxMergeMindMaps     = 10 // 单次最多合并的导图数量
+)
+
+// DuplicateMindMap 复制思维导图（协作成员均可复制），当前用户成为副本的所有者
+func (s *MindMapServiceImpl) DuplicateMindMap(ctx context.Context, mapID string, req *types.DuplicateMindMapParams) (*entity.MindMap, error) {
+	source, err := s.GetMindMap(ctx, mapID)
+	if err != nil {
+		return nil, err // GetMindMap已经包含权限验证
+	}
+
+	title := req.Title
+	if title == "" {
+		title = truncateUTF8(source.Title, maxTitleLength-len(duplicateTitleSuffix)) + duplicateTitleSuffix
+	}
+
+	attachments, err := s.listSourceAttachments(ctx, mapID)
+	if err != nil {
+		return nil, err
+	}
+
+	mindMap, err := s.CreateMindMap(ctx, &types.CreateMindMapParams{
+		Title:  title,
+		Desc:   source.Desc,
+		Layout: source.Layout,
+		Data:   source.Data.Clone(),
+		Provenance: &entity.MindMapProvenance{
+			Type:    entity.ProvenanceDuplicate,
+			Sources: []entity.MindMapSource{entity.NewMindMapSource(source)},
+		},
+		Attachments: attachments,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	zlog.CtxInfof(ctx, "mindmap duplicated successfully, sourceMapID: %s, mapID: %s", mapID, mindMap.MapID)
+	return mindMap, nil
+}
+
+// ExtractMindMapSubtree 将导图中的一个分支提取为新导图，被提取的节点成为新导图的根节点
+// 从原导图中移除分支需要编辑权限，移除失败时撤销新导图的创建
+func (s *MindMapServiceImpl) ExtractMindMapSubtree(ctx context.Context, mapID string, req *types.ExtractMindMapSubtreeParams) (*entity.MindMap, error) {
+	if req == nil || req.NodeID == "" {
+		zlog.CtxErrorf(ctx, "nodeID is required")
+		return nil, ErrInvalidParams
+	}
+
+	required := entity.MindMapRoleViewer
+	if req.RemoveFromSource {
+		required = entity.MindMapRoleEditor
+	}
+	source, err := s.getMindMapForRole(ctx, mapID, required)
+	if err != nil {
+		return nil, err // getMindMapForRole已经包含权限验证
+	}
+
+	if req.RemoveFromSource {
+		if req.NodeID == source.Data.Data.UID {
+			zlog.CtxErrorf(ctx, "root node cannot be removed from source, mapID: %s", mapID)
+			return nil, ErrInvalidParams
+		}
+		if req.Version != nil && *req.Version != source.Version {
+			zlog.CtxWarnf(ctx, "mindmap version conflict, mapID: %s, expected: %d, current: %d", mapID, *req.Version, source.Version)
+			return nil, ErrVersionConflict
+		}
+	}
+
+	subtree, err := source.Data.Subtree(req.NodeID)
+	if err != nil {
+		zlog.CtxWarnf(ctx, "node not found in mindmap, mapID: %s, nodeID: %s", mapID, req.NodeID)
+		return nil, ErrInvalidParams
+	}
+
+	title := req.Title
+	if title == "" {
+		title = truncateUTF8(strings.TrimSpace(subtree.Data.Text), maxTitleLength)
+	}
+	if title == "" {
+		title = source.Title
+	}
+	layout := req.Layout
+	if layout == "" {
+		layout = source.Layout
+	}
+
+	attachments, err := s.listSourceAttachments(ctx, mapID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 分支引用的附件先复制到新导图，原导图的附件保留以便恢复历史版本
+	mindMap, err := s.CreateMindMap(ctx, &types.CreateMindMapParams{
+		Title:  title,
+		Layout: layout,
+		Data:   subtree,
+		Provenance: &entity.MindMapProvenance{
+			Type:    entity.ProvenanceExtract,
+			Sources: []entity.MindMapSource{entity.NewMindMapSource(source)},
+			NodeID:  req.NodeID,
+		},
+		Attachments: attachments,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if req.RemoveFromSource {
+		// 以读取时的版本作为期望版本，期间原导图被修改时整体失败
+		expectedVersion := source.Version
+		_, err := s.PatchMindMap(ctx, mapID, &types.PatchMindMapParams{
+			Ops:     []entity.MindMapPatchOp{{Op: entity.PatchOpDelete, NodeID: req.NodeID}},
+			Version: &expectedVersion,
+		})
+		if err != nil {
+			zlog.CtxWarnf(ctx, "failed to remove extracted subtree from source, mapID: %s: %v", mapID, err)
+			s.discardMindMap(ctx, mindMap.MapID)
+			return nil, err
+		}
+	}
+
+	zlog.CtxInfof(ctx, "mindmap subtree extracted successfully, sourceMapID: %s, nodeID: %s, mapID: %s, removed: %t", mapID, req.NodeID, mindMap.MapID, req.RemoveFromSource)
+	return mindMap, nil
+}
+
+// MergeMindMaps 将多张导图作为新根节点的子节点合并为新导图（需要对每张导图有查看权限）
+func (s *MindMapServiceImpl) MergeMindMaps(ctx context.Context, req *types.MergeMindMapsParams) (*entity.MindMap, error) {
+	if req == nil || len(req.MapIDs) < 2 || len(req.MapIDs) > maxMergeMindMaps {
+		zlog.CtxErrorf(ctx, "merge requires 2 to %d mindmaps", maxMergeMindMaps)
+		return nil, ErrInvalidParams
+	}
+
+	seen := make(map[string]struct{}, len(req.MapIDs))
+	sources := make([]*entity.MindMap, 0, len(req.MapIDs))
+	for _, mapID := range req.MapIDs {
+		if _, dup := seen[mapID]; dup {
+			zlog.CtxErrorf(ctx, "duplicate mapID in merge request: %s", mapID)
+			return nil, ErrInvalidParams
+		}
+		seen[mapID] = struct{}{}
+
+		source, err := s.GetMindMap(ctx, mapID)
+		if err != nil {
+			return nil, err // GetMindMap已经包含权限验证
+		}
+		sources = append(sources, source)
+	}
+
+	trees := make([]entity.MindMapData, 0, len(sources))
+	provenance := &entity.MindMapProvenance{Type: entity.ProvenanceMerge}
+	var attachments []*entity.MindMapAttachment
+	for _, source := range sources {
+		trees = append(trees, source.Data)
+		provenance.Sources = append(provenance.Sources, entity.NewMindMapSource(source))
+
+		sourceAttachments, err := s.listSourceAttachments(ctx, source.MapID)
+		if err != nil {
+			return nil, err
+		}
+		attachments = append(attachments, sourceAttachments...)
+	}
+
+	data, err := entity.MergeMindMapData(req.Title, trees, req.Duplicate)
+	if err != nil {
+		zlog.CtxErrorf(ctx, "failed to merge mindmaps: %v", err)
+		return nil, ErrInvalidParams
+	}
+
+	layout := req.Layout
+	if layout == "" {
+		layout = sources[0].Layout
+	}
+
+	mindMap, err := s.CreateMindMap(ctx, &types.CreateMindMapParams{
+		Title:       req.Title,
+		Desc:        req.Desc,
+		Layout:      layout,
+		Data:        data,
+		Provenance:  provenance,
+		Attachments: attachments,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	zlog.CtxInfof(ctx, "mindmaps merged successfully, sourceMapIDs: %v, mapID: %s, duplicate: %s", req.MapIDs, mindMap.MapID, req.Duplicate)
+	return mindMap, nil
+}
+
+// listSourceAttachments 获取来源导图的全部附件，用于复制到派生导图
+func (s *MindMapServiceImpl) listSourceAttachments(ctx context.Context, mapID string) ([]*entity.MindMapAttachment, error) {
+	attachments, err := s.mindMapRepo.ListMindMapAttachments(ctx, mapID, "")
+	if err != nil {
+		zlog.CtxErrorf(ctx, "failed to list mindmap attachments, mapID: %s: %v", mapID, err)
+		return nil, ErrInternalError
+	}
+	return attachments, nil
+}
+
+// discardMindMap 撤销刚创建的导图，失败时仅记录日志
+func (s *MindMapServiceImpl) discardMindMap(ctx context.Context, mapID string) {
+	if err := s.mindMapRepo.DeleteMindMap(ctx, mapID); err != nil {
+		zlog.CtxErrorf(ctx, "failed to discard mindmap, mapID: %s: %v", mapID, err)
+		return
+	}
+	if err := s.mindMapRepo.PurgeMindMap(ctx, mapID); err != nil {
+		zlog.CtxErrorf(ctx, "failed to purge discarded mindmap, mapID: %s: %v", mapID, err)
+	}
+}
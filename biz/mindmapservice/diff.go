@@ -0,0 +1,66 @@
+package mindmapservice
+
+import (
+	"context"
+
+	"forge/biz/entity"
+	"forge/biz/types"
+	"forge/pkg/log/zlog"
+)
+
+// DiffMindMap 比较思维导图两个版本之间，或某个版本与提交的导图数据之间的结构差异（协作成员均可查看）
+func (s *MindMapServiceImpl) DiffMindMap(ctx context.Context, mapID string, req *types.DiffMindMapParams) (*entity.MindMapDiff, error) {
+	if req == nil || req.FromVersion < 0 || req.ToVersion < 0 {
+		zlog.CtxErrorf(ctx, "invalid diff versions")
+		return nil, ErrInvalidParams
+	}
+
+	mindMap, err := s.GetMindMap(ctx, mapID)
+	if err != nil {
+		return nil, err // GetMindMap已经包含权限验证
+	}
+
+	base, err := s.mindMapDataAtVersion(ctx, mindMap, req.FromVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	var target entity.MindMapData
+	if req.Data != nil {
+		// 提交的导图数据同样按保存时的规则校验，避免超大的树
+		proposal := &entity.MindMap{Layout: mindMap.Layout, Data: *req.Data}
+		if err := proposal.ValidateTree(entity.TreeValidationRulesFromConfig()); err != nil {
+			zlog.CtxWarnf(ctx, "invalid proposed mindmap data, mapID: %s: %v", mapID, err)
+			return nil, newInvalidDataError(err)
+		}
+		target = *req.Data
+	} else {
+		target, err = s.mindMapDataAtVersion(ctx, mindMap, req.ToVersion)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	diff := entity.DiffMindMapData(&base, &target)
+	zlog.CtxInfof(ctx, "mindmap diff computed successfully, mapID: %s, from: %d, to: %d, proposed: %t, changes: %d",
+		mapID, req.FromVersion, req.ToVersion, req.Data != nil, len(diff.Changes))
+	return diff, nil
+}
+
+// mindMapDataAtVersion 获取导图指定版本的数据，版本为 0 或当前版本时使用当前数据
+func (s *MindMapServiceImpl) mindMapDataAtVersion(ctx context.Context, mindMap *entity.MindMap, version int64) (entity.MindMapData, error) {
+	if version == 0 || version == mindMap.Version {
+		return mindMap.Data, nil
+	}
+
+	revision, err := s.mindMapRepo.GetMindMapRevision(ctx, mindMap.MapID, version)
+	if err != nil {
+		zlog.CtxErrorf(ctx, "failed to get mindmap revision: %v", err)
+		return entity.MindMapData{}, ErrInternalError
+	}
+	if revision == nil {
+		zlog.CtxWarnf(ctx, "mindmap revision not found, mapID: %s, version: %d", mindMap.MapID, version)
+		return entity.MindMapData{}, ErrRevisionNotFound
+	}
+	return revision.Data, nil
+}
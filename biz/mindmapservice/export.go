@@ -0,0 +1,105 @@
+package mindmapservice
+
+import (
+	"context"
+	"regexp"
+	"strings"
+
+	"forge/biz/adapter"
+	"forge/biz/entity"
+	"forge/biz/types"
+	"forge/pkg/log/zlog"
+)
+
+// 仅支持导出的图片与文档格式
+const (
+	FormatSVG  = "svg"
+	FormatPNG  = "png"
+	FormatPDF  = "pdf"
+	FormatDOCX = "docx"
+	FormatPPTX = "pptx"
+)
+
+// 导出 PNG 时的缩放倍数，保证高分屏下清晰
+const exportPNGScale = 2
+
+// 导出格式对应的文件扩展名与 Content-Type
+var exportFormats = map[string]struct {
+	ext         string
+	contentType string
+}{
+	FormatMarkdown: {ext: ".md", contentType: "text/markdown; charset=utf-8"},
+	FormatOPML:     {ext: ".opml", contentType: "text/x-opml; charset=utf-8"},
+	FormatFreeMind: {ext: ".mm", contentType: "application/x-freemind; charset=utf-8"},
+	FormatSVG:      {ext: ".svg", contentType: "image/svg+xml"},
+	FormatPNG:      {ext: ".png", contentType: "image/png"},
+	FormatPDF:      {ext: ".pdf", contentType: "application/pdf"},
+	FormatDOCX:     {ext: ".docx", contentType: "application/vnd.openxmlformats-officedocument.wordprocessingml.document"},
+	FormatPPTX:     {ext: ".pptx", contentType: "application/vnd.openxmlformats-officedocument.presentationml.presentation"},
+}
+
+// 文件名中不允许出现的字符
+var invalidFileNameChars = regexp.MustCompile(`[\\/:*?"<>|\x00-\x1f]`)
+
+// ExportMindMap 将思维导图导出为指定格式的文件（用户只能导出自己的思维导图）
+func (s *MindMapServiceImpl) ExportMindMap(ctx context.Context, mapID string, format string) (*types.ExportMindMapResult, error) {
+	if _, ok := exportFormats[format]; !ok {
+		zlog.CtxWarnf(ctx, "unsupported export format: %q", format)
+		return nil, ErrUnsupportedFormat
+	}
+
+	mindMap, err := s.GetMindMap(ctx, mapID)
+	if err != nil {
+		return nil, err // GetMindMap已经包含权限验证
+	}
+
+	return s.exportMindMap(ctx, mindMap, format)
+}
+
+// exportMindMap 渲染导出文件，调用方需已完成权限校验与格式校验
+func (s *MindMapServiceImpl) exportMindMap(ctx context.Context, mindMap *entity.MindMap, format string) (*types.ExportMindMapResult, error) {
+	spec := exportFormats[format]
+	content, err := s.renderMindMap(ctx, mindMap, format)
+	if err != nil {
+		zlog.CtxErrorf(ctx, "failed to export mindmap %s as %s: %v", mindMap.MapID, format, err)
+		return nil, ErrInternalError
+	}
+
+	zlog.CtxInfof(ctx, "mindmap exported successfully, mapID: %s, format: %s, size: %d", mindMap.MapID, format, len(content))
+	return &types.ExportMindMapResult{
+		FileName:    exportFileName(mindMap) + spec.ext,
+		ContentType: spec.contentType,
+		Content:     content,
+	}, nil
+}
+
+func (s *MindMapServiceImpl) renderMindMap(ctx context.Context, mindMap *entity.MindMap, format string) ([]byte, error) {
+	switch format {
+	case FormatSVG:
+		return s.renderer.RenderSVG(ctx, mindMap)
+	case FormatPNG:
+		return s.renderer.RenderPNG(ctx, mindMap, adapter.RenderOptions{Scale: exportPNGScale})
+	case FormatPDF:
+		return s.documentExporter.ExportPDF(ctx, mindMap)
+	case FormatDOCX:
+		return s.documentExporter.ExportDOCX(ctx, mindMap)
+	case FormatPPTX:
+		return s.documentExporter.ExportPPTX(ctx, mindMap)
+	case FormatMarkdown:
+		return renderMarkdown(mindMap), nil
+	case FormatOPML:
+		return renderOPML(mindMap)
+	case FormatFreeMind:
+		return renderFreeMind(mindMap)
+	}
+	return nil, ErrUnsupportedFormat
+}
+
+// exportFileName 根据导图标题生成安全的文件名（不含扩展名）
+func exportFileName(mindMap *entity.MindMap) string {
+	name := strings.TrimSpace(invalidFileNameChars.ReplaceAllString(mindMap.Title, "_"))
+	if name == "" {
+		name = mindMap.MapID
+	}
+	return name
+}
@@ -0,0 +1,335 @@
+package mindmapservice
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"forge/biz/entity"
+	"forge/biz/repo"
+	"forge/biz/types"
+	"forge/pkg/log/zlog"
+	"forge/util"
+)
+
+// 单次最多批量移动的导图数量
+const maxMoveMindMaps = 100
+
+// ListMindMapFolders 获取当前用户的全部文件夹，由客户端按 ParentID 组装为树
+func (s *MindMapServiceImpl) ListMindMapFolders(ctx context.Context) ([]*entity.MindMapFolder, error) {
+	user, ok := entity.GetUser(ctx)
+	if !ok {
+		zlog.CtxErrorf(ctx, "failed to get user from context")
+		return nil, ErrPermissionDenied
+	}
+
+	folders, err := s.mindMapRepo.ListMindMapFolders(ctx, user.UserID)
+	if err != nil {
+		zlog.CtxErrorf(ctx, "failed to list mindmap folders: %v", err)
+		return nil, ErrInternalError
+	}
+	return folders, nil
+}
+
+// CreateMindMapFolder 创建文件夹
+func (s *MindMapServiceImpl) CreateMindMapFolder(ctx context.Context, req *types.CreateMindMapFolderParams) (*entity.MindMapFolder, error) {
+	user, ok := entity.GetUser(ctx)
+	if !ok {
+		zlog.CtxErrorf(ctx, "failed to get user from context")
+		return nil, ErrPermissionDenied
+	}
+
+	folders, err := s.loadMindMapFolders(ctx, user.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if len(folders) >= entity.MaxMindMapFolders {
+		zlog.CtxWarnf(ctx, "too many mindmap folders, userID: %s", user.UserID)
+		return nil, ErrInvalidParams
+	}
+
+	folderID, err := util.GenerateStringID()
+	if err != nil {
+		zlog.CtxErrorf(ctx, "failed to generate folder id: %v", err)
+		return nil, ErrInternalError
+	}
+	folder := &entity.MindMapFolder{
+		FolderID: folderID,
+		UserID:   user.UserID,
+		ParentID: req.ParentID,
+		Name:     strings.TrimSpace(req.Name),
+	}
+	if err := checkMindMapFolder(ctx, folders, folder); err != nil {
+		return nil, err
+	}
+
+	if err := s.mindMapRepo.CreateMindMapFolder(ctx, folder); err != nil {
+		zlog.CtxErrorf(ctx, "failed to create mindmap folder: %v", err)
+		return nil, ErrInternalError
+	}
+
+	zlog.CtxInfof(ctx, "mindmap folder created successfully, folderID: %s, userID: %s", folderID, user.UserID)
+	return folder, nil
+}
+
+// UpdateMindMapFolder 重命名文件夹或移动到其他文件夹下，不能移动到自身或其子文件夹下
+func (s *MindMapServiceImpl) UpdateMindMapFolder(ctx context.Context, folderID string, req *types.UpdateMindMapFolderParams) (*entity.MindMapFolder, error) {
+	user, ok := entity.GetUser(ctx)
+	if !ok {
+		zlog.CtxErrorf(ctx, "failed to get user from context")
+		return nil, ErrPermissionDenied
+	}
+
+	folders, err := s.loadMindMapFolders(ctx, user.UserID)
+	if err != nil {
+		return nil, err
+	}
+	existing, ok := folders[folderID]
+	if !ok {
+		zlog.CtxWarnf(ctx, "mindmap folder not found, folderID: %s, userID: %s", folderID, user.UserID)
+		return nil, ErrFolderNotFound
+	}
+
+	folder := *existing
+	if req.Name != nil {
+		folder.Name = strings.TrimSpace(*req.Name)
+	}
+	if req.ParentID != nil {
+		folder.ParentID = *req.ParentID
+	}
+	if err := checkMindMapFolder(ctx, folders, &folder); err != nil {
+		return nil, err
+	}
+
+	if err := s.mindMapRepo.UpdateMindMapFolder(ctx, &folder); err != nil {
+		if errors.Is(err, repo.ErrMindMapFolderNotFound) {
+			return nil, ErrFolderNotFound
+		}
+		zlog.CtxErrorf(ctx, "failed to update mindmap folder: %v", err)
+		return nil, ErrInternalError
+	}
+
+	zlog.CtxInfof(ctx, "mindmap folder updated successfully, folderID: %s, userID: %s", folderID, user.UserID)
+	return &folder, nil
+}
+
+// DeleteMindMapFolder 删除文件夹及其子文件夹，其中的导图移回顶层（导图本身不受影响）
+func (s *MindMapServiceImpl) DeleteMindMapFolder(ctx context.Context, folderID string) error {
+	user, ok := entity.GetUser(ctx)
+	if !ok {
+		zlog.CtxErrorf(ctx, "failed to get user from context")
+		return ErrPermissionDenied
+	}
+
+	folders, err := s.loadMindMapFolders(ctx, user.UserID)
+	if err != nil {
+		return err
+	}
+	if _, ok := folders[folderID]; !ok {
+		zlog.CtxWarnf(ctx, "mindmap folder not found, folderID: %s, userID: %s", folderID, user.UserID)
+		return ErrFolderNotFound
+	}
+
+	folderIDs := []string{folderID}
+	for id := range folders {
+		if id != folderID && isMindMapFolderAncestor(folders, folderID, id) {
+			folderIDs = append(folderIDs, id)
+		}
+	}
+	if err := s.mindMapRepo.DeleteMindMapFolders(ctx, user.UserID, folderIDs); err != nil {
+		zlog.CtxErrorf(ctx, "failed to delete mindmap folders: %v", err)
+		return ErrInternalError
+	}
+
+	zlog.CtxInfof(ctx, "mindmap folder deleted successfully, folderID: %s, count: %d, userID: %s", folderID, len(folderIDs), user.UserID)
+	return nil
+}
+
+// MoveMindMapsToFolder 批量将当前用户可访问的导图移入文件夹，无权访问的导图忽略
+func (s *MindMapServiceImpl) MoveMindMapsToFolder(ctx context.Context, req *types.MoveMindMapsToFolderParams) (int64, error) {
+	user, ok := entity.GetUser(ctx)
+	if !ok {
+		zlog.CtxErrorf(ctx, "failed to get user from context")
+		return 0, ErrPermissionDenied
+	}
+
+	if req == nil || len(req.MapIDs) == 0 || len(req.MapIDs) > maxMoveMindMaps {
+		zlog.CtxErrorf(ctx, "move requires 1 to %d mindmaps", maxMoveMindMaps)
+		return 0, ErrInvalidParams
+	}
+
+	folderID := req.FolderID
+	if folderID == entity.MindMapRootFolder {
+		folderID = ""
+	}
+	if folderID != "" {
+		folder, err := s.mindMapRepo.GetMindMapFolder(ctx, folderID)
+		if err != nil {
+			zlog.CtxErrorf(ctx, "failed to get mindmap folder: %v", err)
+			return 0, ErrInternalError
+		}
+		if folder == nil || folder.UserID != user.UserID {
+			zlog.CtxWarnf(ctx, "mindmap folder not found, folderID: %s, userID: %s", folderID, user.UserID)
+			return 0, ErrFolderNotFound
+		}
+	}
+
+	moved, err := s.mindMapRepo.MoveMindMapsToFolder(ctx, user.UserID, req.MapIDs, folderID)
+	if err != nil {
+		zlog.CtxErrorf(ctx, "failed to move mindmaps to folder: %v", err)
+		return 0, ErrInternalError
+	}
+
+	zlog.CtxInfof(ctx, "mindmaps moved to folder successfully, folderID: %s, requested: %d, moved: %d, userID: %s", folderID, len(req.MapIDs), moved, user.UserID)
+	return moved, nil
+}
+
+// StarMindMap 收藏或取消收藏导图（协作成员均可操作，仅对自己生效）
+func (s *MindMapServiceImpl) StarMindMap(ctx context.Context, mapID string, starred bool) error {
+	user, _, err := s.authorize(ctx, mapID, entity.MindMapRoleViewer)
+	if err != nil {
+		return err
+	}
+
+	if err := s.mindMapRepo.SetMindMapStarred(ctx, mapID, user.UserID, starred); err != nil {
+		if errors.Is(err, repo.ErrMindMapMemberNotFound) {
+			return ErrMindMapNotFound
+		}
+		zlog.CtxErrorf(ctx, "failed to set mindmap starred: %v", err)
+		return ErrInternalError
+	}
+
+	zlog.CtxInfof(ctx, "mindmap starred updated, mapID: %s, userID: %s, starred: %t", mapID, user.UserID, starred)
+	return nil
+}
+
+// PinMindMap 置顶或取消置顶导图（协作成员均可操作，仅对自己生效）
+func (s *MindMapServiceImpl) PinMindMap(ctx context.Context, mapID string, pinned bool) error {
+	user, _, err := s.authorize(ctx, mapID, entity.MindMapRoleViewer)
+	if err != nil {
+		return err
+	}
+
+	if err := s.mindMapRepo.SetMindMapPinned(ctx, mapID, user.UserID, pinned); err != nil {
+		if errors.Is(err, repo.ErrMindMapMemberNotFound) {
+			return ErrMindMapNotFound
+		}
+		zlog.CtxErrorf(ctx, "failed to set mindmap pinned: %v", err)
+		return ErrInternalError
+	}
+
+	zlog.CtxInfof(ctx, "mindmap pinned updated, mapID: %s, userID: %s, pinned: %t", mapID, user.UserID, pinned)
+	return nil
+}
+
+// SetMindMapTags 整体替换导图的标签（协作成员均可操作，仅对自己生效）
+func (s *MindMapServiceImpl) SetMindMapTags(ctx context.Context, mapID string, tags []string) ([]string, error) {
+	user, _, err := s.authorize(ctx, mapID, entity.MindMapRoleViewer)
+	if err != nil {
+		return nil, err
+	}
+
+	normalized, err := entity.NormalizeMindMapTags(tags)
+	if err != nil {
+		zlog.CtxErrorf(ctx, "invalid mindmap tags: %v", err)
+		return nil, newInvalidDataError(err)
+	}
+
+	if err := s.mindMapRepo.SetMindMapTags(ctx, mapID, user.UserID, normalized); err != nil {
+		zlog.CtxErrorf(ctx, "failed to set mindmap tags: %v", err)
+		return nil, ErrInternalError
+	}
+
+	zlog.CtxInfof(ctx, "mindmap tags updated, mapID: %s, userID: %s, count: %d", mapID, user.UserID, len(normalized))
+	return normalized, nil
+}
+
+// ListMindMapTags 获取当前用户使用过的标签及对应的导图数量
+func (s *MindMapServiceImpl) ListMindMapTags(ctx context.Context) ([]*entity.MindMapTagCount, error) {
+	user, ok := entity.GetUser(ctx)
+	if !ok {
+		zlog.CtxErrorf(ctx, "failed to get user from context")
+		return nil, ErrPermissionDenied
+	}
+
+	counts, err := s.mindMapRepo.ListMindMapTagCounts(ctx, user.UserID)
+	if err != nil {
+		zlog.CtxErrorf(ctx, "failed to list mindmap tags: %v", err)
+		return nil, ErrInternalError
+	}
+	return counts, nil
+}
+
+// loadMindMapFolders 获取用户的全部文件夹，按标识索引
+func (s *MindMapServiceImpl) loadMindMapFolders(ctx context.Context, userID string) (map[string]*entity.MindMapFolder, error) {
+	folders, err := s.mindMapRepo.ListMindMapFolders(ctx, userID)
+	if err != nil {
+		zlog.CtxErrorf(ctx, "failed to list mindmap folders: %v", err)
+		return nil, ErrInternalError
+	}
+	byID := make(map[string]*entity.MindMapFolder, len(folders))
+	for _, folder := range folders {
+		byID[folder.FolderID] = folder
+	}
+	return byID, nil
+}
+
+// checkMindMapFolder 校验新建或修改后的文件夹：名称合法且同级不重名，上级文件夹存在且不形成循环，层级不超过限制
+func checkMindMapFolder(ctx context.Context, folders map[string]*entity.MindMapFolder, folder *entity.MindMapFolder) error {
+	if err := folder.Validate(); err != nil {
+		zlog.CtxErrorf(ctx, "mindmap folder validation failed: %v", err)
+		return newInvalidDataError(err)
+	}
+
+	depth := 1
+	if folder.ParentID != "" {
+		if _, ok := folders[folder.ParentID]; !ok {
+			zlog.CtxWarnf(ctx, "parent folder not found, parentID: %s", folder.ParentID)
+			return ErrFolderNotFound
+		}
+		if isMindMapFolderAncestor(folders, folder.FolderID, folder.ParentID) {
+			zlog.CtxWarnf(ctx, "folder cannot be moved into itself or its subfolders, folderID: %s", folder.FolderID)
+			return ErrInvalidParams
+		}
+		for parent, ok := folders[folder.ParentID]; ok; parent, ok = folders[parent.ParentID] {
+			depth++
+		}
+	}
+	if depth+mindMapFolderHeight(folders, folder.FolderID) > entity.MaxMindMapFolderDepth {
+		zlog.CtxWarnf(ctx, "mindmap folder too deep, folderID: %s", folder.FolderID)
+		return ErrInvalidParams
+	}
+
+	for _, other := range folders {
+		if other.FolderID != folder.FolderID && other.ParentID == folder.ParentID && strings.EqualFold(other.Name, folder.Name) {
+			return ErrFolderNameExists
+		}
+	}
+	return nil
+}
+
+// isMindMapFolderAncestor 判断 ancestorID 是否为 folderID 自身或其上级文件夹
+func isMindMapFolderAncestor(folders map[string]*entity.MindMapFolder, ancestorID, folderID string) bool {
+	for id := folderID; id != ""; {
+		if id == ancestorID {
+			return true
+		}
+		folder, ok := folders[id]
+		if !ok {
+			return false
+		}
+		id = folder.ParentID
+	}
+	return false
+}
+
+// mindMapFolderHeight 文件夹下子文件夹的最大层数，没有子文件夹时为 0
+func mindMapFolderHeight(folders map[string]*entity.MindMapFolder, folderID string) int {
+	height := 0
+	for _, folder := range folders {
+		if folder.ParentID == folderID && folder.FolderID != folderID {
+			height = max(height, 1+mindMapFolderHeight(folders, folder.FolderID))
+		}
+	}
+	return height
+}
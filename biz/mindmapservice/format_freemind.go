@@ -0,0 +1,253 @@
+package mindmapservice
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	"forge/biz/entity"
+)
+
+// freeMindMap FreeMind .mm 文档结构
+type freeMindMap struct {
+	XMLName xml.Name       `xml:"map"`
+	Version string         `xml:"version,attr"`
+	Node    []freeMindNode `xml:"node"`
+}
+
+// freeMindNode FreeMind 节点，文本可能位于 TEXT 属性或 TYPE="NODE" 的富文本中
+type freeMindNode struct {
+	ID              string                `xml:"ID,attr,omitempty"`
+	Text            string                `xml:"TEXT,attr,omitempty"`
+	Link            string                `xml:"LINK,attr,omitempty"`
+	Folded          string                `xml:"FOLDED,attr,omitempty"`
+	Color           string                `xml:"COLOR,attr,omitempty"`
+	BackgroundColor string                `xml:"BACKGROUND_COLOR,attr,omitempty"`
+	Icons           []freeMindIcon        `xml:"icon"`
+	Attributes      []freeMindAttribute   `xml:"attribute"`
+	RichContent     []freeMindRichContent `xml:"richcontent"`
+	Children        []freeMindNode        `xml:"node"`
+}
+
+type freeMindIcon struct {
+	Builtin string `xml:"BUILTIN,attr"`
+}
+
+// freeMindAttribute FreeMind 节点属性，同名属性可出现多次
+type freeMindAttribute struct {
+	Name  string `xml:"NAME,attr"`
+	Value string `xml:"VALUE,attr"`
+}
+
+type freeMindRichContent struct {
+	Type  string `xml:"TYPE,attr"`
+	Inner []byte `xml:",innerxml"`
+}
+
+var (
+	freeMindPriorityIcon = regexp.MustCompile(`^full-([1-9])$`)
+	hexColorPattern      = regexp.MustCompile(`^#([0-9a-fA-F]{3}|[0-9a-fA-F]{6})$`)
+	whitespacePattern    = regexp.MustCompile(`\s+`)
+	tabReferencePattern  = regexp.MustCompile(`&#(0*9|[xX]0*9);`)
+)
+
+// tabPlaceholder 解析富文本时暂代以字符引用写出的制表符（私有区字符），避免被当作排版空白合并
+const tabPlaceholder = "\ue009"
+
+// FreeMind 富文本类型
+const (
+	freeMindRichNode = "NODE"
+	freeMindRichNote = "NOTE"
+)
+
+// 节点属性名，每个标签单独一个属性
+const (
+	freeMindAttrTag       = "tag"
+	freeMindAttrLinkTitle = "linkTitle"
+)
+
+// parseFreeMind 解析 FreeMind .mm 文档
+func parseFreeMind(content []byte) (*importedMindMap, error) {
+	var doc freeMindMap
+	decoder := xml.NewDecoder(bytes.NewReader(content))
+	decoder.CharsetReader = xmlCharsetReader
+	if err := decoder.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("FreeMind 格式错误: %w", err)
+	}
+	if len(doc.Node) == 0 {
+		return nil, errors.New("FreeMind 文档中没有节点")
+	}
+
+	root := &outlineNode{}
+	for _, node := range doc.Node {
+		root.children = append(root.children, freeMindToOutlineNode(node))
+	}
+	return outlineResult(root, "")
+}
+
+func freeMindToOutlineNode(node freeMindNode) *outlineNode {
+	data := entity.NodeData{
+		Text:            node.Text,
+		Hyperlink:       node.Link,
+		Color:           sanitizeColor(node.Color),
+		BackgroundColor: sanitizeColor(node.BackgroundColor),
+		Collapsed:       node.Folded == "true",
+	}
+	// full-1 ~ full-9 为 FreeMind 内置的优先级图标
+	for _, icon := range node.Icons {
+		if m := freeMindPriorityIcon.FindStringSubmatch(icon.Builtin); m != nil && data.Priority == 0 {
+			data.Priority = int(m[1][0] - '0')
+		} else if data.Icon == "" {
+			data.Icon = icon.Builtin
+		}
+	}
+	for _, attr := range node.Attributes {
+		switch attr.Name {
+		case freeMindAttrTag:
+			data.Tags = append(data.Tags, attr.Value)
+		case freeMindAttrLinkTitle:
+			data.HyperlinkTitle = attr.Value
+		}
+	}
+	for _, rich := range node.RichContent {
+		switch strings.ToUpper(rich.Type) {
+		case freeMindRichNode:
+			if data.Text == "" {
+				data.Text = strings.TrimSpace(htmlToText(rich.Inner))
+			}
+		case freeMindRichNote:
+			data.Note = strings.Trim(htmlToText(rich.Inner), "\n")
+		}
+	}
+
+	result := &outlineNode{data: data}
+	for _, child := range node.Children {
+		result.children = append(result.children, freeMindToOutlineNode(child))
+	}
+	return result
+}
+
+// htmlToText 提取 FreeMind 富文本（XHTML）中的纯文本，段落与换行转换为换行符
+func htmlToText(inner []byte) string {
+	var sb strings.Builder
+	// 排版产生的制表符不会写成字符引用，字符引用形式的制表符属于正文内容
+	content := tabReferencePattern.ReplaceAll(inner, []byte(tabPlaceholder))
+	decoder := xml.NewDecoder(bytes.NewReader(content))
+	decoder.Strict = false
+	decoder.AutoClose = xml.HTMLAutoClose
+	decoder.Entity = xml.HTMLEntity
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			if err != io.EOF {
+				// 无法解析的富文本退化为原始内容
+				return string(inner)
+			}
+			break
+		}
+		switch t := token.(type) {
+		case xml.CharData:
+			sb.WriteString(whitespacePattern.ReplaceAllString(string(t), " "))
+		case xml.StartElement:
+			if t.Name.Local == "br" {
+				sb.WriteString("\n")
+			}
+		case xml.EndElement:
+			switch t.Name.Local {
+			case "p", "div", "li", "h1", "h2", "h3", "h4", "h5", "h6":
+				sb.WriteString("\n")
+			}
+		}
+	}
+	// 仅去除排版产生的 ASCII 空白，&nbsp; 表示的行首缩进还原为空格
+	lines := strings.Split(sb.String(), "\n")
+	for i := range lines {
+		line := strings.ReplaceAll(strings.Trim(lines[i], " \t\r"), "\u00a0", " ")
+		lines[i] = strings.ReplaceAll(line, tabPlaceholder, "\t")
+	}
+	return strings.Join(lines, "\n")
+}
+
+// sanitizeColor 仅保留十六进制颜色，其他格式（如颜色名）丢弃
+func sanitizeColor(color string) string {
+	if !hexColorPattern.MatchString(color) {
+		return ""
+	}
+	return strings.ToLower(color)
+}
+
+// xmlCharsetReader 导入文件仅支持 UTF-8（及其子集 ASCII）编码
+func xmlCharsetReader(charset string, input io.Reader) (io.Reader, error) {
+	switch strings.ToLower(charset) {
+	case "", "utf-8", "utf8", "us-ascii", "ascii":
+		return input, nil
+	}
+	return nil, fmt.Errorf("不支持的文件编码: %s", charset)
+}
+
+// renderFreeMind 将导图渲染为 FreeMind .mm 文档
+func renderFreeMind(mindMap *entity.MindMap) ([]byte, error) {
+	doc := freeMindMap{
+		Version: "1.0.1",
+		Node:    []freeMindNode{mindMapToFreeMindNode(mindMap.Data)},
+	}
+	return xml.MarshalIndent(doc, "", "  ")
+}
+
+func mindMapToFreeMindNode(node entity.MindMapData) freeMindNode {
+	data := node.Data
+	result := freeMindNode{
+		Text:            data.Text,
+		Link:            data.Hyperlink,
+		Color:           data.Color,
+		BackgroundColor: data.BackgroundColor,
+	}
+	if data.UID != "" {
+		result.ID = "ID_" + data.UID
+	}
+	if data.Collapsed && len(node.Children) > 0 {
+		result.Folded = "true"
+	}
+	if data.Priority > 0 {
+		result.Icons = append(result.Icons, freeMindIcon{Builtin: fmt.Sprintf("full-%d", data.Priority)})
+	}
+	if data.Icon != "" {
+		result.Icons = append(result.Icons, freeMindIcon{Builtin: data.Icon})
+	}
+	for _, tag := range data.Tags {
+		result.Attributes = append(result.Attributes, freeMindAttribute{Name: freeMindAttrTag, Value: tag})
+	}
+	if data.HyperlinkTitle != "" {
+		result.Attributes = append(result.Attributes, freeMindAttribute{Name: freeMindAttrLinkTitle, Value: data.HyperlinkTitle})
+	}
+	if data.Note != "" {
+		result.RichContent = append(result.RichContent, freeMindRichContent{
+			Type:  freeMindRichNote,
+			Inner: textToHTML(data.Note),
+		})
+	}
+	for _, child := range node.Children {
+		result.Children = append(result.Children, mindMapToFreeMindNode(child))
+	}
+	return result
+}
+
+// textToHTML 将纯文本备注转换为 FreeMind 富文本（XHTML），每行一个段落
+func textToHTML(text string) []byte {
+	var buf bytes.Buffer
+	buf.WriteString("<html><head></head><body>")
+	for _, line := range strings.Split(text, "\n") {
+		buf.WriteString("<p>")
+		// 行首缩进使用 &nbsp; 保留，避免被当作排版空白丢弃
+		trimmed := strings.TrimLeft(line, " ")
+		buf.WriteString(strings.Repeat("&#160;", len(line)-len(trimmed)))
+		_ = xml.EscapeText(&buf, []byte(trimmed))
+		buf.WriteString("</p>")
+	}
+	buf.WriteString("</body></html>")
+	return buf.Bytes()
+}
@@ -0,0 +1,23 @@
+package mindmapservice
+
+import (
+	"reflect"
+	"testing"
+)
+
+// TestFreeMindRoundTrip FreeMind 文档没有标题，导入时使用根节点文本，只比较节点数据
+func TestFreeMindRoundTrip(t *testing.T) {
+	mindMap := roundTripMindMap()
+	content, err := renderFreeMind(mindMap)
+	if err != nil {
+		t.Fatalf("renderFreeMind: %v", err)
+	}
+
+	imported, err := parseFreeMind(content)
+	if err != nil {
+		t.Fatalf("parseFreeMind: %v", err)
+	}
+	if want := expectedRoundTrip(mindMap.Data); !reflect.DeepEqual(imported.Data, want) {
+		t.Errorf("round trip mismatch\nfreemind:\n%s\ngot:  %+v\nwant: %+v", content, imported.Data, want)
+	}
+}
@@ -0,0 +1,317 @@
+package mindmapservice
+
+import (
+	"encoding/json"
+	"errors"
+	"regexp"
+	"strings"
+
+	"forge/biz/entity"
+)
+
+var (
+	mdHeadingPattern = regexp.MustCompile(`^ {0,3}(#{1,6})\s+(.*?)(?:\s+#+)?\s*$`)
+	mdBulletPattern  = regexp.MustCompile(`^([ \t]*)((?:[-*+]|\d+[.)])\s+)(.*?)\s*$`)
+	mdLinkPattern    = regexp.MustCompile(`^\[([^\]]+)\]\(([^)\s]+)\)$`)
+	mdMetaPattern    = regexp.MustCompile(`^<!--\s*forge:(.*?)\s*-->$`)
+)
+
+// markdownNodeMeta 节点文本以外的属性，导出为紧跟节点行的 HTML 注释，导入时还原
+type markdownNodeMeta struct {
+	Hyperlink       string   `json:"hyperlink,omitempty"` // 无法表示为 Markdown 链接时写入
+	HyperlinkTitle  string   `json:"hyperlinkTitle,omitempty"`
+	Tags            []string `json:"tags,omitempty"`
+	Color           string   `json:"color,omitempty"`
+	BackgroundColor string   `json:"backgroundColor,omitempty"`
+	Icon            string   `json:"icon,omitempty"`
+	Priority        int      `json:"priority,omitempty"`
+	Collapsed       bool     `json:"collapsed,omitempty"`
+}
+
+// outlineNode 解析大纲时使用的可变树节点
+type outlineNode struct {
+	data     entity.NodeData
+	children []*outlineNode
+}
+
+func (n *outlineNode) toMindMapData() entity.MindMapData {
+	data := entity.MindMapData{Data: n.data, Children: make([]entity.MindMapData, 0, len(n.children))}
+	for _, child := range n.children {
+		data.Children = append(data.Children, child.toMindMapData())
+	}
+	return data
+}
+
+// appendNote 追加一行备注，blank 表示与上一行之间有空行
+func (n *outlineNode) appendNote(line string, blank bool) {
+	switch {
+	case n.data.Note == "":
+		n.data.Note = line
+	case blank:
+		n.data.Note += "\n\n" + line
+	default:
+		n.data.Note += "\n" + line
+	}
+}
+
+// outlineResult 将解析出的大纲转换为导图：只有一个顶层节点时作为根节点，否则由调用方补充根节点文本
+func outlineResult(root *outlineNode, title string) (*importedMindMap, error) {
+	if len(root.children) == 0 && root.data.Text == "" {
+		return nil, errors.New("未解析到任何大纲内容")
+	}
+	if root.data.Text == "" && root.data.Note == "" && len(root.children) == 1 {
+		root = root.children[0]
+	}
+	return &importedMindMap{Title: title, Data: root.toMindMapData()}, nil
+}
+
+// parseMarkdown 解析 Markdown 大纲：标题按级别、列表按缩进确定层级，普通段落作为上一个节点的备注
+// 备注去掉节点内容列宽度的缩进后保留其余缩进，紧跟节点行的 forge 注释还原为节点属性
+func parseMarkdown(content []byte) (*importedMindMap, error) {
+	text := strings.TrimPrefix(string(content), "\ufeff")
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+
+	type frame struct {
+		level int
+		node  *outlineNode
+	}
+	root := &outlineNode{}
+	stack := []frame{{level: 0, node: root}}
+	last := root
+	noteIndent := 0     // 当前节点备注的缩进宽度，即节点内容所在列
+	expectMeta := false // 上一行是否为节点行
+
+	push := func(level int, data entity.NodeData, indent int) {
+		for len(stack) > 1 && stack[len(stack)-1].level >= level {
+			stack = stack[:len(stack)-1]
+		}
+		node := &outlineNode{data: data}
+		parent := stack[len(stack)-1].node
+		parent.children = append(parent.children, node)
+		stack = append(stack, frame{level: level, node: node})
+		last, noteIndent = node, indent
+	}
+
+	headingLevel := 0
+	var bulletIndents []int // 当前标题下各层列表项的缩进宽度
+	inFence, blank := false, false
+
+	for _, line := range strings.Split(text, "\n") {
+		trimmed := strings.TrimSpace(line)
+		afterNode := expectMeta
+		expectMeta = false
+
+		// 代码块原样保留在备注中，其中的 # 和 - 不作为大纲解析
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			inFence = !inFence
+			last.appendNote(trimIndent(line, noteIndent), blank)
+			blank = false
+			continue
+		}
+		if inFence {
+			last.appendNote(trimIndent(line, noteIndent), false)
+			continue
+		}
+
+		if trimmed == "" {
+			blank = true
+			continue
+		}
+
+		if m := mdHeadingPattern.FindStringSubmatch(line); m != nil {
+			headingLevel = len(m[1])
+			bulletIndents = nil
+			push(headingLevel, parseMarkdownNodeText(unescapeMarkdownHeading(m[2])), 0)
+			expectMeta = true
+		} else if m := mdBulletPattern.FindStringSubmatch(line); m != nil {
+			indent := indentWidth(m[1])
+			for len(bulletIndents) > 0 && bulletIndents[len(bulletIndents)-1] > indent {
+				bulletIndents = bulletIndents[:len(bulletIndents)-1]
+			}
+			if len(bulletIndents) == 0 || bulletIndents[len(bulletIndents)-1] < indent {
+				bulletIndents = append(bulletIndents, indent)
+			}
+			push(headingLevel+len(bulletIndents), parseMarkdownNodeText(m[3]), indent+len(m[2]))
+			expectMeta = true
+		} else if afterNode && !blank && applyMarkdownNodeMeta(&last.data, trimmed) {
+			continue
+		} else {
+			last.appendNote(unescapeMarkdownLine(trimIndent(line, noteIndent)), blank)
+		}
+		blank = false
+	}
+
+	title := ""
+	if len(root.children) == 1 {
+		title = root.children[0].data.Text
+	}
+	return outlineResult(root, title)
+}
+
+// parseMarkdownNodeText 解析节点文本，整行为链接时提取为节点超链接，导出时转义的文本去掉开头的反斜杠
+func parseMarkdownNodeText(text string) entity.NodeData {
+	if m := mdLinkPattern.FindStringSubmatch(text); m != nil {
+		return entity.NodeData{Text: m[1], Hyperlink: m[2]}
+	}
+	if rest := strings.TrimPrefix(text, `\`); rest != text &&
+		(mdLinkPattern.MatchString(rest) || strings.HasPrefix(rest, `\`)) {
+		return entity.NodeData{Text: rest}
+	}
+	return entity.NodeData{Text: text}
+}
+
+// unescapeMarkdownHeading 还原导出时转义的标题结尾 #
+func unescapeMarkdownHeading(text string) string {
+	if strings.HasSuffix(text, `\#`) {
+		return strings.TrimSuffix(text, `\#`) + "#"
+	}
+	return text
+}
+
+// applyMarkdownNodeMeta 解析节点行之后的 forge 注释并写入节点属性，不是 forge 注释时返回 false
+func applyMarkdownNodeMeta(data *entity.NodeData, line string) bool {
+	m := mdMetaPattern.FindStringSubmatch(line)
+	if m == nil {
+		return false
+	}
+	var meta markdownNodeMeta
+	if err := json.Unmarshal([]byte(m[1]), &meta); err != nil {
+		return false
+	}
+	if meta.Hyperlink != "" {
+		data.Hyperlink = meta.Hyperlink
+	}
+	data.HyperlinkTitle = meta.HyperlinkTitle
+	data.Tags = meta.Tags
+	data.Color = meta.Color
+	data.BackgroundColor = meta.BackgroundColor
+	data.Icon = meta.Icon
+	data.Priority = meta.Priority
+	data.Collapsed = meta.Collapsed
+	return true
+}
+
+// unescapeMarkdownLine 还原导出时为避免误解析而转义的备注行，行首缩进保持不变
+func unescapeMarkdownLine(line string) string {
+	body := strings.TrimLeft(line, " \t")
+	indent := line[:len(line)-len(body)]
+	if rest := strings.TrimPrefix(body, `\`); rest != body &&
+		(mdHeadingPattern.MatchString(indent+rest) || mdBulletPattern.MatchString(indent+rest) || mdMetaPattern.MatchString(rest)) {
+		return indent + rest
+	}
+	return line
+}
+
+// indentWidth 计算缩进宽度，制表符按4个空格计算
+func indentWidth(indent string) int {
+	width := 0
+	for _, r := range indent {
+		if r == '\t' {
+			width += 4
+		} else {
+			width++
+		}
+	}
+	return width
+}
+
+// trimIndent 去掉行首不超过 width 的缩进
+func trimIndent(line string, width int) string {
+	i := 0
+	for i < len(line) && i < width && (line[i] == ' ' || line[i] == '\t') {
+		i++
+	}
+	return line[i:]
+}
+
+// renderMarkdown 将导图渲染为 Markdown 大纲：根节点为一级标题，其余节点为按层级缩进的列表，备注缩进写在节点下方
+// 标签、颜色等属性写在节点行之后的 forge 注释中，常见 Markdown 渲染器不会显示
+func renderMarkdown(mindMap *entity.MindMap) []byte {
+	var sb strings.Builder
+	root := mindMap.Data
+	text := markdownNodeText(root.Data)
+	if strings.HasSuffix(text, "#") {
+		// 避免结尾的 # 被当作标题的闭合标记去掉
+		text = strings.TrimSuffix(text, "#") + `\#`
+	}
+	sb.WriteString("# " + text + "\n")
+	writeMarkdownNodeMeta(&sb, root.Data, "")
+	writeMarkdownNote(&sb, root.Data.Note, "")
+	for _, child := range root.Children {
+		writeMarkdownNode(&sb, child, 0)
+	}
+	return []byte(sb.String())
+}
+
+func writeMarkdownNode(sb *strings.Builder, node entity.MindMapData, depth int) {
+	indent := strings.Repeat("  ", depth)
+	sb.WriteString(indent + "- " + markdownNodeText(node.Data) + "\n")
+	writeMarkdownNodeMeta(sb, node.Data, indent+"  ")
+	writeMarkdownNote(sb, node.Data.Note, indent+"  ")
+	for _, child := range node.Children {
+		writeMarkdownNode(sb, child, depth+1)
+	}
+}
+
+func writeMarkdownNote(sb *strings.Builder, note, indent string) {
+	if note == "" {
+		return
+	}
+	inFence := false
+	for _, line := range strings.Split(note, "\n") {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~"):
+			inFence = !inFence
+		case trimmed == "" && !inFence:
+			sb.WriteString("\n")
+			continue
+		case !inFence && (mdHeadingPattern.MatchString(line) || mdBulletPattern.MatchString(line) || mdMetaPattern.MatchString(trimmed)):
+			// 避免备注中的标题、列表或 forge 注释被解析为节点
+			body := strings.TrimLeft(line, " \t")
+			line = line[:len(line)-len(body)] + `\` + body
+		}
+		sb.WriteString(indent + line + "\n")
+	}
+}
+
+// writeMarkdownNodeMeta 节点有文本以外的属性时在节点行之后写入 forge 注释
+func writeMarkdownNodeMeta(sb *strings.Builder, data entity.NodeData, indent string) {
+	meta := markdownNodeMeta{
+		HyperlinkTitle:  data.HyperlinkTitle,
+		Tags:            data.Tags,
+		Color:           data.Color,
+		BackgroundColor: data.BackgroundColor,
+		Icon:            data.Icon,
+		Priority:        data.Priority,
+		Collapsed:       data.Collapsed,
+	}
+	if data.Hyperlink != "" && !markdownLinkable(data) {
+		meta.Hyperlink = data.Hyperlink
+	}
+	// json 默认转义 <、> 注释内容不会提前闭合
+	content, err := json.Marshal(meta)
+	if err != nil || string(content) == "{}" {
+		return
+	}
+	sb.WriteString(indent + "<!-- forge:" + string(content) + " -->\n")
+}
+
+// markdownNodeText 节点文本转为单行 Markdown，带超链接时输出为链接
+// 文本本身形如链接或以反斜杠开头时加反斜杠转义，避免导入时被解析为超链接
+func markdownNodeText(data entity.NodeData) string {
+	text := strings.Join(strings.Fields(data.Text), " ")
+	if data.Hyperlink != "" && markdownLinkable(data) {
+		return "[" + text + "](" + data.Hyperlink + ")"
+	}
+	if mdLinkPattern.MatchString(text) || strings.HasPrefix(text, `\`) {
+		return `\` + text
+	}
+	return text
+}
+
+// markdownLinkable 节点文本与超链接能否写成导入时可还原的 Markdown 链接
+func markdownLinkable(data entity.NodeData) bool {
+	text := strings.Join(strings.Fields(data.Text), " ")
+	return mdLinkPattern.MatchString("[" + text + "](" + data.Hyperlink + ")")
+}
@@ -0,0 +1,74 @@
+package mindmapservice
+
+import (
+	"reflect"
+	"testing"
+
+	"forge/biz/entity"
+)
+
+// roundTripMindMap 覆盖节点属性、备注缩进与需转义文本的导图
+func roundTripMindMap() *entity.MindMap {
+	return &entity.MindMap{
+		Title: "C#",
+		Data: entity.MindMapData{
+			Data: entity.NodeData{UID: "root", Text: "C#", Note: "根节点备注\n  缩进一行", Color: "#333"},
+			Children: []entity.MindMapData{
+				{
+					Data: entity.NodeData{
+						UID:             "a",
+						Text:            "带属性",
+						Note:            "第一段\n    缩进代码\n\n- 不是子节点\n<!-- forge:{} -->",
+						Tags:            []string{"重要", "a,b"},
+						Color:           "#f00",
+						BackgroundColor: "#00ff00",
+						Icon:            "star",
+						Priority:        2,
+						Collapsed:       true,
+						Attachments:     []string{"att"},
+					},
+					Children: []entity.MindMapData{
+						{Data: entity.NodeData{UID: "a1", Text: "[x](y)"}},
+						{Data: entity.NodeData{UID: "a2", Text: `\转义`}},
+						{Data: entity.NodeData{UID: "a3", Text: "官网", Hyperlink: "https://example.com", HyperlinkTitle: "示例"}},
+						{Data: entity.NodeData{UID: "a4", Text: "链接 [含] 括号", Hyperlink: "https://example.com/a(b)"}},
+					},
+				},
+				{
+					Data: entity.NodeData{UID: "b", Text: "代码", Note: "```go\nfunc main() {\n\tprintln()\n\n}\n```"},
+					Children: []entity.MindMapData{
+						{Data: entity.NodeData{UID: "b1", Text: "标题 #"}},
+					},
+				},
+			},
+		},
+	}
+}
+
+// expectedRoundTrip 导出不包含节点标识与附件，导入后的叶子节点子节点为空切片
+func expectedRoundTrip(data entity.MindMapData) entity.MindMapData {
+	data.Data.UID = ""
+	data.Data.Attachments = nil
+	children := make([]entity.MindMapData, 0, len(data.Children))
+	for _, child := range data.Children {
+		children = append(children, expectedRoundTrip(child))
+	}
+	data.Children = children
+	return data
+}
+
+func TestMarkdownRoundTrip(t *testing.T) {
+	mindMap := roundTripMindMap()
+	content := renderMarkdown(mindMap)
+
+	imported, err := parseMarkdown(content)
+	if err != nil {
+		t.Fatalf("parseMarkdown: %v", err)
+	}
+	if imported.Title != mindMap.Data.Data.Text {
+		t.Errorf("title = %q, want %q", imported.Title, mindMap.Data.Data.Text)
+	}
+	if want := expectedRoundTrip(mindMap.Data); !reflect.DeepEqual(imported.Data, want) {
+		t.Errorf("round trip mismatch\nmarkdown:\n%s\ngot:  %+v\nwant: %+v", content, imported.Data, want)
+	}
+}
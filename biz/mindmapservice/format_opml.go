@@ -0,0 +1,117 @@
+package mindmapservice
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+
+	"forge/biz/entity"
+)
+
+// opmlDocument OPML 2.0 文档结构
+type opmlDocument struct {
+	XMLName xml.Name      `xml:"opml"`
+	Version string        `xml:"version,attr"`
+	Title   string        `xml:"head>title"`
+	Outline []opmlOutline `xml:"body>outline"`
+}
+
+// opmlOutline OPML 大纲节点，_note 为 OmniOutliner 等工具通用的备注扩展属性
+// 其余以下划线开头的属性为本系统的扩展属性，用于导出后再导入时保留节点样式
+type opmlOutline struct {
+	Text            string        `xml:"text,attr"`
+	Title           string        `xml:"title,attr,omitempty"`
+	Note            string        `xml:"_note,attr,omitempty"`
+	URL             string        `xml:"url,attr,omitempty"`
+	HyperlinkTitle  string        `xml:"_hyperlinkTitle,attr,omitempty"`
+	Tags            string        `xml:"_tags,attr,omitempty"` // JSON 数组，标签中可能含逗号
+	Color           string        `xml:"_color,attr,omitempty"`
+	BackgroundColor string        `xml:"_backgroundColor,attr,omitempty"`
+	Icon            string        `xml:"_icon,attr,omitempty"`
+	Priority        int           `xml:"_priority,attr,omitempty"`
+	Collapsed       bool          `xml:"_collapsed,attr,omitempty"`
+	Children        []opmlOutline `xml:"outline"`
+}
+
+// parseOPML 解析 OPML 文档，outline 层级即导图层级
+func parseOPML(content []byte) (*importedMindMap, error) {
+	var doc opmlDocument
+	decoder := xml.NewDecoder(bytes.NewReader(content))
+	decoder.CharsetReader = xmlCharsetReader
+	if err := decoder.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("OPML 格式错误: %w", err)
+	}
+	if len(doc.Outline) == 0 {
+		return nil, errors.New("OPML 中没有 outline 节点")
+	}
+
+	root := &outlineNode{}
+	for _, outline := range doc.Outline {
+		root.children = append(root.children, opmlToOutlineNode(outline))
+	}
+	return outlineResult(root, doc.Title)
+}
+
+func opmlToOutlineNode(outline opmlOutline) *outlineNode {
+	text := outline.Text
+	if text == "" {
+		text = outline.Title
+	}
+	node := &outlineNode{data: entity.NodeData{
+		Text:            text,
+		Note:            outline.Note,
+		Hyperlink:       outline.URL,
+		HyperlinkTitle:  outline.HyperlinkTitle,
+		Color:           outline.Color,
+		BackgroundColor: outline.BackgroundColor,
+		Icon:            outline.Icon,
+		Priority:        outline.Priority,
+		Collapsed:       outline.Collapsed,
+	}}
+	if outline.Tags != "" {
+		// 无法解析的标签忽略，不影响导入
+		_ = json.Unmarshal([]byte(outline.Tags), &node.data.Tags)
+	}
+	for _, child := range outline.Children {
+		node.children = append(node.children, opmlToOutlineNode(child))
+	}
+	return node
+}
+
+// renderOPML 将导图渲染为 OPML 2.0 文档，根节点为 body 下唯一的 outline
+func renderOPML(mindMap *entity.MindMap) ([]byte, error) {
+	doc := opmlDocument{
+		Version: "2.0",
+		Title:   mindMap.Title,
+		Outline: []opmlOutline{mindMapToOPMLOutline(mindMap.Data)},
+	}
+	content, err := xml.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), content...), nil
+}
+
+func mindMapToOPMLOutline(node entity.MindMapData) opmlOutline {
+	outline := opmlOutline{
+		Text:            node.Data.Text,
+		Note:            node.Data.Note,
+		URL:             node.Data.Hyperlink,
+		HyperlinkTitle:  node.Data.HyperlinkTitle,
+		Color:           node.Data.Color,
+		BackgroundColor: node.Data.BackgroundColor,
+		Icon:            node.Data.Icon,
+		Priority:        node.Data.Priority,
+		Collapsed:       node.Data.Collapsed,
+	}
+	if len(node.Data.Tags) > 0 {
+		tags, _ := json.Marshal(node.Data.Tags)
+		outline.Tags = string(tags)
+	}
+	for _, child := range node.Children {
+		outline.Children = append(outline.Children, mindMapToOPMLOutline(child))
+	}
+	return outline
+}
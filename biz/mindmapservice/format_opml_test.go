@@ -0,0 +1,25 @@
+package mindmapservice
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestOPMLRoundTrip(t *testing.T) {
+	mindMap := roundTripMindMap()
+	content, err := renderOPML(mindMap)
+	if err != nil {
+		t.Fatalf("renderOPML: %v", err)
+	}
+
+	imported, err := parseOPML(content)
+	if err != nil {
+		t.Fatalf("parseOPML: %v", err)
+	}
+	if imported.Title != mindMap.Title {
+		t.Errorf("title = %q, want %q", imported.Title, mindMap.Title)
+	}
+	if want := expectedRoundTrip(mindMap.Data); !reflect.DeepEqual(imported.Data, want) {
+		t.Errorf("round trip mismatch\nopml:\n%s\ngot:  %+v\nwant: %+v", content, imported.Data, want)
+	}
+}
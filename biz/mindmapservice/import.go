@@ -0,0 +1,133 @@
+package mindmapservice
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"forge/biz/entity"
+	"forge/biz/types"
+	"forge/pkg/log/zlog"
+)
+
+// 导入/导出支持的文件格式
+const (
+	FormatMarkdown = "markdown"
+	FormatOPML     = "opml"
+	FormatFreeMind = "freemind"
+)
+
+const (
+	maxImportFileSize = 5 << 20 // 导入文件大小上限 5MB
+	defaultLayout     = "mindMap"
+	maxTitleLength    = 100
+)
+
+// 文件扩展名与格式的对应关系
+var importExtensions = map[string]string{
+	".md":       FormatMarkdown,
+	".markdown": FormatMarkdown,
+	".opml":     FormatOPML,
+	".mm":       FormatFreeMind,
+}
+
+var (
+	ErrUnsupportedFormat = errors.New("不支持的文件格式")
+	ErrImportFailed      = errors.New("导入文件解析失败")
+)
+
+// importedMindMap 导入文件解析结果
+type importedMindMap struct {
+	Title string // 文档标题，可能为空
+	Data  entity.MindMapData
+}
+
+// ImportMindMap 将 Markdown、OPML、FreeMind 文件确定性地转换为思维导图并保存（不调用模型）
+func (s *MindMapServiceImpl) ImportMindMap(ctx context.Context, req *types.ImportMindMapParams) (*entity.MindMap, error) {
+	if req == nil || req.File == nil {
+		zlog.CtxErrorf(ctx, "import file is required")
+		return nil, ErrInvalidParams
+	}
+	if req.File.Size > maxImportFileSize {
+		zlog.CtxErrorf(ctx, "import file too large: %d", req.File.Size)
+		return nil, fmt.Errorf("%w: 文件大小不能超过5MB", ErrImportFailed)
+	}
+
+	format := req.Format
+	if format == "" {
+		format = importExtensions[strings.ToLower(filepath.Ext(req.File.Filename))]
+	}
+
+	file, err := req.File.Open()
+	if err != nil {
+		zlog.CtxErrorf(ctx, "failed to open import file: %v", err)
+		return nil, ErrInternalError
+	}
+	defer file.Close()
+
+	content, err := io.ReadAll(io.LimitReader(file, maxImportFileSize+1))
+	if err != nil {
+		zlog.CtxErrorf(ctx, "failed to read import file: %v", err)
+		return nil, ErrInternalError
+	}
+
+	var imported *importedMindMap
+	switch format {
+	case FormatMarkdown:
+		imported, err = parseMarkdown(content)
+	case FormatOPML:
+		imported, err = parseOPML(content)
+	case FormatFreeMind:
+		imported, err = parseFreeMind(content)
+	default:
+		zlog.CtxWarnf(ctx, "unsupported import format: %q, filename: %s", format, req.File.Filename)
+		return nil, ErrUnsupportedFormat
+	}
+	if err != nil {
+		zlog.CtxWarnf(ctx, "failed to parse import file %s as %s: %v", req.File.Filename, format, err)
+		return nil, fmt.Errorf("%w: %v", ErrImportFailed, err)
+	}
+
+	// 标题优先级：请求指定 > 文档标题 > 根节点文本 > 文件名
+	title := firstNonEmpty(req.Title, imported.Title, imported.Data.Data.Text,
+		strings.TrimSuffix(filepath.Base(req.File.Filename), filepath.Ext(req.File.Filename)))
+	if imported.Data.Data.Text == "" {
+		imported.Data.Data.Text = title
+	}
+
+	layout := req.Layout
+	if layout == "" {
+		layout = defaultLayout
+	}
+
+	// 复用创建流程，统一执行校验、分配节点标识并写入首个版本
+	return s.CreateMindMap(ctx, &types.CreateMindMapParams{
+		Title:  truncateUTF8(title, maxTitleLength),
+		Layout: layout,
+		Data:   imported.Data,
+	})
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v = strings.TrimSpace(v); v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// truncateUTF8 按字节截断字符串且不截断多字节字符（实体校验按字节计算标题长度）
+func truncateUTF8(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
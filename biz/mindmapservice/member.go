@@ -0,0 +1,224 @@
+package mindmapservice
+
+import (
+	"context"
+	"errors"
+
+	"forge/biz/adapter"
+	"forge/biz/entity"
+	"forge/biz/repo"
+	"forge/biz/types"
+	"forge/pkg/log/zlog"
+)
+
+// authorize 校验当前用户在导图中至少拥有 required 角色，返回当前用户及其成员记录
+// 非成员返回 ErrMindMapNotFound，不暴露导图是否存在；回收站中的导图同样可校验
+func (s *MindMapServiceImpl) authorize(ctx context.Context, mapID, required string) (*entity.User, *entity.MindMapMember, error) {
+	// 从JWT token上下文中获取用户信息
+	user, ok := entity.GetUser(ctx)
+	if !ok {
+		zlog.CtxErrorf(ctx, "failed to get user from context")
+		return nil, nil, ErrPermissionDenied
+	}
+
+	// 参数校验
+	if mapID == "" {
+		zlog.CtxErrorf(ctx, "mapID is required")
+		return nil, nil, ErrInvalidParams
+	}
+
+	member, err := s.mindMapRepo.GetMindMapMember(ctx, mapID, user.UserID)
+	if err != nil {
+		zlog.CtxErrorf(ctx, "failed to get mindmap member: %v", err)
+		return nil, nil, ErrInternalError
+	}
+	if member == nil {
+		zlog.CtxWarnf(ctx, "user is not a member of mindmap, mapID: %s, userID: %s", mapID, user.UserID)
+		return nil, nil, ErrMindMapNotFound
+	}
+	if !entity.MindMapRoleAllows(member.Role, required) {
+		zlog.CtxWarnf(ctx, "mindmap permission denied, mapID: %s, userID: %s, role: %s, required: %s", mapID, user.UserID, member.Role, required)
+		return nil, nil, ErrPermissionDenied
+	}
+	return user, member, nil
+}
+
+// ListMindMapMembers 获取思维导图的协作成员列表（协作成员均可查看）
+func (s *MindMapServiceImpl) ListMindMapMembers(ctx context.Context, mapID string) ([]*entity.MindMapMember, error) {
+	if _, err := s.GetMindMap(ctx, mapID); err != nil {
+		return nil, err // GetMindMap已经包含权限验证
+	}
+
+	members, err := s.mindMapRepo.ListMindMapMembers(ctx, mapID)
+	if err != nil {
+		zlog.CtxErrorf(ctx, "failed to list mindmap members: %v", err)
+		return nil, ErrInternalError
+	}
+
+	// 填充成员的用户信息，用户已注销时保持为空
+	for _, member := range members {
+		memberUser, err := s.userRepo.GetUser(ctx, repo.NewUserQueryByID(member.UserID))
+		if err != nil {
+			zlog.CtxErrorf(ctx, "failed to get member user, userID: %s: %v", member.UserID, err)
+			return nil, ErrInternalError
+		}
+		if memberUser != nil {
+			member.UserName = memberUser.UserName
+			member.Avatar = memberUser.Avatar
+		}
+	}
+
+	zlog.CtxInfof(ctx, "mindmap members listed successfully, mapID: %s, count: %d", mapID, len(members))
+	return members, nil
+}
+
+// InviteMindMapMember 通过邮箱或手机号邀请已注册用户协作（仅所有者可邀请）
+// 邀请通知异步发送，发送失败不影响邀请结果
+func (s *MindMapServiceImpl) InviteMindMapMember(ctx context.Context, mapID string, req *types.InviteMindMapMemberParams) (*entity.MindMapMember, error) {
+	// 参数校验：邮箱与手机号二选一，且不能邀请为所有者
+	if req == nil || (req.Email == "") == (req.Phone == "") {
+		zlog.CtxErrorf(ctx, "exactly one of email and phone is required")
+		return nil, ErrInvalidParams
+	}
+	if req.Role != entity.MindMapRoleEditor && req.Role != entity.MindMapRoleViewer {
+		zlog.CtxErrorf(ctx, "invalid invite role: %s", req.Role)
+		return nil, ErrInvalidParams
+	}
+
+	mindMap, err := s.getMindMapForRole(ctx, mapID, entity.MindMapRoleOwner)
+	if err != nil {
+		return nil, err // getMindMapForRole已经包含权限验证
+	}
+	user, _ := entity.GetUser(ctx)
+
+	query := repo.NewUserQueryByEmail(req.Email)
+	if req.Phone != "" {
+		query = repo.NewUserQueryByPhone(req.Phone)
+	}
+	invitee, err := s.userRepo.GetUser(ctx, query)
+	if err != nil {
+		zlog.CtxErrorf(ctx, "failed to get invitee: %v", err)
+		return nil, ErrInternalError
+	}
+	if invitee == nil {
+		zlog.CtxWarnf(ctx, "invitee not found, mapID: %s", mapID)
+		return nil, ErrInviteeNotFound
+	}
+
+	member := &entity.MindMapMember{
+		MapID:     mapID,
+		UserID:    invitee.UserID,
+		Role:      req.Role,
+		InvitedBy: user.UserID,
+		UserName:  invitee.UserName,
+		Avatar:    invitee.Avatar,
+	}
+	if err := s.mindMapRepo.AddMindMapMember(ctx, member); err != nil {
+		if errors.Is(err, repo.ErrMindMapMemberExists) {
+			return nil, ErrMemberAlreadyExists
+		}
+		zlog.CtxErrorf(ctx, "failed to add mindmap member: %v", err)
+		return nil, ErrInternalError
+	}
+
+	invitation := &adapter.MindMapInvitation{
+		InviterName: user.UserName,
+		MapID:       mapID,
+		MapTitle:    mindMap.Title,
+		Role:        req.Role,
+	}
+	go s.sendInvitation(context.WithoutCancel(ctx), req.Email, req.Phone, invitation)
+
+	zlog.CtxInfof(ctx, "mindmap member invited successfully, mapID: %s, userID: %s, role: %s", mapID, invitee.UserID, req.Role)
+	return member, nil
+}
+
+// sendInvitation 按邀请方式发送邀请通知，失败仅记录日志
+func (s *MindMapServiceImpl) sendInvitation(ctx context.Context, email, phone string, invitation *adapter.MindMapInvitation) {
+	var err error
+	if email != "" {
+		err = s.notifier.SendMindMapInviteEmail(ctx, email, invitation)
+	} else {
+		err = s.notifier.SendMindMapInviteSMS(ctx, phone, invitation)
+	}
+	if err != nil {
+		zlog.CtxWarnf(ctx, "failed to send mindmap invitation, mapID: %s: %v", invitation.MapID, err)
+	}
+}
+
+// UpdateMindMapMemberRole 修改协作成员角色（仅所有者可修改，所有者本身的角色不可修改）
+func (s *MindMapServiceImpl) UpdateMindMapMemberRole(ctx context.Context, mapID, userID, role string) error {
+	if userID == "" || (role != entity.MindMapRoleEditor && role != entity.MindMapRoleViewer) {
+		zlog.CtxErrorf(ctx, "invalid member userID or role: %s", role)
+		return ErrInvalidParams
+	}
+
+	if _, err := s.getMindMapForRole(ctx, mapID, entity.MindMapRoleOwner); err != nil {
+		return err // getMindMapForRole已经包含权限验证
+	}
+
+	if err := s.checkManageableMember(ctx, mapID, userID); err != nil {
+		return err
+	}
+
+	if err := s.mindMapRepo.UpdateMindMapMemberRole(ctx, mapID, userID, role); err != nil {
+		if errors.Is(err, repo.ErrMindMapMemberNotFound) {
+			return ErrMemberNotFound
+		}
+		zlog.CtxErrorf(ctx, "failed to update mindmap member role: %v", err)
+		return ErrInternalError
+	}
+
+	zlog.CtxInfof(ctx, "mindmap member role updated successfully, mapID: %s, userID: %s, role: %s", mapID, userID, role)
+	return nil
+}
+
+// RemoveMindMapMember 移除协作成员：所有者可移除其他成员，成员可移除自己以退出协作
+func (s *MindMapServiceImpl) RemoveMindMapMember(ctx context.Context, mapID, userID string) error {
+	if userID == "" {
+		zlog.CtxErrorf(ctx, "member userID is required")
+		return ErrInvalidParams
+	}
+
+	mindMap, err := s.GetMindMap(ctx, mapID)
+	if err != nil {
+		return err // GetMindMap已经包含权限验证
+	}
+	user, _ := entity.GetUser(ctx)
+	if userID != user.UserID && mindMap.Role != entity.MindMapRoleOwner {
+		zlog.CtxWarnf(ctx, "only owner can remove other members, mapID: %s, userID: %s", mapID, user.UserID)
+		return ErrPermissionDenied
+	}
+
+	if err := s.checkManageableMember(ctx, mapID, userID); err != nil {
+		return err
+	}
+
+	if err := s.mindMapRepo.RemoveMindMapMember(ctx, mapID, userID); err != nil {
+		if errors.Is(err, repo.ErrMindMapMemberNotFound) {
+			return ErrMemberNotFound
+		}
+		zlog.CtxErrorf(ctx, "failed to remove mindmap member: %v", err)
+		return ErrInternalError
+	}
+
+	zlog.CtxInfof(ctx, "mindmap member removed successfully, mapID: %s, userID: %s, operator: %s", mapID, userID, user.UserID)
+	return nil
+}
+
+// checkManageableMember 校验成员存在且不是所有者，所有者不能被移除或降级
+func (s *MindMapServiceImpl) checkManageableMember(ctx context.Context, mapID, userID string) error {
+	member, err := s.mindMapRepo.GetMindMapMember(ctx, mapID, userID)
+	if err != nil {
+		zlog.CtxErrorf(ctx, "failed to get mindmap member: %v", err)
+		return ErrInternalError
+	}
+	if member == nil {
+		return ErrMemberNotFound
+	}
+	if member.Role == entity.MindMapRoleOwner {
+		zlog.CtxWarnf(ctx, "mindmap owner cannot be changed, mapID: %s, userID: %s", mapID, userID)
+		return ErrInvalidParams
+	}
+	return nil
+}
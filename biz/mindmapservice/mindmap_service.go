@@ -3,11 +3,14 @@ package mindmapservice
 import (
 	"context"
 	"errors"
+	"fmt"
+	"forge/biz/adapter"
 	"forge/biz/entity"
 	"forge/biz/repo"
 	"forge/biz/types"
 	"forge/pkg/log/zlog"
 	"forge/util"
+	"time"
 )
 
 // 错误定义
@@ -17,20 +20,59 @@ var (
 	ErrInvalidParams        = errors.New("参数无效")
 	ErrPermissionDenied     = errors.New("权限不足")
 	ErrInternalError        = errors.New("内部错误")
+	ErrVersionConflict      = errors.New("思维导图已被修改，请刷新后重试")
+	ErrRevisionNotFound     = errors.New("历史版本不存在")
+	ErrInvalidPatch         = errors.New("无效的节点操作")
+	ErrInvalidMindMapData   = errors.New("思维导图数据无效")
+	ErrShareNotFound        = errors.New("分享链接不存在或已失效")
+	ErrSharePasswordNeeded  = errors.New("需要输入分享密码")
+	ErrSharePasswordWrong   = errors.New("分享密码错误")
+	ErrShareLocked          = errors.New("分享密码错误次数过多，请稍后再试")
+	ErrMemberNotFound       = errors.New("协作成员不存在")
+	ErrMemberAlreadyExists  = errors.New("该用户已是协作成员")
+	ErrInviteeNotFound      = errors.New("被邀请的用户不存在")
+	ErrCollabConflict       = errors.New("节点已被其他协作者修改，请同步后重试")
+	ErrTemplateNotFound     = errors.New("模板不存在")
+	ErrFolderNotFound       = errors.New("文件夹不存在")
+	ErrFolderNameExists     = errors.New("同一位置已存在同名文件夹")
+	ErrCommentNotFound      = errors.New("评论不存在")
 )
 
+// newInvalidDataError 包装实体校验错误，保留具体原因（如出错节点路径）供接口层返回
+func newInvalidDataError(err error) error {
+	return fmt.Errorf("%w: %w", ErrInvalidMindMapData, err)
+}
+
 // MindMapServiceImpl 思维导图服务实现
 type MindMapServiceImpl struct {
-	mindMapRepo repo.IMindMapRepo
+	mindMapRepo      repo.IMindMapRepo
+	userRepo         repo.UserRepo
+	renderer         adapter.MindMapRenderer
+	documentExporter adapter.DocumentExporter
+	cosService       adapter.COSService
+	notifier         adapter.NotificationService
+	rateLimiter      adapter.RateLimiter
+	collab           *collabHub
+	syncRetention    time.Duration // 变更记录保留期，超过保留期的同步游标失效
+	shareImages      *shareImageCache
 }
 
-func NewMindMapServiceImpl(mindMapRepo repo.IMindMapRepo) *MindMapServiceImpl {
+func NewMindMapServiceImpl(mindMapRepo repo.IMindMapRepo, userRepo repo.UserRepo, renderer adapter.MindMapRenderer, documentExporter adapter.DocumentExporter, cosService adapter.COSService, notifier adapter.NotificationService, collabBroker adapter.CollabBroker, rateLimiter adapter.RateLimiter) *MindMapServiceImpl {
 	return &MindMapServiceImpl{
-		mindMapRepo: mindMapRepo,
+		mindMapRepo:      mindMapRepo,
+		userRepo:         userRepo,
+		renderer:         renderer,
+		documentExporter: documentExporter,
+		cosService:       cosService,
+		notifier:         notifier,
+		rateLimiter:      rateLimiter,
+		collab:           newCollabHub(collabBroker),
+		syncRetention:    DefaultSyncRetention,
+		shareImages:      newShareImageCache(),
 	}
 }
 
-// CreateMindMap 创建思维导图（用户只能创建自己的思维导图）
+// CreateMindMap 创建思维导图，创建者成为导图所有者
 func (s *MindMapServiceImpl) CreateMindMap(ctx context.Context, req *types.CreateMindMapParams) (*entity.MindMap, error) {
 	// 从JWT token上下文中获取用户信息
 	user, ok := entity.GetUser(ctx)
@@ -48,64 +90,90 @@ func (s *MindMapServiceImpl) CreateMindMap(ctx context.Context, req *types.Creat
 
 	// 构建实体
 	mindMap := &entity.MindMap{
-		MapID:  mapID,
-		UserID: user.UserID, // 从JWT token中获取的用户ID
-		Title:  req.Title,
-		Desc:   req.Desc,
-		Layout: req.Layout,
-		Data:   req.Data,
+		MapID:      mapID,
+		UserID:     user.UserID, // 从JWT token中获取的用户ID
+		Title:      req.Title,
+		Desc:       req.Desc,
+		Layout:     req.Layout,
+		Data:       req.Data,
+		Provenance: req.Provenance,
 	}
 
 	// 实体校验
 	if err := mindMap.Validate(); err != nil {
 		zlog.CtxErrorf(ctx, "mindmap validation failed: %v", err)
+		return nil, newInvalidDataError(err)
+	}
+
+	// 附件归属于所在导图，派生导图引用的来源附件复制到新导图，其余附件引用移除
+	attachments, err := s.copyAttachments(ctx, user.UserID, mapID, &mindMap.Data, req.Attachments)
+	if err != nil {
 		return nil, err
 	}
 
 	// 持久化
 	if err := s.mindMapRepo.CreateMindMap(ctx, mindMap); err != nil {
 		zlog.CtxErrorf(ctx, "failed to create mindmap: %v", err)
+		s.deleteAttachmentFiles(ctx, attachments)
 		return nil, ErrInternalError
 	}
+	for _, attachment := range attachments {
+		if err := s.mindMapRepo.CreateMindMapAttachment(ctx, attachment); err != nil {
+			zlog.CtxErrorf(ctx, "failed to create copied attachment, mapID: %s: %v", mapID, err)
+			s.discardMindMap(ctx, mapID)
+			s.deleteAttachmentFiles(ctx, attachments)
+			return nil, ErrInternalError
+		}
+	}
 
 	zlog.CtxInfof(ctx, "mindmap created successfully, mapID: %s, userID: %s", mapID, user.UserID)
 	return mindMap, nil
 }
 
-// GetMindMap 获取思维导图（用户只能获取自己的思维导图）
+// GetMindMap 获取思维导图（协作成员均可查看）
 func (s *MindMapServiceImpl) GetMindMap(ctx context.Context, mapID string) (*entity.MindMap, error) {
-	// 从JWT token上下文中获取用户信息
-	user, ok := entity.GetUser(ctx)
-	if !ok {
-		zlog.CtxErrorf(ctx, "failed to get user from context")
-		return nil, ErrPermissionDenied
+	mindMap, err := s.getMindMapForRole(ctx, mapID, entity.MindMapRoleViewer)
+	if err != nil {
+		return nil, err
 	}
 
-	// 参数校验
-	if mapID == "" {
-		zlog.CtxErrorf(ctx, "mapID is required")
-		return nil, ErrInvalidParams
+	user, _ := entity.GetUser(ctx)
+	tags, err := s.mindMapRepo.GetMindMapTags(ctx, mapID, user.UserID)
+	if err != nil {
+		zlog.CtxErrorf(ctx, "failed to get mindmap tags: %v", err)
+		return nil, ErrInternalError
 	}
+	mindMap.Tags = tags
+	return mindMap, nil
+}
 
-	// 构建查询条件（包含用户ID验证）
-	query := repo.NewMindMapQueryByID(user.UserID, mapID)
+// getMindMapForRole 校验当前用户在导图中至少拥有 required 角色后获取思维导图，并填充用户角色
+func (s *MindMapServiceImpl) getMindMapForRole(ctx context.Context, mapID, required string) (*entity.MindMap, error) {
+	user, member, err := s.authorize(ctx, mapID, required)
+	if err != nil {
+		return nil, err
+	}
 
 	// 查询思维导图
-	mindMap, err := s.mindMapRepo.GetMindMap(ctx, query)
+	mindMap, err := s.mindMapRepo.GetMindMap(ctx, mapID)
 	if err != nil {
 		zlog.CtxErrorf(ctx, "failed to get mindmap: %v", err)
 		return nil, ErrInternalError
 	}
 	if mindMap == nil {
-		zlog.CtxWarnf(ctx, "mindmap not found or permission denied, mapID: %s, userID: %s", mapID, user.UserID)
+		zlog.CtxWarnf(ctx, "mindmap not found, mapID: %s, userID: %s", mapID, user.UserID)
 		return nil, ErrMindMapNotFound
 	}
+	mindMap.Role = member.Role
+	mindMap.FolderID = member.FolderID
+	mindMap.Starred = member.StarredAt != nil
+	mindMap.Pinned = member.PinnedAt != nil
 
-	zlog.CtxInfof(ctx, "mindmap retrieved successfully, mapID: %s, userID: %s", mapID, user.UserID)
+	zlog.CtxInfof(ctx, "mindmap retrieved successfully, mapID: %s, userID: %s, role: %s", mapID, user.UserID, member.Role)
 	return mindMap, nil
 }
 
-// ListMindMaps 获取思维导图列表（用户只能获取自己的思维导图列表）
+// ListMindMaps 获取用户作为协作成员可访问的思维导图列表
 func (s *MindMapServiceImpl) ListMindMaps(ctx context.Context, req *types.ListMindMapsParams) ([]*entity.MindMap, int64, error) {
 	// 从JWT token上下文中获取用户信息
 	user, ok := entity.GetUser(ctx)
@@ -116,13 +184,9 @@ func (s *MindMapServiceImpl) ListMindMaps(ctx context.Context, req *types.ListMi
 
 	// 构建查询条件（强制包含用户ID）
 	query := repo.NewMindMapQueryForList(user.UserID, req.Page, req.PageSize)
-
-	// 添加可选筛选条件
-	if req.Title != "" {
-		query.Title = req.Title
-	}
-	if req.Layout != "" {
-		query.Layout = req.Layout
+	if err := applyMindMapListFilters(&query, req); err != nil {
+		zlog.CtxErrorf(ctx, "invalid mindmap list filter: %v", err)
+		return nil, 0, ErrInvalidParams
 	}
 
 	// 查询列表
@@ -136,28 +200,124 @@ func (s *MindMapServiceImpl) ListMindMaps(ctx context.Context, req *types.ListMi
 	return mindMaps, total, nil
 }
 
-// UpdateMindMap 更新思维导图（用户只能更新自己的思维导图）
-func (s *MindMapServiceImpl) UpdateMindMap(ctx context.Context, mapID string, req *types.UpdateMindMapParams) error {
+// ListMindMapsByCursor 按游标分页获取用户作为协作成员可访问的思维导图列表，返回下一页游标，没有更多时为空
+func (s *MindMapServiceImpl) ListMindMapsByCursor(ctx context.Context, req *types.ListMindMapsParams) ([]*entity.MindMap, string, error) {
 	// 从JWT token上下文中获取用户信息
 	user, ok := entity.GetUser(ctx)
 	if !ok {
 		zlog.CtxErrorf(ctx, "failed to get user from context")
-		return ErrPermissionDenied
+		return nil, "", ErrPermissionDenied
+	}
+
+	// 构建查询条件（强制包含用户ID）
+	query := repo.NewMindMapQueryForCursor(user.UserID, req.Cursor, req.Limit)
+	if err := applyMindMapListFilters(&query, req); err != nil {
+		zlog.CtxErrorf(ctx, "invalid mindmap list filter: %v", err)
+		return nil, "", ErrInvalidParams
+	}
+
+	mindMaps, nextCursor, err := s.mindMapRepo.ListMindMapsByCursor(ctx, query)
+	if err != nil {
+		if errors.Is(err, repo.ErrInvalidMindMapCursor) {
+			zlog.CtxWarnf(ctx, "invalid mindmap cursor, userID: %s", user.UserID)
+			return nil, "", fmt.Errorf("%w: 无效的分页游标", ErrInvalidParams)
+		}
+		zlog.CtxErrorf(ctx, "failed to list mindmaps by cursor: %v", err)
+		return nil, "", ErrInternalError
+	}
+
+	zlog.CtxInfof(ctx, "mindmaps listed by cursor successfully, userID: %s, count: %d, hasMore: %t", user.UserID, len(mindMaps), nextCursor != "")
+	return mindMaps, nextCursor, nil
+}
+
+// applyMindMapListFilters 将列表的筛选与排序参数写入查询条件
+func applyMindMapListFilters(query *repo.MindMapQuery, req *types.ListMindMapsParams) error {
+	// 添加可选筛选条件
+	if req.Title != "" {
+		query.Title = req.Title
+	}
+	if req.Layout != "" {
+		query.Layout = req.Layout
+	}
+	if req.Role != "" {
+		query.Role = req.Role
+	}
+	if req.FolderID != "" {
+		folderID := req.FolderID
+		if folderID == entity.MindMapRootFolder {
+			folderID = ""
+		}
+		query.FolderID = &folderID
+	}
+	if len(req.Tags) > 0 {
+		tags, err := entity.NormalizeMindMapTags(req.Tags)
+		if err != nil {
+			return err
+		}
+		query.Tags = tags
+	}
+	query.Starred = req.Starred
+
+	// 排序
+	switch req.SortBy {
+	case "", repo.MindMapSortByTitle, repo.MindMapSortByCreatedAt, repo.MindMapSortByUpdatedAt:
+		query.SortBy = req.SortBy
+	default:
+		return fmt.Errorf("unsupported sort field: %s", req.SortBy)
+	}
+	switch req.SortOrder {
+	case "", repo.SortOrderAsc, repo.SortOrderDesc:
+		query.SortOrder = req.SortOrder
+	default:
+		return fmt.Errorf("unsupported sort order: %s", req.SortOrder)
+	}
+
+	// 时间范围，起始时间包含在内，结束时间不包含
+	if req.CreatedFrom != nil && req.CreatedTo != nil && !req.CreatedFrom.Before(*req.CreatedTo) {
+		return fmt.Errorf("created_from must be before created_to")
+	}
+	if req.UpdatedFrom != nil && req.UpdatedTo != nil && !req.UpdatedFrom.Before(*req.UpdatedTo) {
+		return fmt.Errorf("updated_from must be before updated_to")
+	}
+	query.CreatedFrom, query.CreatedTo = req.CreatedFrom, req.CreatedTo
+	query.UpdatedFrom, query.UpdatedTo = req.UpdatedFrom, req.UpdatedTo
+	return nil
+}
+
+// UpdateMindMap 更新思维导图（需要编辑权限），返回更新后的版本号
+func (s *MindMapServiceImpl) UpdateMindMap(ctx context.Context, mapID string, req *types.UpdateMindMapParams) (int64, error) {
+	// 从JWT token上下文中获取用户信息
+	user, ok := entity.GetUser(ctx)
+	if !ok {
+		zlog.CtxErrorf(ctx, "failed to get user from context")
+		return 0, ErrPermissionDenied
 	}
 
 	// 参数校验
 	if mapID == "" {
 		zlog.CtxErrorf(ctx, "mapID is required")
-		return ErrInvalidParams
+		return 0, ErrInvalidParams
 	}
 
 	// 先获取现有思维导图（用于校验和构建临时实体）
-	existingMindMap, err := s.GetMindMap(ctx, mapID)
+	existingMindMap, err := s.getMindMapForRole(ctx, mapID, entity.MindMapRoleEditor)
 	if err != nil {
-		return err // GetMindMap已经包含权限验证
+		return 0, err // getMindMapForRole已经包含权限验证
 	}
 	if existingMindMap == nil {
-		return ErrMindMapNotFound
+		return 0, ErrMindMapNotFound
+	}
+
+	// 整体覆盖导图数据必须携带版本号，避免覆盖其他客户端的修改
+	if req.Data != nil && req.Version == nil {
+		zlog.CtxErrorf(ctx, "version is required when updating mindmap data, mapID: %s", mapID)
+		return 0, ErrInvalidParams
+	}
+
+	// 提前发现版本冲突，避免无意义的校验
+	if req.Version != nil && *req.Version != existingMindMap.Version {
+		zlog.CtxWarnf(ctx, "mindmap version conflict, mapID: %s, expected: %d, current: %d", mapID, *req.Version, existingMindMap.Version)
+		return 0, ErrVersionConflict
 	}
 
 	// 将更新应用到临时实体以进行校验（复用实体层的校验逻辑）
@@ -178,49 +338,62 @@ func (s *MindMapServiceImpl) UpdateMindMap(ctx context.Context, mapID string, re
 	// 使用实体层的校验方法统一校验
 	if err := tempMindMap.Validate(); err != nil {
 		zlog.CtxErrorf(ctx, "mindmap validation failed after update: %v", err)
-		return err
+		return 0, newInvalidDataError(err)
 	}
 
 	// 构建更新信息
 	updateInfo := &repo.MindMapUpdateInfo{
-		MapID:  mapID,
-		UserID: user.UserID, // 确保只能更新自己的思维导图
-		Title:  req.Title,
-		Desc:   req.Desc,
-		Layout: req.Layout,
-		Data:   req.Data,
+		MapID:           mapID,
+		UserID:          user.UserID, // 记录本次修改的操作人
+		Title:           req.Title,
+		Desc:            req.Desc,
+		Layout:          req.Layout,
+		Data:            req.Data,
+		ExpectedVersion: req.Version,
+	}
+
+	return s.updateMindMap(ctx, updateInfo)
+}
+
+// updateMindMap 执行更新并统一转换仓储层错误
+// 保存导图数据时校验附件引用
+func (s *MindMapServiceImpl) updateMindMap(ctx context.Context, updateInfo *repo.MindMapUpdateInfo) (int64, error) {
+	if updateInfo.Data != nil {
+		if err := s.retainKnownAttachments(ctx, updateInfo.MapID, updateInfo.Data); err != nil {
+			return 0, err
+		}
 	}
 
-	// 执行更新（repo层已包含权限校验）
-	if err := s.mindMapRepo.UpdateMindMap(ctx, updateInfo); err != nil {
+	// 执行更新（repo层已包含版本校验）
+	version, err := s.mindMapRepo.UpdateMindMap(ctx, updateInfo)
+	if err != nil {
 		if errors.Is(err, repo.ErrMindMapNotFound) {
-			return ErrMindMapNotFound
+			return 0, ErrMindMapNotFound
+		}
+		if errors.Is(err, repo.ErrMindMapVersionConflict) {
+			zlog.CtxWarnf(ctx, "mindmap version conflict, mapID: %s", updateInfo.MapID)
+			return 0, ErrVersionConflict
 		}
 		zlog.CtxErrorf(ctx, "failed to update mindmap: %v", err)
-		return ErrInternalError
+		return 0, ErrInternalError
 	}
 
-	zlog.CtxInfof(ctx, "mindmap updated successfully, mapID: %s, userID: %s", mapID, user.UserID)
-	return nil
+	// 通知实时协作中的客户端重新加载
+	s.publishCollabEvent(ctx, &entity.CollabEvent{Type: entity.CollabEventReload, MapID: updateInfo.MapID, UserID: updateInfo.UserID, Version: version})
+
+	zlog.CtxInfof(ctx, "mindmap updated successfully, mapID: %s, userID: %s, version: %d", updateInfo.MapID, updateInfo.UserID, version)
+	return version, nil
 }
 
-// DeleteMindMap 删除思维导图（用户只能删除自己的思维导图）
+// DeleteMindMap 删除思维导图（仅所有者可删除）
 func (s *MindMapServiceImpl) DeleteMindMap(ctx context.Context, mapID string) error {
-	// 从JWT token上下文中获取用户信息
-	user, ok := entity.GetUser(ctx)
-	if !ok {
-		zlog.CtxErrorf(ctx, "failed to get user from context")
-		return ErrPermissionDenied
-	}
-
-	// 参数校验
-	if mapID == "" {
-		zlog.CtxErrorf(ctx, "mapID is required")
-		return ErrInvalidParams
+	user, _, err := s.authorize(ctx, mapID, entity.MindMapRoleOwner)
+	if err != nil {
+		return err
 	}
 
-	// 执行删除（软删除，repo层已包含权限校验）
-	if err := s.mindMapRepo.DeleteMindMap(ctx, mapID, user.UserID); err != nil {
+	// 执行删除（软删除）
+	if err := s.mindMapRepo.DeleteMindMap(ctx, mapID); err != nil {
 		if errors.Is(err, repo.ErrMindMapNotFound) {
 			return ErrMindMapNotFound
 		}
@@ -0,0 +1,67 @@
+package mindmapservice
+
+import (
+	"context"
+	"errors"
+
+	"forge/biz/entity"
+	"forge/biz/repo"
+	"forge/biz/types"
+	"forge/pkg/log/zlog"
+)
+
+// 最多允许单次提交的补丁操作数量
+const maxPatchOps = 500
+
+// PatchMindMap 节点级增量更新思维导图（需要编辑权限）
+// 所有操作在数据副本上依次执行并整体校验，任一操作失败则整体不生效
+func (s *MindMapServiceImpl) PatchMindMap(ctx context.Context, mapID string, req *types.PatchMindMapParams) (int64, error) {
+	user, ok := entity.GetUser(ctx)
+	if !ok {
+		zlog.CtxErrorf(ctx, "failed to get user from context")
+		return 0, ErrPermissionDenied
+	}
+
+	if req == nil || len(req.Ops) == 0 || len(req.Ops) > maxPatchOps {
+		zlog.CtxErrorf(ctx, "invalid patch ops")
+		return 0, ErrInvalidParams
+	}
+
+	existingMindMap, err := s.getMindMapForRole(ctx, mapID, entity.MindMapRoleEditor)
+	if err != nil {
+		return 0, err // getMindMapForRole已经包含权限验证
+	}
+
+	if req.Version != nil && *req.Version != existingMindMap.Version {
+		zlog.CtxWarnf(ctx, "mindmap version conflict, mapID: %s, expected: %d, current: %d", mapID, *req.Version, existingMindMap.Version)
+		return 0, ErrVersionConflict
+	}
+
+	// 在副本上执行补丁
+	data := existingMindMap.Data.Clone()
+	if err := data.ApplyPatch(req.Ops); err != nil {
+		zlog.CtxWarnf(ctx, "failed to apply mindmap patch, mapID: %s: %v", mapID, err)
+		if errors.Is(err, entity.ErrInvalidPatchOp) {
+			return 0, ErrInvalidPatch
+		}
+		return 0, ErrInvalidParams
+	}
+
+	tempMindMap := *existingMindMap
+	tempMindMap.Data = data
+	if err := tempMindMap.Validate(); err != nil {
+		zlog.CtxErrorf(ctx, "mindmap validation failed after patch: %v", err)
+		return 0, newInvalidDataError(err)
+	}
+
+	// 始终以读取时的版本作为期望版本，避免覆盖期间发生的并发修改
+	expectedVersion := existingMindMap.Version
+	updateInfo := &repo.MindMapUpdateInfo{
+		MapID:           mapID,
+		UserID:          user.UserID,
+		Data:            &data,
+		ExpectedVersion: &expectedVersion,
+	}
+
+	return s.updateMindMap(ctx, updateInfo)
+}
@@ -0,0 +1,77 @@
+package mindmapservice
+
+import (
+	"context"
+
+	"forge/biz/entity"
+	"forge/biz/repo"
+	"forge/biz/types"
+	"forge/pkg/log/zlog"
+)
+
+// ListMindMapRevisions 获取思维导图历史版本列表（协作成员均可查看）
+func (s *MindMapServiceImpl) ListMindMapRevisions(ctx context.Context, mapID string, req *types.ListMindMapRevisionsParams) ([]*entity.MindMapRevision, int64, error) {
+	// 权限校验（GetMindMap 已包含用户与参数校验）
+	if _, err := s.GetMindMap(ctx, mapID); err != nil {
+		return nil, 0, err
+	}
+
+	query := repo.NewMindMapRevisionQueryForList(mapID, req.Page, req.PageSize)
+	revisions, total, err := s.mindMapRepo.ListMindMapRevisions(ctx, query)
+	if err != nil {
+		zlog.CtxErrorf(ctx, "failed to list mindmap revisions: %v", err)
+		return nil, 0, ErrInternalError
+	}
+
+	zlog.CtxInfof(ctx, "mindmap revisions listed successfully, mapID: %s, count: %d, total: %d", mapID, len(revisions), total)
+	return revisions, total, nil
+}
+
+// GetMindMapRevision 获取指定历史版本的完整快照
+func (s *MindMapServiceImpl) GetMindMapRevision(ctx context.Context, mapID string, version int64) (*entity.MindMapRevision, error) {
+	if version <= 0 {
+		zlog.CtxErrorf(ctx, "invalid revision version: %d", version)
+		return nil, ErrInvalidParams
+	}
+
+	// 权限校验（GetMindMap 已包含用户与参数校验）
+	if _, err := s.GetMindMap(ctx, mapID); err != nil {
+		return nil, err
+	}
+
+	revision, err := s.mindMapRepo.GetMindMapRevision(ctx, mapID, version)
+	if err != nil {
+		zlog.CtxErrorf(ctx, "failed to get mindmap revision: %v", err)
+		return nil, ErrInternalError
+	}
+	if revision == nil {
+		zlog.CtxWarnf(ctx, "mindmap revision not found, mapID: %s, version: %d", mapID, version)
+		return nil, ErrRevisionNotFound
+	}
+
+	return revision, nil
+}
+
+// RestoreMindMapRevision 将思维导图恢复到指定历史版本
+// 恢复本身也是一次更新，会产生新的版本号，历史快照保持不变
+func (s *MindMapServiceImpl) RestoreMindMapRevision(ctx context.Context, mapID string, req *types.RestoreMindMapRevisionParams) (int64, error) {
+	revision, err := s.GetMindMapRevision(ctx, mapID, req.Version)
+	if err != nil {
+		return 0, err
+	}
+
+	// 恢复会整体覆盖当前数据，UpdateMindMap 在编辑权限校验后要求携带客户端持有的当前版本号
+	version, err := s.UpdateMindMap(ctx, mapID, &types.UpdateMindMapParams{
+		Title:   &revision.Title,
+		Desc:    &revision.Desc,
+		Layout:  &revision.Layout,
+		Data:    &revision.Data,
+		Version: req.ExpectedVersion,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	zlog.CtxInfof(ctx, "mindmap restored successfully, mapID: %s, from version: %d, new version: %d", mapID, req.Version, version)
+	return version, nil
+}
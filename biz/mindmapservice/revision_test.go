@@ -0,0 +1,43 @@
+package mindmapservice
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"forge/biz/entity"
+	"forge/biz/types"
+)
+
+// TestOverwriteRequiresVersion 整体覆盖导图数据与恢复历史版本都必须携带版本号
+func TestOverwriteRequiresVersion(t *testing.T) {
+	mindMapRepo := newFakeMindMapRepo()
+	s := newTestMindMapService(mindMapRepo, &fakeCOSService{})
+	ctx := entity.WithUser(context.Background(), &entity.User{UserID: "u1"})
+
+	mindMapRepo.save(&entity.MindMap{
+		MapID:   "m1",
+		UserID:  "u1",
+		Title:   "导图",
+		Layout:  "mindMap",
+		Version: 1,
+		Data:    entity.MindMapData{Data: entity.NodeData{UID: "root", Text: "根"}},
+	})
+
+	data := entity.MindMapData{Data: entity.NodeData{UID: "root", Text: "新根"}}
+	if _, err := s.UpdateMindMap(ctx, "m1", &types.UpdateMindMapParams{Data: &data}); !errors.Is(err, ErrInvalidParams) {
+		t.Errorf("UpdateMindMap without version: err = %v, want ErrInvalidParams", err)
+	}
+	if _, err := s.RestoreMindMapRevision(ctx, "m1", &types.RestoreMindMapRevisionParams{Version: 1}); !errors.Is(err, ErrInvalidParams) {
+		t.Errorf("RestoreMindMapRevision without expected version: err = %v, want ErrInvalidParams", err)
+	}
+	if got := mindMapRepo.mindMaps["m1"].Version; got != 1 {
+		t.Errorf("version = %d, want 1", got)
+	}
+
+	// 只修改标题时版本号仍为可选
+	title := "新标题"
+	if _, err := s.UpdateMindMap(ctx, "m1", &types.UpdateMindMapParams{Title: &title}); err != nil {
+		t.Errorf("UpdateMindMap title only: %v", err)
+	}
+}
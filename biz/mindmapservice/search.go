@@ -0,0 +1,120 @@
+package mindmapservice
+
+import (
+	"context"
+	"html"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"forge/biz/entity"
+	"forge/biz/repo"
+	"forge/biz/types"
+	"forge/pkg/log/zlog"
+)
+
+const (
+	searchMaxHitsPerMap = 5  // 每个导图最多返回的命中项
+	searchSnippetRunes  = 30 // 命中文本过长时，关键词前后保留的字符数
+	searchKeywordMaxLen = 100
+)
+
+// SearchMindMaps 在标题、描述、节点文本中搜索关键词（用户只能搜索自己的思维导图）
+// 命中文本经过 HTML 转义，关键词以 <em> 标记，过长的文本截取关键词附近的片段
+func (s *MindMapServiceImpl) SearchMindMaps(ctx context.Context, req *types.SearchMindMapsParams) ([]*entity.MindMapSearchResult, int64, error) {
+	// 从JWT token上下文中获取用户信息
+	user, ok := entity.GetUser(ctx)
+	if !ok {
+		zlog.CtxErrorf(ctx, "failed to get user from context")
+		return nil, 0, ErrPermissionDenied
+	}
+
+	// 参数校验
+	keyword := strings.TrimSpace(req.Keyword)
+	if keyword == "" || utf8.RuneCountInString(keyword) > searchKeywordMaxLen {
+		zlog.CtxErrorf(ctx, "invalid search keyword: %q", req.Keyword)
+		return nil, 0, ErrInvalidParams
+	}
+
+	query := repo.NewMindMapSearchQuery(user.UserID, keyword, req.Page, req.PageSize)
+	query.MaxHitsPerMap = searchMaxHitsPerMap
+
+	results, total, err := s.mindMapRepo.SearchMindMaps(ctx, query)
+	if err != nil {
+		zlog.CtxErrorf(ctx, "failed to search mindmaps: %v", err)
+		return nil, 0, ErrInternalError
+	}
+
+	for _, result := range results {
+		for i := range result.Hits {
+			result.Hits[i].Text = highlightKeyword(result.Hits[i].Text, keyword)
+		}
+	}
+
+	zlog.CtxInfof(ctx, "mindmaps searched successfully, userID: %s, keyword: %s, count: %d, total: %d", user.UserID, keyword, len(results), total)
+	return results, total, nil
+}
+
+// highlightKeyword 截取关键词附近的片段，HTML 转义后将关键词（忽略大小写）包裹在 <em> 中
+func highlightKeyword(text, keyword string) string {
+	runes := []rune(text)
+	matches := findKeyword(runes, []rune(keyword))
+	if len(matches) == 0 {
+		return html.EscapeString(text)
+	}
+
+	// 以第一个命中位置为中心截取片段
+	start, end := 0, len(runes)
+	kwLen := utf8.RuneCountInString(keyword)
+	if first := matches[0]; len(runes) > kwLen+2*searchSnippetRunes {
+		start = max(first-searchSnippetRunes, 0)
+		end = min(first+kwLen+searchSnippetRunes, len(runes))
+	}
+
+	var sb strings.Builder
+	if start > 0 {
+		sb.WriteString("…")
+	}
+	pos := start
+	for _, m := range matches {
+		if m < pos {
+			continue
+		}
+		if m+kwLen > end {
+			break
+		}
+		sb.WriteString(html.EscapeString(string(runes[pos:m])))
+		sb.WriteString("<em>")
+		sb.WriteString(html.EscapeString(string(runes[m : m+kwLen])))
+		sb.WriteString("</em>")
+		pos = m + kwLen
+	}
+	sb.WriteString(html.EscapeString(string(runes[pos:end])))
+	if end < len(runes) {
+		sb.WriteString("…")
+	}
+	return sb.String()
+}
+
+// findKeyword 返回关键词在文本中所有不重叠出现的位置（按字符计，忽略大小写）
+func findKeyword(text, keyword []rune) []int {
+	var matches []int
+	for i := 0; i+len(keyword) <= len(text); {
+		if equalFoldRunes(text[i:i+len(keyword)], keyword) {
+			matches = append(matches, i)
+			i += len(keyword)
+			continue
+		}
+		i++
+	}
+	return matches
+}
+
+func equalFoldRunes(a, b []rune) bool {
+	for i := range a {
+		if unicode.ToLower(a[i]) != unicode.ToLower(b[i]) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,272 @@
+package mindmapservice
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"time"
+
+	"forge/biz/entity"
+	"forge/biz/repo"
+	"forge/biz/types"
+	"forge/pkg/log/zlog"
+	"forge/util"
+
+	"github.com/bluele/gcache"
+	"golang.org/x/sync/singleflight"
+)
+
+const (
+	shareTokenBytes     = 24 // 分享 Token 的随机字节数，编码后为 32 个字符
+	shareMaxExpiresIn   = 365 * 24 * time.Hour
+	sharePasswordMaxLen = 64 // bcrypt 最多处理 72 字节
+	shareMinExpiresIn   = time.Minute
+
+	// 同一分享链接的密码在锁定时长内最多错误的次数，超出后在剩余锁定时长内拒绝校验
+	sharePasswordMaxFailures = 10
+	sharePasswordLockout     = 15 * time.Minute
+	sharePasswordFailKey     = "share:pwd:%s"
+
+	// 分享图片缓存：按导图版本缓存渲染结果，导图更新后版本变化自然失效
+	shareImageCacheSize = 64
+	shareImageCacheTTL  = time.Hour
+)
+
+// 分享链接可导出的格式，仅提供图片渲染
+var shareExportFormats = map[string]bool{
+	FormatSVG: true,
+	FormatPNG: true,
+}
+
+// CreateMindMapShare 为思维导图创建只读分享链接（仅所有者可分享）
+func (s *MindMapServiceImpl) CreateMindMapShare(ctx context.Context, mapID string, req *types.CreateMindMapShareParams) (*entity.MindMapShare, error) {
+	// 参数校验
+	if req.ExpiresIn != 0 && (req.ExpiresIn < shareMinExpiresIn || req.ExpiresIn > shareMaxExpiresIn) {
+		zlog.CtxErrorf(ctx, "invalid share expiresIn: %s", req.ExpiresIn)
+		return nil, ErrInvalidParams
+	}
+	if len(req.Password) > sharePasswordMaxLen {
+		zlog.CtxErrorf(ctx, "share password too long")
+		return nil, ErrInvalidParams
+	}
+
+	mindMap, err := s.getMindMapForRole(ctx, mapID, entity.MindMapRoleOwner)
+	if err != nil {
+		return nil, err // getMindMapForRole已经包含权限验证
+	}
+
+	shareID, err := util.GenerateStringID()
+	if err != nil {
+		zlog.CtxErrorf(ctx, "failed to generate share id: %v", err)
+		return nil, ErrInternalError
+	}
+	token, err := generateShareToken()
+	if err != nil {
+		zlog.CtxErrorf(ctx, "failed to generate share token: %v", err)
+		return nil, ErrInternalError
+	}
+
+	share := &entity.MindMapShare{
+		ShareID: shareID,
+		MapID:   mindMap.MapID,
+		UserID:  mindMap.UserID,
+		Token:   token,
+	}
+	if req.ExpiresIn > 0 {
+		expiresAt := time.Now().Add(req.ExpiresIn)
+		share.ExpiresAt = &expiresAt
+	}
+	if req.Password != "" {
+		share.PasswordHash, err = util.HashPassword(req.Password)
+		if err != nil {
+			zlog.CtxErrorf(ctx, "failed to hash share password: %v", err)
+			return nil, ErrInternalError
+		}
+	}
+
+	if err := s.mindMapRepo.CreateMindMapShare(ctx, share); err != nil {
+		zlog.CtxErrorf(ctx, "failed to create mindmap share: %v", err)
+		return nil, ErrInternalError
+	}
+
+	zlog.CtxInfof(ctx, "mindmap share created successfully, mapID: %s, shareID: %s", mapID, shareID)
+	return share, nil
+}
+
+// ListMindMapShares 获取用户未过期的分享链接，mapID 为空时返回全部导图的分享
+func (s *MindMapServiceImpl) ListMindMapShares(ctx context.Context, mapID string) ([]*entity.MindMapShare, error) {
+	// 从JWT token上下文中获取用户信息
+	user, ok := entity.GetUser(ctx)
+	if !ok {
+		zlog.CtxErrorf(ctx, "failed to get user from context")
+		return nil, ErrPermissionDenied
+	}
+
+	shares, err := s.mindMapRepo.ListMindMapShares(ctx, repo.MindMapShareQuery{UserID: user.UserID, MapID: mapID})
+	if err != nil {
+		zlog.CtxErrorf(ctx, "failed to list mindmap shares: %v", err)
+		return nil, ErrInternalError
+	}
+
+	zlog.CtxInfof(ctx, "mindmap shares listed successfully, userID: %s, count: %d", user.UserID, len(shares))
+	return shares, nil
+}
+
+// RevokeMindMapShare 撤销分享链接（用户只能撤销自己创建的分享）
+func (s *MindMapServiceImpl) RevokeMindMapShare(ctx context.Context, shareID string) error {
+	// 从JWT token上下文中获取用户信息
+	user, ok := entity.GetUser(ctx)
+	if !ok {
+		zlog.CtxErrorf(ctx, "failed to get user from context")
+		return ErrPermissionDenied
+	}
+
+	// 参数校验
+	if shareID == "" {
+		zlog.CtxErrorf(ctx, "shareID is required")
+		return ErrInvalidParams
+	}
+
+	if err := s.mindMapRepo.DeleteMindMapShare(ctx, shareID, user.UserID); err != nil {
+		if errors.Is(err, repo.ErrMindMapShareNotFound) {
+			return ErrShareNotFound
+		}
+		zlog.CtxErrorf(ctx, "failed to revoke mindmap share: %v", err)
+		return ErrInternalError
+	}
+
+	zlog.CtxInfof(ctx, "mindmap share revoked successfully, shareID: %s, userID: %s", shareID, user.UserID)
+	return nil
+}
+
+// GetSharedMindMap 通过分享链接获取思维导图（无需登录）
+func (s *MindMapServiceImpl) GetSharedMindMap(ctx context.Context, token, password string) (*entity.MindMap, error) {
+	return s.resolveShare(ctx, token, password)
+}
+
+// ExportSharedMindMap 通过分享链接获取思维导图的渲染图片（无需登录）
+func (s *MindMapServiceImpl) ExportSharedMindMap(ctx context.Context, token, password, format string) (*types.ExportMindMapResult, error) {
+	if !shareExportFormats[format] {
+		zlog.CtxWarnf(ctx, "unsupported share export format: %q", format)
+		return nil, ErrUnsupportedFormat
+	}
+
+	mindMap, err := s.resolveShare(ctx, token, password)
+	if err != nil {
+		return nil, err
+	}
+	return s.shareImages.get(mindMap, format, func() (*types.ExportMindMapResult, error) {
+		return s.exportMindMap(ctx, mindMap, format)
+	})
+}
+
+// resolveShare 校验分享链接的有效期与访问密码，返回分享的导图
+func (s *MindMapServiceImpl) resolveShare(ctx context.Context, token, password string) (*entity.MindMap, error) {
+	if token == "" {
+		return nil, ErrShareNotFound
+	}
+
+	share, err := s.mindMapRepo.GetMindMapShareByToken(ctx, token)
+	if err != nil {
+		if errors.Is(err, repo.ErrMindMapShareNotFound) {
+			return nil, ErrShareNotFound
+		}
+		zlog.CtxErrorf(ctx, "failed to get mindmap share: %v", err)
+		return nil, ErrInternalError
+	}
+	if share.IsExpired(time.Now()) {
+		zlog.CtxInfof(ctx, "mindmap share expired, shareID: %s", share.ShareID)
+		return nil, ErrShareNotFound
+	}
+
+	if share.HasPassword() {
+		if password == "" {
+			return nil, ErrSharePasswordNeeded
+		}
+		// 先计数再比较密码，并发尝试也无法越过次数上限；密码正确时撤销本次计数
+		failKey := fmt.Sprintf(sharePasswordFailKey, share.ShareID)
+		counted := true
+		allowed, err := s.rateLimiter.Allow(ctx, failKey, sharePasswordMaxFailures, sharePasswordLockout)
+		if err != nil {
+			// 计数器不可用时不锁定
+			zlog.CtxErrorf(ctx, "failed to count share password attempt: %v", err)
+			counted, allowed = false, true
+		}
+		if !allowed {
+			zlog.CtxWarnf(ctx, "share password locked, shareID: %s", share.ShareID)
+			return nil, ErrShareLocked
+		}
+		match, err := util.ComparePassword(share.PasswordHash, password)
+		if err != nil {
+			zlog.CtxErrorf(ctx, "failed to compare share password: %v", err)
+			return nil, ErrInternalError
+		}
+		if !match {
+			zlog.CtxWarnf(ctx, "wrong share password, shareID: %s", share.ShareID)
+			return nil, ErrSharePasswordWrong
+		}
+		if counted {
+			if err := s.rateLimiter.Release(ctx, failKey); err != nil {
+				zlog.CtxErrorf(ctx, "failed to release share password attempt: %v", err)
+			}
+		}
+	}
+
+	// 导图已移入回收站时分享链接暂时失效，恢复后可继续访问
+	mindMap, err := s.mindMapRepo.GetMindMap(ctx, share.MapID)
+	if err != nil {
+		zlog.CtxErrorf(ctx, "failed to get shared mindmap: %v", err)
+		return nil, ErrInternalError
+	}
+	if mindMap == nil {
+		return nil, ErrShareNotFound
+	}
+	mindMap.UserID = "" // 不向访问者暴露导图所有者
+
+	zlog.CtxInfof(ctx, "shared mindmap accessed, shareID: %s, mapID: %s", share.ShareID, share.MapID)
+	return mindMap, nil
+}
+
+// shareImageCache 分享图片的进程内缓存，按导图ID、版本与格式区分
+// 同一图片的并发请求只渲染一次，避免分享链接被频繁访问时重复渲染
+type shareImageCache struct {
+	cache gcache.Cache
+	group singleflight.Group
+}
+
+func newShareImageCache() *shareImageCache {
+	return &shareImageCache{
+		cache: gcache.New(shareImageCacheSize).LRU().Expiration(shareImageCacheTTL).Build(),
+	}
+}
+
+func (c *shareImageCache) get(mindMap *entity.MindMap, format string, render func() (*types.ExportMindMapResult, error)) (*types.ExportMindMapResult, error) {
+	key := fmt.Sprintf("%s:%d:%s", mindMap.MapID, mindMap.Version, format)
+	if v, err := c.cache.Get(key); err == nil {
+		return v.(*types.ExportMindMapResult), nil
+	}
+
+	v, err, _ := c.group.Do(key, func() (interface{}, error) {
+		result, err := render()
+		if err != nil {
+			return nil, err
+		}
+		_ = c.cache.Set(key, result)
+		return result, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return v.(*types.ExportMindMapResult), nil
+}
+
+// generateShareToken 生成 URL 安全的随机分享 Token
+func generateShareToken() (string, error) {
+	b := make([]byte, shareTokenBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
@@ -0,0 +1,246 @@
+package mindmapservice
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"forge/biz/entity"
+	"forge/biz/types"
+	"forge/pkg/log/zlog"
+)
+
+// 增量同步默认配置
+const (
+	DefaultSyncRetention = 30 * 24 * time.Hour
+	syncPruneInterval    = time.Hour
+	defaultSyncLimit     = 100
+	maxSyncLimit         = 500
+
+	// syncSettleDelay 只读取写入超过该时长的变更记录
+	// 变更序号在事务提交前分配，序号较小的事务可能晚于序号较大的事务提交，等待片刻减少游标越过尚未提交的记录
+	syncSettleDelay = 5 * time.Second
+	// syncOverlap 每轮增量同步额外回扫游标时间之前该时长内写入、序号不大于游标的记录
+	// 覆盖提交耗时超过 syncSettleDelay 或各实例时钟偏差导致被游标越过的记录，回扫到的导图与本批去重后返回当前状态
+	syncOverlap = 2 * time.Minute
+)
+
+var ErrSyncCursorExpired = errors.New("同步游标已过期，请重新全量同步")
+
+// syncCursor 同步位置，编码后对调用方不透明
+type syncCursor struct {
+	Seq      int64  `json:"s"`           // 已同步到的变更序号
+	Full     bool   `json:"f,omitempty"` // 是否处于全量同步阶段
+	AfterMap string `json:"m,omitempty"` // 全量同步已返回的最后一个导图ID
+	IssuedAt int64  `json:"t"`           // Seq 对应的时间（Unix 秒），早于保留期时变更记录可能已被清理
+	Paging   bool   `json:"p,omitempty"` // 上一批记录未读完，本批已在该轮首批回扫过，不再回扫
+}
+
+func decodeSyncCursor(encoded string) (*syncCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, err
+	}
+	var cursor syncCursor
+	if err := json.Unmarshal(raw, &cursor); err != nil {
+		return nil, err
+	}
+	return &cursor, nil
+}
+
+func (c *syncCursor) encode() string {
+	raw, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+// SyncMindMaps 增量同步用户可访问的导图
+// 游标为空时先按导图ID顺序分批返回全部导图，并记下开始时的变更序号；全量同步完成后按变更记录返回之后发生变化的导图
+// 同一导图的多次变化只返回一次当前状态，已删除、移入回收站或失去访问权限的导图只返回ID
+// 为避免漏掉晚提交的变更，近期已返回过的导图可能在之后的同步中再次返回，客户端按导图ID覆盖即可
+func (s *MindMapServiceImpl) SyncMindMaps(ctx context.Context, req *types.SyncMindMapsParams) (*types.MindMapSyncResult, error) {
+	// 从JWT token上下文中获取用户信息
+	user, ok := entity.GetUser(ctx)
+	if !ok {
+		zlog.CtxErrorf(ctx, "failed to get user from context")
+		return nil, ErrPermissionDenied
+	}
+
+	limit := req.Limit
+	if limit <= 0 {
+		limit = defaultSyncLimit
+	}
+	if limit > maxSyncLimit {
+		limit = maxSyncLimit
+	}
+
+	now := time.Now()
+	var cursor *syncCursor
+	if req.Cursor == "" {
+		seq, err := s.mindMapRepo.GetLatestMindMapChangeSeq(ctx, now.Add(-syncSettleDelay))
+		if err != nil {
+			zlog.CtxErrorf(ctx, "failed to get latest mindmap change: %v", err)
+			return nil, ErrInternalError
+		}
+		cursor = &syncCursor{Seq: seq, Full: true, IssuedAt: now.Add(-syncSettleDelay).Unix()}
+	} else {
+		var err error
+		if cursor, err = decodeSyncCursor(req.Cursor); err != nil {
+			zlog.CtxWarnf(ctx, "invalid sync cursor, userID: %s", user.UserID)
+			return nil, fmt.Errorf("%w: 无效的同步游标", ErrInvalidParams)
+		}
+		if time.Unix(cursor.IssuedAt, 0).Before(now.Add(-s.syncRetention)) {
+			zlog.CtxWarnf(ctx, "sync cursor expired, userID: %s, issuedAt: %d", user.UserID, cursor.IssuedAt)
+			return nil, ErrSyncCursorExpired
+		}
+	}
+
+	var result *types.MindMapSyncResult
+	var err error
+	if cursor.Full {
+		result, err = s.syncAllMindMaps(ctx, user.UserID, cursor, limit)
+	} else {
+		result, err = s.syncChangedMindMaps(ctx, user.UserID, cursor, now, limit)
+	}
+	if err != nil {
+		zlog.CtxErrorf(ctx, "failed to sync mindmaps: %v", err)
+		return nil, ErrInternalError
+	}
+
+	zlog.CtxInfof(ctx, "mindmaps synced successfully, userID: %s, full: %t, changed: %d, deleted: %d, hasMore: %t",
+		user.UserID, cursor.Full, len(result.Changed), len(result.Deleted), result.HasMore)
+	return result, nil
+}
+
+// syncAllMindMaps 全量同步阶段，返回 cursor.AfterMap 之后的一批导图
+func (s *MindMapServiceImpl) syncAllMindMaps(ctx context.Context, userID string, cursor *syncCursor, limit int) (*types.MindMapSyncResult, error) {
+	// 多查一条用于判断是否还有下一批
+	mindMaps, err := s.mindMapRepo.ListMindMapsForSync(ctx, userID, cursor.AfterMap, limit+1)
+	if err != nil {
+		return nil, err
+	}
+	hasMore := len(mindMaps) > limit
+	if hasMore {
+		mindMaps = mindMaps[:limit]
+	}
+
+	next := &syncCursor{Seq: cursor.Seq, IssuedAt: cursor.IssuedAt}
+	if hasMore {
+		next.Full, next.AfterMap = true, mindMaps[len(mindMaps)-1].MapID
+	}
+	// 全量同步期间发生的变化记录在开始时的序号之后，完成后继续按变更记录同步
+	return &types.MindMapSyncResult{
+		Changed: mindMaps,
+		Deleted: []string{},
+		Cursor:  next.encode(),
+		HasMore: true,
+	}, nil
+}
+
+// syncChangedMindMaps 按变更记录返回 cursor.Seq 之后发生变化的导图
+func (s *MindMapServiceImpl) syncChangedMindMaps(ctx context.Context, userID string, cursor *syncCursor, now time.Time, limit int) (*types.MindMapSyncResult, error) {
+	settled := now.Add(-syncSettleDelay)
+	// 同一导图可能有多条记录，按记录条数限制单次读取量，多查一条用于判断是否还有更多
+	changes, err := s.mindMapRepo.ListMindMapChanges(ctx, userID, cursor.Seq, settled, limit+1)
+	if err != nil {
+		return nil, err
+	}
+	hasMore := len(changes) > limit
+	if hasMore {
+		changes = changes[:limit]
+	}
+
+	// 每轮首批回扫游标之前近期写入的记录，补上读取时尚未提交的变更
+	if !cursor.Paging {
+		late, err := s.mindMapRepo.ListLateMindMapChanges(ctx, userID, cursor.Seq,
+			time.Unix(cursor.IssuedAt, 0).Add(-syncOverlap), maxSyncLimit)
+		if err != nil {
+			return nil, err
+		}
+		changes = append(changes, late...)
+	}
+
+	next := &syncCursor{Seq: cursor.Seq, IssuedAt: settled.Unix()}
+	mapIDs := make([]string, 0, len(changes))
+	seen := make(map[string]struct{}, len(changes))
+	for _, change := range changes {
+		if change.Seq > next.Seq {
+			next.Seq = change.Seq
+		}
+		if _, dup := seen[change.MapID]; dup {
+			continue
+		}
+		seen[change.MapID] = struct{}{}
+		mapIDs = append(mapIDs, change.MapID)
+	}
+	if hasMore {
+		// 后续记录尚未读取，游标时间只能推进到本批最后一条记录
+		next.IssuedAt = cursor.IssuedAt
+		next.Paging = true
+	}
+
+	mindMaps, err := s.mindMapRepo.GetMindMapsForUser(ctx, userID, mapIDs)
+	if err != nil {
+		return nil, err
+	}
+	accessible := make(map[string]struct{}, len(mindMaps))
+	for _, mindMap := range mindMaps {
+		accessible[mindMap.MapID] = struct{}{}
+	}
+	deleted := make([]string, 0)
+	for _, mapID := range mapIDs {
+		if _, ok := accessible[mapID]; !ok {
+			deleted = append(deleted, mapID)
+		}
+	}
+
+	return &types.MindMapSyncResult{
+		Changed: mindMaps,
+		Deleted: deleted,
+		Cursor:  next.encode(),
+		HasMore: hasMore,
+	}, nil
+}
+
+// StartChangePruner 启动后台清理任务，定期删除超过保留期的变更记录，ctx 取消时退出
+// 超过保留期的同步游标随之失效，客户端需重新全量同步
+func (s *MindMapServiceImpl) StartChangePruner(ctx context.Context, retention time.Duration) {
+	if retention <= 0 {
+		retention = DefaultSyncRetention
+	}
+	s.syncRetention = retention
+	zlog.Infof("mindmap change pruner started, retention: %s", retention)
+
+	go func() {
+		ticker := time.NewTicker(syncPruneInterval)
+		defer ticker.Stop()
+		for {
+			s.pruneMindMapChanges(ctx, time.Now().Add(-retention))
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// pruneMindMapChanges 删除过期的变更记录，失败时等待下一轮重试
+func (s *MindMapServiceImpl) pruneMindMapChanges(ctx context.Context, createdBefore time.Time) {
+	defer func() {
+		if r := recover(); r != nil {
+			zlog.Errorf("mindmap change pruner panic: %v", r)
+		}
+	}()
+
+	count, err := s.mindMapRepo.PruneMindMapChanges(ctx, createdBefore)
+	if err != nil {
+		zlog.Errorf("failed to prune mindmap changes: %v", err)
+		return
+	}
+	if count > 0 {
+		zlog.Infof("expired mindmap changes pruned, count: %d, createdBefore: %s", count, createdBefore.Format(time.DateTime))
+	}
+}
@@ -0,0 +1,168 @@
+package mindmapservice
+
+import (
+	"context"
+	"errors"
+
+	"forge/biz/entity"
+	"forge/biz/repo"
+	"forge/biz/types"
+	"forge/pkg/log/zlog"
+	"forge/util"
+)
+
+// 模板名称与描述的长度限制（字节）与导图标题、描述一致
+const (
+	maxTemplateNameLength = 100
+	maxTemplateDescLength = 500
+)
+
+// ListMindMapTemplates 获取系统模板与当前用户的模板
+func (s *MindMapServiceImpl) ListMindMapTemplates(ctx context.Context, req *types.ListMindMapTemplatesParams) ([]*entity.MindMapTemplate, int64, error) {
+	user, ok := entity.GetUser(ctx)
+	if !ok {
+		zlog.CtxErrorf(ctx, "failed to get user from context")
+		return nil, 0, ErrPermissionDenied
+	}
+
+	query := repo.NewMindMapTemplateQueryForList(user.UserID, req.Page, req.PageSize)
+	query.Scope = req.Scope
+	query.Category = req.Category
+
+	templates, total, err := s.mindMapRepo.ListMindMapTemplates(ctx, query)
+	if err != nil {
+		zlog.CtxErrorf(ctx, "failed to list mindmap templates: %v", err)
+		return nil, 0, ErrInternalError
+	}
+
+	zlog.CtxInfof(ctx, "mindmap templates listed successfully, userID: %s, count: %d, total: %d", user.UserID, len(templates), total)
+	return templates, total, nil
+}
+
+// GetMindMapTemplate 获取模板详情（系统模板或当前用户自己的模板）
+func (s *MindMapServiceImpl) GetMindMapTemplate(ctx context.Context, templateID string) (*entity.MindMapTemplate, error) {
+	user, ok := entity.GetUser(ctx)
+	if !ok {
+		zlog.CtxErrorf(ctx, "failed to get user from context")
+		return nil, ErrPermissionDenied
+	}
+
+	if templateID == "" {
+		zlog.CtxErrorf(ctx, "templateID is required")
+		return nil, ErrInvalidParams
+	}
+
+	template, err := s.mindMapRepo.GetMindMapTemplate(ctx, templateID)
+	if err != nil {
+		zlog.CtxErrorf(ctx, "failed to get mindmap template: %v", err)
+		return nil, ErrInternalError
+	}
+	// 他人的模板按不存在处理
+	if template == nil || (!template.IsSystem() && template.UserID != user.UserID) {
+		zlog.CtxWarnf(ctx, "mindmap template not found, templateID: %s, userID: %s", templateID, user.UserID)
+		return nil, ErrTemplateNotFound
+	}
+
+	return template, nil
+}
+
+// SaveMindMapAsTemplate 将已有导图保存为当前用户的模板（协作成员均可保存）
+// 模板保存的是导图当前内容的副本，节点标识被清除，之后导图的修改不影响模板
+func (s *MindMapServiceImpl) SaveMindMapAsTemplate(ctx context.Context, mapID string, req *types.SaveMindMapAsTemplateParams) (*entity.MindMapTemplate, error) {
+	mindMap, err := s.GetMindMap(ctx, mapID)
+	if err != nil {
+		return nil, err // GetMindMap已经包含权限验证
+	}
+	user, _ := entity.GetUser(ctx)
+
+	name := req.Name
+	if name == "" {
+		name = mindMap.Title
+	}
+	if len(name) > maxTemplateNameLength || len(req.Desc) > maxTemplateDescLength {
+		zlog.CtxErrorf(ctx, "template name or desc is too long")
+		return nil, ErrInvalidParams
+	}
+
+	templateID, err := util.GenerateStringID()
+	if err != nil {
+		zlog.CtxErrorf(ctx, "failed to generate template id: %v", err)
+		return nil, ErrInternalError
+	}
+
+	data := mindMap.Data.Clone()
+	data.ClearNodeIDs()
+	data.ClearAttachments()
+	template := &entity.MindMapTemplate{
+		TemplateID: templateID,
+		UserID:     user.UserID,
+		Name:       name,
+		Desc:       req.Desc,
+		Category:   req.Category,
+		Layout:     mindMap.Layout,
+		Data:       data,
+	}
+	if err := s.mindMapRepo.CreateMindMapTemplate(ctx, template); err != nil {
+		zlog.CtxErrorf(ctx, "failed to create mindmap template: %v", err)
+		return nil, ErrInternalError
+	}
+
+	zlog.CtxInfof(ctx, "mindmap saved as template successfully, mapID: %s, templateID: %s, userID: %s", mapID, templateID, user.UserID)
+	return template, nil
+}
+
+// DeleteMindMapTemplate 删除当前用户自己的模板，系统模板不可删除
+func (s *MindMapServiceImpl) DeleteMindMapTemplate(ctx context.Context, templateID string) error {
+	template, err := s.GetMindMapTemplate(ctx, templateID)
+	if err != nil {
+		return err // GetMindMapTemplate已经包含权限验证
+	}
+	if template.IsSystem() {
+		zlog.CtxWarnf(ctx, "system template cannot be deleted, templateID: %s", templateID)
+		return ErrPermissionDenied
+	}
+
+	if err := s.mindMapRepo.DeleteMindMapTemplate(ctx, templateID, template.UserID); err != nil {
+		if errors.Is(err, repo.ErrMindMapTemplateNotFound) {
+			return ErrTemplateNotFound
+		}
+		zlog.CtxErrorf(ctx, "failed to delete mindmap template: %v", err)
+		return ErrInternalError
+	}
+
+	zlog.CtxInfof(ctx, "mindmap template deleted successfully, templateID: %s, userID: %s", templateID, template.UserID)
+	return nil
+}
+
+// CreateMindMapFromTemplate 基于模板创建思维导图，当前用户成为导图所有者
+func (s *MindMapServiceImpl) CreateMindMapFromTemplate(ctx context.Context, templateID string, req *types.CreateMindMapFromTemplateParams) (*entity.MindMap, error) {
+	template, err := s.GetMindMapTemplate(ctx, templateID)
+	if err != nil {
+		return nil, err // GetMindMapTemplate已经包含权限验证
+	}
+
+	params := &types.CreateMindMapParams{
+		Title:  req.Title,
+		Desc:   req.Desc,
+		Layout: req.Layout,
+		Data:   template.Data,
+		Provenance: &entity.MindMapProvenance{
+			Type:       entity.ProvenanceTemplate,
+			TemplateID: template.TemplateID,
+		},
+	}
+	if params.Title == "" {
+		params.Title = template.Name
+	}
+	if params.Layout == "" {
+		params.Layout = template.Layout
+	}
+
+	mindMap, err := s.CreateMindMap(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	zlog.CtxInfof(ctx, "mindmap created from template successfully, templateID: %s, mapID: %s", templateID, mindMap.MapID)
+	return mindMap, nil
+}
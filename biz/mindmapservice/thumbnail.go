@@ -0,0 +1,52 @@
+package mindmapservice
+
+import (
+	"context"
+	"fmt"
+	"path"
+
+	"forge/biz/adapter"
+	"forge/biz/entity"
+	"forge/pkg/log/zlog"
+)
+
+// 缩略图最大尺寸，按导图比例等比缩放
+const (
+	thumbnailMaxWidth  = 800
+	thumbnailMaxHeight = 600
+)
+
+// GenerateThumbnail 渲染思维导图缩略图并上传到 COS，返回缩略图地址（需要编辑权限）
+func (s *MindMapServiceImpl) GenerateThumbnail(ctx context.Context, mapID string) (string, error) {
+	mindMap, err := s.getMindMapForRole(ctx, mapID, entity.MindMapRoleEditor)
+	if err != nil {
+		return "", err // getMindMapForRole已经包含权限验证
+	}
+
+	content, err := s.renderer.RenderPNG(ctx, mindMap, adapter.RenderOptions{
+		Scale:     1,
+		MaxWidth:  thumbnailMaxWidth,
+		MaxHeight: thumbnailMaxHeight,
+	})
+	if err != nil {
+		zlog.CtxErrorf(ctx, "failed to render thumbnail, mapID: %s, error: %v", mapID, err)
+		return "", ErrInternalError
+	}
+
+	// 同一导图的缩略图使用所有者目录下的固定路径覆盖上传，地址附带版本号避免 CDN 与浏览器缓存旧图
+	resourcePath := path.Join(mindMapFileDir(mindMap.UserID, mindMap.MapID), "thumbnail.png")
+	url, err := s.cosService.UploadFile(ctx, resourcePath, content, "image/png")
+	if err != nil {
+		zlog.CtxErrorf(ctx, "failed to upload thumbnail, mapID: %s, resourcePath: %s, error: %v", mapID, resourcePath, err)
+		return "", ErrInternalError
+	}
+	thumbnail := fmt.Sprintf("%s?v=%d", url, mindMap.Version)
+
+	if err := s.mindMapRepo.UpdateMindMapThumbnail(ctx, mindMap.MapID, thumbnail); err != nil {
+		zlog.CtxErrorf(ctx, "failed to save thumbnail, mapID: %s, error: %v", mapID, err)
+		return "", ErrInternalError
+	}
+
+	zlog.CtxInfof(ctx, "mindmap thumbnail generated successfully, mapID: %s, size: %d", mapID, len(content))
+	return thumbnail, nil
+}
@@ -0,0 +1,143 @@
+package mindmapservice
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"forge/biz/entity"
+	"forge/biz/repo"
+	"forge/biz/types"
+	"forge/pkg/log/zlog"
+)
+
+// 回收站默认配置
+const (
+	DefaultTrashRetention     = 30 * 24 * time.Hour
+	DefaultTrashPurgeInterval = time.Hour
+	trashPurgeBatchSize       = 100
+)
+
+// ListTrashMindMaps 获取回收站中的思维导图列表（仅包含用户所有的思维导图）
+func (s *MindMapServiceImpl) ListTrashMindMaps(ctx context.Context, req *types.ListMindMapsParams) ([]*entity.MindMap, int64, error) {
+	// 从JWT token上下文中获取用户信息
+	user, ok := entity.GetUser(ctx)
+	if !ok {
+		zlog.CtxErrorf(ctx, "failed to get user from context")
+		return nil, 0, ErrPermissionDenied
+	}
+
+	// 构建查询条件（强制包含用户ID）
+	query := repo.NewMindMapQueryForList(user.UserID, req.Page, req.PageSize)
+	query.Title = req.Title
+	query.Layout = req.Layout
+
+	mindMaps, total, err := s.mindMapRepo.ListDeletedMindMaps(ctx, query)
+	if err != nil {
+		zlog.CtxErrorf(ctx, "failed to list trash mindmaps: %v", err)
+		return nil, 0, ErrInternalError
+	}
+
+	zlog.CtxInfof(ctx, "trash mindmaps listed successfully, userID: %s, count: %d, total: %d", user.UserID, len(mindMaps), total)
+	return mindMaps, total, nil
+}
+
+// RestoreMindMap 从回收站恢复思维导图（仅所有者可恢复）
+func (s *MindMapServiceImpl) RestoreMindMap(ctx context.Context, mapID string) error {
+	user, _, err := s.authorize(ctx, mapID, entity.MindMapRoleOwner)
+	if err != nil {
+		return err
+	}
+
+	if err := s.mindMapRepo.RestoreMindMap(ctx, mapID); err != nil {
+		if errors.Is(err, repo.ErrMindMapNotFound) {
+			return ErrMindMapNotFound
+		}
+		zlog.CtxErrorf(ctx, "failed to restore mindmap: %v", err)
+		return ErrInternalError
+	}
+
+	zlog.CtxInfof(ctx, "mindmap restored successfully, mapID: %s, userID: %s", mapID, user.UserID)
+	return nil
+}
+
+// PurgeMindMap 彻底删除回收站中的思维导图（仅所有者可删除），不可恢复
+func (s *MindMapServiceImpl) PurgeMindMap(ctx context.Context, mapID string) error {
+	user, _, err := s.authorize(ctx, mapID, entity.MindMapRoleOwner)
+	if err != nil {
+		return err
+	}
+
+	// 只能彻底删除已在回收站中的导图
+	if err := s.mindMapRepo.PurgeMindMap(ctx, mapID); err != nil {
+		if errors.Is(err, repo.ErrMindMapNotFound) {
+			return ErrMindMapNotFound
+		}
+		zlog.CtxErrorf(ctx, "failed to purge mindmap: %v", err)
+		return ErrInternalError
+	}
+	// 只有所有者可以彻底删除，文件存放在当前用户目录下
+	s.deleteMindMapFiles(ctx, user.UserID, mapID)
+
+	zlog.CtxInfof(ctx, "mindmap purged successfully, mapID: %s, userID: %s", mapID, user.UserID)
+	return nil
+}
+
+// StartTrashPurger 启动后台清理任务，定期彻底删除超过保留期的回收站导图，ctx 取消时退出
+func (s *MindMapServiceImpl) StartTrashPurger(ctx context.Context, retention, interval time.Duration) {
+	if retention <= 0 {
+		retention = DefaultTrashRetention
+	}
+	if interval <= 0 {
+		interval = DefaultTrashPurgeInterval
+	}
+	zlog.Infof("trash purger started, retention: %s, interval: %s", retention, interval)
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			s.purgeExpiredMindMaps(ctx, time.Now().Add(-retention))
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// purgeExpiredMindMaps 分批删除所有过期导图，单批失败时等待下一轮重试
+func (s *MindMapServiceImpl) purgeExpiredMindMaps(ctx context.Context, deletedBefore time.Time) {
+	defer func() {
+		if r := recover(); r != nil {
+			zlog.Errorf("trash purger panic: %v", r)
+		}
+	}()
+
+	total := 0
+	for ctx.Err() == nil {
+		purged, err := s.mindMapRepo.PurgeDeletedMindMaps(ctx, deletedBefore, trashPurgeBatchSize)
+		if err != nil {
+			zlog.Errorf("failed to purge expired mindmaps: %v", err)
+			break
+		}
+		for _, p := range purged {
+			s.deleteMindMapFiles(ctx, p.UserID, p.MapID)
+		}
+		total += len(purged)
+		if len(purged) < trashPurgeBatchSize {
+			break
+		}
+	}
+	if total > 0 {
+		zlog.Infof("expired mindmaps purged, count: %d, deletedBefore: %s", total, deletedBefore.Format(time.DateTime))
+	}
+}
+
+// deleteMindMapFiles 删除导图在 COS 中的附件与缩略图，失败时仅记录日志，不影响导图的删除
+func (s *MindMapServiceImpl) deleteMindMapFiles(ctx context.Context, ownerID, mapID string) {
+	if err := s.cosService.DeleteDirectory(ctx, mindMapFileDir(ownerID, mapID)); err != nil {
+		zlog.CtxErrorf(ctx, "failed to delete mindmap files, mapID: %s, ownerID: %s: %v", mapID, ownerID, err)
+	}
+}
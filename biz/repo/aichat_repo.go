@@ -7,8 +7,8 @@ import (
 )
 
 type AiChatRepo interface {
-	//获取某个会话
-	GetConversation(ctx context.Context, conversationID, userID string) (*entity.Conversation, error)
+	//获取某个会话 不校验归属 由服务层完成授权
+	GetConversation(ctx context.Context, conversationID string) (*entity.Conversation, error)
 
 	//获取某个导图的所有会话
 	GetMapAllConversation(ctx context.Context, mapID, userID string) ([]*entity.Conversation, error)
@@ -23,13 +23,16 @@ type AiChatRepo interface {
 	UpdateConversationTitle(ctx context.Context, conversation *entity.Conversation) error
 
 	//删除某个会话
-	DeleteConversation(ctx context.Context, conversationID, userID string) error
+	DeleteConversation(ctx context.Context, conversationID string) error
 }
 
 type EinoServer interface {
 	//向ai发送消息
 	SendMessage(ctx context.Context, messages []*entity.Message) (types.AgentResponse, error)
 
+	//流式向ai发送消息 生成过程中通过onEvent推送文本增量与工具调用 返回与SendMessage相同的完整结果
+	SendMessageStream(ctx context.Context, messages []*entity.Message, onEvent func(types.AgentEvent)) (types.AgentResponse, error)
+
 	//生成导图
 	GenerateMindMap(ctx context.Context, text, userID string) (string, error)
 	
@@ -4,51 +4,254 @@ import (
 	"context"
 	"errors"
 	"forge/biz/entity"
+	"time"
 )
 
 // 哨兵错误定义
 var (
-	ErrMindMapNotFound = errors.New("mindmap not found or no permission")
+	ErrMindMapNotFound           = errors.New("mindmap not found or no permission")
+	ErrMindMapVersionConflict    = errors.New("mindmap version conflict")
+	ErrMindMapRevisionNotFound   = errors.New("mindmap revision not found")
+	ErrMindMapShareNotFound      = errors.New("mindmap share not found")
+	ErrMindMapMemberNotFound     = errors.New("mindmap member not found")
+	ErrMindMapMemberExists       = errors.New("mindmap member already exists")
+	ErrMindMapTemplateNotFound   = errors.New("mindmap template not found")
+	ErrMindMapFolderNotFound     = errors.New("mindmap folder not found")
+	ErrInvalidMindMapCursor      = errors.New("invalid mindmap cursor")
+	ErrMindMapCommentNotFound    = errors.New("mindmap comment not found")
+	ErrMindMapAttachmentNotFound = errors.New("mindmap attachment not found")
 )
 
 // IMindMapRepo 思维导图仓储接口
+// 仓储层不做权限校验，访问控制由服务层基于协作成员角色完成
 type IMindMapRepo interface {
+	// CreateMindMap 创建思维导图，创建者自动成为所有者
 	CreateMindMap(ctx context.Context, mindmap *entity.MindMap) error
-	GetMindMap(ctx context.Context, query MindMapQuery) (*entity.MindMap, error)
+	// GetMindMap 获取思维导图，不存在或已移入回收站时返回 nil
+	GetMindMap(ctx context.Context, mapID string) (*entity.MindMap, error)
+	// ListMindMaps 获取用户作为成员可访问的思维导图，并填充用户角色
 	ListMindMaps(ctx context.Context, query MindMapQuery) ([]*entity.MindMap, int64, error)
-	UpdateMindMap(ctx context.Context, updateInfo *MindMapUpdateInfo) error
-	DeleteMindMap(ctx context.Context, mapID string, userID string) error
+	// ListMindMapsByCursor 按游标获取 query.Cursor 之后的一页导图，返回下一页游标，没有更多时为空
+	// 游标与排序方式绑定，排序方式变化时返回 ErrInvalidMindMapCursor
+	// 翻页期间排序字段变化的导图可能重复或遗漏，不保证返回完整列表
+	ListMindMapsByCursor(ctx context.Context, query MindMapQuery) ([]*entity.MindMap, string, error)
+	// UpdateMindMap 更新思维导图并写入新版本快照，返回更新后的版本号
+	UpdateMindMap(ctx context.Context, updateInfo *MindMapUpdateInfo) (int64, error)
+	DeleteMindMap(ctx context.Context, mapID string) error
+	// UpdateMindMapThumbnail 更新缩略图地址，不产生新版本
+	UpdateMindMapThumbnail(ctx context.Context, mapID, thumbnail string) error
+
+	// SearchMindMaps 在用户可访问的导图的标题、描述、节点文本中搜索关键词（不包含回收站中的导图）
+	SearchMindMaps(ctx context.Context, query MindMapSearchQuery) ([]*entity.MindMapSearchResult, int64, error)
+
+	// 回收站
+	// ListDeletedMindMaps 获取用户所有的回收站中的思维导图
+	ListDeletedMindMaps(ctx context.Context, query MindMapQuery) ([]*entity.MindMap, int64, error)
+	RestoreMindMap(ctx context.Context, mapID string) error
+	// PurgeMindMap 彻底删除回收站中的思维导图及其历史版本、分享链接、协作成员、评论、附件记录、会话
+	// COS 中的附件与缩略图文件由调用方清理
+	PurgeMindMap(ctx context.Context, mapID string) error
+	// PurgeDeletedMindMaps 彻底删除在 deletedBefore 之前移入回收站的思维导图，单次最多 limit 个，返回被删除的导图
+	PurgeDeletedMindMaps(ctx context.Context, deletedBefore time.Time, limit int) ([]PurgedMindMap, error)
+
+	// 历史版本
+	ListMindMapRevisions(ctx context.Context, query MindMapRevisionQuery) ([]*entity.MindMapRevision, int64, error)
+	GetMindMapRevision(ctx context.Context, mapID string, version int64) (*entity.MindMapRevision, error)
+
+	// 分享链接
+	CreateMindMapShare(ctx context.Context, share *entity.MindMapShare) error
+	GetMindMapShareByToken(ctx context.Context, token string) (*entity.MindMapShare, error)
+	// ListMindMapShares 获取用户未过期的分享链接
+	ListMindMapShares(ctx context.Context, query MindMapShareQuery) ([]*entity.MindMapShare, error)
+	DeleteMindMapShare(ctx context.Context, shareID, userID string) error
+
+	// 协作成员
+	// GetMindMapMember 获取用户在导图中的成员记录，不是成员时返回 nil
+	GetMindMapMember(ctx context.Context, mapID, userID string) (*entity.MindMapMember, error)
+	ListMindMapMembers(ctx context.Context, mapID string) ([]*entity.MindMapMember, error)
+	// AddMindMapMember 添加协作成员，用户已是成员时返回 ErrMindMapMemberExists
+	AddMindMapMember(ctx context.Context, member *entity.MindMapMember) error
+	UpdateMindMapMemberRole(ctx context.Context, mapID, userID, role string) error
+	// RemoveMindMapMember 移除协作成员及该成员为导图设置的标签
+	RemoveMindMapMember(ctx context.Context, mapID, userID string) error
+
+	// 模板
+	// ListMindMapTemplates 获取系统模板与用户自己的模板（不包含模板数据）
+	ListMindMapTemplates(ctx context.Context, query MindMapTemplateQuery) ([]*entity.MindMapTemplate, int64, error)
+	// GetMindMapTemplate 获取模板，不存在时返回 nil
+	GetMindMapTemplate(ctx context.Context, templateID string) (*entity.MindMapTemplate, error)
+	CreateMindMapTemplate(ctx context.Context, template *entity.MindMapTemplate) error
+	// DeleteMindMapTemplate 删除用户模板（用户只能删除自己创建的模板）
+	DeleteMindMapTemplate(ctx context.Context, templateID, userID string) error
+
+	// 文件夹、标签与收藏，均按用户独立保存
+	// ListMindMapFolders 获取用户的全部文件夹
+	ListMindMapFolders(ctx context.Context, userID string) ([]*entity.MindMapFolder, error)
+	// GetMindMapFolder 获取文件夹，不存在时返回 nil
+	GetMindMapFolder(ctx context.Context, folderID string) (*entity.MindMapFolder, error)
+	CreateMindMapFolder(ctx context.Context, folder *entity.MindMapFolder) error
+	// UpdateMindMapFolder 修改文件夹名称与上级文件夹
+	UpdateMindMapFolder(ctx context.Context, folder *entity.MindMapFolder) error
+	// DeleteMindMapFolders 删除用户的文件夹，其中的导图移回顶层
+	DeleteMindMapFolders(ctx context.Context, userID string, folderIDs []string) error
+	// MoveMindMapsToFolder 将用户作为成员的导图移入文件夹，folderID 为空表示移回顶层，返回实际移动的导图数量
+	MoveMindMapsToFolder(ctx context.Context, userID string, mapIDs []string, folderID string) (int64, error)
+	// SetMindMapStarred 设置用户是否收藏导图，用户不是成员时返回 ErrMindMapMemberNotFound
+	SetMindMapStarred(ctx context.Context, mapID, userID string, starred bool) error
+	// SetMindMapPinned 设置用户是否置顶导图，用户不是成员时返回 ErrMindMapMemberNotFound
+	SetMindMapPinned(ctx context.Context, mapID, userID string, pinned bool) error
+	// GetMindMapTags 获取用户为导图设置的标签
+	GetMindMapTags(ctx context.Context, mapID, userID string) ([]string, error)
+	// SetMindMapTags 以 tags 整体替换用户为导图设置的标签
+	SetMindMapTags(ctx context.Context, mapID, userID string, tags []string) error
+	// ListMindMapTagCounts 获取用户使用过的标签及对应的导图数量（不包含回收站中的导图）
+	ListMindMapTagCounts(ctx context.Context, userID string) ([]*entity.MindMapTagCount, error)
+
+	// 节点评论
+	// ListMindMapComments 获取导图的评论（包含回复），按创建时间排列
+	ListMindMapComments(ctx context.Context, query MindMapCommentQuery) ([]*entity.MindMapComment, error)
+	// GetMindMapComment 获取导图中的评论，不存在时返回 nil
+	GetMindMapComment(ctx context.Context, mapID, commentID string) (*entity.MindMapComment, error)
+	CreateMindMapComment(ctx context.Context, comment *entity.MindMapComment) error
+	// UpdateMindMapComment 修改评论内容与@的用户，并记录编辑时间
+	UpdateMindMapComment(ctx context.Context, comment *entity.MindMapComment) error
+	// SetMindMapCommentResolved 设置线程是否已解决
+	SetMindMapCommentResolved(ctx context.Context, mapID, commentID, userID string, resolved bool) error
+	// DeleteMindMapComment 删除评论，删除线程首条评论时一并删除其回复
+	DeleteMindMapComment(ctx context.Context, mapID, commentID string) error
+
+	// 节点附件
+	// ListMindMapAttachments 获取导图的附件，nodeID 不为空时只返回上传到该节点的附件，按上传时间排列
+	ListMindMapAttachments(ctx context.Context, mapID, nodeID string) ([]*entity.MindMapAttachment, error)
+	// GetMindMapAttachment 获取导图中的附件，不存在时返回 nil
+	GetMindMapAttachment(ctx context.Context, mapID, attachmentID string) (*entity.MindMapAttachment, error)
+	CreateMindMapAttachment(ctx context.Context, attachment *entity.MindMapAttachment) error
+	// DeleteMindMapAttachment 删除附件记录，不删除 COS 中的文件
+	DeleteMindMapAttachment(ctx context.Context, mapID, attachmentID string) error
+	// SumUserAttachmentSize 统计用户上传的全部附件大小（字节），包含回收站中导图的附件
+	SumUserAttachmentSize(ctx context.Context, userID string) (int64, error)
+
+	// 增量同步
+	// ListMindMapChanges 获取用户在序号 afterSeq 之后、createdBefore 之前写入的变更记录，按序号排列，单次最多 limit 条
+	ListMindMapChanges(ctx context.Context, userID string, afterSeq int64, createdBefore time.Time, limit int) ([]MindMapChange, error)
+	// ListLateMindMapChanges 获取用户序号不大于 upToSeq、createdAfter 之后写入的变更记录，按序号倒序排列，单次最多 limit 条
+	// 用于回扫游标之前晚于序号顺序提交的记录
+	ListLateMindMapChanges(ctx context.Context, userID string, upToSeq int64, createdAfter time.Time, limit int) ([]MindMapChange, error)
+	// GetLatestMindMapChangeSeq 获取 createdBefore 之前写入的最大变更序号，没有变更记录时为 0
+	GetLatestMindMapChangeSeq(ctx context.Context, createdBefore time.Time) (int64, error)
+	// PruneMindMapChanges 删除 createdBefore 之前写入的变更记录，返回删除的条数
+	PruneMindMapChanges(ctx context.Context, createdBefore time.Time) (int64, error)
+	// ListMindMapsForSync 按导图ID顺序获取用户可访问的 afterMapID 之后的最多 limit 个导图（不包含回收站中的），并填充用户角色与整理信息
+	ListMindMapsForSync(ctx context.Context, userID, afterMapID string, limit int) ([]*entity.MindMap, error)
+	// GetMindMapsForUser 获取 mapIDs 中用户可访问的导图（不包含回收站中的），并填充用户角色与整理信息
+	GetMindMapsForUser(ctx context.Context, userID string, mapIDs []string) ([]*entity.MindMap, error)
+}
+
+// MindMapChange 导图变更记录，只记录发生变化的导图，导图的当前状态在同步时读取
+// 导图内容、缩略图、删除与恢复为全部成员记录，成员变化与用户的整理信息变化只为该用户记录
+type MindMapChange struct {
+	Seq   int64
+	MapID string
+}
+
+// PurgedMindMap 被彻底删除的导图，用于清理导图在 COS 中的文件
+type PurgedMindMap struct {
+	MapID  string
+	UserID string // 所有者
 }
 
 // MindMapQuery 查询条件
 type MindMapQuery struct {
-	UserID   string // 用户ID（必填）
-	MapID    string // 思维导图ID
-	Title    string // 标题关键词（模糊查询）
-	Layout   string // 布局类型
-	Page     int    // 页码（从1开始）
-	PageSize int    // 每页大小（最大99）
+	UserID   string   // 用户ID（必填）
+	Role     string   // 用户在导图中的角色，为空时不限制
+	Title    string   // 标题关键词（模糊查询）
+	Layout   string   // 布局类型
+	FolderID *string  // 用户归入的文件夹，为空时不限制，指向空字符串时只查询未归入文件夹的导图
+	Tags     []string // 同时带有全部标签的导图
+	Starred  bool     // 只查询用户收藏的导图
+	Page     int      // 页码（从1开始）
+	PageSize int      // 每页大小（最大99），游标分页时为单页数量
+
+	SortBy      string     // 排序字段：title、created_at、updated_at，为空时按 updated_at
+	SortOrder   string     // 排序方向：asc、desc，为空时为 desc
+	CreatedFrom *time.Time // 创建时间不早于
+	CreatedTo   *time.Time // 创建时间早于
+	UpdatedFrom *time.Time // 更新时间不早于
+	UpdatedTo   *time.Time // 更新时间早于
+	Cursor      string     // 游标分页时上一页返回的游标，为空表示第一页
 }
 
+// 导图列表排序
+const (
+	MindMapSortByTitle     = "title"
+	MindMapSortByCreatedAt = "created_at"
+	MindMapSortByUpdatedAt = "updated_at"
+
+	SortOrderAsc  = "asc"
+	SortOrderDesc = "desc"
+)
+
 // MindMapUpdateInfo 更新信息（部分更新）
 type MindMapUpdateInfo struct {
 	MapID  string              // 思维导图ID（必填）
-	UserID string              // 用户ID（用于权限验证）
+	UserID string              // 操作人ID（记录在版本快照中）
 	Title  *string             // 标题
 	Desc   *string             // 描述
 	Layout *string             // 布局
 	Data   *entity.MindMapData // 数据（全量更新）
+
+	ExpectedVersion *int64 // 期望的当前版本，不为空时与库中版本不一致则返回 ErrMindMapVersionConflict
+}
+
+// MindMapSearchQuery 搜索条件
+type MindMapSearchQuery struct {
+	UserID        string // 用户ID（必填），仅搜索用户作为成员可访问的导图
+	Keyword       string // 关键词（必填）
+	Page          int    // 页码（从1开始）
+	PageSize      int    // 每页大小（最大99）
+	MaxHitsPerMap int    // 每个导图最多返回的命中项，0 表示不限制
+}
+
+// MindMapCommentQuery 评论查询条件，线程筛选条件对回复同样生效
+type MindMapCommentQuery struct {
+	MapID    string // 导图ID（必填）
+	NodeID   string // 节点标识，为空时不限制
+	Resolved *bool  // 线程是否已解决，为空时不限制
+}
+
+// MindMapShareQuery 分享链接查询条件
+type MindMapShareQuery struct {
+	UserID string // 用户ID（必填）
+	MapID  string // 思维导图ID，为空时查询用户的全部分享
 }
 
+// MindMapRevisionQuery 历史版本查询条件
+type MindMapRevisionQuery struct {
+	MapID    string // 思维导图ID（必填）
+	Page     int    // 页码（从1开始）
+	PageSize int    // 每页大小（最大99）
+}
+
+// MindMapTemplateQuery 模板查询条件
+type MindMapTemplateQuery struct {
+	UserID   string // 用户ID（必填）
+	Scope    string // system 仅系统模板，mine 仅用户模板，为空时两者都包含
+	Category string // 分类
+	Page     int    // 页码（从1开始）
+	PageSize int    // 每页大小（最大99）
+}
+
+// 模板范围
+const (
+	MindMapTemplateScopeSystem = "system"
+	MindMapTemplateScopeMine   = "mine"
+)
+
 // 查询构建函数
 func NewMindMapQueryByUserID(userID string) MindMapQuery {
 	return MindMapQuery{UserID: userID}
 }
 
-func NewMindMapQueryByID(userID, mapID string) MindMapQuery {
-	return MindMapQuery{UserID: userID, MapID: mapID}
-}
-
 func NewMindMapQueryForList(userID string, page, pageSize int) MindMapQuery {
 	if page <= 0 {
 		page = 1
@@ -61,3 +264,53 @@ func NewMindMapQueryForList(userID string, page, pageSize int) MindMapQuery {
 	}
 	return MindMapQuery{UserID: userID, Page: page, PageSize: pageSize}
 }
+
+// NewMindMapQueryForCursor 游标分页查询，单页最多200条
+func NewMindMapQueryForCursor(userID, cursor string, limit int) MindMapQuery {
+	if limit <= 0 {
+		limit = 20
+	}
+	if limit > 200 {
+		limit = 200
+	}
+	return MindMapQuery{UserID: userID, PageSize: limit, Cursor: cursor}
+}
+
+func NewMindMapSearchQuery(userID, keyword string, page, pageSize int) MindMapSearchQuery {
+	if page <= 0 {
+		page = 1
+	}
+	if pageSize <= 0 {
+		pageSize = 20
+	}
+	if pageSize > 99 {
+		pageSize = 99
+	}
+	return MindMapSearchQuery{UserID: userID, Keyword: keyword, Page: page, PageSize: pageSize}
+}
+
+func NewMindMapRevisionQueryForList(mapID string, page, pageSize int) MindMapRevisionQuery {
+	if page <= 0 {
+		page = 1
+	}
+	if pageSize <= 0 {
+		pageSize = 20
+	}
+	if pageSize > 99 {
+		pageSize = 99
+	}
+	return MindMapRevisionQuery{MapID: mapID, Page: page, PageSize: pageSize}
+}
+
+func NewMindMapTemplateQueryForList(userID string, page, pageSize int) MindMapTemplateQuery {
+	if page <= 0 {
+		page = 1
+	}
+	if pageSize <= 0 {
+		pageSize = 20
+	}
+	if pageSize > 99 {
+		pageSize = 99
+	}
+	return MindMapTemplateQuery{UserID: userID, Page: page, PageSize: pageSize}
+}
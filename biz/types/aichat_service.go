@@ -12,6 +12,9 @@ type IAiChatService interface {
 	//处理用户消息
 	ProcessUserMessage(ctx context.Context, req *ProcessUserMessageParams) (AgentResponse, error)
 
+	//流式处理用户消息 生成过程中通过onEvent推送事件 完成后保存聊天记录并返回完整结果
+	ProcessUserMessageStream(ctx context.Context, req *ProcessUserMessageParams, onEvent func(AgentEvent)) (AgentResponse, error)
+
 	//保存新的会话
 	SaveNewConversation(ctx context.Context, req *SaveNewConversationParams) (string, error)
 
@@ -37,13 +40,11 @@ type IAiChatService interface {
 type ProcessUserMessageParams struct {
 	ConversationID string
 	Message        string
-	MapData        string
 }
 
 type SaveNewConversationParams struct {
-	Title   string
-	MapID   string
-	MapData string
+	Title string
+	MapID string
 }
 
 type GetConversationListParams struct {
@@ -66,8 +67,29 @@ type UpdateConversationTitleParams struct {
 type AgentResponse struct {
 	NewMapJson string            `json:"new_map_json"`
 	Content    string            `json:"content"`
-	ToolCallID string            `json:"tool_call_id"`
-	ToolCalls  []schema.ToolCall `json:"tool_calls"`
+	Messages   []*schema.Message `json:"messages"` // 本轮模型与工具产生的消息（含工具调用及结果），按顺序保存到会话
+
+	NewMapData *entity.MindMapData `json:"-"`              // 工具修改后的导图树，未修改导图时为空，由服务层序列化为 NewMapJson
+	Diff       *entity.MindMapDiff `json:"diff,omitempty"` // NewMapJson 相对修改前导图的结构差异，未修改导图或导图无法解析时为空
+	MapVersion int64               `json:"map_version"`    // 本轮对话所基于的导图版本，应用 NewMapJson 时作为乐观锁版本
+}
+
+// 流式对话事件类型
+const (
+	AgentEventDelta      = "delta"       // 回复文本增量
+	AgentEventToolCall   = "tool_call"   // 开始调用工具
+	AgentEventToolResult = "tool_result" // 工具调用结果 即修改后的完整导图JSON
+)
+
+// AgentEvent 流式对话过程中的事件
+type AgentEvent struct {
+	Type       string
+	Content    string // delta 为文本增量 tool_result 为修改后的完整导图JSON
+	ToolCallID string
+	ToolName   string
+	Arguments  string // tool_call 的调用参数JSON
+
+	MapData *entity.MindMapData // tool_result 为本次工具调用后的导图树，由服务层序列化为 Content
 }
 
 type GenerateMindMapParams struct {
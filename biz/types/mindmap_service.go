@@ -3,28 +3,178 @@ package types
 import (
 	"context"
 	"forge/biz/entity"
+	"mime/multipart"
+	"time"
 )
 
 type IMindMapService interface {
 	CreateMindMap(ctx context.Context, req *CreateMindMapParams) (*entity.MindMap, error)
 	GetMindMap(ctx context.Context, mapID string) (*entity.MindMap, error)
 	ListMindMaps(ctx context.Context, req *ListMindMapsParams) ([]*entity.MindMap, int64, error)
-	UpdateMindMap(ctx context.Context, mapID string, req *UpdateMindMapParams) error
+	// ListMindMapsByCursor 按游标分页获取思维导图列表，返回下一页游标，没有更多时为空
+	ListMindMapsByCursor(ctx context.Context, req *ListMindMapsParams) ([]*entity.MindMap, string, error)
+	// SyncMindMaps 增量同步，返回游标之后发生变化的导图与已删除或失去访问权限的导图，游标为空时从全量同步开始
+	SyncMindMaps(ctx context.Context, req *SyncMindMapsParams) (*MindMapSyncResult, error)
+	UpdateMindMap(ctx context.Context, mapID string, req *UpdateMindMapParams) (int64, error)
+	// DeleteMindMap 将思维导图移入回收站
 	DeleteMindMap(ctx context.Context, mapID string) error
+	// PatchMindMap 节点级增量更新，返回更新后的版本号
+	PatchMindMap(ctx context.Context, mapID string, req *PatchMindMapParams) (int64, error)
+	// ImportMindMap 从 Markdown、OPML、FreeMind 文件导入思维导图
+	ImportMindMap(ctx context.Context, req *ImportMindMapParams) (*entity.MindMap, error)
+	// ExportMindMap 导出思维导图为指定格式的文件
+	ExportMindMap(ctx context.Context, mapID string, format string) (*ExportMindMapResult, error)
+	// SearchMindMaps 在标题、描述、节点文本中搜索关键词，命中文本中的关键词以 <em> 标记
+	SearchMindMaps(ctx context.Context, req *SearchMindMapsParams) ([]*entity.MindMapSearchResult, int64, error)
+	// GenerateThumbnail 生成并保存思维导图缩略图，返回缩略图地址
+	GenerateThumbnail(ctx context.Context, mapID string) (string, error)
+
+	// 回收站
+	ListTrashMindMaps(ctx context.Context, req *ListMindMapsParams) ([]*entity.MindMap, int64, error)
+	RestoreMindMap(ctx context.Context, mapID string) error
+	// PurgeMindMap 彻底删除回收站中的思维导图
+	PurgeMindMap(ctx context.Context, mapID string) error
+
+	// 历史版本
+	ListMindMapRevisions(ctx context.Context, mapID string, req *ListMindMapRevisionsParams) ([]*entity.MindMapRevision, int64, error)
+	GetMindMapRevision(ctx context.Context, mapID string, version int64) (*entity.MindMapRevision, error)
+	RestoreMindMapRevision(ctx context.Context, mapID string, req *RestoreMindMapRevisionParams) (int64, error)
+	// DiffMindMap 比较两个版本之间，或某个版本与提交的导图数据之间的结构差异
+	DiffMindMap(ctx context.Context, mapID string, req *DiffMindMapParams) (*entity.MindMapDiff, error)
+
+	// 节点评论
+	ListMindMapComments(ctx context.Context, mapID string, req *ListMindMapCommentsParams) ([]*entity.MindMapCommentThread, error)
+	CreateMindMapComment(ctx context.Context, mapID string, req *CreateMindMapCommentParams) (*entity.MindMapComment, error)
+	UpdateMindMapComment(ctx context.Context, mapID, commentID string, req *UpdateMindMapCommentParams) (*entity.MindMapComment, error)
+	DeleteMindMapComment(ctx context.Context, mapID, commentID string) error
+	// ResolveMindMapComment 将评论线程标记为已解决或重新打开
+	ResolveMindMapComment(ctx context.Context, mapID, commentID string, resolved bool) error
+
+	// 节点附件
+	// ListMindMapAttachments 获取导图的附件，nodeID 不为空时只返回上传到该节点的附件
+	ListMindMapAttachments(ctx context.Context, mapID, nodeID string) ([]*entity.MindMapAttachment, error)
+	// UploadMindMapAttachment 上传附件并添加到节点
+	UploadMindMapAttachment(ctx context.Context, mapID string, req *UploadMindMapAttachmentParams) (*entity.MindMapAttachment, error)
+	DeleteMindMapAttachment(ctx context.Context, mapID, attachmentID string) error
+	// GetAttachmentUsage 获取当前用户的附件存储用量与配额
+	GetAttachmentUsage(ctx context.Context) (*AttachmentUsage, error)
+
+	// 分享链接
+	CreateMindMapShare(ctx context.Context, mapID string, req *CreateMindMapShareParams) (*entity.MindMapShare, error)
+	// ListMindMapShares 获取未过期的分享链接，mapID 为空时返回全部导图的分享
+	ListMindMapShares(ctx context.Context, mapID string) ([]*entity.MindMapShare, error)
+	RevokeMindMapShare(ctx context.Context, shareID string) error
+	// GetSharedMindMap 通过分享链接获取思维导图，无需登录
+	GetSharedMindMap(ctx context.Context, token, password string) (*entity.MindMap, error)
+	// ExportSharedMindMap 通过分享链接获取思维导图的渲染图片（svg、png），无需登录
+	ExportSharedMindMap(ctx context.Context, token, password, format string) (*ExportMindMapResult, error)
+
+	// 协作成员
+	ListMindMapMembers(ctx context.Context, mapID string) ([]*entity.MindMapMember, error)
+	// InviteMindMapMember 通过邮箱或手机号邀请已注册用户协作，并发送邀请通知
+	InviteMindMapMember(ctx context.Context, mapID string, req *InviteMindMapMemberParams) (*entity.MindMapMember, error)
+	UpdateMindMapMemberRole(ctx context.Context, mapID, userID, role string) error
+	// RemoveMindMapMember 移除协作成员，成员也可以移除自己以退出协作
+	RemoveMindMapMember(ctx context.Context, mapID, userID string) error
+
+	// 实时协作
+	// JoinCollab 加入导图的实时协作，返回导图快照与当前在线状态，之后的协作事件通过会话推送
+	JoinCollab(ctx context.Context, mapID string) (*CollabSession, error)
+	// LeaveCollab 离开实时协作，连接断开时调用
+	LeaveCollab(ctx context.Context, mapID, connID string)
+	// ApplyCollabOps 将基于 BaseVersion 的节点操作变换到最新版本后保存并广播
+	ApplyCollabOps(ctx context.Context, mapID, connID string, req *ApplyCollabOpsParams) (*ApplyCollabOpsResult, error)
+	// UpdateCollabCursor 更新光标所在节点并广播
+	UpdateCollabCursor(ctx context.Context, mapID, connID, nodeID string) error
+	// TouchCollab 刷新在线状态，连接保持期间需定期调用
+	TouchCollab(ctx context.Context, mapID, connID string) error
+
+	// 模板
+	// ListMindMapTemplates 获取系统模板与当前用户的模板（不包含模板数据）
+	ListMindMapTemplates(ctx context.Context, req *ListMindMapTemplatesParams) ([]*entity.MindMapTemplate, int64, error)
+	// GetMindMapTemplate 获取模板详情用于预览
+	GetMindMapTemplate(ctx context.Context, templateID string) (*entity.MindMapTemplate, error)
+	// SaveMindMapAsTemplate 将已有导图保存为当前用户的模板
+	SaveMindMapAsTemplate(ctx context.Context, mapID string, req *SaveMindMapAsTemplateParams) (*entity.MindMapTemplate, error)
+	DeleteMindMapTemplate(ctx context.Context, templateID string) error
+	// CreateMindMapFromTemplate 基于模板创建思维导图
+	CreateMindMapFromTemplate(ctx context.Context, templateID string, req *CreateMindMapFromTemplateParams) (*entity.MindMap, error)
+
+	// 复制、拆分与合并
+	// DuplicateMindMap 复制导图为当前用户的新导图
+	DuplicateMindMap(ctx context.Context, mapID string, req *DuplicateMindMapParams) (*entity.MindMap, error)
+	// ExtractMindMapSubtree 将导图中的一个分支提取为新导图，可选从原导图中移除
+	ExtractMindMapSubtree(ctx context.Context, mapID string, req *ExtractMindMapSubtreeParams) (*entity.MindMap, error)
+	// MergeMindMaps 将多张导图合并到一个新根节点下生成新导图
+	MergeMindMaps(ctx context.Context, req *MergeMindMapsParams) (*entity.MindMap, error)
+
+	// 文件夹、标签与收藏，均为当前用户独立的整理信息
+	ListMindMapFolders(ctx context.Context) ([]*entity.MindMapFolder, error)
+	CreateMindMapFolder(ctx context.Context, req *CreateMindMapFolderParams) (*entity.MindMapFolder, error)
+	// UpdateMindMapFolder 重命名文件夹或移动到其他文件夹下
+	UpdateMindMapFolder(ctx context.Context, folderID string, req *UpdateMindMapFolderParams) (*entity.MindMapFolder, error)
+	// DeleteMindMapFolder 删除文件夹及其子文件夹，其中的导图移回顶层
+	DeleteMindMapFolder(ctx context.Context, folderID string) error
+	// MoveMindMapsToFolder 批量将导图移入文件夹，返回实际移动的导图数量
+	MoveMindMapsToFolder(ctx context.Context, req *MoveMindMapsToFolderParams) (int64, error)
+	StarMindMap(ctx context.Context, mapID string, starred bool) error
+	PinMindMap(ctx context.Context, mapID string, pinned bool) error
+	// SetMindMapTags 整体替换导图的标签，返回规范化后的标签
+	SetMindMapTags(ctx context.Context, mapID string, tags []string) ([]string, error)
+	// ListMindMapTags 获取当前用户使用过的标签及对应的导图数量
+	ListMindMapTags(ctx context.Context) ([]*entity.MindMapTagCount, error)
 }
 
 // 创建参数 - 服务层参数对象，无需json tag
 type CreateMindMapParams struct {
-	Title  string
-	Desc   string
-	Layout string
-	Data   entity.MindMapData
+	Title      string
+	Desc       string
+	Layout     string
+	Data       entity.MindMapData
+	Provenance *entity.MindMapProvenance // 派生来源，仅服务内部创建派生导图时设置
+	// 来源导图的附件，仅服务内部创建派生导图时设置；节点引用的附件复制到新导图，其余引用移除
+	Attachments []*entity.MindMapAttachment
 }
 
 // 列表查询参数 - 服务层参数对象，无需json tag
 type ListMindMapsParams struct {
 	Title    string
 	Layout   string
+	Role     string   // 用户在导图中的角色，为空时不限制
+	FolderID string   // 为空时不限制，为 root 时只查询未归入文件夹的导图
+	Tags     []string // 同时带有全部标签的导图
+	Starred  bool     // 只查询收藏的导图
+
+	SortBy      string // title、created_at、updated_at，默认 updated_at
+	SortOrder   string // asc、desc，默认 desc
+	CreatedFrom *time.Time
+	CreatedTo   *time.Time
+	UpdatedFrom *time.Time
+	UpdatedTo   *time.Time
+
+	Page     int
+	PageSize int
+	Cursor   string // 游标分页时上一页返回的游标，为空时从第一页开始
+	Limit    int    // 游标分页的单页数量
+}
+
+// 增量同步参数 - 服务层参数对象，无需json tag
+type SyncMindMapsParams struct {
+	Cursor string // 上次同步返回的游标，为空时从全量同步开始
+	Limit  int    // 单次返回的最大数量
+}
+
+// 增量同步结果
+type MindMapSyncResult struct {
+	Changed []*entity.MindMap // 新增或发生变化的导图
+	Deleted []string          // 已删除、移入回收站或失去访问权限的导图ID
+	Cursor  string            // 下次同步使用的游标
+	HasMore bool              // 是否还有未返回的变化，为 true 时应立即使用新游标继续同步
+}
+
+// 搜索参数 - 服务层参数对象，无需json tag
+type SearchMindMapsParams struct {
+	Keyword  string
 	Page     int
 	PageSize int
 }
@@ -35,4 +185,174 @@ type UpdateMindMapParams struct {
 	Desc   *string
 	Layout *string
 	Data   *entity.MindMapData
+
+	Version *int64 // 客户端持有的版本号，用于乐观锁校验，更新导图数据时必填
+}
+
+// 节点级补丁参数 - 服务层参数对象，无需json tag
+type PatchMindMapParams struct {
+	Ops     []entity.MindMapPatchOp
+	Version *int64 // 客户端持有的版本号，不为空时用于乐观锁校验
+}
+
+// 导入参数 - 服务层参数对象，无需json tag
+type ImportMindMapParams struct {
+	File   *multipart.FileHeader
+	Format string // 文件格式，为空时根据扩展名推断
+	Title  string // 导图标题，为空时使用文档标题
+	Layout string // 布局类型，为空时使用默认布局
+}
+
+// 导出结果
+type ExportMindMapResult struct {
+	FileName    string
+	ContentType string
+	Content     []byte
+}
+
+// 历史版本列表参数 - 服务层参数对象，无需json tag
+type ListMindMapRevisionsParams struct {
+	Page     int
+	PageSize int
+}
+
+// 恢复历史版本参数 - 服务层参数对象，无需json tag
+type RestoreMindMapRevisionParams struct {
+	Version         int64  // 要恢复到的历史版本号
+	ExpectedVersion *int64 // 客户端持有的当前版本号，必填，用于乐观锁校验
+}
+
+// 导图差异比较参数 - 服务层参数对象，无需json tag
+type DiffMindMapParams struct {
+	FromVersion int64               // 比较基准版本，0 表示当前版本
+	ToVersion   int64               // 比较目标版本，0 表示当前版本，Data 不为空时忽略
+	Data        *entity.MindMapData // 提交的导图数据，不为空时与基准版本比较
+}
+
+// 评论列表参数 - 服务层参数对象，无需json tag
+type ListMindMapCommentsParams struct {
+	NodeID   string // 为空时不限制
+	Resolved *bool  // 为空时不限制
+}
+
+// 发表评论参数 - 服务层参数对象，无需json tag
+type CreateMindMapCommentParams struct {
+	NodeID   string // 发起线程时评论的节点
+	ParentID string // 回复的评论，不为空时忽略 NodeID
+	Content  string
+	Mentions []string // 被@的用户ID
+}
+
+// 编辑评论参数 - 服务层参数对象，无需json tag
+type UpdateMindMapCommentParams struct {
+	Content  string
+	Mentions []string // 被@的用户ID，整体替换
+}
+
+// 上传附件参数 - 服务层参数对象，无需json tag
+type UploadMindMapAttachmentParams struct {
+	NodeID string // 附件所属的节点
+	File   *multipart.FileHeader
+}
+
+// 附件存储用量
+type AttachmentUsage struct {
+	Used  int64 // 已使用的字节数
+	Quota int64 // 配额字节数
+}
+
+// 创建分享链接参数 - 服务层参数对象，无需json tag
+type CreateMindMapShareParams struct {
+	ExpiresIn time.Duration // 有效期，0 表示永久有效
+	Password  string        // 访问密码，为空表示无需密码
+}
+
+// 邀请协作成员参数 - 服务层参数对象，无需json tag
+type InviteMindMapMemberParams struct {
+	Email string // 被邀请人邮箱，与手机号二选一
+	Phone string // 被邀请人手机号，与邮箱二选一
+	Role  string // editor、viewer
+}
+
+// CollabSession 实时协作会话
+type CollabSession struct {
+	ConnID   string
+	MindMap  *entity.MindMap            // 加入时的导图快照
+	Presence []*entity.CollabPresence   // 加入时的在线状态（包含自身）
+	Events   <-chan *entity.CollabEvent // 其他连接产生的协作事件，会话因消费过慢被移除时关闭
+}
+
+// 实时协作操作参数 - 服务层参数对象，无需json tag
+type ApplyCollabOpsParams struct {
+	BaseVersion int64 // 客户端生成操作时所基于的导图版本
+	Ops         []entity.MindMapPatchOp
+}
+
+// ApplyCollabOpsResult 实时协作操作结果
+type ApplyCollabOpsResult struct {
+	Version int64                   // 操作生效后的导图版本
+	Ops     []entity.MindMapPatchOp // 变换后实际生效的操作
+}
+
+// 模板列表查询参数 - 服务层参数对象，无需json tag
+type ListMindMapTemplatesParams struct {
+	Scope    string // system 仅系统模板，mine 仅用户模板，为空时两者都包含
+	Category string
+	Page     int
+	PageSize int
+}
+
+// 保存为模板参数 - 服务层参数对象，无需json tag
+type SaveMindMapAsTemplateParams struct {
+	Name     string // 为空时使用导图标题
+	Desc     string
+	Category string
+}
+
+// 基于模板创建导图参数 - 服务层参数对象，无需json tag
+type CreateMindMapFromTemplateParams struct {
+	Title  string // 为空时使用模板名称
+	Desc   string
+	Layout string // 为空时使用模板布局
+}
+
+// 复制导图参数 - 服务层参数对象，无需json tag
+type DuplicateMindMapParams struct {
+	Title string // 为空时使用原标题加副本后缀
+}
+
+// 提取子树参数 - 服务层参数对象，无需json tag
+type ExtractMindMapSubtreeParams struct {
+	NodeID           string
+	Title            string // 为空时使用节点文本
+	Layout           string // 为空时使用原导图布局
+	RemoveFromSource bool   // 是否从原导图中移除该分支，需要编辑权限
+	Version          *int64 // 移除分支时客户端持有的原导图版本号，不为空时用于乐观锁校验
+}
+
+// 合并导图参数 - 服务层参数对象，无需json tag
+type MergeMindMapsParams struct {
+	MapIDs    []string // 按合并顺序排列的来源导图
+	Title     string   // 新导图标题，同时作为新根节点文本
+	Desc      string
+	Layout    string // 为空时使用第一张导图的布局
+	Duplicate string // 重复节点处理方式：keep、skip、merge，为空时全部保留
+}
+
+// 创建文件夹参数 - 服务层参数对象，无需json tag
+type CreateMindMapFolderParams struct {
+	Name     string
+	ParentID string // 为空时创建在顶层
+}
+
+// 修改文件夹参数 - 服务层参数对象，无需json tag
+type UpdateMindMapFolderParams struct {
+	Name     *string
+	ParentID *string // 指向空字符串时移动到顶层
+}
+
+// 批量移动导图参数 - 服务层参数对象，无需json tag
+type MoveMindMapsToFolderParams struct {
+	MapIDs   []string
+	FolderID string // 为空或 root 时移回顶层
 }
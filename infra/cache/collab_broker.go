@@ -0,0 +1,178 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"forge/biz/adapter"
+	"forge/pkg/log/zlog"
+
+	"github.com/go-redis/redis/v8"
+)
+
+const (
+	collabChannelKey  = "forge:mindmap:collab:%s"
+	collabPresenceKey = "forge:mindmap:presence:%s"
+	// 订阅 channel 的缓冲大小，分发不及时的消息由 go-redis 内部丢弃
+	collabChannelSize = 256
+)
+
+// NewCollabBroker 创建实时协作消息代理
+// 启用 Redis 时通过 pub/sub 在多实例间广播；未启用时退化为进程内广播，仅支持单实例部署
+func NewCollabBroker() adapter.CollabBroker {
+	if redisClient == nil {
+		zlog.Warnf("redis未启用，实时协作仅支持单实例部署")
+		return newMemoryCollabBroker()
+	}
+	return &redisCollabBroker{client: redisClient}
+}
+
+// ---------- Redis 实现 ----------
+
+type redisCollabBroker struct {
+	client *redis.Client
+}
+
+func (b *redisCollabBroker) Publish(ctx context.Context, mapID string, payload []byte) error {
+	return b.client.Publish(ctx, fmt.Sprintf(collabChannelKey, mapID), payload).Err()
+}
+
+func (b *redisCollabBroker) Subscribe(ctx context.Context, mapID string) (<-chan []byte, error) {
+	pubsub := b.client.Subscribe(ctx, fmt.Sprintf(collabChannelKey, mapID))
+	// 等待订阅确认，保证返回后发布的消息不会丢失
+	if _, err := pubsub.Receive(ctx); err != nil {
+		_ = pubsub.Close()
+		return nil, err
+	}
+
+	ch := make(chan []byte, collabChannelSize)
+	go func() {
+		defer close(ch)
+		defer pubsub.Close()
+		msgs := pubsub.Channel()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case msg, ok := <-msgs:
+				if !ok {
+					return
+				}
+				select {
+				case ch <- []byte(msg.Payload):
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+	return ch, nil
+}
+
+func (b *redisCollabBroker) SavePresence(ctx context.Context, mapID, connID string, payload []byte, ttl time.Duration) error {
+	key := fmt.Sprintf(collabPresenceKey, mapID)
+	pipe := b.client.TxPipeline()
+	pipe.HSet(ctx, key, connID, payload)
+	pipe.Expire(ctx, key, ttl)
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+func (b *redisCollabBroker) DeletePresence(ctx context.Context, mapID, connID string) error {
+	return b.client.HDel(ctx, fmt.Sprintf(collabPresenceKey, mapID), connID).Err()
+}
+
+func (b *redisCollabBroker) ListPresence(ctx context.Context, mapID string) ([][]byte, error) {
+	values, err := b.client.HVals(ctx, fmt.Sprintf(collabPresenceKey, mapID)).Result()
+	if err != nil {
+		return nil, err
+	}
+	result := make([][]byte, 0, len(values))
+	for _, v := range values {
+		result = append(result, []byte(v))
+	}
+	return result, nil
+}
+
+// ---------- 进程内实现 ----------
+
+type memoryCollabBroker struct {
+	mu          sync.Mutex
+	subscribers map[string]map[chan []byte]struct{}
+	presence    map[string]map[string][]byte
+}
+
+func newMemoryCollabBroker() *memoryCollabBroker {
+	return &memoryCollabBroker{
+		subscribers: make(map[string]map[chan []byte]struct{}),
+		presence:    make(map[string]map[string][]byte),
+	}
+}
+
+func (b *memoryCollabBroker) Publish(ctx context.Context, mapID string, payload []byte) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for ch := range b.subscribers[mapID] {
+		select {
+		case ch <- payload:
+		default:
+			zlog.CtxWarnf(ctx, "collab subscriber is full, message dropped, mapID: %s", mapID)
+		}
+	}
+	return nil
+}
+
+func (b *memoryCollabBroker) Subscribe(ctx context.Context, mapID string) (<-chan []byte, error) {
+	ch := make(chan []byte, collabChannelSize)
+	b.mu.Lock()
+	if b.subscribers[mapID] == nil {
+		b.subscribers[mapID] = make(map[chan []byte]struct{})
+	}
+	b.subscribers[mapID][ch] = struct{}{}
+	b.mu.Unlock()
+
+	go func() {
+		<-ctx.Done()
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		delete(b.subscribers[mapID], ch)
+		if len(b.subscribers[mapID]) == 0 {
+			delete(b.subscribers, mapID)
+		}
+		close(ch)
+	}()
+	return ch, nil
+}
+
+// SavePresence 进程内实现不做过期清理，由业务层按更新时间过滤
+func (b *memoryCollabBroker) SavePresence(ctx context.Context, mapID, connID string, payload []byte, ttl time.Duration) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.presence[mapID] == nil {
+		b.presence[mapID] = make(map[string][]byte)
+	}
+	b.presence[mapID][connID] = payload
+	return nil
+}
+
+func (b *memoryCollabBroker) DeletePresence(ctx context.Context, mapID, connID string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	delete(b.presence[mapID], connID)
+	if len(b.presence[mapID]) == 0 {
+		delete(b.presence, mapID)
+	}
+	return nil
+}
+
+func (b *memoryCollabBroker) ListPresence(ctx context.Context, mapID string) ([][]byte, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	result := make([][]byte, 0, len(b.presence[mapID]))
+	for _, payload := range b.presence[mapID] {
+		result = append(result, payload)
+	}
+	return result, nil
+}
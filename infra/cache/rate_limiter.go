@@ -0,0 +1,136 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"forge/biz/adapter"
+	"forge/pkg/log/zlog"
+
+	"github.com/go-redis/redis/v8"
+)
+
+const (
+	rateLimitKey = "forge:ratelimit:%s"
+	// 进程内计数器超过该数量时清理已过期的条目
+	rateLimitSweepSize = 10000
+)
+
+// NewRateLimiter 创建限流计数器
+// 启用 Redis 时多实例共享计数；未启用时退化为进程内计数，多实例部署时限额按实例分别计算
+func NewRateLimiter() adapter.RateLimiter {
+	if redisClient == nil {
+		zlog.Warnf("redis未启用，限流计数仅在实例内生效")
+		return newMemoryRateLimiter()
+	}
+	return &redisRateLimiter{client: redisClient}
+}
+
+// ---------- Redis 实现 ----------
+
+type redisRateLimiter struct {
+	client *redis.Client
+}
+
+// rateLimitScript 计数加一，首次计数时设置过期时间，脚本原子执行，避免进程中断导致计数永不过期
+var rateLimitScript = redis.NewScript(`
+local count = redis.call("INCR", KEYS[1])
+if count == 1 then
+	redis.call("PEXPIRE", KEYS[1], ARGV[1])
+end
+return count
+`)
+
+func (l *redisRateLimiter) Allow(ctx context.Context, key string, limit int64, window time.Duration) (bool, error) {
+	count, err := rateLimitScript.Run(ctx, l.client, []string{fmt.Sprintf(rateLimitKey, key)}, window.Milliseconds()).Int64()
+	if err != nil {
+		return false, err
+	}
+	return count <= limit, nil
+}
+
+// rateReleaseScript 计数大于 0 时减一，保留原有的过期时间
+var rateReleaseScript = redis.NewScript(`
+local count = tonumber(redis.call("GET", KEYS[1]) or "0")
+if count > 0 then
+	redis.call("DECR", KEYS[1])
+end
+return 0
+`)
+
+func (l *redisRateLimiter) Release(ctx context.Context, key string) error {
+	return rateReleaseScript.Run(ctx, l.client, []string{fmt.Sprintf(rateLimitKey, key)}).Err()
+}
+
+func (l *redisRateLimiter) Count(ctx context.Context, key string) (int64, error) {
+	count, err := l.client.Get(ctx, fmt.Sprintf(rateLimitKey, key)).Int64()
+	if err == redis.Nil {
+		return 0, nil
+	}
+	return count, err
+}
+
+// ---------- 进程内实现 ----------
+
+type rateLimitCounter struct {
+	count     int64
+	expiresAt time.Time
+}
+
+type memoryRateLimiter struct {
+	mu       sync.Mutex
+	counters map[string]*rateLimitCounter
+}
+
+func newMemoryRateLimiter() *memoryRateLimiter {
+	return &memoryRateLimiter{counters: make(map[string]*rateLimitCounter)}
+}
+
+func (l *memoryRateLimiter) Allow(ctx context.Context, key string, limit int64, window time.Duration) (bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	counter, ok := l.counters[key]
+	if !ok || !now.Before(counter.expiresAt) {
+		if len(l.counters) >= rateLimitSweepSize {
+			l.sweep(now)
+		}
+		counter = &rateLimitCounter{expiresAt: now.Add(window)}
+		l.counters[key] = counter
+	}
+	counter.count++
+	return counter.count <= limit, nil
+}
+
+func (l *memoryRateLimiter) Count(ctx context.Context, key string) (int64, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	counter, ok := l.counters[key]
+	if !ok || !time.Now().Before(counter.expiresAt) {
+		return 0, nil
+	}
+	return counter.count, nil
+}
+
+func (l *memoryRateLimiter) Release(ctx context.Context, key string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if counter, ok := l.counters[key]; ok && time.Now().Before(counter.expiresAt) && counter.count > 0 {
+		counter.count--
+	}
+	return nil
+}
+
+// sweep 清理已过期的计数器，调用方需持有锁
+func (l *memoryRateLimiter) sweep(now time.Time) {
+	for key, counter := range l.counters {
+		if !now.Before(counter.expiresAt) {
+			delete(l.counters, key)
+		}
+	}
+}
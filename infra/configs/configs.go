@@ -22,6 +22,8 @@ type IConfig interface {
 	GetSMSConfig() SMSConfig
 	GetUniOfficeConfig() UniOfficeConfig
 	GetOAuthConfig() OAuthConfig // OAuth 第三方登录配置
+	GetMindMapConfig() MindMapConfig
+	GetRenderConfig() RenderConfig
 }
 
 var (
@@ -81,6 +83,12 @@ func (c *config) GetSMSConfig() SMSConfig { return c.SMSConfig }
 // oauth配置读取
 func (c *config) GetOAuthConfig() OAuthConfig { return c.OAuthConfig }
 
+// 思维导图配置读取
+func (c *config) GetMindMapConfig() MindMapConfig { return c.MindMapConfig }
+
+// 导图渲染配置读取
+func (c *config) GetRenderConfig() RenderConfig { return c.RenderConfig }
+
 func mustInit(path string) *config {
 	// 初始化时间为东八区的时间
 	var cstZone = time.FixedZone("CST", 8*3600) // 东八
@@ -135,6 +143,8 @@ type config struct {
 	SMSConfig       SMSConfig         `mapstructure:"sms"`
 	UniOfficeConfig UniOfficeConfig   `mapstructure:"unioffice"`
 	OAuthConfig     OAuthConfig       `mapstructure:"oauth"`
+	MindMapConfig   MindMapConfig     `mapstructure:"mindmap"`
+	RenderConfig    RenderConfig      `mapstructure:"render"`
 }
 
 type ApplicationConfig struct {
@@ -201,13 +211,14 @@ type AiChatConfig struct {
 	ApiKey               string `mapstructure:"api_key"`
 	ModelName            string `mapstructure:"model_name"`
 	SystemPrompt         string `mapstructure:"system_prompt"`
-	UpdateSystemPrompt   string `mapstructure:"update_system_prompt"`
 	GenerateSystemPrompt string `mapstructure:"generate_system_prompt"`
 }
 
 type SMSConfig struct {
-	Key      string `mapstructure:"key"`
-	Endpoint string `mapstructure:"endpoint"`
+	Key             string `mapstructure:"key"`
+	Endpoint        string `mapstructure:"endpoint"`
+	InviteEndpoint  string `mapstructure:"invite_endpoint"`  // 协作邀请短信接口URL模板，不配置时不发送邀请短信
+	MentionEndpoint string `mapstructure:"mention_endpoint"` // 评论@提醒短信接口URL模板，不配置时不发送提醒短信
 }
 
 type UniOfficeConfig struct {
@@ -223,3 +234,27 @@ type OAuthConfig struct {
 	WechatCallbackURL  string `mapstructure:"wechat_callback_url"`
 	SessionSecret      string `mapstructure:"session_secret"`
 }
+
+// MindMapConfig 思维导图配置，未配置的项使用默认值
+type MindMapConfig struct {
+	MaxDepth       int      `mapstructure:"max_depth"`        // 最大层级深度
+	MaxNodes       int      `mapstructure:"max_nodes"`        // 最大节点数量
+	MaxTextLength  int      `mapstructure:"max_text_length"`  // 节点文本最大字符数
+	AllowEmptyText bool     `mapstructure:"allow_empty_text"` // 是否允许空文本节点
+	AllowedLayouts []string `mapstructure:"allowed_layouts"`  // 允许的布局类型
+
+	TrashRetentionDays int           `mapstructure:"trash_retention_days"` // 回收站保留天数，超期后彻底删除
+	TrashPurgeInterval time.Duration `mapstructure:"trash_purge_interval"` // 回收站清理任务执行间隔，如 1h
+
+	AttachmentQuota int64 `mapstructure:"attachment_quota"` // 每个用户的节点附件存储配额（字节）
+
+	SyncRetentionDays int `mapstructure:"sync_retention_days"` // 增量同步变更记录保留天数，超期的同步游标失效
+}
+
+// RenderConfig 导图渲染（SVG/PNG 导出、缩略图）配置
+type RenderConfig struct {
+	FontPath string  `mapstructure:"font_path"` // TTF/OTF/TTC 字体文件路径，需包含中文字形
+	FontSize float64 `mapstructure:"font_size"` // 节点字号，默认 14
+
+	PDFFontPath string `mapstructure:"pdf_font_path"` // 导出 PDF 时嵌入的 TTF 字体文件路径（不支持 TTC），需包含中文字形
+}
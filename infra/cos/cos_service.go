@@ -9,6 +9,7 @@ import (
 	"forge/pkg/log/zlog"
 	"net/http"
 	"net/url"
+	"strings"
 
 	"github.com/tencentyun/cos-go-sdk-v5"
 	sts "github.com/tencentyun/qcloud-cos-sts-sdk/go"
@@ -148,3 +149,89 @@ func (c *cosServiceImpl) UploadFile(ctx context.Context, resourcePath string, fi
 	zlog.CtxInfof(ctx, "file uploaded successfully to COS, path: %s", resourcePath)
 	return fullURL, nil
 }
+
+// CopyFile 在COS内复制文件
+func (c *cosServiceImpl) CopyFile(ctx context.Context, srcPath, dstPath string) (string, error) {
+	// 复制源格式：{bucket}-{app_id}.cos.{region}.myqcloud.com/{key}
+	sourceURL := fmt.Sprintf("%s/%s", c.cosClient.BaseURL.BucketURL.Host, srcPath)
+	if _, _, err := c.cosClient.Object.Copy(ctx, dstPath, sourceURL, nil); err != nil {
+		zlog.CtxErrorf(ctx, "failed to copy file in COS, src: %s, dst: %s, error: %v", srcPath, dstPath, err)
+		return "", fmt.Errorf("failed to copy file in COS: %w", err)
+	}
+
+	fullURL, err := url.JoinPath(c.config.BaseURL, dstPath)
+	if err != nil {
+		zlog.CtxErrorf(ctx, "failed to construct file URL: %v", err)
+		return "", fmt.Errorf("failed to construct file URL: %w", err)
+	}
+
+	zlog.CtxInfof(ctx, "file copied successfully in COS, src: %s, dst: %s", srcPath, dstPath)
+	return fullURL, nil
+}
+
+// DeleteFile 删除COS中的文件
+func (c *cosServiceImpl) DeleteFile(ctx context.Context, resourcePath string) error {
+	// COS 删除不存在的对象同样返回成功
+	if _, err := c.cosClient.Object.Delete(ctx, resourcePath); err != nil {
+		zlog.CtxErrorf(ctx, "failed to delete file from COS, path: %s, error: %v", resourcePath, err)
+		return fmt.Errorf("failed to delete file from COS: %w", err)
+	}
+
+	zlog.CtxInfof(ctx, "file deleted successfully from COS, path: %s", resourcePath)
+	return nil
+}
+
+// deleteBatchSize COS 批量删除单次最多 1000 个对象
+const deleteBatchSize = 1000
+
+// DeleteDirectory 分批列出并删除COS中以 prefix 为前缀的所有文件
+func (c *cosServiceImpl) DeleteDirectory(ctx context.Context, prefix string) error {
+	// 防止误删整个存储桶或其他目录
+	if prefix == "" || prefix == "/" || !strings.HasSuffix(prefix, "/") {
+		return fmt.Errorf("invalid COS directory prefix: %q", prefix)
+	}
+
+	deleted := 0
+	marker := ""
+	for {
+		result, _, err := c.cosClient.Bucket.Get(ctx, &cos.BucketGetOptions{
+			Prefix:  prefix,
+			Marker:  marker,
+			MaxKeys: deleteBatchSize,
+		})
+		if err != nil {
+			zlog.CtxErrorf(ctx, "failed to list files from COS, prefix: %s, error: %v", prefix, err)
+			return fmt.Errorf("failed to list files from COS: %w", err)
+		}
+
+		if len(result.Contents) > 0 {
+			objects := make([]cos.Object, 0, len(result.Contents))
+			for _, object := range result.Contents {
+				objects = append(objects, cos.Object{Key: object.Key})
+			}
+			// Quiet 模式下仅返回删除失败的对象
+			deleteResult, _, err := c.cosClient.Object.DeleteMulti(ctx, &cos.ObjectDeleteMultiOptions{
+				Quiet:   true,
+				Objects: objects,
+			})
+			if err != nil {
+				zlog.CtxErrorf(ctx, "failed to delete files from COS, prefix: %s, error: %v", prefix, err)
+				return fmt.Errorf("failed to delete files from COS: %w", err)
+			}
+			if len(deleteResult.Errors) > 0 {
+				first := deleteResult.Errors[0]
+				zlog.CtxErrorf(ctx, "failed to delete %d files from COS, prefix: %s, first key: %s, error: %s", len(deleteResult.Errors), prefix, first.Key, first.Message)
+				return fmt.Errorf("failed to delete %d files from COS: %s", len(deleteResult.Errors), first.Message)
+			}
+			deleted += len(objects)
+		}
+
+		if !result.IsTruncated {
+			break
+		}
+		marker = result.NextMarker
+	}
+
+	zlog.CtxInfof(ctx, "directory deleted successfully from COS, prefix: %s, count: %d", prefix, deleted)
+	return nil
+}
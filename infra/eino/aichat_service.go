@@ -9,11 +9,15 @@ import (
 	"forge/biz/types"
 	"forge/infra/configs"
 	"forge/pkg/log/zlog"
+	"io"
 
 	"github.com/cloudwego/eino-ext/components/model/ark"
+	"github.com/cloudwego/eino/callbacks"
+	einomodel "github.com/cloudwego/eino/components/model"
 	"github.com/cloudwego/eino/components/tool"
 	"github.com/cloudwego/eino/compose"
 	"github.com/cloudwego/eino/schema"
+	ucb "github.com/cloudwego/eino/utils/callbacks"
 	"github.com/volcengine/volcengine-go-sdk/service/arkruntime/model"
 )
 
@@ -24,15 +28,16 @@ type AiChatClient struct {
 	ToolAiClient *ark.ChatModel
 }
 
+// maxToolRounds 单轮对话中模型调用工具的最大轮数
+const maxToolRounds = 10
+
 type State struct {
-	Content   string
-	ToolCalls []schema.ToolCall
+	History  []*schema.Message // 传给模型的完整上下文
+	Messages []*schema.Message // 本轮对话新产生的模型与工具消息
 }
 
 func initState(ctx context.Context) *State {
-	return &State{
-		Content: "",
-	}
+	return &State{}
 }
 
 func NewAiChatClient(apiKey, modelName string) repo.EinoServer {
@@ -67,15 +72,18 @@ func NewAiChatClient(apiKey, modelName string) repo.EinoServer {
 		zlog.Errorf("ai模型连接失败: %v", err)
 		panic(fmt.Errorf("ai模型连接失败: %v", err))
 	}
-	updateMindMapTool := aiChatClient.CreateUpdateMindMapTool()
-	infoTool, err := updateMindMapTool.Info(ctx)
-	if err != nil {
-		zlog.Errorf("ai绑定工具失败: %v", err)
-		panic(fmt.Errorf("ai绑定工具失败: %v", err))
-	}
 
-	infosTool := []*schema.ToolInfo{
-		infoTool,
+	mindMapTools := aiChatClient.CreateMindMapTools()
+	infosTool := make([]*schema.ToolInfo, 0, len(mindMapTools))
+	baseTools := make([]tool.BaseTool, 0, len(mindMapTools))
+	for _, t := range mindMapTools {
+		infoTool, err := t.Info(ctx)
+		if err != nil {
+			zlog.Errorf("ai绑定工具失败: %v", err)
+			panic(fmt.Errorf("ai绑定工具失败: %v", err))
+		}
+		infosTool = append(infosTool, infoTool)
+		baseTools = append(baseTools, t)
 	}
 	err = aiChatModel.BindTools(infosTool)
 	if err != nil {
@@ -83,10 +91,10 @@ func NewAiChatClient(apiKey, modelName string) repo.EinoServer {
 		panic(fmt.Errorf("ai绑定工具失败: %v", err))
 	}
 
+	//工具按模型给出的顺序依次修改导图
 	ToolsNode, err := compose.NewToolNode(ctx, &compose.ToolsNodeConfig{
-		Tools: []tool.BaseTool{
-			updateMindMapTool,
-		},
+		Tools:               baseTools,
+		ExecuteSequentially: true,
 	})
 
 	if err != nil {
@@ -94,61 +102,52 @@ func NewAiChatClient(apiKey, modelName string) repo.EinoServer {
 		panic("创建工具节点失败," + err.Error())
 	}
 
-	//分支中的lambda 用于对其前后输入输出
-	lambda1 := compose.InvokableLambda(func(ctx context.Context, input *schema.Message) (output []*schema.Message, err error) {
-		output = make([]*schema.Message, 0)
-		output = append(output, input)
-		return output, nil
-	})
-
-	//分支结束统一进入的lambda 用于处理输出的数据
-	lambda2 := compose.InvokableLambda(func(ctx context.Context, input []*schema.Message) (output types.AgentResponse, err error) {
-		//fmt.Println("lambda测试：", input)
-
-		if len(input) == 0 {
+	//模型不再调用工具时 整理本轮输出
+	output := compose.InvokableLambda(func(ctx context.Context, input *schema.Message) (output types.AgentResponse, err error) {
+		if input == nil {
 			return types.AgentResponse{}, errors.New("agent出错")
 		}
 
-		output = types.AgentResponse{}
-
-		if input[len(input)-1].Role == schema.Tool {
-			output.NewMapJson = input[len(input)-1].Content
-			output.ToolCallID = input[len(input)-1].ToolCallID
-
-		}
+		output = types.AgentResponse{Content: input.Content}
 		_ = compose.ProcessState[*State](ctx, func(ctx context.Context, state *State) error {
-			output.Content = state.Content
-			output.ToolCalls = state.ToolCalls
+			output.Messages = state.Messages
 			return nil
 		})
 		return output, nil
 	})
 
+	//模型的输入为累积的完整上下文 首次为会话消息 之后追加工具结果
+	chatModelPreHandler := func(ctx context.Context, input []*schema.Message, state *State) ([]*schema.Message, error) {
+		state.History = append(state.History, input...)
+		return state.History, nil
+	}
+
 	//chatModel执行完之后把 输出存一下
 	chatModelPostHandler := func(ctx context.Context, input *schema.Message, state *State) (output *schema.Message, err error) {
-		//fmt.Println("工具使用测试:", input)
-		state.ToolCalls = input.ToolCalls
-		state.Content = input.Content
+		state.History = append(state.History, input)
+		state.Messages = append(state.Messages, input)
+		return input, nil
+	}
+
+	toolsPostHandler := func(ctx context.Context, input []*schema.Message, state *State) ([]*schema.Message, error) {
+		state.Messages = append(state.Messages, input...)
 		return input, nil
 	}
 
 	g := compose.NewGraph[[]*schema.Message, types.AgentResponse](compose.WithGenLocalState(initState))
 
-	err = g.AddChatModelNode("model", aiChatModel, compose.WithStatePostHandler(chatModelPostHandler))
+	err = g.AddChatModelNode("model", aiChatModel,
+		compose.WithStatePreHandler(chatModelPreHandler), compose.WithStatePostHandler(chatModelPostHandler))
 	if err != nil {
 		panic("添加节点失败," + err.Error())
 	}
 
-	err = g.AddToolsNode("tools", ToolsNode)
+	err = g.AddToolsNode("tools", ToolsNode, compose.WithStatePostHandler(toolsPostHandler))
 	if err != nil {
 		panic("添加节点失败," + err.Error())
 	}
 
-	err = g.AddLambdaNode("lambda1", lambda1)
-	if err != nil {
-		panic("添加节点失败" + err.Error())
-	}
-	err = g.AddLambdaNode("lambda2", lambda2)
+	err = g.AddLambdaNode("output", output)
 	if err != nil {
 		panic("添加节点失败" + err.Error())
 	}
@@ -160,36 +159,32 @@ func NewAiChatClient(apiKey, modelName string) repo.EinoServer {
 		panic("添加边失败" + err.Error())
 	}
 
-	//创建边一个分支
+	//模型调用工具时执行工具后回到模型 否则结束
 	err = g.AddBranch("model", compose.NewGraphBranch(func(ctx context.Context, in *schema.Message) (endNode string, err error) {
 		if len(in.ToolCalls) > 0 {
 			return "tools", nil
 		}
-		return "lambda1", nil
+		return "output", nil
 	}, map[string]bool{
-		"tools":   true,
-		"lambda1": true,
+		"tools":  true,
+		"output": true,
 	}))
 	if err != nil {
 		panic("创建分支失败" + err.Error())
 	}
 
-	err = g.AddEdge("tools", "lambda2")
+	err = g.AddEdge("tools", "model")
 	if err != nil {
 		panic("创建边失败" + err.Error())
 	}
 
-	err = g.AddEdge("lambda1", "lambda2")
+	err = g.AddEdge("output", compose.END)
 	if err != nil {
 		panic("创建边失败" + err.Error())
 	}
 
-	err = g.AddEdge("lambda2", compose.END)
-	if err != nil {
-		panic("创建边失败" + err.Error())
-	}
-
-	agent, err := g.Compile(ctx)
+	//每轮工具调用经过 model、tools 两个节点 最后一次模型调用后进入 output
+	agent, err := g.Compile(ctx, compose.WithMaxRunSteps(2*maxToolRounds+2))
 	if err != nil {
 		panic("编译错误" + err.Error())
 	}
@@ -202,12 +197,98 @@ func NewAiChatClient(apiKey, modelName string) repo.EinoServer {
 func (a *AiChatClient) SendMessage(ctx context.Context, messages []*entity.Message) (types.AgentResponse, error) {
 	input := messagesDo2Input(messages)
 
+	ctx, editor, err := withMapEditor(ctx)
+	if err != nil {
+		zlog.Errorf("初始化导图编辑失败%v", err)
+		return types.AgentResponse{}, err
+	}
+
 	resp, err := a.Agent.Invoke(ctx, input)
 
 	if err != nil {
 		zlog.Errorf("模型调用失败%v", err)
 		return types.AgentResponse{}, err
 	}
+	resp.NewMapData = editor.result()
+	return resp, nil
+}
+
+// SendMessageStream 以流式方式运行agent 通过回调推送模型输出的文本增量与工具调用
+// agent 的最终输出只有一个分块 读取后与 SendMessage 的返回一致
+func (a *AiChatClient) SendMessageStream(ctx context.Context, messages []*entity.Message, onEvent func(types.AgentEvent)) (types.AgentResponse, error) {
+	input := messagesDo2Input(messages)
+
+	handler := ucb.NewHandlerHelper().
+		ChatModel(&ucb.ModelCallbackHandler{
+			// 同步读取模型输出的副本 保证文本增量先于工具调用事件推送
+			OnEndWithStreamOutput: func(ctx context.Context, info *callbacks.RunInfo, output *schema.StreamReader[*einomodel.CallbackOutput]) context.Context {
+				defer output.Close()
+				for {
+					chunk, err := output.Recv()
+					if err != nil {
+						// 模型调用出错时由 agent 的输出流返回错误
+						return ctx
+					}
+					if chunk.Message != nil && chunk.Message.Content != "" {
+						onEvent(types.AgentEvent{Type: types.AgentEventDelta, Content: chunk.Message.Content})
+					}
+				}
+			},
+		}).
+		Tool(&ucb.ToolCallbackHandler{
+			OnStart: func(ctx context.Context, info *callbacks.RunInfo, input *tool.CallbackInput) context.Context {
+				onEvent(types.AgentEvent{
+					Type:       types.AgentEventToolCall,
+					ToolCallID: compose.GetToolCallID(ctx),
+					ToolName:   info.Name,
+					Arguments:  input.ArgumentsInJSON,
+				})
+				return ctx
+			},
+			// 推送本次工具调用后的导图 由服务层序列化为完整导图JSON
+			OnEnd: func(ctx context.Context, info *callbacks.RunInfo, output *tool.CallbackOutput) context.Context {
+				var mapData *entity.MindMapData
+				if editor, err := getMapEditor(ctx); err == nil {
+					mapData = editor.snapshot()
+				}
+				onEvent(types.AgentEvent{
+					Type:       types.AgentEventToolResult,
+					ToolCallID: compose.GetToolCallID(ctx),
+					ToolName:   info.Name,
+					Content:    output.Response,
+					MapData:    mapData,
+				})
+				return ctx
+			},
+		}).
+		Handler()
+
+	ctx, editor, err := withMapEditor(ctx)
+	if err != nil {
+		zlog.Errorf("初始化导图编辑失败%v", err)
+		return types.AgentResponse{}, err
+	}
+
+	stream, err := a.Agent.Stream(ctx, input, compose.WithCallbacks(handler))
+	if err != nil {
+		zlog.Errorf("模型流式调用失败%v", err)
+		return types.AgentResponse{}, err
+	}
+	defer stream.Close()
+
+	var resp types.AgentResponse
+	for {
+		chunk, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			zlog.Errorf("模型流式调用失败%v", err)
+			return types.AgentResponse{}, err
+		}
+		resp = chunk
+	}
+	resp.NewMapData = editor.result()
 	return resp, nil
 }
 
@@ -281,7 +362,7 @@ func (a *AiChatClient) generateForSFTTraining(ctx context.Context, text, userID
 		}
 
 		// 创建对话记录
-		conversation, err := entity.NewConversation(userID, "BATCH_GENERATION", fmt.Sprintf("SFT训练-%d", i+1), "")
+		conversation, err := entity.NewConversation(userID, "BATCH_GENERATION", fmt.Sprintf("SFT训练-%d", i+1))
 		if err != nil {
 			zlog.CtxWarnf(ctx, "创建对话失败 index:%d, err:%v", i, err)
 			continue
@@ -392,7 +473,7 @@ func (a *AiChatClient) generateForDPOTraining(ctx context.Context, text, userID
 		}
 
 		// 创建对话记录
-		conversation, err := entity.NewConversation(userID, "BATCH_GENERATION", fmt.Sprintf("DPO训练-%s-%d", qualityPrompt.level, i+1), "")
+		conversation, err := entity.NewConversation(userID, "BATCH_GENERATION", fmt.Sprintf("DPO训练-%s-%d", qualityPrompt.level, i+1))
 		if err != nil {
 			zlog.CtxWarnf(ctx, "创建对话失败 index:%d, err:%v", i, err)
 			continue
@@ -2,42 +2,246 @@ package eino
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"forge/biz/entity"
+	"forge/util"
+	"strings"
+	"sync"
+
 	"github.com/cloudwego/eino/components/tool"
 	"github.com/cloudwego/eino/components/tool/utils"
 	"github.com/cloudwego/eino/schema"
 )
 
-func (a *AiChatClient) UpdateMindMap(ctx context.Context, params *UpdateMindMapParams) (string, error) {
+// mapEditor 单次对话中各工具共享的导图副本 工具按调用顺序直接修改节点树
+type mapEditor struct {
+	mu       sync.Mutex
+	root     entity.MindMapData
+	modified bool
+}
+
+type mapEditorCtxKey struct{}
+
+// withMapEditor 解析会话中的导图作为本次对话的编辑副本并写入ctx
+func withMapEditor(ctx context.Context) (context.Context, *mapEditor, error) {
 	conversation, ok := entity.GetConversation(ctx)
 	if !ok {
-		return "", fmt.Errorf("未能从上下文中获取到导图数据")
+		return ctx, nil, fmt.Errorf("未能从上下文中获取到导图数据")
+	}
+	var mindMap struct {
+		Root entity.MindMapData `json:"root"`
 	}
-	//fmt.Println(conversation.MapData)
-	message := initToolUpdateMindMap(conversation.MapData, params.Requirement)
+	if err := json.Unmarshal([]byte(conversation.MapData), &mindMap); err != nil {
+		return ctx, nil, fmt.Errorf("解析导图数据失败: %w", err)
+	}
+	editor := &mapEditor{root: mindMap.Root}
+	return context.WithValue(ctx, mapEditorCtxKey{}, editor), editor, nil
+}
+
+func getMapEditor(ctx context.Context) (*mapEditor, error) {
+	editor, ok := ctx.Value(mapEditorCtxKey{}).(*mapEditor)
+	if !ok {
+		return nil, fmt.Errorf("未能从上下文中获取到导图数据")
+	}
+	return editor, nil
+}
+
+// apply 在副本上执行单个节点操作 失败时不影响当前导图
+func (e *mapEditor) apply(op entity.MindMapPatchOp) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	root := e.root.Clone()
+	if err := root.ApplyPatch([]entity.MindMapPatchOp{op}); err != nil {
+		return err
+	}
+	// 与保存导图时相同的文本长度、节点数量、层级深度校验 不通过时由模型修正
+	if err := root.ValidateTree(entity.TreeValidationRulesFromConfig()); err != nil {
+		return err
+	}
+	e.root = root
+	e.modified = true
+	return nil
+}
+
+// result 返回修改后的导图 未修改时为空
+func (e *mapEditor) result() *entity.MindMapData {
+	e.mu.Lock()
+	defer e.mu.Unlock()
 
-	resp, err := a.ToolAiClient.Generate(ctx, message)
+	if !e.modified {
+		return nil
+	}
+	root := e.root.Clone()
+	return &root
+}
+
+// snapshot 返回当前导图副本 含本轮已做的修改
+func (e *mapEditor) snapshot() *entity.MindMapData {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	root := e.root.Clone()
+	return &root
+}
+
+// editMindMap 对本次对话的导图副本执行节点操作
+func editMindMap(ctx context.Context, op entity.MindMapPatchOp) error {
+	editor, err := getMapEditor(ctx)
+	if err != nil {
+		return err
+	}
+	return editor.apply(op)
+}
+
+// toolFailure 节点不存在、位置越界、超出导图校验规则等可由模型修正的错误作为工具结果返回 其余错误中断对话
+func toolFailure(err error) (string, error) {
+	var validationErr *entity.TreeValidationError
+	if errors.Is(err, entity.ErrInvalidPatchOp) || errors.Is(err, entity.ErrNodeNotFound) || errors.As(err, &validationErr) {
+		return "操作失败：" + err.Error(), nil
+	}
+	return "", err
+}
+
+func (a *AiChatClient) AddNode(ctx context.Context, params *AddNodeParams) (string, error) {
+	text := strings.TrimSpace(params.Text)
+	if params.ParentID == "" || text == "" {
+		return "操作失败：parent_id 与 text 不能为空", nil
+	}
+	uid, err := util.GenerateStringID()
 	if err != nil {
 		return "", err
 	}
-	return resp.Content, nil
-}
-
-func (a *AiChatClient) CreateUpdateMindMapTool() tool.InvokableTool {
-	updateMindMapTool := utils.NewTool(
-		&schema.ToolInfo{
-			Name: "update_mind_map",
-			Desc: "用于修改导图,需要修改导图时调用该工具,返回完整新导图JSON",
-			ParamsOneOf: schema.NewParamsOneOfByParams(
-				map[string]*schema.ParameterInfo{
-					"requirement": {
-						Type:     schema.String,
-						Desc:     "需要工具修改导图的需求，例如「把 root.children[0].data.text 改成『新产品』」",
-						Required: true,
-					},
-				},
-			),
-		}, a.UpdateMindMap)
-	return updateMindMapTool
+	err = editMindMap(ctx, entity.MindMapPatchOp{
+		Op:     entity.PatchOpAddChild,
+		NodeID: params.ParentID,
+		Index:  params.Index,
+		Node:   &entity.MindMapData{Data: entity.NodeData{UID: uid, Text: text, Note: params.Note}},
+	})
+	if err != nil {
+		return toolFailure(err)
+	}
+	return fmt.Sprintf("已在节点 %s 下添加节点「%s」，uid:%s", params.ParentID, text, uid), nil
+}
+
+func (a *AiChatClient) RenameNode(ctx context.Context, params *RenameNodeParams) (string, error) {
+	text := strings.TrimSpace(params.Text)
+	if params.NodeID == "" || text == "" {
+		return "操作失败：node_id 与 text 不能为空", nil
+	}
+	err := editMindMap(ctx, entity.MindMapPatchOp{Op: entity.PatchOpRename, NodeID: params.NodeID, Text: &text})
+	if err != nil {
+		return toolFailure(err)
+	}
+	return fmt.Sprintf("已将节点 %s 改名为「%s」", params.NodeID, text), nil
+}
+
+func (a *AiChatClient) DeleteNode(ctx context.Context, params *DeleteNodeParams) (string, error) {
+	if params.NodeID == "" {
+		return "操作失败：node_id 不能为空", nil
+	}
+	err := editMindMap(ctx, entity.MindMapPatchOp{Op: entity.PatchOpDelete, NodeID: params.NodeID})
+	if err != nil {
+		return toolFailure(err)
+	}
+	return fmt.Sprintf("已删除节点 %s 及其子节点", params.NodeID), nil
+}
+
+func (a *AiChatClient) MoveNode(ctx context.Context, params *MoveNodeParams) (string, error) {
+	if params.NodeID == "" || params.ParentID == "" {
+		return "操作失败：node_id 与 parent_id 不能为空", nil
+	}
+	err := editMindMap(ctx, entity.MindMapPatchOp{
+		Op:       entity.PatchOpMove,
+		NodeID:   params.NodeID,
+		ParentID: params.ParentID,
+		Index:    params.Index,
+	})
+	if err != nil {
+		return toolFailure(err)
+	}
+	return fmt.Sprintf("已将节点 %s 移动到节点 %s 下", params.NodeID, params.ParentID), nil
+}
+
+// GetSubtree 以缩进大纲返回当前导图（含本轮已做的修改）中的子树 未指定节点时返回整棵树
+func (a *AiChatClient) GetSubtree(ctx context.Context, params *GetSubtreeParams) (string, error) {
+	editor, err := getMapEditor(ctx)
+	if err != nil {
+		return "", err
+	}
+	editor.mu.Lock()
+	subtree := editor.root
+	if params.NodeID != "" {
+		subtree, err = editor.root.Subtree(params.NodeID)
+	}
+	editor.mu.Unlock()
+	if err != nil {
+		return toolFailure(err)
+	}
+
+	var sb strings.Builder
+	writeOutline(&sb, &subtree, 0)
+	return sb.String(), nil
+}
+
+// writeOutline 每行一个节点 格式为「- 文本 [uid:标识]」 子节点多缩进两格
+func writeOutline(sb *strings.Builder, node *entity.MindMapData, depth int) {
+	fmt.Fprintf(sb, "%s- %s [uid:%s]\n", strings.Repeat("  ", depth), node.Data.Text, node.Data.UID)
+	for i := range node.Children {
+		writeOutline(sb, &node.Children[i], depth+1)
+	}
+}
+
+// CreateMindMapTools 创建导图编辑工具 各工具直接修改本次对话的导图副本
+func (a *AiChatClient) CreateMindMapTools() []tool.InvokableTool {
+	nodeID := func(desc string) *schema.ParameterInfo {
+		return &schema.ParameterInfo{Type: schema.String, Desc: desc, Required: true}
+	}
+	index := &schema.ParameterInfo{Type: schema.Integer, Desc: "插入到新父节点子节点中的位置（从0开始），不填则追加到末尾"}
+
+	return []tool.InvokableTool{
+		utils.NewTool(&schema.ToolInfo{
+			Name: "add_node",
+			Desc: "在指定父节点下新增一个子节点，返回新节点的 uid，可继续在新节点下添加子节点",
+			ParamsOneOf: schema.NewParamsOneOfByParams(map[string]*schema.ParameterInfo{
+				"parent_id": nodeID("父节点的 uid"),
+				"text":      {Type: schema.String, Desc: "新节点文本，尽量精炼", Required: true},
+				"note":      {Type: schema.String, Desc: "新节点的备注（markdown），可不填"},
+				"index":     index,
+			}),
+		}, a.AddNode),
+		utils.NewTool(&schema.ToolInfo{
+			Name: "rename_node",
+			Desc: "修改指定节点的文本",
+			ParamsOneOf: schema.NewParamsOneOfByParams(map[string]*schema.ParameterInfo{
+				"node_id": nodeID("要改名的节点 uid"),
+				"text":    {Type: schema.String, Desc: "新的节点文本", Required: true},
+			}),
+		}, a.RenameNode),
+		utils.NewTool(&schema.ToolInfo{
+			Name: "delete_node",
+			Desc: "删除指定节点及其全部子节点，不能删除根节点",
+			ParamsOneOf: schema.NewParamsOneOfByParams(map[string]*schema.ParameterInfo{
+				"node_id": nodeID("要删除的节点 uid"),
+			}),
+		}, a.DeleteNode),
+		utils.NewTool(&schema.ToolInfo{
+			Name: "move_node",
+			Desc: "将指定节点连同其子节点移动到新的父节点下，不能移动根节点或移动到自身的子节点下",
+			ParamsOneOf: schema.NewParamsOneOfByParams(map[string]*schema.ParameterInfo{
+				"node_id":   nodeID("要移动的节点 uid"),
+				"parent_id": nodeID("新父节点的 uid"),
+				"index":     index,
+			}),
+		}, a.MoveNode),
+		utils.NewTool(&schema.ToolInfo{
+			Name: "get_subtree",
+			Desc: "查看当前导图（包含本轮已做的修改）中指定节点的子树，返回缩进大纲，每行为「- 文本 [uid:标识]」",
+			ParamsOneOf: schema.NewParamsOneOfByParams(map[string]*schema.ParameterInfo{
+				"node_id": {Type: schema.String, Desc: "子树根节点的 uid，不填则返回整棵导图"},
+			}),
+		}, a.GetSubtree),
+	}
 }
@@ -36,15 +36,28 @@ func initGenerateMindMapMessage(text, userID string) []*schema.Message {
 	return res
 }
 
-func initToolUpdateMindMap(mapData, requirement string) []*schema.Message {
-	res := make([]*schema.Message, 0)
-	res = append(res, &schema.Message{
-		Content: fmt.Sprintf(configs.Config().GetAiChatConfig().UpdateSystemPrompt, mapData, requirement),
-		Role:    schema.System,
-	})
-	return res
+type AddNodeParams struct {
+	ParentID string `json:"parent_id"`
+	Text     string `json:"text"`
+	Note     string `json:"note"`
+	Index    *int   `json:"index"`
+}
+
+type RenameNodeParams struct {
+	NodeID string `json:"node_id"`
+	Text   string `json:"text"`
+}
+
+type DeleteNodeParams struct {
+	NodeID string `json:"node_id"`
+}
+
+type MoveNodeParams struct {
+	NodeID   string `json:"node_id"`
+	ParentID string `json:"parent_id"`
+	Index    *int   `json:"index"`
 }
 
-type UpdateMindMapParams struct {
-	Requirement string `json:"requirement" jsonschema:"description=更改导图的要求"`
+type GetSubtreeParams struct {
+	NodeID string `json:"node_id"`
 }
@@ -23,6 +23,8 @@ type codeServiceImpl struct {
 	smtpConfig               configs.SMTPConfig
 	smsConfig                configs.SMSConfig
 	verificationCodeTemplate *template.Template
+	mindMapInviteTemplate    *template.Template
+	mindMapMentionTemplate   *template.Template
 	httpClient               *http.Client
 }
 
@@ -35,11 +37,23 @@ func InitCodeService(smtpConfig configs.SMTPConfig, smsConfig configs.SMSConfig)
 		zlog.Errorf("解析验证码邮件模板失败: %v", err)
 		panic(fmt.Sprintf("解析验证码邮件模板失败: %v", err))
 	}
+	inviteTmpl, err := template.New("mindmap_invite").Parse(templateEmail.MindMapInviteTemplate)
+	if err != nil {
+		zlog.Errorf("解析协作邀请邮件模板失败: %v", err)
+		panic(fmt.Sprintf("解析协作邀请邮件模板失败: %v", err))
+	}
+	mentionTmpl, err := template.New("mindmap_mention").Parse(templateEmail.MindMapMentionTemplate)
+	if err != nil {
+		zlog.Errorf("解析评论提醒邮件模板失败: %v", err)
+		panic(fmt.Sprintf("解析评论提醒邮件模板失败: %v", err))
+	}
 
 	cs = &codeServiceImpl{
 		smtpConfig:               smtpConfig,
 		smsConfig:                smsConfig,
 		verificationCodeTemplate: tmpl,
+		mindMapInviteTemplate:    inviteTmpl,
+		mindMapMentionTemplate:   mentionTmpl,
 		httpClient: &http.Client{
 			Timeout: 10 * time.Second,
 		},
@@ -53,6 +67,11 @@ func GetCodeService() adapter.CodeService {
 	return cs
 }
 
+// GetNotificationService 获取业务通知服务实例，与验证码服务共用邮件与短信通道
+func GetNotificationService() adapter.NotificationService {
+	return cs
+}
+
 // SendEmailCode 发送邮件验证码
 func (c *codeServiceImpl) SendEmailCode(ctx context.Context, email, code string) error {
 	if c == nil {
@@ -101,7 +120,16 @@ func (c *codeServiceImpl) SendSMSCode(ctx context.Context, phone, code string) e
 		return fmt.Errorf("sms endpoint not configured")
 	}
 
-	smsURL := fmt.Sprintf(endpoint, c.smsConfig.Key, url.QueryEscape(code), url.QueryEscape(phone))
+	if err := c.sendSMS(ctx, endpoint, code, phone); err != nil {
+		return err
+	}
+	zlog.CtxInfof(ctx, "短信验证码发送成功，手机号: %s", phone)
+	return nil
+}
+
+// sendSMS 按接口URL模板（依次填入 key、内容、手机号）请求短信服务
+func (c *codeServiceImpl) sendSMS(ctx context.Context, endpoint, content, phone string) error {
+	smsURL := fmt.Sprintf(endpoint, c.smsConfig.Key, url.QueryEscape(content), url.QueryEscape(phone))
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, smsURL, nil)
 	if err != nil {
@@ -123,6 +151,5 @@ func (c *codeServiceImpl) SendSMSCode(ctx context.Context, phone, code string) e
 	}
 
 	_, _ = io.Copy(io.Discard, resp.Body)
-	zlog.CtxInfof(ctx, "短信验证码发送成功，手机号: %s", phone)
 	return nil
 }
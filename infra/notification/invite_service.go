@@ -0,0 +1,78 @@
+package notification
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	"forge/biz/adapter"
+	"forge/biz/entity"
+	"forge/pkg/log/zlog"
+
+	"gopkg.in/gomail.v2"
+)
+
+// mindMapRoleNames 协作角色的展示名称
+var mindMapRoleNames = map[string]string{
+	entity.MindMapRoleOwner:  "所有者",
+	entity.MindMapRoleEditor: "编辑者",
+	entity.MindMapRoleViewer: "查看者",
+}
+
+// SendMindMapInviteEmail 发送思维导图协作邀请邮件
+func (c *codeServiceImpl) SendMindMapInviteEmail(ctx context.Context, email string, invitation *adapter.MindMapInvitation) error {
+	if c == nil {
+		return fmt.Errorf("notification service not initialized")
+	}
+
+	m := gomail.NewMessage()
+	m.SetHeader("From", m.FormatAddress(c.smtpConfig.SmtpUser, c.smtpConfig.EncodedName))
+	m.SetHeader("To", email)
+	m.SetHeader("Subject", fmt.Sprintf("%s 邀请您协作思维导图", invitation.InviterName))
+
+	data := map[string]string{
+		"InviterName": invitation.InviterName,
+		"MapTitle":    invitation.MapTitle,
+		"RoleName":    mindMapRoleNames[invitation.Role],
+	}
+	var emailBody bytes.Buffer
+	if err := c.mindMapInviteTemplate.Execute(&emailBody, data); err != nil {
+		zlog.CtxErrorf(ctx, "渲染协作邀请邮件模板失败: %v", err)
+		return fmt.Errorf("渲染协作邀请邮件模板失败: %w", err)
+	}
+
+	m.SetBody("text/html", emailBody.String())
+
+	d := gomail.NewDialer(c.smtpConfig.SmtpHost, c.smtpConfig.SmtpPort, c.smtpConfig.SmtpUser, c.smtpConfig.SmtpPass)
+
+	if err := d.DialAndSend(m); err != nil {
+		zlog.CtxErrorf(ctx, "发送协作邀请邮件失败: %v", err)
+		return fmt.Errorf("发送协作邀请邮件失败: %w", err)
+	}
+
+	zlog.CtxInfof(ctx, "协作邀请邮件发送成功，邮箱: %s, mapID: %s", email, invitation.MapID)
+	return nil
+}
+
+// SendMindMapInviteSMS 发送思维导图协作邀请短信
+func (c *codeServiceImpl) SendMindMapInviteSMS(ctx context.Context, phone string, invitation *adapter.MindMapInvitation) error {
+	if c == nil {
+		return fmt.Errorf("notification service not initialized")
+	}
+
+	if c.smsConfig.Key == "" {
+		return fmt.Errorf("sms key not configured")
+	}
+
+	endpoint := c.smsConfig.InviteEndpoint
+	if endpoint == "" {
+		return fmt.Errorf("sms invite endpoint not configured")
+	}
+
+	content := fmt.Sprintf("%s 邀请您以%s身份协作思维导图「%s」", invitation.InviterName, mindMapRoleNames[invitation.Role], invitation.MapTitle)
+	if err := c.sendSMS(ctx, endpoint, content, phone); err != nil {
+		return err
+	}
+	zlog.CtxInfof(ctx, "协作邀请短信发送成功，手机号: %s, mapID: %s", phone, invitation.MapID)
+	return nil
+}
@@ -0,0 +1,79 @@
+package notification
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"unicode/utf8"
+
+	"forge/biz/adapter"
+	"forge/pkg/log/zlog"
+
+	"gopkg.in/gomail.v2"
+)
+
+// 短信中评论内容的最大长度，超出部分截断
+const mentionSMSContentLength = 50
+
+// SendMindMapMentionEmail 发送评论@提醒邮件
+func (c *codeServiceImpl) SendMindMapMentionEmail(ctx context.Context, email string, mention *adapter.MindMapMention) error {
+	if c == nil {
+		return fmt.Errorf("notification service not initialized")
+	}
+
+	m := gomail.NewMessage()
+	m.SetHeader("From", m.FormatAddress(c.smtpConfig.SmtpUser, c.smtpConfig.EncodedName))
+	m.SetHeader("To", email)
+	m.SetHeader("Subject", fmt.Sprintf("%s 在思维导图评论中提到了您", mention.AuthorName))
+
+	data := map[string]string{
+		"AuthorName": mention.AuthorName,
+		"MapTitle":   mention.MapTitle,
+		"NodeText":   mention.NodeText,
+		"Content":    mention.Content,
+	}
+	var emailBody bytes.Buffer
+	if err := c.mindMapMentionTemplate.Execute(&emailBody, data); err != nil {
+		zlog.CtxErrorf(ctx, "渲染评论提醒邮件模板失败: %v", err)
+		return fmt.Errorf("渲染评论提醒邮件模板失败: %w", err)
+	}
+
+	m.SetBody("text/html", emailBody.String())
+
+	d := gomail.NewDialer(c.smtpConfig.SmtpHost, c.smtpConfig.SmtpPort, c.smtpConfig.SmtpUser, c.smtpConfig.SmtpPass)
+
+	if err := d.DialAndSend(m); err != nil {
+		zlog.CtxErrorf(ctx, "发送评论提醒邮件失败: %v", err)
+		return fmt.Errorf("发送评论提醒邮件失败: %w", err)
+	}
+
+	zlog.CtxInfof(ctx, "评论提醒邮件发送成功，邮箱: %s, mapID: %s", email, mention.MapID)
+	return nil
+}
+
+// SendMindMapMentionSMS 发送评论@提醒短信
+func (c *codeServiceImpl) SendMindMapMentionSMS(ctx context.Context, phone string, mention *adapter.MindMapMention) error {
+	if c == nil {
+		return fmt.Errorf("notification service not initialized")
+	}
+
+	if c.smsConfig.Key == "" {
+		return fmt.Errorf("sms key not configured")
+	}
+
+	endpoint := c.smsConfig.MentionEndpoint
+	if endpoint == "" {
+		return fmt.Errorf("sms mention endpoint not configured")
+	}
+
+	content := mention.Content
+	if utf8.RuneCountInString(content) > mentionSMSContentLength {
+		content = string([]rune(content)[:mentionSMSContentLength]) + "…"
+	}
+	message := fmt.Sprintf("%s 在思维导图「%s」的评论中提到了您：%s", mention.AuthorName, mention.MapTitle, content)
+	if err := c.sendSMS(ctx, endpoint, message, phone); err != nil {
+		return err
+	}
+	zlog.CtxInfof(ctx, "评论提醒短信发送成功，手机号: %s, mapID: %s", phone, mention.MapID)
+	return nil
+}
@@ -0,0 +1,71 @@
+package office
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	"forge/biz/entity"
+
+	"github.com/unidoc/unioffice/v2/document"
+)
+
+// Word 内置标题样式最多 9 级
+const maxHeadingLevel = 9
+
+// ExportDOCX 导出为 Word 文档：根节点为文档标题，其余节点按层级使用对应级别的标题样式，备注作为正文段落
+func (e *documentExporterImpl) ExportDOCX(ctx context.Context, mindMap *entity.MindMap) ([]byte, error) {
+	doc := document.New()
+	defer doc.Close()
+
+	title := documentTitle(mindMap)
+	doc.CoreProperties.SetTitle(title)
+	if mindMap.Desc != "" {
+		doc.CoreProperties.SetDescription(mindMap.Desc)
+	}
+
+	para := doc.AddParagraph()
+	para.SetStyle("Title")
+	para.AddRun().AddText(title)
+	if mindMap.Desc != "" {
+		doc.AddParagraph().AddRun().AddText(mindMap.Desc)
+	}
+	addDocxNote(doc, mindMap.Data.Data.Note)
+
+	for _, child := range mindMap.Data.Children {
+		addDocxNode(doc, child, 1)
+	}
+
+	var buf bytes.Buffer
+	if err := doc.Save(&buf); err != nil {
+		return nil, fmt.Errorf("save docx failed: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+func addDocxNode(doc *document.Document, node entity.MindMapData, depth int) {
+	para := doc.AddParagraph()
+	para.SetStyle(fmt.Sprintf("Heading%d", min(depth, maxHeadingLevel)))
+	text := nodeText(node.Data)
+	if node.Data.Hyperlink != "" {
+		link := para.AddHyperLink()
+		link.SetTarget(node.Data.Hyperlink)
+		if node.Data.HyperlinkTitle != "" {
+			link.SetToolTip(node.Data.HyperlinkTitle)
+		}
+		link.AddRun().AddText(text)
+	} else {
+		para.AddRun().AddText(text)
+	}
+	addDocxNote(doc, node.Data.Note)
+
+	for _, child := range node.Children {
+		addDocxNode(doc, child, depth+1)
+	}
+}
+
+func addDocxNote(doc *document.Document, note string) {
+	for _, line := range noteLines(note) {
+		doc.AddParagraph().AddRun().AddText(line)
+	}
+}
@@ -0,0 +1,55 @@
+package office
+
+import (
+	"os"
+	"strings"
+
+	"forge/biz/adapter"
+	"forge/biz/entity"
+	"forge/infra/configs"
+	"forge/pkg/log/zlog"
+)
+
+type documentExporterImpl struct {
+	pdfFont []byte // PDF 使用的 TTF 字体内容，为空时使用内置的 Helvetica（仅支持拉丁字符）
+}
+
+// NewDocumentExporter 创建思维导图文档导出器
+// DOCX/PPTX 由阅读器按本地字体渲染，PDF 需要嵌入字体，未配置或加载失败时中文无法正常显示
+func NewDocumentExporter(cfg configs.RenderConfig) adapter.DocumentExporter {
+	e := &documentExporterImpl{}
+	fontPath := cfg.PDFFontPath
+	if fontPath == "" {
+		zlog.Warnf("pdf font path not configured, fallback to helvetica")
+		return e
+	}
+	data, err := os.ReadFile(fontPath)
+	if err != nil {
+		zlog.Warnf("failed to load pdf font %s, fallback to helvetica: %v", fontPath, err)
+		return e
+	}
+	e.pdfFont = data
+	return e
+}
+
+// documentTitle 文档标题，导图标题为空时使用根节点文本
+func documentTitle(mindMap *entity.MindMap) string {
+	if title := strings.TrimSpace(mindMap.Title); title != "" {
+		return title
+	}
+	return nodeText(mindMap.Data.Data)
+}
+
+// nodeText 节点文本转为单行
+func nodeText(data entity.NodeData) string {
+	return strings.Join(strings.Fields(data.Text), " ")
+}
+
+// noteLines 备注按行拆分，去掉首尾空行
+func noteLines(note string) []string {
+	note = strings.Trim(strings.ReplaceAll(note, "\r\n", "\n"), "\n")
+	if strings.TrimSpace(note) == "" {
+		return nil
+	}
+	return strings.Split(note, "\n")
+}
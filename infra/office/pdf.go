@@ -0,0 +1,142 @@
+package office
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"forge/biz/entity"
+
+	"github.com/unidoc/unipdf/v4/creator"
+	"github.com/unidoc/unipdf/v4/model"
+)
+
+// PDF 讲义版式（单位：磅）
+const (
+	pdfMargin      = 50
+	pdfIndent      = 16 // 每层缩进
+	pdfTitleSize   = 22
+	pdfNoteSize    = 10
+	pdfMaxIndented = 6 // 超过该层级不再继续缩进，避免正文过窄
+)
+
+// 各层级字号，超出部分使用最后一项
+var pdfHeadingSizes = []float64{16, 13, 11.5, 10.5}
+
+var (
+	pdfTextColor = creator.ColorRGBFromHex("#1f2d3d")
+	pdfNoteColor = creator.ColorRGBFromHex("#666666")
+	pdfLinkColor = creator.ColorRGBFromHex("#3366cc")
+)
+
+// ExportPDF 导出为 PDF 讲义：根节点为标题，其余节点带层级编号（如 1.2.3）并按层级缩进，备注以小号灰色正文显示
+func (e *documentExporterImpl) ExportPDF(ctx context.Context, mindMap *entity.MindMap) ([]byte, error) {
+	font, err := e.newPDFFont()
+	if err != nil {
+		return nil, fmt.Errorf("load pdf font failed: %w", err)
+	}
+
+	c := creator.New()
+	c.SetPageMargins(pdfMargin, pdfMargin, pdfMargin, pdfMargin)
+	if e.pdfFont != nil {
+		c.EnableFontSubsetting(font)
+	}
+	w := &pdfWriter{c: c, font: font}
+
+	w.paragraph(documentTitle(mindMap), 0, pdfTitleSize, pdfTextColor, 0, 12)
+	if mindMap.Desc != "" {
+		w.paragraph(mindMap.Desc, 0, pdfNoteSize+1, pdfNoteColor, 0, 6)
+	}
+	w.note(mindMap.Data.Data.Note, 0)
+	for i, child := range mindMap.Data.Children {
+		w.node(child, []int{i + 1})
+	}
+	if w.err != nil {
+		return nil, w.err
+	}
+
+	var buf bytes.Buffer
+	if err := c.Write(&buf); err != nil {
+		return nil, fmt.Errorf("write pdf failed: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// newPDFFont 每次导出创建独立的字体对象，字体子集化会修改字体状态，不能在并发导出间共享
+func (e *documentExporterImpl) newPDFFont() (*model.PdfFont, error) {
+	if e.pdfFont == nil {
+		return model.NewStandard14Font(model.HelveticaName)
+	}
+	return model.NewCompositePdfFontFromTTF(bytes.NewReader(e.pdfFont))
+}
+
+// pdfWriter 按顺序向文档追加段落，记录第一个出现的错误
+type pdfWriter struct {
+	c    *creator.Creator
+	font *model.PdfFont
+	err  error
+}
+
+func (w *pdfWriter) node(node entity.MindMapData, number []int) {
+	depth := len(number)
+	indent := float64(min(depth-1, pdfMaxIndented) * pdfIndent)
+	size := pdfHeadingSizes[min(depth, len(pdfHeadingSizes))-1]
+
+	numbers := make([]string, len(number))
+	for i, n := range number {
+		numbers[i] = strconv.Itoa(n)
+	}
+
+	p := w.c.NewStyledParagraph()
+	p.SetMargins(indent, 0, 8, 2)
+	label := p.Append(strings.Join(numbers, ".") + "  ")
+	label.Style.Font = w.font
+	label.Style.FontSize = size
+	label.Style.Color = pdfTextColor
+	// 带超链接的节点文本作为外部链接
+	var text *creator.TextChunk
+	if node.Data.Hyperlink != "" {
+		text = p.AddExternalLink(nodeText(node.Data), node.Data.Hyperlink)
+		text.Style.Color = pdfLinkColor
+	} else {
+		text = p.Append(nodeText(node.Data))
+		text.Style.Color = pdfTextColor
+	}
+	text.Style.Font = w.font
+	text.Style.FontSize = size
+	w.draw(p)
+	w.note(node.Data.Note, indent)
+
+	for i, child := range node.Children {
+		w.node(child, append(number[:len(number):len(number)], i+1))
+	}
+}
+
+func (w *pdfWriter) note(note string, indent float64) {
+	lines := noteLines(note)
+	if len(lines) == 0 {
+		return
+	}
+	w.paragraph(strings.Join(lines, "\n"), indent, pdfNoteSize, pdfNoteColor, 2, 2)
+}
+
+func (w *pdfWriter) paragraph(text string, indent, size float64, color creator.Color, top, bottom float64) {
+	p := w.c.NewStyledParagraph()
+	p.SetMargins(indent, 0, top, bottom)
+	chunk := p.Append(text)
+	chunk.Style.Font = w.font
+	chunk.Style.FontSize = size
+	chunk.Style.Color = color
+	w.draw(p)
+}
+
+func (w *pdfWriter) draw(d creator.Drawable) {
+	if w.err != nil {
+		return
+	}
+	if err := w.c.Draw(d); err != nil {
+		w.err = fmt.Errorf("draw pdf failed: %w", err)
+	}
+}
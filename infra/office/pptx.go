@@ -0,0 +1,102 @@
+package office
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	"forge/biz/entity"
+
+	"github.com/unidoc/unioffice/v2/color"
+	"github.com/unidoc/unioffice/v2/measurement"
+	"github.com/unidoc/unioffice/v2/presentation"
+)
+
+// 幻灯片版式（16:9，单位：磅）
+const (
+	slideWidth     = 720
+	slideHeight    = 405
+	slideMargin    = 36
+	slideTitleSize = 28
+	slideBodySize  = 18
+	maxBulletLevel = 8 // 演示文稿列表最多 9 级（0~8）
+)
+
+var (
+	slideTitleColor = color.RGB(0x1f, 0x2d, 0x3d)
+	slideBodyColor  = color.RGB(0x33, 0x33, 0x33)
+)
+
+// ExportPPTX 导出为演示文稿：首页为导图标题，之后每个一级分支一页，分支下的节点按层级缩进为列表
+func (e *documentExporterImpl) ExportPPTX(ctx context.Context, mindMap *entity.MindMap) ([]byte, error) {
+	ppt := presentation.New()
+	defer ppt.Close()
+
+	size := ppt.SlideSize()
+	size.SetSize(presentation.NewSlideScreenSizeWithValue(
+		int32(measurement.ToEMU(slideWidth)), int32(measurement.ToEMU(slideHeight))))
+	ppt.CoreProperties.SetTitle(documentTitle(mindMap))
+
+	// 封面
+	cover := ppt.AddSlide()
+	addSlideText(cover, documentTitle(mindMap), slideMargin, slideHeight/2-60, slideHeight/4, slideTitleSize+8, true)
+	if mindMap.Desc != "" {
+		addSlideText(cover, mindMap.Desc, slideMargin, slideHeight/2+20, slideHeight/4, slideBodySize, false)
+	}
+
+	for _, branch := range mindMap.Data.Children {
+		slide := ppt.AddSlide()
+		addSlideText(slide, nodeText(branch.Data), slideMargin, slideMargin, 56, slideTitleSize, true)
+
+		body := slide.AddTextBox()
+		body.Properties().SetPosition(slideMargin, slideMargin+72)
+		body.Properties().SetSize(slideWidth-2*slideMargin, slideHeight-2*slideMargin-72)
+		for _, line := range noteLines(branch.Data.Note) {
+			addSlideParagraph(body, line, 0, false)
+		}
+		for _, child := range branch.Children {
+			addSlideBullets(body, child, 0)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := ppt.Save(&buf); err != nil {
+		return nil, fmt.Errorf("save pptx failed: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// addSlideText 添加单段文本框
+func addSlideText(slide presentation.Slide, text string, x, y, height measurement.Distance, size measurement.Distance, title bool) {
+	tb := slide.AddTextBox()
+	tb.Properties().SetPosition(x, y)
+	tb.Properties().SetSize(slideWidth-2*slideMargin, height)
+	run := tb.AddParagraph().AddRun()
+	run.SetText(text)
+	run.Properties().SetSize(size)
+	if title {
+		run.Properties().SetBold(true)
+		run.Properties().SetSolidFill(slideTitleColor)
+	} else {
+		run.Properties().SetSolidFill(slideBodyColor)
+	}
+}
+
+func addSlideBullets(tb presentation.TextBox, node entity.MindMapData, level int) {
+	addSlideParagraph(tb, nodeText(node.Data), min(level, maxBulletLevel), true)
+	for _, child := range node.Children {
+		addSlideBullets(tb, child, level+1)
+	}
+}
+
+func addSlideParagraph(tb presentation.TextBox, text string, level int, bullet bool) {
+	para := tb.AddParagraph()
+	para.Properties().SetLevel(int32(level))
+	if bullet {
+		para.Properties().SetBulletChar("•")
+	}
+	run := para.AddRun()
+	run.SetText(text)
+	run.Properties().SetSize(slideBodySize - measurement.Distance(min(level, 3)*2))
+	run.Properties().SetSolidFill(slideBodyColor)
+}
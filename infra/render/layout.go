@@ -0,0 +1,306 @@
+package render
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"forge/biz/entity"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/math/fixed"
+)
+
+// 排版参数（单位：像素，缩放倍数为 1 时）
+const (
+	nodePaddingX   = 12
+	nodePaddingY   = 8
+	levelGap       = 48 // 父子节点之间的间距
+	siblingGap     = 12 // 兄弟子树之间的间距
+	catalogIndent  = 24 // 目录组织图中每层的缩进
+	canvasMargin   = 24
+	maxDisplayText = 40 // 节点最多显示的字符数，超出部分省略
+)
+
+type point struct {
+	X, Y float64
+}
+
+// layoutNode 排版中的节点，坐标为节点左上角
+type layoutNode struct {
+	data     entity.NodeData
+	text     string
+	depth    int
+	x, y     float64
+	w, h     float64
+	extent   float64 // 子树在兄弟排列方向上占用的长度
+	children []*layoutNode
+}
+
+// edge 连线，curve 为 true 时 points 为三次贝塞尔曲线的 4 个控制点，否则为折线
+type edge struct {
+	points []point
+	curve  bool
+}
+
+type layoutResult struct {
+	nodes         []*layoutNode // 按绘制顺序排列
+	edges         []edge
+	width, height float64
+	lineHeight    float64
+}
+
+// measurer 基于字体度量文本宽度
+type measurer struct {
+	face       font.Face
+	lineHeight float64
+}
+
+func newMeasurer(face font.Face) *measurer {
+	return &measurer{face: face, lineHeight: fixedToFloat(face.Metrics().Height)}
+}
+
+func (m *measurer) textWidth(text string) float64 {
+	return fixedToFloat(font.MeasureString(m.face, text))
+}
+
+func fixedToFloat(v fixed.Int26_6) float64 {
+	return float64(v) / 64
+}
+
+// layoutMindMap 根据导图布局类型排版
+// 时间轴类布局按目录组织图排版，鱼骨图等暂不支持的布局按逻辑结构图排版
+func layoutMindMap(mindMap *entity.MindMap, m *measurer) *layoutResult {
+	root := buildLayoutTree(mindMap.Data, 0, m)
+
+	var edges []edge
+	switch mindMap.Layout {
+	case "mindMap":
+		edges = layoutMindMapBothSides(root)
+	case "logicalStructureLeft":
+		edges = layoutHorizontal(root, -1)
+	case "organizationStructure":
+		edges = layoutVertical(root)
+	case "catalogOrganization", "timeline", "timeline2":
+		edges = layoutCatalog(root)
+	default:
+		edges = layoutHorizontal(root, 1)
+	}
+
+	result := &layoutResult{edges: edges, lineHeight: m.lineHeight}
+	collectNodes(root, &result.nodes)
+	normalize(result)
+	return result
+}
+
+func buildLayoutTree(data entity.MindMapData, depth int, m *measurer) *layoutNode {
+	text := strings.Join(strings.Fields(data.Data.Text), " ")
+	if utf8.RuneCountInString(text) > maxDisplayText {
+		text = string([]rune(text)[:maxDisplayText]) + "…"
+	}
+	node := &layoutNode{
+		data:  data.Data,
+		text:  text,
+		depth: depth,
+		w:     m.textWidth(text) + 2*nodePaddingX,
+		h:     m.lineHeight + 2*nodePaddingY,
+	}
+	// 与前端一致，折叠节点不展示子节点
+	if data.Data.Collapsed {
+		return node
+	}
+	for _, child := range data.Children {
+		node.children = append(node.children, buildLayoutTree(child, depth+1, m))
+	}
+	return node
+}
+
+func collectNodes(n *layoutNode, nodes *[]*layoutNode) {
+	*nodes = append(*nodes, n)
+	for _, child := range n.children {
+		collectNodes(child, nodes)
+	}
+}
+
+// normalize 平移坐标使画布从边距处开始，并计算画布尺寸
+func normalize(result *layoutResult) {
+	minX, minY := result.nodes[0].x, result.nodes[0].y
+	maxX, maxY := minX, minY
+	for _, n := range result.nodes {
+		minX, minY = min(minX, n.x), min(minY, n.y)
+		maxX, maxY = max(maxX, n.x+n.w), max(maxY, n.y+n.h)
+	}
+	dx, dy := canvasMargin-minX, canvasMargin-minY
+	for _, n := range result.nodes {
+		n.x += dx
+		n.y += dy
+	}
+	for _, e := range result.edges {
+		for i := range e.points {
+			e.points[i].X += dx
+			e.points[i].Y += dy
+		}
+	}
+	result.width = maxX - minX + 2*canvasMargin
+	result.height = maxY - minY + 2*canvasMargin
+}
+
+// ---------- 水平布局（逻辑结构图） ----------
+
+// measureVerticalExtent 计算水平布局下子树占用的高度
+func measureVerticalExtent(n *layoutNode) float64 {
+	n.extent = n.h
+	if len(n.children) > 0 {
+		n.extent = max(n.h, childrenExtent(n, measureVerticalExtent))
+	}
+	return n.extent
+}
+
+// measureHorizontalExtent 计算垂直布局下子树占用的宽度
+func measureHorizontalExtent(n *layoutNode) float64 {
+	n.extent = n.w
+	if len(n.children) > 0 {
+		n.extent = max(n.w, childrenExtent(n, measureHorizontalExtent))
+	}
+	return n.extent
+}
+
+func childrenExtent(n *layoutNode, measure func(*layoutNode) float64) float64 {
+	total := 0.0
+	for i, child := range n.children {
+		if i > 0 {
+			total += siblingGap
+		}
+		total += measure(child)
+	}
+	return total
+}
+
+func sumExtent(nodes []*layoutNode) float64 {
+	total := 0.0
+	for i, n := range nodes {
+		if i > 0 {
+			total += siblingGap
+		}
+		total += n.extent
+	}
+	return total
+}
+
+// layoutHorizontal 根节点在一侧，子节点向 dir 方向（1 向右，-1 向左）展开
+func layoutHorizontal(root *layoutNode, dir float64) []edge {
+	measureVerticalExtent(root)
+	var edges []edge
+	placeHorizontal(root, 0, 0, dir, &edges)
+	return edges
+}
+
+// placeHorizontal 放置子树，anchorX 为节点靠近父节点一侧的横坐标，top 为子树顶部
+func placeHorizontal(n *layoutNode, anchorX, top, dir float64, edges *[]edge) {
+	if dir > 0 {
+		n.x = anchorX
+	} else {
+		n.x = anchorX - n.w
+	}
+	n.y = top + (n.extent-n.h)/2
+	placeHorizontalChildren(n, n.children, top+(n.extent-sumExtent(n.children))/2, dir, edges)
+}
+
+func placeHorizontalChildren(parent *layoutNode, children []*layoutNode, top, dir float64, edges *[]edge) {
+	for _, child := range children {
+		if dir > 0 {
+			placeHorizontal(child, parent.x+parent.w+levelGap, top, dir, edges)
+		} else {
+			placeHorizontal(child, parent.x-levelGap, top, dir, edges)
+		}
+		*edges = append(*edges, horizontalEdge(parent, child, dir))
+		top += child.extent + siblingGap
+	}
+}
+
+func horizontalEdge(parent, child *layoutNode, dir float64) edge {
+	start := point{X: parent.x + parent.w, Y: parent.y + parent.h/2}
+	end := point{X: child.x, Y: child.y + child.h/2}
+	if dir < 0 {
+		start.X = parent.x
+		end.X = child.x + child.w
+	}
+	midX := (start.X + end.X) / 2
+	return edge{points: []point{start, {X: midX, Y: start.Y}, {X: midX, Y: end.Y}, end}, curve: true}
+}
+
+// layoutMindMapBothSides 思维导图：一级分支前一半在右侧，后一半在左侧
+func layoutMindMapBothSides(root *layoutNode) []edge {
+	measureVerticalExtent(root)
+	split := (len(root.children) + 1) / 2
+	right, left := root.children[:split], root.children[split:]
+
+	root.x, root.y = 0, -root.h/2
+	var edges []edge
+	placeHorizontalChildren(root, right, -sumExtent(right)/2, 1, &edges)
+	placeHorizontalChildren(root, left, -sumExtent(left)/2, -1, &edges)
+	return edges
+}
+
+// ---------- 垂直布局（组织结构图） ----------
+
+func layoutVertical(root *layoutNode) []edge {
+	measureHorizontalExtent(root)
+	var edges []edge
+	placeVertical(root, 0, 0, &edges)
+	return edges
+}
+
+func placeVertical(n *layoutNode, left, y float64, edges *[]edge) {
+	n.x = left + (n.extent-n.w)/2
+	n.y = y
+	childLeft := left + (n.extent-sumExtent(n.children))/2
+	for _, child := range n.children {
+		placeVertical(child, childLeft, n.y+n.h+levelGap, edges)
+		start := point{X: n.x + n.w/2, Y: n.y + n.h}
+		end := point{X: child.x + child.w/2, Y: child.y}
+		midY := (start.Y + end.Y) / 2
+		*edges = append(*edges, edge{points: []point{start, {X: start.X, Y: midY}, {X: end.X, Y: midY}, end}, curve: true})
+		childLeft += child.extent + siblingGap
+	}
+}
+
+// ---------- 目录组织图 ----------
+
+// layoutCatalog 根节点居上，一级分支横向排列，其下的节点按层级缩进纵向排列
+func layoutCatalog(root *layoutNode) []edge {
+	var edges []edge
+	columnTop := root.h + levelGap
+	left := 0.0
+	for _, child := range root.children {
+		bottom := columnTop
+		right := placeCatalogColumn(child, left, &bottom, &edges)
+		left = right + levelGap
+	}
+
+	columnsWidth := max(left-levelGap, 0)
+	root.x = (columnsWidth - root.w) / 2
+	root.y = 0
+
+	// 根节点到一级分支的折线
+	start := point{X: root.x + root.w/2, Y: root.y + root.h}
+	midY := root.h + levelGap/2
+	for _, child := range root.children {
+		end := point{X: child.x + child.w/2, Y: child.y}
+		edges = append(edges, edge{points: []point{start, {X: start.X, Y: midY}, {X: end.X, Y: midY}, end}})
+	}
+	return edges
+}
+
+// placeCatalogColumn 纵向排列子树，返回子树占用的最右侧横坐标
+func placeCatalogColumn(n *layoutNode, left float64, y *float64, edges *[]edge) float64 {
+	n.x, n.y = left, *y
+	*y += n.h + siblingGap
+	right := n.x + n.w
+	for _, child := range n.children {
+		right = max(right, placeCatalogColumn(child, left+catalogIndent, y, edges))
+		start := point{X: n.x + catalogIndent/2, Y: n.y + n.h}
+		end := point{X: child.x, Y: child.y + child.h/2}
+		*edges = append(*edges, edge{points: []point{start, {X: start.X, Y: end.Y}, end}})
+	}
+	return right
+}
@@ -0,0 +1,201 @@
+package render
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+	"strconv"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/math/fixed"
+	"golang.org/x/image/vector"
+)
+
+const (
+	bezierSegments = 16
+	// kappa 用三次贝塞尔曲线近似四分之一圆弧的控制点系数
+	kappa = 0.5523
+)
+
+// path 由若干闭合多边形或曲线组成的图形
+type path struct {
+	ops    []pathOp
+	bounds [4]float64 // minX, minY, maxX, maxY
+}
+
+type pathOp struct {
+	kind   byte // 'M' 'L' 'C' 'Z'
+	points []point
+}
+
+func (p *path) add(kind byte, points ...point) {
+	for _, pt := range points {
+		if len(p.ops) == 0 {
+			p.bounds = [4]float64{pt.X, pt.Y, pt.X, pt.Y}
+		}
+		p.bounds = [4]float64{min(p.bounds[0], pt.X), min(p.bounds[1], pt.Y), max(p.bounds[2], pt.X), max(p.bounds[3], pt.Y)}
+	}
+	p.ops = append(p.ops, pathOp{kind: kind, points: points})
+}
+
+// merge 追加另一图形的全部子路径
+func (p *path) merge(q *path) {
+	for _, op := range q.ops {
+		p.add(op.kind, op.points...)
+	}
+}
+
+// canvas 位图画布，所有坐标在绘制时乘以 scale
+type canvas struct {
+	img   *image.RGBA
+	scale float64
+}
+
+// renderPNG 将排版结果绘制为位图
+func renderPNG(result *layoutResult, face font.Face, scale float64) *image.RGBA {
+	w := int(math.Ceil(result.width * scale))
+	h := int(math.Ceil(result.height * scale))
+	c := &canvas{img: image.NewRGBA(image.Rect(0, 0, w, h)), scale: scale}
+	draw.Draw(c.img, c.img.Bounds(), image.White, image.Point{}, draw.Src)
+
+	// 所有连线合并为一个图形一次光栅化，避免每条连线按其包围盒分配光栅缓冲
+	edges := &path{}
+	for _, e := range result.edges {
+		edges.merge(strokePath(flattenEdge(e), edgeWidth/2))
+	}
+	c.fill(edges, parseHexColor(edgeColor))
+	for _, n := range result.nodes {
+		style := styleOf(n)
+		// 先以边框色填充整个圆角矩形，再以填充色绘制内缩的圆角矩形
+		c.fill(roundedRect(n.x, n.y, n.w, n.h, nodeRadius), parseHexColor(style.stroke))
+		c.fill(roundedRect(n.x+borderWidth, n.y+borderWidth, n.w-2*borderWidth, n.h-2*borderWidth, nodeRadius-borderWidth),
+			parseHexColor(style.fill))
+		c.text(n, face, parseHexColor(style.text))
+	}
+	return c.img
+}
+
+// fill 以非零环绕规则填充图形，光栅化范围限定在图形包围盒内
+func (c *canvas) fill(p *path, col color.Color) {
+	if len(p.ops) == 0 {
+		return
+	}
+	box := image.Rect(
+		int(math.Floor(p.bounds[0]*c.scale)), int(math.Floor(p.bounds[1]*c.scale)),
+		int(math.Ceil(p.bounds[2]*c.scale))+1, int(math.Ceil(p.bounds[3]*c.scale))+1,
+	).Intersect(c.img.Bounds())
+	if box.Empty() {
+		return
+	}
+
+	z := vector.NewRasterizer(box.Dx(), box.Dy())
+	z.DrawOp = draw.Over
+	tr := func(pt point) (float32, float32) {
+		return float32(pt.X*c.scale - float64(box.Min.X)), float32(pt.Y*c.scale - float64(box.Min.Y))
+	}
+	for _, op := range p.ops {
+		switch op.kind {
+		case 'M':
+			z.MoveTo(tr(op.points[0]))
+		case 'L':
+			z.LineTo(tr(op.points[0]))
+		case 'C':
+			x1, y1 := tr(op.points[0])
+			x2, y2 := tr(op.points[1])
+			x3, y3 := tr(op.points[2])
+			z.CubeTo(x1, y1, x2, y2, x3, y3)
+		case 'Z':
+			z.ClosePath()
+		}
+	}
+	z.Draw(c.img, box, image.NewUniform(col), image.Point{})
+}
+
+// text 在节点中居中绘制文本
+func (c *canvas) text(n *layoutNode, face font.Face, col color.Color) {
+	metrics := face.Metrics()
+	width := font.MeasureString(face, n.text)
+	cx := (n.x + n.w/2) * c.scale
+	cy := (n.y + n.h/2) * c.scale
+	d := font.Drawer{
+		Dst:  c.img,
+		Src:  image.NewUniform(col),
+		Face: face,
+		Dot: fixed.Point26_6{
+			X: fixed.Int26_6(cx*64) - width/2,
+			Y: fixed.Int26_6(cy*64) + (metrics.Ascent-metrics.Descent)/2,
+		},
+	}
+	d.DrawString(n.text)
+}
+
+// roundedRect 圆角矩形，顺时针方向
+func roundedRect(x, y, w, h, r float64) *path {
+	r = max(0, min(r, w/2, h/2))
+	k := r * kappa
+	p := &path{}
+	p.add('M', point{x + r, y})
+	p.add('L', point{x + w - r, y})
+	p.add('C', point{x + w - r + k, y}, point{x + w, y + r - k}, point{x + w, y + r})
+	p.add('L', point{x + w, y + h - r})
+	p.add('C', point{x + w, y + h - r + k}, point{x + w - r + k, y + h}, point{x + w - r, y + h})
+	p.add('L', point{x + r, y + h})
+	p.add('C', point{x + r - k, y + h}, point{x, y + h - r + k}, point{x, y + h - r})
+	p.add('L', point{x, y + r})
+	p.add('C', point{x, y + r - k}, point{x + r - k, y}, point{x + r, y})
+	p.add('Z')
+	return p
+}
+
+// flattenEdge 将连线转换为折线，贝塞尔曲线按固定段数采样
+func flattenEdge(e edge) []point {
+	if !e.curve {
+		return e.points
+	}
+	p0, p1, p2, p3 := e.points[0], e.points[1], e.points[2], e.points[3]
+	points := make([]point, 0, bezierSegments+1)
+	for i := 0; i <= bezierSegments; i++ {
+		t := float64(i) / bezierSegments
+		u := 1 - t
+		points = append(points, point{
+			X: u*u*u*p0.X + 3*u*u*t*p1.X + 3*u*t*t*p2.X + t*t*t*p3.X,
+			Y: u*u*u*p0.Y + 3*u*u*t*p1.Y + 3*u*t*t*p2.Y + t*t*t*p3.Y,
+		})
+	}
+	return points
+}
+
+// strokePath 将折线描边转换为可填充的图形：每条线段为一个四边形，线段两端向外延伸半个线宽以衔接拐角
+// 所有四边形环绕方向一致，重叠部分不会相互抵消
+func strokePath(points []point, halfWidth float64) *path {
+	p := &path{}
+	for i := 1; i < len(points); i++ {
+		a, b := points[i-1], points[i]
+		dx, dy := b.X-a.X, b.Y-a.Y
+		length := math.Hypot(dx, dy)
+		if length == 0 {
+			continue
+		}
+		ux, uy := dx/length*halfWidth, dy/length*halfWidth
+		a = point{a.X - ux, a.Y - uy}
+		b = point{b.X + ux, b.Y + uy}
+		nx, ny := -uy, ux
+		p.add('M', point{a.X + nx, a.Y + ny})
+		p.add('L', point{b.X + nx, b.Y + ny})
+		p.add('L', point{b.X - nx, b.Y - ny})
+		p.add('L', point{a.X - nx, a.Y - ny})
+		p.add('Z')
+	}
+	return p
+}
+
+// parseHexColor 解析 #rgb 或 #rrggbb 格式的颜色，调用方需保证格式合法
+func parseHexColor(s string) color.RGBA {
+	hex := s[1:]
+	if len(hex) == 3 {
+		hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+	}
+	v, _ := strconv.ParseUint(hex, 16, 32)
+	return color.RGBA{R: uint8(v >> 16), G: uint8(v >> 8), B: uint8(v), A: 0xff}
+}
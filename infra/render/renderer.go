@@ -0,0 +1,150 @@
+package render
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	"image/png"
+	"math"
+	"os"
+
+	"forge/biz/adapter"
+	"forge/biz/entity"
+	"forge/infra/configs"
+	"forge/pkg/log/zlog"
+
+	"golang.org/x/image/draw"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/font/opentype"
+)
+
+const (
+	defaultFontSize = 14
+	// 位图单边最大像素与总像素数，超出时自动降低缩放倍数，避免超大导图占用过多内存与 CPU
+	maxCanvasSize   = 8192
+	maxCanvasPixels = 4096 * 4096
+)
+
+type mindMapRendererImpl struct {
+	font     *opentype.Font // 为空时使用内置的 basicfont（仅支持 ASCII 字符）
+	fontSize float64
+}
+
+// NewMindMapRenderer 创建思维导图渲染器
+// 配置的字体加载失败时退化为内置字体，中文等非 ASCII 字符在 PNG 中将无法正常显示
+func NewMindMapRenderer(cfg configs.RenderConfig) adapter.MindMapRenderer {
+	r := &mindMapRendererImpl{fontSize: cfg.FontSize}
+	if r.fontSize <= 0 {
+		r.fontSize = defaultFontSize
+	}
+
+	if cfg.FontPath != "" {
+		f, err := loadFont(cfg.FontPath)
+		if err != nil {
+			zlog.Warnf("failed to load render font %s, fallback to basic font: %v", cfg.FontPath, err)
+		} else {
+			r.font = f
+		}
+	} else {
+		zlog.Warnf("render font path not configured, fallback to basic font")
+	}
+	if r.font == nil {
+		// basicfont 为固定尺寸的点阵字体
+		r.fontSize = float64(basicfont.Face7x13.Height)
+	}
+	return r
+}
+
+func loadFont(path string) (*opentype.Font, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	collection, err := opentype.ParseCollection(data)
+	if err != nil {
+		return nil, err
+	}
+	// 字体集合（.ttc）取第一个字体
+	return collection.Font(0)
+}
+
+// newFace 创建指定缩放倍数下的字体，face 非并发安全，每次渲染单独创建
+func (r *mindMapRendererImpl) newFace(scale float64) (font.Face, error) {
+	if r.font == nil {
+		return basicfont.Face7x13, nil
+	}
+	return opentype.NewFace(r.font, &opentype.FaceOptions{
+		Size:    r.fontSize * scale,
+		DPI:     72,
+		Hinting: font.HintingFull,
+	})
+}
+
+// RenderSVG 渲染为 SVG 矢量图
+func (r *mindMapRendererImpl) RenderSVG(ctx context.Context, mindMap *entity.MindMap) ([]byte, error) {
+	face, err := r.newFace(1)
+	if err != nil {
+		return nil, fmt.Errorf("create font face failed: %w", err)
+	}
+	defer face.Close()
+
+	result := layoutMindMap(mindMap, newMeasurer(face))
+	return renderSVG(result, r.fontSize), nil
+}
+
+// RenderPNG 渲染为 PNG 位图
+func (r *mindMapRendererImpl) RenderPNG(ctx context.Context, mindMap *entity.MindMap, opts adapter.RenderOptions) ([]byte, error) {
+	layoutFace, err := r.newFace(1)
+	if err != nil {
+		return nil, fmt.Errorf("create font face failed: %w", err)
+	}
+	defer layoutFace.Close()
+	result := layoutMindMap(mindMap, newMeasurer(layoutFace))
+
+	// 输出尺寸受 MaxWidth/MaxHeight 与画布上限约束，与使用的字体无关
+	scale := opts.Scale
+	if scale <= 0 {
+		scale = 1
+	}
+	if opts.MaxWidth > 0 {
+		scale = min(scale, float64(opts.MaxWidth)/result.width)
+	}
+	if opts.MaxHeight > 0 {
+		scale = min(scale, float64(opts.MaxHeight)/result.height)
+	}
+	canvasLimit := canvasScaleLimit(result)
+	scale = min(scale, canvasLimit)
+
+	// 点阵字体无法缩放，在画布上限内按原始尺寸绘制后再整体缩小
+	drawScale := scale
+	if r.font == nil {
+		drawScale = max(scale, min(1, canvasLimit))
+	}
+	face, err := r.newFace(drawScale)
+	if err != nil {
+		return nil, fmt.Errorf("create font face failed: %w", err)
+	}
+	defer face.Close()
+
+	var img image.Image = renderPNG(result, face, drawScale)
+	if drawScale != scale {
+		w, h := int(result.width*scale+0.5), int(result.height*scale+0.5)
+		dst := image.NewRGBA(image.Rect(0, 0, max(w, 1), max(h, 1)))
+		draw.CatmullRom.Scale(dst, dst.Bounds(), img, img.Bounds(), draw.Src, nil)
+		img = dst
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, fmt.Errorf("encode png failed: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// canvasScaleLimit 画布不超过单边与总像素上限时允许的最大缩放倍数
+func canvasScaleLimit(result *layoutResult) float64 {
+	return min(maxCanvasSize/max(result.width, result.height),
+		math.Sqrt(maxCanvasPixels/(result.width*result.height)))
+}
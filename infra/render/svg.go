@@ -0,0 +1,104 @@
+package render
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+const (
+	edgeColor   = "#9aa5b1"
+	edgeWidth   = 1.5
+	nodeRadius  = 6
+	borderWidth = 1
+	fontFamily  = `"PingFang SC", "Microsoft YaHei", "Noto Sans CJK SC", sans-serif`
+)
+
+var hexColorPattern = regexp.MustCompile(`^#([0-9a-fA-F]{3}|[0-9a-fA-F]{6})$`)
+
+// nodeStyle 节点配色，节点自定义的颜色优先于按层级的默认配色
+type nodeStyle struct {
+	fill   string
+	stroke string
+	text   string
+}
+
+func styleOf(n *layoutNode) nodeStyle {
+	var style nodeStyle
+	switch n.depth {
+	case 0:
+		style = nodeStyle{fill: "#1f2d3d", stroke: "#1f2d3d", text: "#ffffff"}
+	case 1:
+		style = nodeStyle{fill: "#eef2ff", stroke: "#5b6cff", text: "#1f2d3d"}
+	default:
+		style = nodeStyle{fill: "#ffffff", stroke: "#d0d7de", text: "#333333"}
+	}
+	if hexColorPattern.MatchString(n.data.BackgroundColor) {
+		style.fill = n.data.BackgroundColor
+	}
+	if hexColorPattern.MatchString(n.data.Color) {
+		style.text = n.data.Color
+	}
+	return style
+}
+
+// renderSVG 将排版结果输出为 SVG 文档，文字由浏览器按本地字体渲染
+func renderSVG(result *layoutResult, fontSize float64) []byte {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, `<svg xmlns="http://www.w3.org/2000/svg" width="%s" height="%s" viewBox="0 0 %s %s">`+"\n",
+		num(result.width), num(result.height), num(result.width), num(result.height))
+	fmt.Fprintf(&buf, `<rect width="100%%" height="100%%" fill="#ffffff"/>`+"\n")
+
+	fmt.Fprintf(&buf, `<g fill="none" stroke="%s" stroke-width="%s">`+"\n", edgeColor, num(edgeWidth))
+	for _, e := range result.edges {
+		fmt.Fprintf(&buf, `<path d="%s"/>`+"\n", svgPath(e))
+	}
+	buf.WriteString("</g>\n")
+
+	fmt.Fprintf(&buf, `<g font-family='%s' font-size="%s" text-anchor="middle" dominant-baseline="central">`+"\n",
+		fontFamily, num(fontSize))
+	for _, n := range result.nodes {
+		style := styleOf(n)
+		link := n.data.Hyperlink != ""
+		if link {
+			buf.WriteString(`<a href="`)
+			_ = xml.EscapeText(&buf, []byte(n.data.Hyperlink))
+			buf.WriteString(`" target="_blank">`)
+		}
+		fmt.Fprintf(&buf, `<rect x="%s" y="%s" width="%s" height="%s" rx="%d" fill="%s" stroke="%s" stroke-width="%d"/>`,
+			num(n.x), num(n.y), num(n.w), num(n.h), nodeRadius, style.fill, style.stroke, borderWidth)
+		fmt.Fprintf(&buf, `<text x="%s" y="%s" fill="%s">`, num(n.x+n.w/2), num(n.y+n.h/2), style.text)
+		_ = xml.EscapeText(&buf, []byte(n.text))
+		buf.WriteString("</text>")
+		if link {
+			buf.WriteString("</a>")
+		}
+		buf.WriteString("\n")
+	}
+	buf.WriteString("</g>\n</svg>\n")
+	return buf.Bytes()
+}
+
+func svgPath(e edge) string {
+	var sb strings.Builder
+	sb.WriteString("M" + num(e.points[0].X) + " " + num(e.points[0].Y))
+	if e.curve {
+		sb.WriteString(" C")
+		for _, p := range e.points[1:] {
+			sb.WriteString(" " + num(p.X) + " " + num(p.Y))
+		}
+		return sb.String()
+	}
+	for _, p := range e.points[1:] {
+		sb.WriteString(" L" + num(p.X) + " " + num(p.Y))
+	}
+	return sb.String()
+}
+
+// num 格式化坐标，保留一位小数以减小文件体积
+func num(v float64) string {
+	s := strings.TrimRight(fmt.Sprintf("%.1f", v), "0")
+	return strings.TrimSuffix(s, ".")
+}
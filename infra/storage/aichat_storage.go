@@ -31,15 +31,13 @@ func InitAiChatStorage() {
 
 func GetAiChatPersistence() repo.AiChatRepo { return cp }
 
-func (a *aiChatPersistence) GetConversation(ctx context.Context, conversationID, userID string) (*entity.Conversation, error) {
+func (a *aiChatPersistence) GetConversation(ctx context.Context, conversationID string) (*entity.Conversation, error) {
 	if conversationID == "" {
 		return nil, aichatservice.CONVERSATION_ID_NOT_NULL
-	} else if userID == "" {
-		return nil, aichatservice.USER_ID_NOT_NULL
 	}
 
 	var conversationPO po.ConversationPO
-	if err := a.db.WithContext(ctx).Model(&po.ConversationPO{}).Where("conversation_id = ? AND user_id = ?", conversationID, userID).First(&conversationPO).Error; err != nil {
+	if err := a.db.WithContext(ctx).Model(&po.ConversationPO{}).Where("conversation_id = ?", conversationID).First(&conversationPO).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, aichatservice.CONVERSATION_NOT_EXIST
 		}
@@ -128,7 +126,7 @@ func (a *aiChatPersistence) UpdateConversationMessage(ctx context.Context, conve
 		Updates["messages"] = conversationPO.Messages
 	}
 
-	err = a.db.WithContext(ctx).Model(&po.ConversationPO{}).Where("conversation_id = ? AND user_id = ?", conversationPO.ConversationID, conversationPO.UserID).Updates(Updates).Error
+	err = a.db.WithContext(ctx).Model(&po.ConversationPO{}).Where("conversation_id = ?", conversationPO.ConversationID).Updates(Updates).Error
 	if err != nil {
 		return fmt.Errorf("更新会话时 数据库出错 %w", err)
 	}
@@ -160,21 +158,19 @@ func (a *aiChatPersistence) UpdateConversationTitle(ctx context.Context, convers
 		Updates["title"] = conversationPO.Title
 	}
 
-	err = a.db.WithContext(ctx).Model(&po.ConversationPO{}).Where("conversation_id = ? AND user_id = ?", conversationPO.ConversationID, conversationPO.UserID).Updates(Updates).Error
+	err = a.db.WithContext(ctx).Model(&po.ConversationPO{}).Where("conversation_id = ?", conversationPO.ConversationID).Updates(Updates).Error
 	if err != nil {
 		return fmt.Errorf("更新会话时 数据库出错 %w", err)
 	}
 	return nil
 }
 
-func (a *aiChatPersistence) DeleteConversation(ctx context.Context, conversationID, userID string) error {
+func (a *aiChatPersistence) DeleteConversation(ctx context.Context, conversationID string) error {
 	if conversationID == "" {
 		return aichatservice.CONVERSATION_ID_NOT_NULL
-	} else if userID == "" {
-		return aichatservice.USER_ID_NOT_NULL
 	}
 
-	result := a.db.WithContext(ctx).Model(&po.ConversationPO{}).Where("conversation_id = ? AND user_id = ?", conversationID, userID).Delete(&po.ConversationPO{})
+	result := a.db.WithContext(ctx).Model(&po.ConversationPO{}).Where("conversation_id = ?", conversationID).Delete(&po.ConversationPO{})
 	if result.RowsAffected == 0 {
 		return aichatservice.CONVERSATION_NOT_EXIST
 	}
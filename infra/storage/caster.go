@@ -82,12 +82,22 @@ func CastMindMapDO2PO(mindmap *entity.MindMap) (*po.MindMapPO, error) {
 	}
 
 	mindmapPO := &po.MindMapPO{
-		MapID:  mindmap.MapID,
-		UserID: mindmap.UserID,
-		Title:  mindmap.Title,
-		Desc:   mindmap.Desc,
-		Data:   string(dataBytes),
-		Layout: mindmap.Layout,
+		MapID:     mindmap.MapID,
+		UserID:    mindmap.UserID,
+		Title:     mindmap.Title,
+		Desc:      mindmap.Desc,
+		Data:      string(dataBytes),
+		Layout:    mindmap.Layout,
+		Version:   mindmap.Version,
+		Thumbnail: mindmap.Thumbnail,
+	}
+	if mindmap.Provenance != nil {
+		provenanceBytes, err := json.Marshal(mindmap.Provenance)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal mindmap provenance: %w", err)
+		}
+		provenance := string(provenanceBytes)
+		mindmapPO.Provenance = &provenance
 	}
 
 	// 处理时间字段
@@ -101,7 +111,7 @@ func CastMindMapDO2PO(mindmap *entity.MindMap) (*po.MindMapPO, error) {
 	return mindmapPO, nil
 }
 
-// CastMindMapPO2DO 持久化对象转领域对象
+// CastMindMapPO2DO 持久化对象转领域对象（搜索等查询时Data可能为空）
 func CastMindMapPO2DO(mindmapPO *po.MindMapPO) (*entity.MindMap, error) {
 	if mindmapPO == nil {
 		return nil, nil
@@ -109,17 +119,28 @@ func CastMindMapPO2DO(mindmapPO *po.MindMapPO) (*entity.MindMap, error) {
 
 	// 反序列化JSON数据
 	var data entity.MindMapData
-	if err := json.Unmarshal([]byte(mindmapPO.Data), &data); err != nil {
-		return nil, fmt.Errorf("unmarshal data failed: %w", err)
+	if mindmapPO.Data != "" {
+		if err := json.Unmarshal([]byte(mindmapPO.Data), &data); err != nil {
+			return nil, fmt.Errorf("unmarshal data failed: %w", err)
+		}
 	}
 
 	mindmap := &entity.MindMap{
-		MapID:  mindmapPO.MapID,
-		UserID: mindmapPO.UserID,
-		Title:  mindmapPO.Title,
-		Desc:   mindmapPO.Desc,
-		Data:   data,
-		Layout: mindmapPO.Layout,
+		MapID:     mindmapPO.MapID,
+		UserID:    mindmapPO.UserID,
+		Title:     mindmapPO.Title,
+		Desc:      mindmapPO.Desc,
+		Data:      data,
+		Layout:    mindmapPO.Layout,
+		Version:   mindmapPO.Version,
+		Thumbnail: mindmapPO.Thumbnail,
+	}
+	if mindmapPO.Provenance != nil && *mindmapPO.Provenance != "" {
+		var provenance entity.MindMapProvenance
+		if err := json.Unmarshal([]byte(*mindmapPO.Provenance), &provenance); err != nil {
+			return nil, fmt.Errorf("unmarshal provenance failed: %w", err)
+		}
+		mindmap.Provenance = &provenance
 	}
 
 	// 处理时间字段
@@ -129,10 +150,40 @@ func CastMindMapPO2DO(mindmapPO *po.MindMapPO) (*entity.MindMap, error) {
 	if mindmapPO.UpdatedAt != nil {
 		mindmap.UpdatedAt = *mindmapPO.UpdatedAt
 	}
+	mindmap.DeletedAt = mindmapPO.DeletedAt
 
 	return mindmap, nil
 }
 
+// CastMindMapRevisionPO2DO 历史版本持久化对象转领域对象（列表查询时Data可能为空）
+func CastMindMapRevisionPO2DO(revisionPO *po.MindMapRevisionPO) (*entity.MindMapRevision, error) {
+	if revisionPO == nil {
+		return nil, nil
+	}
+
+	var data entity.MindMapData
+	if revisionPO.Data != "" {
+		if err := json.Unmarshal([]byte(revisionPO.Data), &data); err != nil {
+			return nil, fmt.Errorf("unmarshal revision data failed: %w", err)
+		}
+	}
+
+	revision := &entity.MindMapRevision{
+		MapID:   revisionPO.MapID,
+		Version: revisionPO.Version,
+		UserID:  revisionPO.UserID,
+		Title:   revisionPO.Title,
+		Desc:    revisionPO.Desc,
+		Layout:  revisionPO.Layout,
+		Data:    data,
+	}
+	if revisionPO.CreatedAt != nil {
+		revision.CreatedAt = *revisionPO.CreatedAt
+	}
+
+	return revision, nil
+}
+
 func CastConversationPO2DO(conversationPO *po.ConversationPO) (*entity.Conversation, error) {
 	if conversationPO == nil {
 		return nil, nil
@@ -194,3 +245,267 @@ func CastConversationDO2PO(conversation *entity.Conversation) (*po.ConversationP
 	return conversationPO, nil
 
 }
+
+// CastMindMapShareDO2PO 分享链接领域对象转持久化对象
+func CastMindMapShareDO2PO(share *entity.MindMapShare) *po.MindMapSharePO {
+	if share == nil {
+		return nil
+	}
+	return &po.MindMapSharePO{
+		ShareID:      share.ShareID,
+		Token:        share.Token,
+		MapID:        share.MapID,
+		UserID:       share.UserID,
+		PasswordHash: share.PasswordHash,
+		ExpiresAt:    share.ExpiresAt,
+	}
+}
+
+// CastMindMapSharePO2DO 分享链接持久化对象转领域对象
+func CastMindMapSharePO2DO(sharePO *po.MindMapSharePO) *entity.MindMapShare {
+	if sharePO == nil {
+		return nil
+	}
+	share := &entity.MindMapShare{
+		ShareID:      sharePO.ShareID,
+		Token:        sharePO.Token,
+		MapID:        sharePO.MapID,
+		UserID:       sharePO.UserID,
+		PasswordHash: sharePO.PasswordHash,
+		ExpiresAt:    sharePO.ExpiresAt,
+	}
+	if sharePO.CreatedAt != nil {
+		share.CreatedAt = *sharePO.CreatedAt
+	}
+	return share
+}
+
+// CastMindMapMemberDO2PO 协作成员领域对象转持久化对象
+func CastMindMapMemberDO2PO(member *entity.MindMapMember) *po.MindMapMemberPO {
+	if member == nil {
+		return nil
+	}
+	return &po.MindMapMemberPO{
+		MapID:     member.MapID,
+		UserID:    member.UserID,
+		Role:      member.Role,
+		InvitedBy: member.InvitedBy,
+	}
+}
+
+// CastMindMapMemberPO2DO 协作成员持久化对象转领域对象
+func CastMindMapMemberPO2DO(memberPO *po.MindMapMemberPO) *entity.MindMapMember {
+	if memberPO == nil {
+		return nil
+	}
+	member := &entity.MindMapMember{
+		MapID:     memberPO.MapID,
+		UserID:    memberPO.UserID,
+		Role:      memberPO.Role,
+		InvitedBy: memberPO.InvitedBy,
+		FolderID:  memberPO.FolderID,
+		StarredAt: memberPO.StarredAt,
+		PinnedAt:  memberPO.PinnedAt,
+	}
+	if memberPO.CreatedAt != nil {
+		member.CreatedAt = *memberPO.CreatedAt
+	}
+	if memberPO.UpdatedAt != nil {
+		member.UpdatedAt = *memberPO.UpdatedAt
+	}
+	return member
+}
+
+// CastMindMapTemplateDO2PO 模板领域对象转持久化对象
+func CastMindMapTemplateDO2PO(template *entity.MindMapTemplate) (*po.MindMapTemplatePO, error) {
+	if template == nil {
+		return nil, nil
+	}
+
+	dataBytes, err := json.Marshal(template.Data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal template data: %w", err)
+	}
+
+	return &po.MindMapTemplatePO{
+		TemplateID: template.TemplateID,
+		UserID:     template.UserID,
+		Name:       template.Name,
+		Desc:       template.Desc,
+		Category:   template.Category,
+		Layout:     template.Layout,
+		Data:       string(dataBytes),
+	}, nil
+}
+
+// CastMindMapTemplatePO2DO 模板持久化对象转领域对象（列表查询时Data可能为空）
+func CastMindMapTemplatePO2DO(templatePO *po.MindMapTemplatePO) (*entity.MindMapTemplate, error) {
+	if templatePO == nil {
+		return nil, nil
+	}
+
+	var data entity.MindMapData
+	if templatePO.Data != "" {
+		if err := json.Unmarshal([]byte(templatePO.Data), &data); err != nil {
+			return nil, fmt.Errorf("unmarshal template data failed: %w", err)
+		}
+	}
+
+	template := &entity.MindMapTemplate{
+		TemplateID: templatePO.TemplateID,
+		UserID:     templatePO.UserID,
+		Name:       templatePO.Name,
+		Desc:       templatePO.Desc,
+		Category:   templatePO.Category,
+		Layout:     templatePO.Layout,
+		Data:       data,
+	}
+	if templatePO.CreatedAt != nil {
+		template.CreatedAt = *templatePO.CreatedAt
+	}
+	if templatePO.UpdatedAt != nil {
+		template.UpdatedAt = *templatePO.UpdatedAt
+	}
+	return template, nil
+}
+
+// CastMindMapFolderDO2PO 文件夹领域对象转持久化对象
+func CastMindMapFolderDO2PO(folder *entity.MindMapFolder) *po.MindMapFolderPO {
+	if folder == nil {
+		return nil
+	}
+	return &po.MindMapFolderPO{
+		FolderID: folder.FolderID,
+		UserID:   folder.UserID,
+		ParentID: folder.ParentID,
+		Name:     folder.Name,
+	}
+}
+
+// CastMindMapFolderPO2DO 文件夹持久化对象转领域对象
+func CastMindMapFolderPO2DO(folderPO *po.MindMapFolderPO) *entity.MindMapFolder {
+	if folderPO == nil {
+		return nil
+	}
+	folder := &entity.MindMapFolder{
+		FolderID: folderPO.FolderID,
+		UserID:   folderPO.UserID,
+		ParentID: folderPO.ParentID,
+		Name:     folderPO.Name,
+	}
+	if folderPO.CreatedAt != nil {
+		folder.CreatedAt = *folderPO.CreatedAt
+	}
+	if folderPO.UpdatedAt != nil {
+		folder.UpdatedAt = *folderPO.UpdatedAt
+	}
+	return folder
+}
+
+// CastMindMapCommentDO2PO 评论领域对象转持久化对象
+func CastMindMapCommentDO2PO(comment *entity.MindMapComment) (*po.MindMapCommentPO, error) {
+	if comment == nil {
+		return nil, nil
+	}
+	commentPO := &po.MindMapCommentPO{
+		CommentID:  comment.CommentID,
+		MapID:      comment.MapID,
+		NodeID:     comment.NodeID,
+		ParentID:   comment.ParentID,
+		UserID:     comment.UserID,
+		Content:    comment.Content,
+		Resolved:   comment.Resolved,
+		ResolvedBy: comment.ResolvedBy,
+		ResolvedAt: comment.ResolvedAt,
+		EditedAt:   comment.EditedAt,
+	}
+	mentions, err := marshalCommentMentions(comment.Mentions)
+	if err != nil {
+		return nil, err
+	}
+	commentPO.Mentions = mentions
+	return commentPO, nil
+}
+
+// CastMindMapCommentPO2DO 评论持久化对象转领域对象
+func CastMindMapCommentPO2DO(commentPO *po.MindMapCommentPO) (*entity.MindMapComment, error) {
+	if commentPO == nil {
+		return nil, nil
+	}
+	comment := &entity.MindMapComment{
+		CommentID:  commentPO.CommentID,
+		MapID:      commentPO.MapID,
+		NodeID:     commentPO.NodeID,
+		ParentID:   commentPO.ParentID,
+		UserID:     commentPO.UserID,
+		Content:    commentPO.Content,
+		Resolved:   commentPO.Resolved,
+		ResolvedBy: commentPO.ResolvedBy,
+		ResolvedAt: commentPO.ResolvedAt,
+		EditedAt:   commentPO.EditedAt,
+	}
+	if commentPO.Mentions != nil && *commentPO.Mentions != "" {
+		if err := json.Unmarshal([]byte(*commentPO.Mentions), &comment.Mentions); err != nil {
+			return nil, fmt.Errorf("unmarshal comment mentions failed: %w", err)
+		}
+	}
+	if commentPO.CreatedAt != nil {
+		comment.CreatedAt = *commentPO.CreatedAt
+	}
+	return comment, nil
+}
+
+// marshalCommentMentions 序列化被@的用户ID，没有时为 NULL
+func marshalCommentMentions(mentions []string) (*string, error) {
+	if len(mentions) == 0 {
+		return nil, nil
+	}
+	mentionsBytes, err := json.Marshal(mentions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal comment mentions: %w", err)
+	}
+	encoded := string(mentionsBytes)
+	return &encoded, nil
+}
+
+// CastMindMapAttachmentDO2PO 附件领域对象转持久化对象
+func CastMindMapAttachmentDO2PO(attachment *entity.MindMapAttachment) *po.MindMapAttachmentPO {
+	if attachment == nil {
+		return nil
+	}
+	return &po.MindMapAttachmentPO{
+		AttachmentID: attachment.AttachmentID,
+		MapID:        attachment.MapID,
+		NodeID:       attachment.NodeID,
+		UserID:       attachment.UserID,
+		FileName:     attachment.FileName,
+		ContentType:  attachment.ContentType,
+		Kind:         attachment.Kind,
+		Size:         attachment.Size,
+		ObjectKey:    attachment.ObjectKey,
+		URL:          attachment.URL,
+	}
+}
+
+// CastMindMapAttachmentPO2DO 附件持久化对象转领域对象
+func CastMindMapAttachmentPO2DO(attachmentPO *po.MindMapAttachmentPO) *entity.MindMapAttachment {
+	if attachmentPO == nil {
+		return nil
+	}
+	attachment := &entity.MindMapAttachment{
+		AttachmentID: attachmentPO.AttachmentID,
+		MapID:        attachmentPO.MapID,
+		NodeID:       attachmentPO.NodeID,
+		UserID:       attachmentPO.UserID,
+		FileName:     attachmentPO.FileName,
+		ContentType:  attachmentPO.ContentType,
+		Kind:         attachmentPO.Kind,
+		Size:         attachmentPO.Size,
+		ObjectKey:    attachmentPO.ObjectKey,
+		URL:          attachmentPO.URL,
+	}
+	if attachmentPO.CreatedAt != nil {
+		attachment.CreatedAt = *attachmentPO.CreatedAt
+	}
+	return attachment
+}
@@ -0,0 +1,99 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"forge/biz/entity"
+	"forge/biz/repo"
+	"forge/infra/storage/po"
+
+	"gorm.io/gorm"
+)
+
+// ListMindMapAttachments 获取导图的附件，按上传时间排列
+func (m *mindMapPersistence) ListMindMapAttachments(ctx context.Context, mapID, nodeID string) ([]*entity.MindMapAttachment, error) {
+	if mapID == "" {
+		return nil, fmt.Errorf("MapID is required")
+	}
+
+	db := m.db.WithContext(ctx).Where("map_id = ?", mapID)
+	if nodeID != "" {
+		db = db.Where("node_id = ?", nodeID)
+	}
+
+	var attachmentPOs []po.MindMapAttachmentPO
+	if err := db.Order("created_at").Order("id").Find(&attachmentPOs).Error; err != nil {
+		return nil, fmt.Errorf("list mindmap attachments failed: %w", err)
+	}
+
+	attachments := make([]*entity.MindMapAttachment, 0, len(attachmentPOs))
+	for i := range attachmentPOs {
+		attachments = append(attachments, CastMindMapAttachmentPO2DO(&attachmentPOs[i]))
+	}
+	return attachments, nil
+}
+
+// GetMindMapAttachment 获取导图中的附件，不存在时返回 nil
+func (m *mindMapPersistence) GetMindMapAttachment(ctx context.Context, mapID, attachmentID string) (*entity.MindMapAttachment, error) {
+	if mapID == "" || attachmentID == "" {
+		return nil, fmt.Errorf("MapID and AttachmentID are required")
+	}
+
+	var attachmentPO po.MindMapAttachmentPO
+	if err := m.db.WithContext(ctx).Where("map_id = ? AND attachment_id = ?", mapID, attachmentID).First(&attachmentPO).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("get mindmap attachment failed: %w", err)
+	}
+	return CastMindMapAttachmentPO2DO(&attachmentPO), nil
+}
+
+// CreateMindMapAttachment 创建附件记录
+func (m *mindMapPersistence) CreateMindMapAttachment(ctx context.Context, attachment *entity.MindMapAttachment) error {
+	if attachment == nil || attachment.AttachmentID == "" || attachment.MapID == "" || attachment.UserID == "" {
+		return fmt.Errorf("AttachmentID, MapID and UserID are required")
+	}
+
+	attachmentPO := CastMindMapAttachmentDO2PO(attachment)
+	if err := m.db.WithContext(ctx).Create(attachmentPO).Error; err != nil {
+		return fmt.Errorf("create mindmap attachment failed: %w", err)
+	}
+	attachment.CreatedAt = *attachmentPO.CreatedAt
+	return nil
+}
+
+// DeleteMindMapAttachment 删除附件记录
+func (m *mindMapPersistence) DeleteMindMapAttachment(ctx context.Context, mapID, attachmentID string) error {
+	if mapID == "" || attachmentID == "" {
+		return fmt.Errorf("MapID and AttachmentID are required")
+	}
+
+	result := m.db.WithContext(ctx).Where("map_id = ? AND attachment_id = ?", mapID, attachmentID).Delete(&po.MindMapAttachmentPO{})
+	if result.Error != nil {
+		return fmt.Errorf("delete mindmap attachment failed: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return repo.ErrMindMapAttachmentNotFound
+	}
+	return nil
+}
+
+// SumUserAttachmentSize 统计用户上传的附件总大小
+func (m *mindMapPersistence) SumUserAttachmentSize(ctx context.Context, userID string) (int64, error) {
+	if userID == "" {
+		return 0, fmt.Errorf("UserID is required")
+	}
+
+	var total int64
+	if err := m.db.WithContext(ctx).
+		Model(&po.MindMapAttachmentPO{}).
+		Select("COALESCE(SUM(size), 0)").
+		Where("user_id = ?", userID).
+		Scan(&total).Error; err != nil {
+		return 0, fmt.Errorf("sum user attachment size failed: %w", err)
+	}
+	return total, nil
+}
@@ -0,0 +1,147 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"forge/biz/entity"
+	"forge/biz/repo"
+	"forge/infra/storage/po"
+
+	"gorm.io/gorm"
+)
+
+// ListMindMapChanges 获取用户在序号 afterSeq 之后、createdBefore 之前写入的变更记录，按序号排列
+func (m *mindMapPersistence) ListMindMapChanges(ctx context.Context, userID string, afterSeq int64, createdBefore time.Time, limit int) ([]repo.MindMapChange, error) {
+	if userID == "" || limit <= 0 {
+		return nil, fmt.Errorf("UserID and Limit are required")
+	}
+
+	var changePOs []po.MindMapChangePO
+	if err := m.db.WithContext(ctx).
+		Select("id", "map_id").
+		Where("user_id = ? AND id > ? AND created_at < ?", userID, afterSeq, createdBefore).
+		Order("id").
+		Limit(limit).
+		Find(&changePOs).Error; err != nil {
+		return nil, fmt.Errorf("list mindmap changes failed: %w", err)
+	}
+
+	changes := make([]repo.MindMapChange, 0, len(changePOs))
+	for _, changePO := range changePOs {
+		changes = append(changes, repo.MindMapChange{Seq: int64(changePO.ID), MapID: changePO.MapID})
+	}
+	return changes, nil
+}
+
+// ListLateMindMapChanges 获取用户序号不大于 upToSeq、createdAfter 之后写入的变更记录，按序号倒序排列
+func (m *mindMapPersistence) ListLateMindMapChanges(ctx context.Context, userID string, upToSeq int64, createdAfter time.Time, limit int) ([]repo.MindMapChange, error) {
+	if userID == "" || limit <= 0 {
+		return nil, fmt.Errorf("UserID and Limit are required")
+	}
+
+	var changePOs []po.MindMapChangePO
+	if err := m.db.WithContext(ctx).
+		Select("id", "map_id").
+		Where("user_id = ? AND id <= ? AND created_at >= ?", userID, upToSeq, createdAfter).
+		Order("id DESC").
+		Limit(limit).
+		Find(&changePOs).Error; err != nil {
+		return nil, fmt.Errorf("list late mindmap changes failed: %w", err)
+	}
+
+	changes := make([]repo.MindMapChange, 0, len(changePOs))
+	for _, changePO := range changePOs {
+		changes = append(changes, repo.MindMapChange{Seq: int64(changePO.ID), MapID: changePO.MapID})
+	}
+	return changes, nil
+}
+
+// GetLatestMindMapChangeSeq 获取 createdBefore 之前写入的最大变更序号
+func (m *mindMapPersistence) GetLatestMindMapChangeSeq(ctx context.Context, createdBefore time.Time) (int64, error) {
+	var seq int64
+	if err := m.db.WithContext(ctx).
+		Model(&po.MindMapChangePO{}).
+		Where("created_at < ?", createdBefore).
+		Select("COALESCE(MAX(id), 0)").
+		Scan(&seq).Error; err != nil {
+		return 0, fmt.Errorf("get latest mindmap change failed: %w", err)
+	}
+	return seq, nil
+}
+
+// PruneMindMapChanges 删除超过保留期的变更记录
+func (m *mindMapPersistence) PruneMindMapChanges(ctx context.Context, createdBefore time.Time) (int64, error) {
+	result := m.db.WithContext(ctx).Where("created_at < ?", createdBefore).Delete(&po.MindMapChangePO{})
+	if result.Error != nil {
+		return 0, fmt.Errorf("prune mindmap changes failed: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
+
+// ListMindMapsForSync 按导图ID顺序分批获取用户可访问的导图，用于全量同步
+func (m *mindMapPersistence) ListMindMapsForSync(ctx context.Context, userID, afterMapID string, limit int) ([]*entity.MindMap, error) {
+	if userID == "" || limit <= 0 {
+		return nil, fmt.Errorf("UserID and Limit are required")
+	}
+
+	var mindmapPOs []po.MindMapPO
+	if err := m.db.WithContext(ctx).
+		Where("is_deleted = 0 AND map_id > ?", afterMapID).
+		Where("map_id IN (?)", memberMapIDs(m.db, userID, "")).
+		Order("map_id").
+		Limit(limit).
+		Find(&mindmapPOs).Error; err != nil {
+		return nil, fmt.Errorf("list mindmaps for sync failed: %w", err)
+	}
+	return m.castListedMindMaps(ctx, userID, mindmapPOs)
+}
+
+// GetMindMapsForUser 获取 mapIDs 中用户可访问的导图，不可访问或已移入回收站的导图不返回
+func (m *mindMapPersistence) GetMindMapsForUser(ctx context.Context, userID string, mapIDs []string) ([]*entity.MindMap, error) {
+	if userID == "" {
+		return nil, fmt.Errorf("UserID is required")
+	}
+	if len(mapIDs) == 0 {
+		return nil, nil
+	}
+
+	var mindmapPOs []po.MindMapPO
+	if err := m.db.WithContext(ctx).
+		Where("is_deleted = 0 AND map_id IN ?", mapIDs).
+		Where("map_id IN (?)", memberMapIDs(m.db, userID, "")).
+		Find(&mindmapPOs).Error; err != nil {
+		return nil, fmt.Errorf("get mindmaps for user failed: %w", err)
+	}
+	return m.castListedMindMaps(ctx, userID, mindmapPOs)
+}
+
+// recordMindMapChanges 在事务中为导图的全部成员写入变更记录
+func recordMindMapChanges(tx *gorm.DB, mapIDs []string) error {
+	changeTable := po.MindMapChangePO{}.TableName()
+	memberTable := po.MindMapMemberPO{}.TableName()
+	if err := tx.Exec(
+		"INSERT INTO "+changeTable+" (user_id, map_id, created_at) "+
+			"SELECT user_id, map_id, ? FROM "+memberTable+" WHERE map_id IN ?",
+		time.Now(), mapIDs,
+	).Error; err != nil {
+		return fmt.Errorf("record mindmap changes failed: %w", err)
+	}
+	return nil
+}
+
+// recordMindMapMemberChanges 在事务中为用户作为成员的导图写入变更记录，不是成员的导图忽略
+// 移除成员时须在删除成员记录之前调用
+func recordMindMapMemberChanges(tx *gorm.DB, userID string, mapIDs []string) error {
+	changeTable := po.MindMapChangePO{}.TableName()
+	memberTable := po.MindMapMemberPO{}.TableName()
+	if err := tx.Exec(
+		"INSERT INTO "+changeTable+" (user_id, map_id, created_at) "+
+			"SELECT user_id, map_id, ? FROM "+memberTable+" WHERE user_id = ? AND map_id IN ?",
+		time.Now(), userID, mapIDs,
+	).Error; err != nil {
+		return fmt.Errorf("record mindmap member changes failed: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,155 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"forge/biz/entity"
+	"forge/biz/repo"
+	"forge/infra/storage/po"
+
+	"gorm.io/gorm"
+)
+
+// ListMindMapComments 获取导图的评论（包含回复），按创建时间排列
+func (m *mindMapPersistence) ListMindMapComments(ctx context.Context, query repo.MindMapCommentQuery) ([]*entity.MindMapComment, error) {
+	if query.MapID == "" {
+		return nil, fmt.Errorf("MapID is required")
+	}
+
+	// 筛选条件作用于线程，回复随线程一起返回
+	threads := m.db.Model(&po.MindMapCommentPO{}).Select("comment_id").Where("map_id = ? AND parent_id = ''", query.MapID)
+	if query.NodeID != "" {
+		threads = threads.Where("node_id = ?", query.NodeID)
+	}
+	if query.Resolved != nil {
+		threads = threads.Where("resolved = ?", *query.Resolved)
+	}
+
+	var commentPOs []po.MindMapCommentPO
+	if err := m.db.WithContext(ctx).
+		Where("map_id = ?", query.MapID).
+		Where("comment_id IN (?) OR parent_id IN (?)", threads, threads).
+		Order("created_at").Order("id").
+		Find(&commentPOs).Error; err != nil {
+		return nil, fmt.Errorf("list mindmap comments failed: %w", err)
+	}
+
+	comments := make([]*entity.MindMapComment, 0, len(commentPOs))
+	for i := range commentPOs {
+		comment, err := CastMindMapCommentPO2DO(&commentPOs[i])
+		if err != nil {
+			return nil, err
+		}
+		comments = append(comments, comment)
+	}
+	return comments, nil
+}
+
+// GetMindMapComment 获取导图中的评论，不存在时返回 nil
+func (m *mindMapPersistence) GetMindMapComment(ctx context.Context, mapID, commentID string) (*entity.MindMapComment, error) {
+	if mapID == "" || commentID == "" {
+		return nil, fmt.Errorf("MapID and CommentID are required")
+	}
+
+	var commentPO po.MindMapCommentPO
+	if err := m.db.WithContext(ctx).Where("map_id = ? AND comment_id = ?", mapID, commentID).First(&commentPO).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("get mindmap comment failed: %w", err)
+	}
+	return CastMindMapCommentPO2DO(&commentPO)
+}
+
+// CreateMindMapComment 创建评论
+func (m *mindMapPersistence) CreateMindMapComment(ctx context.Context, comment *entity.MindMapComment) error {
+	if comment == nil || comment.CommentID == "" || comment.MapID == "" || comment.UserID == "" {
+		return fmt.Errorf("CommentID, MapID and UserID are required")
+	}
+
+	commentPO, err := CastMindMapCommentDO2PO(comment)
+	if err != nil {
+		return err
+	}
+	if err := m.db.WithContext(ctx).Create(commentPO).Error; err != nil {
+		return fmt.Errorf("create mindmap comment failed: %w", err)
+	}
+	comment.CreatedAt = *commentPO.CreatedAt
+	return nil
+}
+
+// UpdateMindMapComment 修改评论内容与@的用户，并记录编辑时间
+func (m *mindMapPersistence) UpdateMindMapComment(ctx context.Context, comment *entity.MindMapComment) error {
+	if comment == nil || comment.MapID == "" || comment.CommentID == "" {
+		return fmt.Errorf("MapID and CommentID are required")
+	}
+
+	mentions, err := marshalCommentMentions(comment.Mentions)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	result := m.db.WithContext(ctx).Model(&po.MindMapCommentPO{}).
+		Where("map_id = ? AND comment_id = ?", comment.MapID, comment.CommentID).
+		Updates(map[string]interface{}{
+			"content":   comment.Content,
+			"mentions":  mentions,
+			"edited_at": now,
+		})
+	if result.Error != nil {
+		return fmt.Errorf("update mindmap comment failed: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return repo.ErrMindMapCommentNotFound
+	}
+	comment.EditedAt = &now
+	return nil
+}
+
+// SetMindMapCommentResolved 设置线程是否已解决，重新打开时清除解决人与解决时间
+func (m *mindMapPersistence) SetMindMapCommentResolved(ctx context.Context, mapID, commentID, userID string, resolved bool) error {
+	if mapID == "" || commentID == "" {
+		return fmt.Errorf("MapID and CommentID are required")
+	}
+
+	updates := map[string]interface{}{
+		"resolved":    false,
+		"resolved_by": "",
+		"resolved_at": nil,
+	}
+	if resolved {
+		updates = map[string]interface{}{
+			"resolved":    true,
+			"resolved_by": userID,
+			"resolved_at": time.Now(),
+		}
+	}
+	if err := m.db.WithContext(ctx).Model(&po.MindMapCommentPO{}).
+		Where("map_id = ? AND comment_id = ? AND parent_id = ''", mapID, commentID).
+		Updates(updates).Error; err != nil {
+		return fmt.Errorf("update mindmap comment resolved failed: %w", err)
+	}
+	// 状态未变化时 MySQL 返回的影响行数为 0，因此不以影响行数判断评论是否存在，由调用方先行校验
+	return nil
+}
+
+// DeleteMindMapComment 删除评论，删除线程首条评论时一并删除其回复
+func (m *mindMapPersistence) DeleteMindMapComment(ctx context.Context, mapID, commentID string) error {
+	if mapID == "" || commentID == "" {
+		return fmt.Errorf("MapID and CommentID are required")
+	}
+
+	result := m.db.WithContext(ctx).
+		Where("map_id = ? AND (comment_id = ? OR parent_id = ?)", mapID, commentID, commentID).
+		Delete(&po.MindMapCommentPO{})
+	if result.Error != nil {
+		return fmt.Errorf("delete mindmap comment failed: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return repo.ErrMindMapCommentNotFound
+	}
+	return nil
+}
@@ -0,0 +1,272 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"forge/biz/entity"
+	"forge/biz/repo"
+	"forge/infra/storage/po"
+
+	"gorm.io/gorm"
+)
+
+// ListMindMapFolders 获取用户的全部文件夹，按名称排序
+func (m *mindMapPersistence) ListMindMapFolders(ctx context.Context, userID string) ([]*entity.MindMapFolder, error) {
+	if userID == "" {
+		return nil, fmt.Errorf("UserID is required")
+	}
+
+	var folderPOs []po.MindMapFolderPO
+	if err := m.db.WithContext(ctx).
+		Where("user_id = ?", userID).
+		Order("name").Order("id").
+		Find(&folderPOs).Error; err != nil {
+		return nil, fmt.Errorf("list mindmap folders failed: %w", err)
+	}
+
+	folders := make([]*entity.MindMapFolder, 0, len(folderPOs))
+	for i := range folderPOs {
+		folders = append(folders, CastMindMapFolderPO2DO(&folderPOs[i]))
+	}
+	return folders, nil
+}
+
+// GetMindMapFolder 获取文件夹，不存在时返回 nil
+func (m *mindMapPersistence) GetMindMapFolder(ctx context.Context, folderID string) (*entity.MindMapFolder, error) {
+	if folderID == "" {
+		return nil, fmt.Errorf("FolderID is required")
+	}
+
+	var folderPO po.MindMapFolderPO
+	if err := m.db.WithContext(ctx).Where("folder_id = ?", folderID).First(&folderPO).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("get mindmap folder failed: %w", err)
+	}
+	return CastMindMapFolderPO2DO(&folderPO), nil
+}
+
+// CreateMindMapFolder 创建文件夹
+func (m *mindMapPersistence) CreateMindMapFolder(ctx context.Context, folder *entity.MindMapFolder) error {
+	if folder == nil || folder.FolderID == "" || folder.UserID == "" {
+		return fmt.Errorf("FolderID and UserID are required")
+	}
+
+	folderPO := CastMindMapFolderDO2PO(folder)
+	if err := m.db.WithContext(ctx).Create(folderPO).Error; err != nil {
+		return fmt.Errorf("create mindmap folder failed: %w", err)
+	}
+	folder.CreatedAt = *folderPO.CreatedAt
+	folder.UpdatedAt = *folderPO.UpdatedAt
+	return nil
+}
+
+// UpdateMindMapFolder 修改文件夹名称与上级文件夹
+func (m *mindMapPersistence) UpdateMindMapFolder(ctx context.Context, folder *entity.MindMapFolder) error {
+	if folder == nil || folder.FolderID == "" || folder.UserID == "" {
+		return fmt.Errorf("FolderID and UserID are required")
+	}
+
+	var folderPO po.MindMapFolderPO
+	if err := m.db.WithContext(ctx).
+		Where("folder_id = ? AND user_id = ?", folder.FolderID, folder.UserID).
+		First(&folderPO).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return repo.ErrMindMapFolderNotFound
+		}
+		return fmt.Errorf("get mindmap folder failed: %w", err)
+	}
+
+	if err := m.db.WithContext(ctx).Model(&folderPO).Updates(map[string]interface{}{
+		"name":      folder.Name,
+		"parent_id": folder.ParentID,
+	}).Error; err != nil {
+		return fmt.Errorf("update mindmap folder failed: %w", err)
+	}
+	folder.UpdatedAt = *folderPO.UpdatedAt
+	return nil
+}
+
+// DeleteMindMapFolders 删除用户的文件夹，其中的导图移回顶层
+func (m *mindMapPersistence) DeleteMindMapFolders(ctx context.Context, userID string, folderIDs []string) error {
+	if userID == "" || len(folderIDs) == 0 {
+		return fmt.Errorf("UserID and FolderIDs are required")
+	}
+
+	return m.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var mapIDs []string
+		if err := tx.Model(&po.MindMapMemberPO{}).
+			Where("user_id = ? AND folder_id IN ?", userID, folderIDs).
+			Pluck("map_id", &mapIDs).Error; err != nil {
+			return fmt.Errorf("list mindmaps in folders failed: %w", err)
+		}
+		if len(mapIDs) > 0 {
+			if err := recordMindMapMemberChanges(tx, userID, mapIDs); err != nil {
+				return err
+			}
+		}
+		if err := tx.Model(&po.MindMapMemberPO{}).
+			Where("user_id = ? AND folder_id IN ?", userID, folderIDs).
+			UpdateColumn("folder_id", "").Error; err != nil {
+			return fmt.Errorf("move mindmaps out of folders failed: %w", err)
+		}
+		if err := tx.Where("user_id = ? AND folder_id IN ?", userID, folderIDs).
+			Delete(&po.MindMapFolderPO{}).Error; err != nil {
+			return fmt.Errorf("delete mindmap folders failed: %w", err)
+		}
+		return nil
+	})
+}
+
+// MoveMindMapsToFolder 将用户作为成员的导图移入文件夹，不是成员的导图忽略
+func (m *mindMapPersistence) MoveMindMapsToFolder(ctx context.Context, userID string, mapIDs []string, folderID string) (int64, error) {
+	if userID == "" || len(mapIDs) == 0 {
+		return 0, fmt.Errorf("UserID and MapIDs are required")
+	}
+
+	// 已在目标文件夹中的导图不产生影响行数，因此按成员记录统计
+	var count int64
+	err := m.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&po.MindMapMemberPO{}).
+			Where("user_id = ? AND map_id IN ?", userID, mapIDs).
+			Count(&count).Error; err != nil {
+			return fmt.Errorf("count mindmap members failed: %w", err)
+		}
+		if err := tx.Model(&po.MindMapMemberPO{}).
+			Where("user_id = ? AND map_id IN ?", userID, mapIDs).
+			UpdateColumn("folder_id", folderID).Error; err != nil {
+			return fmt.Errorf("move mindmaps to folder failed: %w", err)
+		}
+		return recordMindMapMemberChanges(tx, userID, mapIDs)
+	})
+	if err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// SetMindMapStarred 设置用户是否收藏导图
+func (m *mindMapPersistence) SetMindMapStarred(ctx context.Context, mapID, userID string, starred bool) error {
+	return m.setMindMapMemberMark(ctx, mapID, userID, "starred_at", starred)
+}
+
+// SetMindMapPinned 设置用户是否置顶导图
+func (m *mindMapPersistence) SetMindMapPinned(ctx context.Context, mapID, userID string, pinned bool) error {
+	return m.setMindMapMemberMark(ctx, mapID, userID, "pinned_at", pinned)
+}
+
+// setMindMapMemberMark 设置或清除成员记录中的时间标记，已设置时保留原时间
+func (m *mindMapPersistence) setMindMapMemberMark(ctx context.Context, mapID, userID, column string, marked bool) error {
+	if mapID == "" || userID == "" {
+		return fmt.Errorf("MapID and UserID are required")
+	}
+
+	var memberPO po.MindMapMemberPO
+	if err := m.db.WithContext(ctx).Where("map_id = ? AND user_id = ?", mapID, userID).First(&memberPO).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return repo.ErrMindMapMemberNotFound
+		}
+		return fmt.Errorf("get mindmap member failed: %w", err)
+	}
+
+	return m.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		db := tx.Model(&po.MindMapMemberPO{}).Where("id = ?", memberPO.ID)
+		if marked {
+			db = db.Where(column+" IS NULL").UpdateColumn(column, time.Now())
+		} else {
+			db = db.UpdateColumn(column, nil)
+		}
+		if db.Error != nil {
+			return fmt.Errorf("update mindmap member %s failed: %w", column, db.Error)
+		}
+		return recordMindMapMemberChanges(tx, userID, []string{mapID})
+	})
+}
+
+// GetMindMapTags 获取用户为导图设置的标签，按设置顺序排列
+func (m *mindMapPersistence) GetMindMapTags(ctx context.Context, mapID, userID string) ([]string, error) {
+	if mapID == "" || userID == "" {
+		return nil, fmt.Errorf("MapID and UserID are required")
+	}
+
+	var tags []string
+	if err := m.db.WithContext(ctx).Model(&po.MindMapTagPO{}).
+		Where("map_id = ? AND user_id = ?", mapID, userID).
+		Order("id").
+		Pluck("tag", &tags).Error; err != nil {
+		return nil, fmt.Errorf("get mindmap tags failed: %w", err)
+	}
+	return tags, nil
+}
+
+// SetMindMapTags 以 tags 整体替换用户为导图设置的标签
+func (m *mindMapPersistence) SetMindMapTags(ctx context.Context, mapID, userID string, tags []string) error {
+	if mapID == "" || userID == "" {
+		return fmt.Errorf("MapID and UserID are required")
+	}
+
+	return m.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("map_id = ? AND user_id = ?", mapID, userID).Delete(&po.MindMapTagPO{}).Error; err != nil {
+			return fmt.Errorf("delete mindmap tags failed: %w", err)
+		}
+		if err := recordMindMapMemberChanges(tx, userID, []string{mapID}); err != nil {
+			return err
+		}
+		if len(tags) == 0 {
+			return nil
+		}
+		tagPOs := make([]*po.MindMapTagPO, 0, len(tags))
+		for _, tag := range tags {
+			tagPOs = append(tagPOs, &po.MindMapTagPO{UserID: userID, MapID: mapID, Tag: tag})
+		}
+		if err := tx.Create(tagPOs).Error; err != nil {
+			return fmt.Errorf("create mindmap tags failed: %w", err)
+		}
+		return nil
+	})
+}
+
+// ListMindMapTagCounts 获取用户使用过的标签及对应的导图数量，按导图数量倒序
+func (m *mindMapPersistence) ListMindMapTagCounts(ctx context.Context, userID string) ([]*entity.MindMapTagCount, error) {
+	if userID == "" {
+		return nil, fmt.Errorf("UserID is required")
+	}
+
+	var rows []struct {
+		Tag   string
+		Count int64
+	}
+	if err := m.db.WithContext(ctx).Model(&po.MindMapTagPO{}).
+		Select("tag, COUNT(*) AS count").
+		Where("user_id = ?", userID).
+		Where("map_id IN (?)", m.db.Model(&po.MindMapPO{}).Select("map_id").Where("is_deleted = 0")).
+		Group("tag").
+		Order("count DESC").Order("tag").
+		Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("list mindmap tag counts failed: %w", err)
+	}
+
+	counts := make([]*entity.MindMapTagCount, 0, len(rows))
+	for _, row := range rows {
+		counts = append(counts, &entity.MindMapTagCount{Tag: row.Tag, Count: row.Count})
+	}
+	return counts, nil
+}
+
+// taggedMapIDs 用户同时设置了全部标签的导图ID子查询
+func taggedMapIDs(db *gorm.DB, userID string, tags []string) *gorm.DB {
+	return db.Model(&po.MindMapTagPO{}).
+		Select("map_id").
+		Where("user_id = ? AND tag IN ?", userID, tags).
+		Group("map_id").
+		Having("COUNT(DISTINCT tag) = ?", len(tags))
+}
+
+// pinnedMapIDs 用户置顶的导图ID子查询
+func pinnedMapIDs(db *gorm.DB, userID string) *gorm.DB {
+	return db.Model(&po.MindMapMemberPO{}).Select("map_id").Where("user_id = ? AND pinned_at IS NOT NULL", userID)
+}
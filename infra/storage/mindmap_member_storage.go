@@ -0,0 +1,193 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"forge/biz/entity"
+	"forge/biz/repo"
+	"forge/infra/storage/po"
+
+	"gorm.io/gorm"
+)
+
+// GetMindMapMember 获取用户在导图中的成员记录（包括回收站中的导图），不是成员时返回 nil
+func (m *mindMapPersistence) GetMindMapMember(ctx context.Context, mapID, userID string) (*entity.MindMapMember, error) {
+	if mapID == "" || userID == "" {
+		return nil, fmt.Errorf("MapID and UserID are required")
+	}
+
+	var memberPO po.MindMapMemberPO
+	if err := m.db.WithContext(ctx).Where("map_id = ? AND user_id = ?", mapID, userID).First(&memberPO).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("get mindmap member failed: %w", err)
+	}
+	return CastMindMapMemberPO2DO(&memberPO), nil
+}
+
+// ListMindMapMembers 获取导图的全部成员，按加入顺序排列（所有者记录随导图创建，始终在最前）
+func (m *mindMapPersistence) ListMindMapMembers(ctx context.Context, mapID string) ([]*entity.MindMapMember, error) {
+	if mapID == "" {
+		return nil, fmt.Errorf("MapID is required")
+	}
+
+	var memberPOs []po.MindMapMemberPO
+	if err := m.db.WithContext(ctx).
+		Where("map_id = ?", mapID).
+		Order("id").
+		Find(&memberPOs).Error; err != nil {
+		return nil, fmt.Errorf("list mindmap members failed: %w", err)
+	}
+
+	members := make([]*entity.MindMapMember, 0, len(memberPOs))
+	for i := range memberPOs {
+		members = append(members, CastMindMapMemberPO2DO(&memberPOs[i]))
+	}
+	return members, nil
+}
+
+// AddMindMapMember 添加协作成员，用户已是成员时返回 repo.ErrMindMapMemberExists
+func (m *mindMapPersistence) AddMindMapMember(ctx context.Context, member *entity.MindMapMember) error {
+	if member == nil || member.MapID == "" || member.UserID == "" {
+		return fmt.Errorf("MapID and UserID are required")
+	}
+
+	return m.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var count int64
+		if err := tx.Model(&po.MindMapMemberPO{}).
+			Where("map_id = ? AND user_id = ?", member.MapID, member.UserID).
+			Count(&count).Error; err != nil {
+			return fmt.Errorf("check mindmap member exists failed: %w", err)
+		}
+		if count > 0 {
+			return repo.ErrMindMapMemberExists
+		}
+
+		memberPO := CastMindMapMemberDO2PO(member)
+		if err := tx.Create(memberPO).Error; err != nil {
+			return fmt.Errorf("create mindmap member failed: %w", err)
+		}
+		member.CreatedAt = *memberPO.CreatedAt
+		member.UpdatedAt = *memberPO.UpdatedAt
+		return recordMindMapMemberChanges(tx, member.UserID, []string{member.MapID})
+	})
+}
+
+// UpdateMindMapMemberRole 修改成员角色
+func (m *mindMapPersistence) UpdateMindMapMemberRole(ctx context.Context, mapID, userID, role string) error {
+	if mapID == "" || userID == "" || role == "" {
+		return fmt.Errorf("MapID, UserID and Role are required")
+	}
+
+	// 角色未变化时 MySQL 返回的影响行数为 0，因此先确认成员存在
+	var memberPO po.MindMapMemberPO
+	if err := m.db.WithContext(ctx).Where("map_id = ? AND user_id = ?", mapID, userID).First(&memberPO).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return repo.ErrMindMapMemberNotFound
+		}
+		return fmt.Errorf("get mindmap member failed: %w", err)
+	}
+
+	return m.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&memberPO).Update("role", role).Error; err != nil {
+			return fmt.Errorf("update mindmap member role failed: %w", err)
+		}
+		return recordMindMapMemberChanges(tx, userID, []string{mapID})
+	})
+}
+
+// RemoveMindMapMember 移除协作成员
+func (m *mindMapPersistence) RemoveMindMapMember(ctx context.Context, mapID, userID string) error {
+	if mapID == "" || userID == "" {
+		return fmt.Errorf("MapID and UserID are required")
+	}
+
+	return m.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// 成员记录删除后无法再关联到该用户，先写入变更记录
+		if err := recordMindMapMemberChanges(tx, userID, []string{mapID}); err != nil {
+			return err
+		}
+		result := tx.Where("map_id = ? AND user_id = ?", mapID, userID).Delete(&po.MindMapMemberPO{})
+		if result.Error != nil {
+			return fmt.Errorf("delete mindmap member failed: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return repo.ErrMindMapMemberNotFound
+		}
+		if err := tx.Where("map_id = ? AND user_id = ?", mapID, userID).Delete(&po.MindMapTagPO{}).Error; err != nil {
+			return fmt.Errorf("delete mindmap member tags failed: %w", err)
+		}
+		return nil
+	})
+}
+
+// fillMindMapMembership 填充用户在各导图中的角色与整理信息
+func (m *mindMapPersistence) fillMindMapMembership(ctx context.Context, userID string, mindmaps []*entity.MindMap) error {
+	if len(mindmaps) == 0 {
+		return nil
+	}
+	mapIDs := make([]string, 0, len(mindmaps))
+	for _, mindmap := range mindmaps {
+		mapIDs = append(mapIDs, mindmap.MapID)
+	}
+
+	var memberPOs []po.MindMapMemberPO
+	if err := m.db.WithContext(ctx).
+		Select("map_id", "role", "folder_id", "starred_at", "pinned_at").
+		Where("user_id = ? AND map_id IN ?", userID, mapIDs).
+		Find(&memberPOs).Error; err != nil {
+		return fmt.Errorf("get mindmap roles failed: %w", err)
+	}
+
+	var tagPOs []po.MindMapTagPO
+	if err := m.db.WithContext(ctx).
+		Select("map_id", "tag").
+		Where("user_id = ? AND map_id IN ?", userID, mapIDs).
+		Order("id").
+		Find(&tagPOs).Error; err != nil {
+		return fmt.Errorf("get mindmap tags failed: %w", err)
+	}
+
+	members := make(map[string]*po.MindMapMemberPO, len(memberPOs))
+	for i := range memberPOs {
+		members[memberPOs[i].MapID] = &memberPOs[i]
+	}
+	tags := make(map[string][]string)
+	for _, tagPO := range tagPOs {
+		tags[tagPO.MapID] = append(tags[tagPO.MapID], tagPO.Tag)
+	}
+	for _, mindmap := range mindmaps {
+		if memberPO, ok := members[mindmap.MapID]; ok {
+			mindmap.Role = memberPO.Role
+			mindmap.FolderID = memberPO.FolderID
+			mindmap.Starred = memberPO.StarredAt != nil
+			mindmap.Pinned = memberPO.PinnedAt != nil
+		}
+		mindmap.Tags = tags[mindmap.MapID]
+	}
+	return nil
+}
+
+// memberMapIDs 用户作为成员（可指定角色）的导图ID子查询
+func memberMapIDs(db *gorm.DB, userID, role string) *gorm.DB {
+	sub := db.Model(&po.MindMapMemberPO{}).Select("map_id").Where("user_id = ?", userID)
+	if role != "" {
+		sub = sub.Where("role = ?", role)
+	}
+	return sub
+}
+
+// backfillMindMapOwners 为协作功能上线前创建的导图补齐所有者成员记录
+func (m *mindMapPersistence) backfillMindMapOwners() error {
+	memberTable := po.MindMapMemberPO{}.TableName()
+	mindmapTable := po.MindMapPO{}.TableName()
+	return m.db.Exec(
+		"INSERT INTO "+memberTable+" (map_id, user_id, role, invited_by, created_at, updated_at) "+
+			"SELECT m.map_id, m.user_id, ?, '', m.created_at, m.created_at FROM "+mindmapTable+" m "+
+			"WHERE NOT EXISTS (SELECT 1 FROM "+memberTable+" mb WHERE mb.map_id = m.map_id AND mb.user_id = m.user_id)",
+		entity.MindMapRoleOwner,
+	).Error
+}
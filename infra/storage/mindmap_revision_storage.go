@@ -0,0 +1,94 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+
+	"forge/biz/entity"
+	"forge/biz/repo"
+	"forge/infra/storage/po"
+	"forge/pkg/log/zlog"
+
+	"gorm.io/gorm"
+)
+
+// ListMindMapRevisions 获取思维导图历史版本列表（按版本号倒序，不包含导图数据）
+func (m *mindMapPersistence) ListMindMapRevisions(ctx context.Context, query repo.MindMapRevisionQuery) ([]*entity.MindMapRevision, int64, error) {
+	if query.MapID == "" {
+		return nil, 0, fmt.Errorf("MapID is required")
+	}
+
+	var revisionPOs []po.MindMapRevisionPO
+	var total int64
+
+	db := m.db.WithContext(ctx).Model(&po.MindMapRevisionPO{}).Where("map_id = ?", query.MapID)
+
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("count mindmap revisions failed: %w", err)
+	}
+
+	db = db.Omit("data").Order("version DESC")
+	if query.Page > 0 && query.PageSize > 0 {
+		offset := (query.Page - 1) * query.PageSize
+		db = db.Offset(offset).Limit(query.PageSize)
+	}
+
+	if err := db.Find(&revisionPOs).Error; err != nil {
+		return nil, 0, fmt.Errorf("list mindmap revisions failed: %w", err)
+	}
+
+	revisions := make([]*entity.MindMapRevision, 0, len(revisionPOs))
+	for _, revisionPO := range revisionPOs {
+		revision, err := CastMindMapRevisionPO2DO(&revisionPO)
+		if err != nil {
+			zlog.CtxErrorf(ctx, "failed to cast revision PO to DO, mapID: %s, version: %d: %v", revisionPO.MapID, revisionPO.Version, err)
+			continue
+		}
+		revisions = append(revisions, revision)
+	}
+
+	return revisions, total, nil
+}
+
+// GetMindMapRevision 获取指定版本的快照，不存在时返回 nil
+func (m *mindMapPersistence) GetMindMapRevision(ctx context.Context, mapID string, version int64) (*entity.MindMapRevision, error) {
+	if mapID == "" {
+		return nil, fmt.Errorf("MapID is required")
+	}
+
+	var revisionPO po.MindMapRevisionPO
+	if err := m.db.WithContext(ctx).
+		Where("map_id = ? AND version = ?", mapID, version).
+		First(&revisionPO).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("get mindmap revision failed: %w", err)
+	}
+
+	return CastMindMapRevisionPO2DO(&revisionPO)
+}
+
+// newMindMapRevisionPO 基于导图当前状态构建版本快照
+func newMindMapRevisionPO(mindmapPO *po.MindMapPO, userID string) *po.MindMapRevisionPO {
+	return &po.MindMapRevisionPO{
+		MapID:   mindmapPO.MapID,
+		Version: mindmapPO.Version,
+		UserID:  userID,
+		Title:   mindmapPO.Title,
+		Desc:    mindmapPO.Desc,
+		Data:    mindmapPO.Data,
+		Layout:  mindmapPO.Layout,
+	}
+}
+
+// backfillMindMapRevisions 为没有当前版本快照的导图以当前数据写入快照，产生者为导图所有者
+func (m *mindMapPersistence) backfillMindMapRevisions() error {
+	revisionTable := po.MindMapRevisionPO{}.TableName()
+	mindmapTable := po.MindMapPO{}.TableName()
+	return m.db.Exec(
+		"INSERT INTO " + revisionTable + " (map_id, version, user_id, title, `desc`, data, layout, created_at) " +
+			"SELECT m.map_id, m.version, m.user_id, m.title, m.`desc`, m.data, m.layout, m.updated_at FROM " + mindmapTable + " m " +
+			"WHERE NOT EXISTS (SELECT 1 FROM " + revisionTable + " r WHERE r.map_id = m.map_id AND r.version = m.version)",
+	).Error
+}
@@ -0,0 +1,229 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"forge/biz/entity"
+	"forge/biz/repo"
+	"forge/infra/storage/po"
+	"forge/pkg/log/zlog"
+
+	"gorm.io/gorm"
+)
+
+// likeEscaper 转义 LIKE 通配符，MySQL 默认以反斜杠作为转义字符
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// searchFullTextIndex 搜索内容的全文索引，使用 ngram 分词以支持中文等任意子串
+const searchFullTextIndex = "idx_search_content_ngram"
+
+// ensureSearchFullTextIndex 为搜索内容建立 ngram 全文索引，并读取服务端的分词长度
+// 建索引时在同一连接上关闭停用词，避免 "in"、"to" 等分词被丢弃导致包含它们的关键词无法命中
+func (m *mindMapPersistence) ensureSearchFullTextIndex() error {
+	if err := m.db.Raw("SELECT @@ngram_token_size").Scan(&m.ngramTokenSize).Error; err != nil {
+		return fmt.Errorf("get ngram token size failed: %w", err)
+	}
+	if m.db.Migrator().HasIndex(&po.MindMapSearchIndexPO{}, searchFullTextIndex) {
+		return nil
+	}
+	return m.db.Connection(func(tx *gorm.DB) error {
+		if err := tx.Exec("SET SESSION innodb_ft_enable_stopword = OFF").Error; err != nil {
+			return fmt.Errorf("disable fulltext stopwords failed: %w", err)
+		}
+		return tx.Exec("CREATE FULLTEXT INDEX " + searchFullTextIndex + " ON " + po.MindMapSearchIndexPO{}.TableName() + " (content) WITH PARSER ngram").Error
+	})
+}
+
+// searchContentCondition 关键词匹配条件：关键词不短于分词长度时先用全文索引短语匹配缩小范围，再以 LIKE 保证子串精确匹配
+// ngram 分词在空白与标点处断开，短于分词长度或包含空白、标点的关键词无法可靠地表示为短语，仅使用 LIKE 匹配
+func (m *mindMapPersistence) searchContentCondition(column, keyword string) (string, []interface{}) {
+	pattern := "%" + likeEscaper.Replace(keyword) + "%"
+	if m.ngramTokenSize <= 0 || utf8.RuneCountInString(keyword) < m.ngramTokenSize || !isNgramPhrase(keyword) {
+		return column + " LIKE ?", []interface{}{pattern}
+	}
+	return "MATCH(" + column + ") AGAINST(? IN BOOLEAN MODE) AND " + column + " LIKE ?", []interface{}{`"` + keyword + `"`, pattern}
+}
+
+// isNgramPhrase 关键词是否只由字母与数字组成
+func isNgramPhrase(keyword string) bool {
+	for _, r := range keyword {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			return false
+		}
+	}
+	return true
+}
+
+// SearchMindMaps 在标题、描述、节点文本中搜索关键词，按导图最后更新时间倒序分页
+// 关键词优先通过 ngram 全文索引匹配，无法表示为短语的关键词退化为在可访问导图的索引中 LIKE 匹配
+func (m *mindMapPersistence) SearchMindMaps(ctx context.Context, query repo.MindMapSearchQuery) ([]*entity.MindMapSearchResult, int64, error) {
+	if query.UserID == "" || query.Keyword == "" {
+		return nil, 0, fmt.Errorf("UserID and Keyword are required")
+	}
+
+	// 命中的导图（排除回收站中的导图）
+	matched := m.db.WithContext(ctx).
+		Table(po.MindMapSearchIndexPO{}.TableName()+" AS i").
+		Joins("JOIN "+po.MindMapPO{}.TableName()+" AS m ON m.map_id = i.map_id").
+		Where("i.map_id IN (?)", memberMapIDs(m.db, query.UserID, "")).
+		Where("m.is_deleted = 0")
+	cond, args := m.searchContentCondition("i.content", query.Keyword)
+	matched = matched.Where(cond, args...)
+
+	var total int64
+	if err := matched.Session(&gorm.Session{}).Distinct("i.map_id").Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("count search results failed: %w", err)
+	}
+	if total == 0 {
+		return []*entity.MindMapSearchResult{}, 0, nil
+	}
+
+	var mapIDs []string
+	db := matched.Session(&gorm.Session{}).
+		Select("i.map_id").
+		Group("i.map_id").
+		Order("MAX(m.updated_at) DESC")
+	if query.Page > 0 && query.PageSize > 0 {
+		db = db.Offset((query.Page - 1) * query.PageSize).Limit(query.PageSize)
+	}
+	if err := db.Pluck("i.map_id", &mapIDs).Error; err != nil {
+		return nil, 0, fmt.Errorf("search mindmaps failed: %w", err)
+	}
+	if len(mapIDs) == 0 {
+		return []*entity.MindMapSearchResult{}, total, nil
+	}
+
+	// 导图基本信息（不加载导图数据）
+	var mindmapPOs []po.MindMapPO
+	if err := m.db.WithContext(ctx).Omit("data").Where("map_id IN ?", mapIDs).Find(&mindmapPOs).Error; err != nil {
+		return nil, 0, fmt.Errorf("load search result mindmaps failed: %w", err)
+	}
+	results := make(map[string]*entity.MindMapSearchResult, len(mindmapPOs))
+	for i := range mindmapPOs {
+		mindmap, err := CastMindMapPO2DO(&mindmapPOs[i])
+		if err != nil {
+			zlog.CtxErrorf(ctx, "failed to cast mindmap PO to DO for mapID %s: %v", mindmapPOs[i].MapID, err)
+			continue
+		}
+		results[mindmap.MapID] = &entity.MindMapSearchResult{MindMap: mindmap}
+	}
+
+	// 命中项，按写入顺序即标题、描述、节点先序遍历顺序
+	var indexPOs []po.MindMapSearchIndexPO
+	cond, args = m.searchContentCondition("content", query.Keyword)
+	if err := m.db.WithContext(ctx).
+		Where("map_id IN ?", mapIDs).
+		Where(cond, args...).
+		Order("id").
+		Find(&indexPOs).Error; err != nil {
+		return nil, 0, fmt.Errorf("load search hits failed: %w", err)
+	}
+	for _, indexPO := range indexPOs {
+		result, ok := results[indexPO.MapID]
+		if !ok {
+			continue
+		}
+		result.HitCount++
+		if query.MaxHitsPerMap > 0 && len(result.Hits) >= query.MaxHitsPerMap {
+			continue
+		}
+		hit := entity.MindMapSearchHit{Field: indexPO.Field, NodeUID: indexPO.NodeUID, Text: indexPO.Content}
+		if hit.Field == entity.SearchFieldNode {
+			hit.Path = parseIndexPath(indexPO.Path)
+		}
+		result.Hits = append(result.Hits, hit)
+	}
+
+	// 保持分页查询的排序
+	list := make([]*entity.MindMapSearchResult, 0, len(mapIDs))
+	for _, mapID := range mapIDs {
+		if result, ok := results[mapID]; ok {
+			list = append(list, result)
+		}
+	}
+	return list, total, nil
+}
+
+// rebuildSearchIndex 在事务中重建导图的搜索索引
+func rebuildSearchIndex(tx *gorm.DB, mindmap *entity.MindMap) error {
+	if err := deleteSearchIndex(tx, []string{mindmap.MapID}); err != nil {
+		return err
+	}
+	entries := mindmap.SearchEntries()
+	indexPOs := make([]po.MindMapSearchIndexPO, 0, len(entries))
+	for _, entry := range entries {
+		indexPOs = append(indexPOs, po.MindMapSearchIndexPO{
+			MapID:   mindmap.MapID,
+			Field:   entry.Field,
+			NodeUID: entry.NodeUID,
+			Path:    formatIndexPath(entry.Path),
+			Content: entry.Text,
+		})
+	}
+	if err := tx.CreateInBatches(indexPOs, 500).Error; err != nil {
+		return fmt.Errorf("create mindmap search index failed: %w", err)
+	}
+	return nil
+}
+
+// rebuildSearchIndexByPO 根据持久化对象重建搜索索引
+func rebuildSearchIndexByPO(tx *gorm.DB, mindmapPO *po.MindMapPO) error {
+	mindmap, err := CastMindMapPO2DO(mindmapPO)
+	if err != nil {
+		return fmt.Errorf("convert mindmap PO failed: %w", err)
+	}
+	return rebuildSearchIndex(tx, mindmap)
+}
+
+func deleteSearchIndex(tx *gorm.DB, mapIDs []string) error {
+	if err := tx.Where("map_id IN ?", mapIDs).Delete(&po.MindMapSearchIndexPO{}).Error; err != nil {
+		return fmt.Errorf("delete mindmap search index failed: %w", err)
+	}
+	return nil
+}
+
+// backfillSearchIndex 为尚未建立索引的导图建立搜索索引
+func (m *mindMapPersistence) backfillSearchIndex() error {
+	var mindmapPOs []po.MindMapPO
+	return m.db.Where("is_deleted = 0").
+		Where("NOT EXISTS (SELECT 1 FROM "+po.MindMapSearchIndexPO{}.TableName()+" i WHERE i.map_id = "+po.MindMapPO{}.TableName()+".map_id)").
+		FindInBatches(&mindmapPOs, 100, func(tx *gorm.DB, batch int) error {
+			for i := range mindmapPOs {
+				err := m.db.Transaction(func(tx *gorm.DB) error {
+					return rebuildSearchIndexByPO(tx, &mindmapPOs[i])
+				})
+				if err != nil {
+					zlog.Errorf("skip backfill search index, mapID: %s, error: %v", mindmapPOs[i].MapID, err)
+				}
+			}
+			return nil
+		}).Error
+}
+
+func formatIndexPath(path []int) string {
+	parts := make([]string, len(path))
+	for i, idx := range path {
+		parts[i] = strconv.Itoa(idx)
+	}
+	return strings.Join(parts, ",")
+}
+
+func parseIndexPath(path string) []int {
+	result := []int{}
+	if path == "" {
+		return result
+	}
+	for _, part := range strings.Split(path, ",") {
+		idx, err := strconv.Atoi(part)
+		if err != nil {
+			return []int{}
+		}
+		result = append(result, idx)
+	}
+	return result
+}
@@ -0,0 +1,87 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"forge/biz/entity"
+	"forge/biz/repo"
+	"forge/infra/storage/po"
+
+	"gorm.io/gorm"
+)
+
+// CreateMindMapShare 创建分享链接
+func (m *mindMapPersistence) CreateMindMapShare(ctx context.Context, share *entity.MindMapShare) error {
+	if share == nil {
+		return fmt.Errorf("share cannot be nil")
+	}
+
+	sharePO := CastMindMapShareDO2PO(share)
+	if err := m.db.WithContext(ctx).Create(sharePO).Error; err != nil {
+		return fmt.Errorf("create mindmap share failed: %w", err)
+	}
+	share.CreatedAt = *sharePO.CreatedAt
+	return nil
+}
+
+// GetMindMapShareByToken 根据 Token 获取分享链接（包括已过期的），不存在时返回 repo.ErrMindMapShareNotFound
+func (m *mindMapPersistence) GetMindMapShareByToken(ctx context.Context, token string) (*entity.MindMapShare, error) {
+	if token == "" {
+		return nil, fmt.Errorf("Token is required")
+	}
+
+	var sharePO po.MindMapSharePO
+	if err := m.db.WithContext(ctx).Where("token = ?", token).First(&sharePO).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, repo.ErrMindMapShareNotFound
+		}
+		return nil, fmt.Errorf("get mindmap share failed: %w", err)
+	}
+	return CastMindMapSharePO2DO(&sharePO), nil
+}
+
+// ListMindMapShares 获取用户未过期的分享链接，按创建时间倒序
+func (m *mindMapPersistence) ListMindMapShares(ctx context.Context, query repo.MindMapShareQuery) ([]*entity.MindMapShare, error) {
+	if query.UserID == "" {
+		return nil, fmt.Errorf("UserID is required")
+	}
+
+	db := m.db.WithContext(ctx).
+		Where("user_id = ?", query.UserID).
+		Where("expires_at IS NULL OR expires_at > ?", time.Now())
+	if query.MapID != "" {
+		db = db.Where("map_id = ?", query.MapID)
+	}
+
+	var sharePOs []po.MindMapSharePO
+	if err := db.Order("created_at DESC").Find(&sharePOs).Error; err != nil {
+		return nil, fmt.Errorf("list mindmap shares failed: %w", err)
+	}
+
+	shares := make([]*entity.MindMapShare, 0, len(sharePOs))
+	for i := range sharePOs {
+		shares = append(shares, CastMindMapSharePO2DO(&sharePOs[i]))
+	}
+	return shares, nil
+}
+
+// DeleteMindMapShare 撤销分享链接（用户只能撤销自己创建的分享）
+func (m *mindMapPersistence) DeleteMindMapShare(ctx context.Context, shareID, userID string) error {
+	if shareID == "" || userID == "" {
+		return fmt.Errorf("ShareID and UserID are required")
+	}
+
+	result := m.db.WithContext(ctx).
+		Where("share_id = ? AND user_id = ?", shareID, userID).
+		Delete(&po.MindMapSharePO{})
+	if result.Error != nil {
+		return fmt.Errorf("delete mindmap share failed: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return repo.ErrMindMapShareNotFound
+	}
+	return nil
+}
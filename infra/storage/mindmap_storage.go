@@ -2,8 +2,10 @@ package storage
 
 import (
 	"context"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
+	"time"
 
 	"forge/biz/entity"
 	"forge/biz/repo"
@@ -12,10 +14,12 @@ import (
 	"forge/pkg/log/zlog"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type mindMapPersistence struct {
-	db *gorm.DB
+	db             *gorm.DB
+	ngramTokenSize int // 全文索引的 ngram 分词长度，为 0 时搜索不使用全文索引
 }
 
 var mmp *mindMapPersistence
@@ -24,50 +28,98 @@ func InitMindMapStorage() {
 	db := database.ForgeDB()
 
 	// 自动迁移思维导图表
-	if err := db.AutoMigrate(&po.MindMapPO{}); err != nil {
+	if err := db.AutoMigrate(&po.MindMapPO{}, &po.MindMapRevisionPO{}, &po.MindMapSearchIndexPO{}, &po.MindMapSharePO{}, &po.MindMapMemberPO{}, &po.MindMapTemplatePO{}, &po.MindMapFolderPO{}, &po.MindMapTagPO{}, &po.MindMapCommentPO{}, &po.MindMapAttachmentPO{}, &po.MindMapChangePO{}, &po.MindMapMigrationPO{}); err != nil {
 		panic(fmt.Sprintf("failed to auto migrate mindmap table: %v", err))
 	}
 
 	mmp = &mindMapPersistence{
 		db: db,
 	}
+
+	// 为历史数据中缺少标识的节点补齐标识，全表扫描只在首次启动时执行
+	if err := mmp.runMigrationOnce(migrationBackfillNodeIDs, mmp.backfillNodeIDs); err != nil {
+		panic(fmt.Sprintf("failed to backfill mindmap node ids: %v", err))
+	}
+
+	// 回收站功能上线前删除的导图没有删除时间，以最后更新时间作为删除时间
+	if err := db.Model(&po.MindMapPO{}).
+		Where("is_deleted = 1 AND deleted_at IS NULL").
+		UpdateColumn("deleted_at", gorm.Expr("updated_at")).Error; err != nil {
+		panic(fmt.Sprintf("failed to backfill mindmap deleted_at: %v", err))
+	}
+
+	// 为版本历史功能上线前创建的导图补齐当前版本的快照，使其可以被恢复
+	if err := mmp.backfillMindMapRevisions(); err != nil {
+		panic(fmt.Sprintf("failed to backfill mindmap revisions: %v", err))
+	}
+
+	// 为协作功能上线前创建的导图补齐所有者成员记录
+	if err := mmp.backfillMindMapOwners(); err != nil {
+		panic(fmt.Sprintf("failed to backfill mindmap owners: %v", err))
+	}
+
+	// 为搜索内容建立全文索引
+	if err := mmp.ensureSearchFullTextIndex(); err != nil {
+		panic(fmt.Sprintf("failed to create mindmap search fulltext index: %v", err))
+	}
+
+	// 为搜索功能上线前创建的导图建立搜索索引
+	if err := mmp.backfillSearchIndex(); err != nil {
+		panic(fmt.Sprintf("failed to backfill mindmap search index: %v", err))
+	}
+
+	// 同步内置的系统模板
+	if err := mmp.syncSystemTemplates(); err != nil {
+		panic(fmt.Sprintf("failed to sync system mindmap templates: %v", err))
+	}
 }
 
 func GetMindMapPersistence() repo.IMindMapRepo {
 	return mmp
 }
 
-// CreateMindMap 创建思维导图
+// CreateMindMap 创建思维导图（同时写入所有者成员记录、第一个版本快照、搜索索引与变更记录）
 func (m *mindMapPersistence) CreateMindMap(ctx context.Context, mindmap *entity.MindMap) error {
+	if _, err := mindmap.Data.EnsureNodeIDs(); err != nil {
+		return fmt.Errorf("assign node ids failed: %w", err)
+	}
 	mindmapPO, err := CastMindMapDO2PO(mindmap)
 	if err != nil {
 		return fmt.Errorf("convert mindmap to PO failed: %w", err)
 	}
-	if err := m.db.WithContext(ctx).Create(mindmapPO).Error; err != nil {
-		return fmt.Errorf("create mindmap failed: %w", err)
+	mindmapPO.Version = 1
+
+	err = m.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(mindmapPO).Error; err != nil {
+			return fmt.Errorf("create mindmap failed: %w", err)
+		}
+		if err := tx.Create(&po.MindMapMemberPO{MapID: mindmap.MapID, UserID: mindmap.UserID, Role: entity.MindMapRoleOwner}).Error; err != nil {
+			return fmt.Errorf("create mindmap owner failed: %w", err)
+		}
+		if err := tx.Create(newMindMapRevisionPO(mindmapPO, mindmap.UserID)).Error; err != nil {
+			return fmt.Errorf("create mindmap revision failed: %w", err)
+		}
+		if err := recordMindMapChanges(tx, []string{mindmap.MapID}); err != nil {
+			return err
+		}
+		return rebuildSearchIndex(tx, mindmap)
+	})
+	if err != nil {
+		return err
 	}
+
+	mindmap.Version = mindmapPO.Version
 	return nil
 }
 
-// GetMindMap 获取思维导图
-func (m *mindMapPersistence) GetMindMap(ctx context.Context, query repo.MindMapQuery) (*entity.MindMap, error) {
-	var mindmapPO po.MindMapPO
-
-	db := m.db.WithContext(ctx).Where("is_deleted = 0")
-
-	// 必须有UserID
-	if query.UserID == "" {
-		return nil, fmt.Errorf("UserID is required")
-	}
-	db = db.Where("user_id = ?", query.UserID)
-
-	// 必须有MapID（GetMindMap用于获取单个实体）
-	if query.MapID == "" {
+// GetMindMap 获取思维导图，不存在或已移入回收站时返回 nil
+func (m *mindMapPersistence) GetMindMap(ctx context.Context, mapID string) (*entity.MindMap, error) {
+	if mapID == "" {
 		return nil, fmt.Errorf("MapID is required")
 	}
-	db = db.Where("map_id = ?", query.MapID)
 
-	if err := db.First(&mindmapPO).Error; err != nil {
+	var mindmapPO po.MindMapPO
+	if err := m.db.WithContext(ctx).Where("map_id = ? AND is_deleted = 0", mapID).First(&mindmapPO).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return nil, nil
 		}
@@ -77,26 +129,46 @@ func (m *mindMapPersistence) GetMindMap(ctx context.Context, query repo.MindMapQ
 	return CastMindMapPO2DO(&mindmapPO)
 }
 
-// ListMindMaps 获取思维导图列表
+// lockMindMapData 在事务中加锁读取当前存储的节点树 导图不存在时返回空
+// 行锁持有到事务结束，保证分配标识所参照的树即为被本次更新覆盖的树
+func lockMindMapData(tx *gorm.DB, mapID string) (*entity.MindMapData, error) {
+	var mindmapPO po.MindMapPO
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Select("data").Where("map_id = ? AND is_deleted = 0", mapID).First(&mindmapPO).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("get mindmap data failed: %w", err)
+	}
+
+	var data entity.MindMapData
+	if err := json.Unmarshal([]byte(mindmapPO.Data), &data); err != nil {
+		return nil, fmt.Errorf("unmarshal data failed: %w", err)
+	}
+	return &data, nil
+}
+
+// ListMindMaps 获取用户可访问的思维导图列表
 func (m *mindMapPersistence) ListMindMaps(ctx context.Context, query repo.MindMapQuery) ([]*entity.MindMap, int64, error) {
+	return m.listMindMaps(ctx, query, false)
+}
+
+// ListDeletedMindMaps 获取用户所有的回收站中的思维导图列表
+func (m *mindMapPersistence) ListDeletedMindMaps(ctx context.Context, query repo.MindMapQuery) ([]*entity.MindMap, int64, error) {
+	return m.listMindMaps(ctx, query, true)
+}
+
+// listMindMaps 按条件分页查询正常或回收站中的思维导图，回收站按删除时间倒序
+func (m *mindMapPersistence) listMindMaps(ctx context.Context, query repo.MindMapQuery, deleted bool) ([]*entity.MindMap, int64, error) {
 	var mindmapPOs []po.MindMapPO
 	var total int64
 
-	db := m.db.WithContext(ctx).Where("is_deleted = 0")
-
 	// 必须有UserID
 	if query.UserID == "" {
 		return nil, 0, fmt.Errorf("UserID is required")
 	}
-	db = db.Where("user_id = ?", query.UserID)
 
-	// 可选筛选条件
-	if query.Title != "" {
-		db = db.Where("title LIKE ?", "%"+query.Title+"%")
-	}
-	if query.Layout != "" {
-		db = db.Where("layout = ?", query.Layout)
-	}
+	db := m.mindMapListScope(ctx, query, deleted)
 
 	// 统计总数（先统计，再应用排序和分页）
 	if err := db.Model(&po.MindMapPO{}).Count(&total).Error; err != nil {
@@ -104,7 +176,12 @@ func (m *mindMapPersistence) ListMindMaps(ctx context.Context, query repo.MindMa
 	}
 
 	// 先排序
-	db = db.Order("updated_at DESC")
+	if deleted {
+		db = db.Order("deleted_at DESC").Order("id DESC")
+	} else {
+		column, desc := mindMapSortColumn(query)
+		db = db.Order(m.mindMapListOrder(query.UserID, column, desc))
+	}
 
 	// 再分页
 	if query.Page > 0 && query.PageSize > 0 {
@@ -116,7 +193,160 @@ func (m *mindMapPersistence) ListMindMaps(ctx context.Context, query repo.MindMa
 		return nil, 0, fmt.Errorf("list mindmaps failed: %w", err)
 	}
 
-	// 转换为领域对象
+	mindmaps, err := m.castListedMindMaps(ctx, query.UserID, mindmapPOs)
+	if err != nil {
+		return nil, 0, err
+	}
+	return mindmaps, total, nil
+}
+
+// ListMindMapsByCursor 按游标查询用户可访问的思维导图，以（置顶、排序字段、主键）作为游标位置
+// 翻页期间新增的导图不会导致已返回的导图重复，但排序字段或置顶状态在翻页期间变化的导图会移动位置：
+// 按 updated_at 等可变字段排序时，被修改的导图可能重复出现或被跳过，需要完整列表时应使用增量同步
+func (m *mindMapPersistence) ListMindMapsByCursor(ctx context.Context, query repo.MindMapQuery) ([]*entity.MindMap, string, error) {
+	if query.UserID == "" || query.PageSize <= 0 {
+		return nil, "", fmt.Errorf("UserID and PageSize are required")
+	}
+
+	column, desc := mindMapSortColumn(query)
+	db := m.mindMapListScope(ctx, query, false)
+	if query.Cursor != "" {
+		cursor, err := decodeMindMapCursor(query.Cursor)
+		if err != nil || cursor.SortBy != column || cursor.Desc != desc {
+			return nil, "", repo.ErrInvalidMindMapCursor
+		}
+		value, err := cursor.sortValue()
+		if err != nil {
+			return nil, "", repo.ErrInvalidMindMapCursor
+		}
+		db = db.Where(m.mindMapCursorCondition(query.UserID, column, desc, cursor, value))
+	}
+
+	// 多查一条用于判断是否还有下一页
+	var mindmapPOs []po.MindMapPO
+	if err := db.Order(m.mindMapListOrder(query.UserID, column, desc)).
+		Limit(query.PageSize + 1).
+		Find(&mindmapPOs).Error; err != nil {
+		return nil, "", fmt.Errorf("list mindmaps by cursor failed: %w", err)
+	}
+	hasMore := len(mindmapPOs) > query.PageSize
+	if hasMore {
+		mindmapPOs = mindmapPOs[:query.PageSize]
+	}
+
+	mindmaps, err := m.castListedMindMaps(ctx, query.UserID, mindmapPOs)
+	if err != nil {
+		return nil, "", err
+	}
+	if !hasMore {
+		return mindmaps, "", nil
+	}
+
+	last := &mindmapPOs[len(mindmapPOs)-1]
+	pinned, err := m.isMindMapPinned(ctx, query.UserID, last.MapID)
+	if err != nil {
+		return nil, "", err
+	}
+	next, err := newMindMapCursor(last, column, desc, pinned).encode()
+	if err != nil {
+		return nil, "", err
+	}
+	return mindmaps, next, nil
+}
+
+// mindMapListScope 导图列表的筛选条件，导图列表包含用户作为成员的导图，回收站只包含用户所有的导图
+func (m *mindMapPersistence) mindMapListScope(ctx context.Context, query repo.MindMapQuery, deleted bool) *gorm.DB {
+	isDeleted, role := 0, query.Role
+	if deleted {
+		isDeleted, role = 1, entity.MindMapRoleOwner
+	}
+	members := memberMapIDs(m.db, query.UserID, role)
+	if query.FolderID != nil {
+		members = members.Where("folder_id = ?", *query.FolderID)
+	}
+	if query.Starred {
+		members = members.Where("starred_at IS NOT NULL")
+	}
+	db := m.db.WithContext(ctx).Where("is_deleted = ?", isDeleted).Where("map_id IN (?)", members)
+	if len(query.Tags) > 0 {
+		db = db.Where("map_id IN (?)", taggedMapIDs(m.db, query.UserID, query.Tags))
+	}
+
+	// 可选筛选条件
+	if query.Title != "" {
+		db = db.Where("title LIKE ?", "%"+query.Title+"%")
+	}
+	if query.Layout != "" {
+		db = db.Where("layout = ?", query.Layout)
+	}
+	if query.CreatedFrom != nil {
+		db = db.Where("created_at >= ?", *query.CreatedFrom)
+	}
+	if query.CreatedTo != nil {
+		db = db.Where("created_at < ?", *query.CreatedTo)
+	}
+	if query.UpdatedFrom != nil {
+		db = db.Where("updated_at >= ?", *query.UpdatedFrom)
+	}
+	if query.UpdatedTo != nil {
+		db = db.Where("updated_at < ?", *query.UpdatedTo)
+	}
+	return db
+}
+
+// mindMapSortColumn 解析排序字段与方向，默认按更新时间倒序
+func mindMapSortColumn(query repo.MindMapQuery) (string, bool) {
+	column := repo.MindMapSortByUpdatedAt
+	switch query.SortBy {
+	case repo.MindMapSortByTitle, repo.MindMapSortByCreatedAt:
+		column = query.SortBy
+	}
+	return column, query.SortOrder != repo.SortOrderAsc
+}
+
+// mindMapListOrder 导图列表排序：用户置顶的导图在前，其次按排序字段，相同时按主键保证顺序稳定
+// 带参数的排序表达式会被后续 Order 覆盖，因此合并为一个表达式
+func (m *mindMapPersistence) mindMapListOrder(userID, column string, desc bool) clause.OrderBy {
+	direction := "ASC"
+	if desc {
+		direction = "DESC"
+	}
+	return clause.OrderBy{Expression: clause.Expr{
+		SQL:  fmt.Sprintf("map_id IN (?) DESC, %s %s, id %s", column, direction, direction),
+		Vars: []interface{}{pinnedMapIDs(m.db, userID)},
+	}}
+}
+
+// mindMapCursorCondition 位于游标之后的导图：置顶的导图之后是未置顶的导图，置顶状态相同时比较（排序字段、主键）
+func (m *mindMapPersistence) mindMapCursorCondition(userID, column string, desc bool, cursor *mindMapCursor, value interface{}) clause.Expr {
+	op := ">"
+	if desc {
+		op = "<"
+	}
+	after := fmt.Sprintf("(%s %s ? OR (%s = ? AND id %s ?))", column, op, column, op)
+	if cursor.Pinned {
+		return clause.Expr{
+			SQL:  "((map_id IN (?) AND " + after + ") OR map_id NOT IN (?))",
+			Vars: []interface{}{pinnedMapIDs(m.db, userID), value, value, cursor.ID, pinnedMapIDs(m.db, userID)},
+		}
+	}
+	return clause.Expr{
+		SQL:  "(map_id NOT IN (?) AND " + after + ")",
+		Vars: []interface{}{pinnedMapIDs(m.db, userID), value, value, cursor.ID},
+	}
+}
+
+// isMindMapPinned 用户是否置顶了导图
+func (m *mindMapPersistence) isMindMapPinned(ctx context.Context, userID, mapID string) (bool, error) {
+	var count int64
+	if err := pinnedMapIDs(m.db.WithContext(ctx), userID).Where("map_id = ?", mapID).Count(&count).Error; err != nil {
+		return false, fmt.Errorf("check mindmap pinned failed: %w", err)
+	}
+	return count > 0, nil
+}
+
+// castListedMindMaps 将列表查询结果转换为领域对象并填充用户的角色与整理信息
+func (m *mindMapPersistence) castListedMindMaps(ctx context.Context, userID string, mindmapPOs []po.MindMapPO) ([]*entity.MindMap, error) {
 	mindmaps := make([]*entity.MindMap, 0, len(mindmapPOs))
 	for _, po := range mindmapPOs {
 		mindmap, err := CastMindMapPO2DO(&po)
@@ -127,13 +357,71 @@ func (m *mindMapPersistence) ListMindMaps(ctx context.Context, query repo.MindMa
 		mindmaps = append(mindmaps, mindmap)
 	}
 
-	return mindmaps, total, nil
+	if err := m.fillMindMapMembership(ctx, userID, mindmaps); err != nil {
+		return nil, err
+	}
+	return mindmaps, nil
+}
+
+// mindMapCursor 游标分页位置，编码后对调用方不透明
+type mindMapCursor struct {
+	SortBy string `json:"s"`
+	Desc   bool   `json:"d"`
+	Pinned bool   `json:"p"`
+	Value  string `json:"v"` // 排序字段的值，时间字段为 RFC3339Nano 格式
+	ID     uint64 `json:"i"`
+}
+
+func newMindMapCursor(mindmapPO *po.MindMapPO, column string, desc, pinned bool) *mindMapCursor {
+	cursor := &mindMapCursor{SortBy: column, Desc: desc, Pinned: pinned, ID: mindmapPO.ID}
+	switch column {
+	case repo.MindMapSortByTitle:
+		cursor.Value = mindmapPO.Title
+	case repo.MindMapSortByCreatedAt:
+		if mindmapPO.CreatedAt != nil {
+			cursor.Value = mindmapPO.CreatedAt.Format(time.RFC3339Nano)
+		}
+	default:
+		if mindmapPO.UpdatedAt != nil {
+			cursor.Value = mindmapPO.UpdatedAt.Format(time.RFC3339Nano)
+		}
+	}
+	return cursor
+}
+
+func decodeMindMapCursor(encoded string) (*mindMapCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, err
+	}
+	var cursor mindMapCursor
+	if err := json.Unmarshal(raw, &cursor); err != nil {
+		return nil, err
+	}
+	return &cursor, nil
+}
+
+func (c *mindMapCursor) encode() (string, error) {
+	raw, err := json.Marshal(c)
+	if err != nil {
+		return "", fmt.Errorf("marshal mindmap cursor failed: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(raw), nil
+}
+
+// sortValue 排序字段的值，用于与数据库中的值比较
+func (c *mindMapCursor) sortValue() (interface{}, error) {
+	if c.SortBy == repo.MindMapSortByTitle {
+		return c.Value, nil
+	}
+	return time.Parse(time.RFC3339Nano, c.Value)
 }
 
 // UpdateMindMap 更新思维导图
-func (m *mindMapPersistence) UpdateMindMap(ctx context.Context, updateInfo *repo.MindMapUpdateInfo) error {
+// 版本号原子递增，并在同一事务中写入更新后的不可变快照、重建搜索索引、写入变更记录
+func (m *mindMapPersistence) UpdateMindMap(ctx context.Context, updateInfo *repo.MindMapUpdateInfo) (int64, error) {
 	if updateInfo.MapID == "" || updateInfo.UserID == "" {
-		return fmt.Errorf("MapID and UserID are required")
+		return 0, fmt.Errorf("MapID and UserID are required")
 	}
 
 	updates := make(map[string]interface{})
@@ -147,52 +435,301 @@ func (m *mindMapPersistence) UpdateMindMap(ctx context.Context, updateInfo *repo
 	if updateInfo.Layout != nil {
 		updates["layout"] = *updateInfo.Layout
 	}
-	if updateInfo.Data != nil {
-		dataBytes, err := json.Marshal(updateInfo.Data)
-		if err != nil {
-			return fmt.Errorf("marshal data failed: %w", err)
+
+	var newVersion int64
+	err := m.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if updateInfo.Data != nil {
+			// 参照当前存储的树分配标识 重复标识始终由新传入的节点让出
+			prev, err := lockMindMapData(tx, updateInfo.MapID)
+			if err != nil {
+				return err
+			}
+			if _, err := updateInfo.Data.EnsureNodeIDsFrom(prev); err != nil {
+				return fmt.Errorf("assign node ids failed: %w", err)
+			}
+			dataBytes, err := json.Marshal(updateInfo.Data)
+			if err != nil {
+				return fmt.Errorf("marshal data failed: %w", err)
+			}
+			updates["data"] = string(dataBytes)
 		}
-		updates["data"] = string(dataBytes)
-	}
 
-	if len(updates) == 0 {
-		return nil // 没有需要更新的字段
-	}
+		db := tx.Model(&po.MindMapPO{}).
+			Where("map_id = ? AND is_deleted = 0", updateInfo.MapID)
+		if updateInfo.ExpectedVersion != nil {
+			db = db.Where("version = ?", *updateInfo.ExpectedVersion)
+		}
+
+		if len(updates) == 0 {
+			// 没有需要更新的字段，返回当前版本
+			var current po.MindMapPO
+			if err := db.Select("version").First(&current).Error; err != nil {
+				if err == gorm.ErrRecordNotFound {
+					return m.classifyUpdateMiss(tx, updateInfo)
+				}
+				return fmt.Errorf("get mindmap version failed: %w", err)
+			}
+			newVersion = current.Version
+			return nil
+		}
+
+		updates["version"] = gorm.Expr("version + 1")
+		result := db.Updates(updates)
+		if result.Error != nil {
+			return fmt.Errorf("update mindmap failed: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return m.classifyUpdateMiss(tx, updateInfo)
+		}
 
-	result := m.db.WithContext(ctx).
-		Model(&po.MindMapPO{}).
-		Where("map_id = ? AND user_id = ? AND is_deleted = 0", updateInfo.MapID, updateInfo.UserID).
-		Updates(updates)
+		// 重新读取更新后的完整数据作为快照
+		var mindmapPO po.MindMapPO
+		if err := tx.Where("map_id = ?", updateInfo.MapID).First(&mindmapPO).Error; err != nil {
+			return fmt.Errorf("reload mindmap failed: %w", err)
+		}
+		if err := tx.Create(newMindMapRevisionPO(&mindmapPO, updateInfo.UserID)).Error; err != nil {
+			return fmt.Errorf("create mindmap revision failed: %w", err)
+		}
+		if err := recordMindMapChanges(tx, []string{updateInfo.MapID}); err != nil {
+			return err
+		}
+		if err := rebuildSearchIndexByPO(tx, &mindmapPO); err != nil {
+			return err
+		}
 
-	if result.Error != nil {
-		return fmt.Errorf("update mindmap failed: %w", result.Error)
+		newVersion = mindmapPO.Version
+		return nil
+	})
+	if err != nil {
+		return 0, err
 	}
 
-	if result.RowsAffected == 0 {
+	return newVersion, nil
+}
+
+// classifyUpdateMiss 区分更新未命中的原因：导图不存在 还是 版本冲突
+func (m *mindMapPersistence) classifyUpdateMiss(tx *gorm.DB, updateInfo *repo.MindMapUpdateInfo) error {
+	if updateInfo.ExpectedVersion == nil {
 		return repo.ErrMindMapNotFound
 	}
 
-	return nil
+	var count int64
+	if err := tx.Model(&po.MindMapPO{}).
+		Where("map_id = ? AND is_deleted = 0", updateInfo.MapID).
+		Count(&count).Error; err != nil {
+		return fmt.Errorf("check mindmap exists failed: %w", err)
+	}
+	if count == 0 {
+		return repo.ErrMindMapNotFound
+	}
+	return repo.ErrMindMapVersionConflict
 }
 
 // DeleteMindMap 删除思维导图（软删除）
-func (m *mindMapPersistence) DeleteMindMap(ctx context.Context, mapID string, userID string) error {
-	if mapID == "" || userID == "" {
-		return fmt.Errorf("MapID and UserID are required for deletion")
+func (m *mindMapPersistence) DeleteMindMap(ctx context.Context, mapID string) error {
+	if mapID == "" {
+		return fmt.Errorf("MapID is required for deletion")
 	}
 
-	result := m.db.WithContext(ctx).
-		Model(&po.MindMapPO{}).
-		Where("map_id = ? AND user_id = ? AND is_deleted = 0", mapID, userID).
-		Update("is_deleted", 1)
+	// 回收站中的导图不参与搜索，移除搜索索引
+	return m.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&po.MindMapPO{}).
+			Where("map_id = ? AND is_deleted = 0", mapID).
+			Updates(map[string]interface{}{"is_deleted": 1, "deleted_at": time.Now()})
+
+		if result.Error != nil {
+			return fmt.Errorf("delete mindmap failed: %w", result.Error)
+		}
 
-	if result.Error != nil {
-		return fmt.Errorf("delete mindmap failed: %w", result.Error)
+		if result.RowsAffected == 0 {
+			return repo.ErrMindMapNotFound
+		}
+
+		if err := recordMindMapChanges(tx, []string{mapID}); err != nil {
+			return err
+		}
+		return deleteSearchIndex(tx, []string{mapID})
+	})
+}
+
+// RestoreMindMap 从回收站恢复思维导图
+func (m *mindMapPersistence) RestoreMindMap(ctx context.Context, mapID string) error {
+	if mapID == "" {
+		return fmt.Errorf("MapID is required for restore")
 	}
 
-	if result.RowsAffected == 0 {
-		return repo.ErrMindMapNotFound
+	// 恢复后重建搜索索引
+	return m.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&po.MindMapPO{}).
+			Where("map_id = ? AND is_deleted = 1", mapID).
+			Updates(map[string]interface{}{"is_deleted": 0, "deleted_at": nil})
+
+		if result.Error != nil {
+			return fmt.Errorf("restore mindmap failed: %w", result.Error)
+		}
+
+		if result.RowsAffected == 0 {
+			return repo.ErrMindMapNotFound
+		}
+
+		if err := recordMindMapChanges(tx, []string{mapID}); err != nil {
+			return err
+		}
+		var mindmapPO po.MindMapPO
+		if err := tx.Where("map_id = ?", mapID).First(&mindmapPO).Error; err != nil {
+			return fmt.Errorf("reload mindmap failed: %w", err)
+		}
+		return rebuildSearchIndexByPO(tx, &mindmapPO)
+	})
+}
+
+// PurgeMindMap 彻底删除回收站中的思维导图（物理删除）
+func (m *mindMapPersistence) PurgeMindMap(ctx context.Context, mapID string) error {
+	if mapID == "" {
+		return fmt.Errorf("MapID is required for purge")
 	}
 
+	return m.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("map_id = ? AND is_deleted = 1", mapID).Delete(&po.MindMapPO{})
+		if result.Error != nil {
+			return fmt.Errorf("purge mindmap failed: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return repo.ErrMindMapNotFound
+		}
+		return purgeMindMapRelations(tx, []string{mapID})
+	})
+}
+
+// PurgeDeletedMindMaps 批量彻底删除超过保留期的回收站导图
+func (m *mindMapPersistence) PurgeDeletedMindMaps(ctx context.Context, deletedBefore time.Time, limit int) ([]repo.PurgedMindMap, error) {
+	var purged []repo.PurgedMindMap
+	err := m.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&po.MindMapPO{}).
+			Select("map_id", "user_id").
+			Where("is_deleted = 1 AND deleted_at < ?", deletedBefore).
+			Order("deleted_at").
+			Limit(limit).
+			Scan(&purged).Error; err != nil {
+			return fmt.Errorf("find expired mindmaps failed: %w", err)
+		}
+		if len(purged) == 0 {
+			return nil
+		}
+		mapIDs := make([]string, 0, len(purged))
+		for _, p := range purged {
+			mapIDs = append(mapIDs, p.MapID)
+		}
+		if err := tx.Where("map_id IN ? AND is_deleted = 1", mapIDs).Delete(&po.MindMapPO{}).Error; err != nil {
+			return fmt.Errorf("purge mindmaps failed: %w", err)
+		}
+		return purgeMindMapRelations(tx, mapIDs)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return purged, nil
+}
+
+// purgeMindMapRelations 删除导图关联的历史版本、搜索索引、分享链接、协作成员、评论、附件记录与会话
+func purgeMindMapRelations(tx *gorm.DB, mapIDs []string) error {
+	if err := deleteSearchIndex(tx, mapIDs); err != nil {
+		return err
+	}
+	if err := tx.Where("map_id IN ?", mapIDs).Delete(&po.MindMapRevisionPO{}).Error; err != nil {
+		return fmt.Errorf("purge mindmap revisions failed: %w", err)
+	}
+	if err := tx.Where("map_id IN ?", mapIDs).Delete(&po.MindMapSharePO{}).Error; err != nil {
+		return fmt.Errorf("purge mindmap shares failed: %w", err)
+	}
+	if err := tx.Where("map_id IN ?", mapIDs).Delete(&po.MindMapMemberPO{}).Error; err != nil {
+		return fmt.Errorf("purge mindmap members failed: %w", err)
+	}
+	if err := tx.Where("map_id IN ?", mapIDs).Delete(&po.MindMapTagPO{}).Error; err != nil {
+		return fmt.Errorf("purge mindmap tags failed: %w", err)
+	}
+	if err := tx.Where("map_id IN ?", mapIDs).Delete(&po.MindMapCommentPO{}).Error; err != nil {
+		return fmt.Errorf("purge mindmap comments failed: %w", err)
+	}
+	if err := tx.Where("map_id IN ?", mapIDs).Delete(&po.MindMapAttachmentPO{}).Error; err != nil {
+		return fmt.Errorf("purge mindmap attachments failed: %w", err)
+	}
+	if err := tx.Where("map_id IN ?", mapIDs).Delete(&po.ConversationPO{}).Error; err != nil {
+		return fmt.Errorf("purge mindmap conversations failed: %w", err)
+	}
 	return nil
 }
+
+// UpdateMindMapThumbnail 更新缩略图地址，不修改版本号和更新时间
+func (m *mindMapPersistence) UpdateMindMapThumbnail(ctx context.Context, mapID, thumbnail string) error {
+	if mapID == "" {
+		return fmt.Errorf("MapID is required")
+	}
+
+	return m.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&po.MindMapPO{}).
+			Where("map_id = ? AND is_deleted = 0", mapID).
+			UpdateColumn("thumbnail", thumbnail)
+
+		if result.Error != nil {
+			return fmt.Errorf("update mindmap thumbnail failed: %w", result.Error)
+		}
+		// 地址未变化时 MySQL 返回的影响行数为 0，因此不以影响行数判断导图是否存在，由调用方先行校验
+
+		return recordMindMapChanges(tx, []string{mapID})
+	})
+}
+
+// 一次性数据迁移名称
+const migrationBackfillNodeIDs = "backfill_node_ids"
+
+// runMigrationOnce 执行尚未完成的一次性数据迁移，成功后写入完成记录
+func (m *mindMapPersistence) runMigrationOnce(name string, migrate func() error) error {
+	var count int64
+	if err := m.db.Model(&po.MindMapMigrationPO{}).Where("name = ?", name).Count(&count).Error; err != nil {
+		return fmt.Errorf("check migration %s failed: %w", name, err)
+	}
+	if count > 0 {
+		return nil
+	}
+	if err := migrate(); err != nil {
+		return err
+	}
+	// 多实例同时启动时可能重复执行迁移，迁移本身可重入，完成记录只保留一条
+	return m.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&po.MindMapMigrationPO{Name: name}).Error
+}
+
+// backfillNodeIDs 为历史导图数据中缺少标识的节点分配标识（仅修改 data 字段，不产生新版本）
+// 无法处理的导图记录日志后跳过，不影响服务启动
+func (m *mindMapPersistence) backfillNodeIDs() error {
+	var mindmapPOs []po.MindMapPO
+	return m.db.Select("id", "map_id", "data").
+		FindInBatches(&mindmapPOs, 100, func(tx *gorm.DB, batch int) error {
+			for _, mindmapPO := range mindmapPOs {
+				var data entity.MindMapData
+				if err := json.Unmarshal([]byte(mindmapPO.Data), &data); err != nil {
+					zlog.Errorf("skip backfill node ids, mapID: %s, unmarshal failed: %v", mindmapPO.MapID, err)
+					continue
+				}
+				changed, err := data.EnsureNodeIDs()
+				if err != nil {
+					zlog.Errorf("skip backfill node ids, mapID: %s, assign failed: %v", mindmapPO.MapID, err)
+					continue
+				}
+				if !changed {
+					continue
+				}
+				dataBytes, err := json.Marshal(data)
+				if err != nil {
+					zlog.Errorf("skip backfill node ids, mapID: %s, marshal failed: %v", mindmapPO.MapID, err)
+					continue
+				}
+				if err := m.db.Model(&po.MindMapPO{}).
+					Where("map_id = ?", mindmapPO.MapID).
+					UpdateColumn("data", string(dataBytes)).Error; err != nil {
+					return err
+				}
+			}
+			return nil
+		}).Error
+}
@@ -0,0 +1,194 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+
+	"forge/biz/entity"
+	"forge/biz/repo"
+	"forge/infra/storage/po"
+	"forge/pkg/log/zlog"
+	mindmaptemplate "forge/template/mindmap"
+
+	"gorm.io/gorm"
+)
+
+// ListMindMapTemplates 获取系统模板与用户自己的模板，系统模板在前，同类按创建时间倒序（不包含模板数据）
+func (m *mindMapPersistence) ListMindMapTemplates(ctx context.Context, query repo.MindMapTemplateQuery) ([]*entity.MindMapTemplate, int64, error) {
+	if query.UserID == "" {
+		return nil, 0, fmt.Errorf("UserID is required")
+	}
+
+	db := m.db.WithContext(ctx).Model(&po.MindMapTemplatePO{})
+	switch query.Scope {
+	case repo.MindMapTemplateScopeSystem:
+		db = db.Where("user_id = ''")
+	case repo.MindMapTemplateScopeMine:
+		db = db.Where("user_id = ?", query.UserID)
+	default:
+		db = db.Where("user_id = '' OR user_id = ?", query.UserID)
+	}
+	if query.Category != "" {
+		db = db.Where("category = ?", query.Category)
+	}
+
+	var total int64
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("count mindmap templates failed: %w", err)
+	}
+
+	db = db.Omit("data").Order("user_id = '' DESC").Order("created_at DESC").Order("id DESC")
+	if query.Page > 0 && query.PageSize > 0 {
+		offset := (query.Page - 1) * query.PageSize
+		db = db.Offset(offset).Limit(query.PageSize)
+	}
+
+	var templatePOs []po.MindMapTemplatePO
+	if err := db.Find(&templatePOs).Error; err != nil {
+		return nil, 0, fmt.Errorf("list mindmap templates failed: %w", err)
+	}
+
+	templates := make([]*entity.MindMapTemplate, 0, len(templatePOs))
+	for i := range templatePOs {
+		template, err := CastMindMapTemplatePO2DO(&templatePOs[i])
+		if err != nil {
+			zlog.CtxErrorf(ctx, "failed to cast template PO to DO, templateID: %s: %v", templatePOs[i].TemplateID, err)
+			continue
+		}
+		templates = append(templates, template)
+	}
+	return templates, total, nil
+}
+
+// GetMindMapTemplate 获取模板，不存在时返回 nil
+func (m *mindMapPersistence) GetMindMapTemplate(ctx context.Context, templateID string) (*entity.MindMapTemplate, error) {
+	if templateID == "" {
+		return nil, fmt.Errorf("TemplateID is required")
+	}
+
+	var templatePO po.MindMapTemplatePO
+	if err := m.db.WithContext(ctx).Where("template_id = ?", templateID).First(&templatePO).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("get mindmap template failed: %w", err)
+	}
+	return CastMindMapTemplatePO2DO(&templatePO)
+}
+
+// CreateMindMapTemplate 创建用户模板
+func (m *mindMapPersistence) CreateMindMapTemplate(ctx context.Context, template *entity.MindMapTemplate) error {
+	if template == nil || template.UserID == "" {
+		return fmt.Errorf("template and UserID are required")
+	}
+
+	templatePO, err := CastMindMapTemplateDO2PO(template)
+	if err != nil {
+		return err
+	}
+	if err := m.db.WithContext(ctx).Create(templatePO).Error; err != nil {
+		return fmt.Errorf("create mindmap template failed: %w", err)
+	}
+	template.CreatedAt = *templatePO.CreatedAt
+	template.UpdatedAt = *templatePO.UpdatedAt
+	return nil
+}
+
+// DeleteMindMapTemplate 删除用户模板，系统模板与他人模板返回 repo.ErrMindMapTemplateNotFound
+func (m *mindMapPersistence) DeleteMindMapTemplate(ctx context.Context, templateID, userID string) error {
+	if templateID == "" || userID == "" {
+		return fmt.Errorf("TemplateID and UserID are required")
+	}
+
+	result := m.db.WithContext(ctx).
+		Where("template_id = ? AND user_id = ?", templateID, userID).
+		Delete(&po.MindMapTemplatePO{})
+	if result.Error != nil {
+		return fmt.Errorf("delete mindmap template failed: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return repo.ErrMindMapTemplateNotFound
+	}
+	return nil
+}
+
+// systemTemplateFile 内置系统模板文件格式
+type systemTemplateFile struct {
+	TemplateID string             `json:"templateId"`
+	Name       string             `json:"name"`
+	Desc       string             `json:"desc"`
+	Category   string             `json:"category"`
+	Layout     string             `json:"layout"`
+	Root       systemTemplateNode `json:"root"`
+}
+
+type systemTemplateNode struct {
+	Text     string               `json:"text"`
+	Children []systemTemplateNode `json:"children"`
+}
+
+func (n systemTemplateNode) toMindMapData() entity.MindMapData {
+	data := entity.MindMapData{Data: entity.NodeData{Text: n.Text}}
+	for _, child := range n.Children {
+		data.Children = append(data.Children, child.toMindMapData())
+	}
+	return data
+}
+
+// syncSystemTemplates 将内置系统模板同步到数据库，已存在的系统模板以内置内容为准
+func (m *mindMapPersistence) syncSystemTemplates() error {
+	files, err := fs.Glob(mindmaptemplate.SystemTemplates, "*.json")
+	if err != nil {
+		return err
+	}
+
+	for _, file := range files {
+		content, err := fs.ReadFile(mindmaptemplate.SystemTemplates, file)
+		if err != nil {
+			return err
+		}
+		var tf systemTemplateFile
+		if err := json.Unmarshal(content, &tf); err != nil {
+			return fmt.Errorf("parse system template %s failed: %w", file, err)
+		}
+		if tf.TemplateID == "" || tf.Name == "" || tf.Layout == "" {
+			return fmt.Errorf("system template %s is missing templateId, name or layout", file)
+		}
+
+		templatePO, err := CastMindMapTemplateDO2PO(&entity.MindMapTemplate{
+			TemplateID: tf.TemplateID,
+			Name:       tf.Name,
+			Desc:       tf.Desc,
+			Category:   tf.Category,
+			Layout:     tf.Layout,
+			Data:       tf.Root.toMindMapData(),
+		})
+		if err != nil {
+			return err
+		}
+
+		var existing po.MindMapTemplatePO
+		err = m.db.Where("template_id = ?", tf.TemplateID).First(&existing).Error
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			err = m.db.Create(templatePO).Error
+		case err == nil:
+			err = m.db.Model(&existing).Updates(map[string]interface{}{
+				"name":     templatePO.Name,
+				"desc":     templatePO.Desc,
+				"category": templatePO.Category,
+				"layout":   templatePO.Layout,
+				"data":     templatePO.Data,
+			}).Error
+		}
+		if err != nil {
+			return fmt.Errorf("sync system template %s failed: %w", tf.TemplateID, err)
+		}
+	}
+
+	zlog.Infof("system mindmap templates synced, count: %d", len(files))
+	return nil
+}
@@ -0,0 +1,33 @@
+package po
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// MindMapAttachmentPO 导图节点附件持久化对象
+type MindMapAttachmentPO struct {
+	ID           uint64     `gorm:"column:id;primaryKey;autoIncrement" json:"id"`
+	AttachmentID string     `gorm:"column:attachment_id;type:varchar(64);uniqueIndex" json:"attachment_id"`
+	MapID        string     `gorm:"column:map_id;type:varchar(64);index:idx_map_node,priority:1" json:"map_id"`
+	NodeID       string     `gorm:"column:node_id;type:varchar(64);index:idx_map_node,priority:2" json:"node_id"`
+	UserID       string     `gorm:"column:user_id;type:varchar(64);index" json:"user_id"` // 上传者
+	FileName     string     `gorm:"column:file_name;type:varchar(255)" json:"file_name"`
+	ContentType  string     `gorm:"column:content_type;type:varchar(128)" json:"content_type"`
+	Kind         string     `gorm:"column:kind;type:varchar(20)" json:"kind"`
+	Size         int64      `gorm:"column:size" json:"size"`
+	ObjectKey    string     `gorm:"column:object_key;type:varchar(512)" json:"object_key"`
+	URL          string     `gorm:"column:url;type:varchar(1024)" json:"url"`
+	CreatedAt    *time.Time `gorm:"column:created_at" json:"created_at"`
+}
+
+func (MindMapAttachmentPO) TableName() string {
+	return "achobeta_forge_mindmap_attachment"
+}
+
+func (m *MindMapAttachmentPO) BeforeCreate(tx *gorm.DB) error {
+	now := time.Now()
+	m.CreatedAt = &now
+	return nil
+}
@@ -0,0 +1,25 @@
+package po
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// MindMapChangePO 导图变更记录 - 每次变更为每个受影响的成员写入一行，自增主键作为同步序号
+type MindMapChangePO struct {
+	ID        uint64     `gorm:"column:id;primaryKey;autoIncrement;index:idx_user_change,priority:2" json:"id"`
+	UserID    string     `gorm:"column:user_id;type:varchar(64);index:idx_user_change,priority:1" json:"user_id"`
+	MapID     string     `gorm:"column:map_id;type:varchar(64)" json:"map_id"`
+	CreatedAt *time.Time `gorm:"column:created_at;index" json:"created_at"`
+}
+
+func (MindMapChangePO) TableName() string {
+	return "achobeta_forge_mindmap_change"
+}
+
+func (m *MindMapChangePO) BeforeCreate(tx *gorm.DB) error {
+	now := time.Now()
+	m.CreatedAt = &now
+	return nil
+}
@@ -0,0 +1,34 @@
+package po
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// MindMapCommentPO 导图节点评论持久化对象
+type MindMapCommentPO struct {
+	ID         uint64     `gorm:"column:id;primaryKey;autoIncrement" json:"id"`
+	CommentID  string     `gorm:"column:comment_id;type:varchar(64);uniqueIndex" json:"comment_id"`
+	MapID      string     `gorm:"column:map_id;type:varchar(64);index:idx_map_node,priority:1" json:"map_id"`
+	NodeID     string     `gorm:"column:node_id;type:varchar(64);index:idx_map_node,priority:2" json:"node_id"`
+	ParentID   string     `gorm:"column:parent_id;type:varchar(64);not null;default:'';index" json:"parent_id"` // 线程首条评论为空
+	UserID     string     `gorm:"column:user_id;type:varchar(64)" json:"user_id"`
+	Content    string     `gorm:"column:content;type:text" json:"content"`
+	Mentions   *string    `gorm:"column:mentions;type:json" json:"mentions"` // 被@的用户ID，JSON数组字符串存储，没有时为NULL
+	Resolved   bool       `gorm:"column:resolved;not null;default:false" json:"resolved"`
+	ResolvedBy string     `gorm:"column:resolved_by;type:varchar(64);not null;default:''" json:"resolved_by"`
+	ResolvedAt *time.Time `gorm:"column:resolved_at" json:"resolved_at"`
+	EditedAt   *time.Time `gorm:"column:edited_at" json:"edited_at"`
+	CreatedAt  *time.Time `gorm:"column:created_at" json:"created_at"`
+}
+
+func (MindMapCommentPO) TableName() string {
+	return "achobeta_forge_mindmap_comment"
+}
+
+func (m *MindMapCommentPO) BeforeCreate(tx *gorm.DB) error {
+	now := time.Now()
+	m.CreatedAt = &now
+	return nil
+}
@@ -0,0 +1,54 @@
+package po
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// MindMapFolderPO 导图文件夹持久化对象
+type MindMapFolderPO struct {
+	ID        uint64     `gorm:"column:id;primaryKey;autoIncrement" json:"id"`
+	FolderID  string     `gorm:"column:folder_id;type:varchar(64);uniqueIndex" json:"folder_id"`
+	UserID    string     `gorm:"column:user_id;type:varchar(64);index" json:"user_id"`
+	ParentID  string     `gorm:"column:parent_id;type:varchar(64);not null;default:''" json:"parent_id"` // 顶层为空
+	Name      string     `gorm:"column:name;type:varchar(200)" json:"name"`
+	CreatedAt *time.Time `gorm:"column:created_at" json:"created_at"`
+	UpdatedAt *time.Time `gorm:"column:updated_at" json:"updated_at"`
+}
+
+func (MindMapFolderPO) TableName() string {
+	return "achobeta_forge_mindmap_folder"
+}
+
+func (m *MindMapFolderPO) BeforeCreate(tx *gorm.DB) error {
+	now := time.Now()
+	m.CreatedAt = &now
+	m.UpdatedAt = &now
+	return nil
+}
+
+func (m *MindMapFolderPO) BeforeUpdate(tx *gorm.DB) error {
+	now := time.Now()
+	m.UpdatedAt = &now
+	return nil
+}
+
+// MindMapTagPO 用户为导图设置的标签，每个导图每个用户每个标签一条记录
+type MindMapTagPO struct {
+	ID        uint64     `gorm:"column:id;primaryKey;autoIncrement" json:"id"`
+	UserID    string     `gorm:"column:user_id;type:varchar(64);uniqueIndex:uk_user_map_tag,priority:1;index:idx_user_tag,priority:1" json:"user_id"`
+	MapID     string     `gorm:"column:map_id;type:varchar(64);uniqueIndex:uk_user_map_tag,priority:2;index" json:"map_id"`
+	Tag       string     `gorm:"column:tag;type:varchar(100);uniqueIndex:uk_user_map_tag,priority:3;index:idx_user_tag,priority:2" json:"tag"`
+	CreatedAt *time.Time `gorm:"column:created_at" json:"created_at"`
+}
+
+func (MindMapTagPO) TableName() string {
+	return "achobeta_forge_mindmap_tag"
+}
+
+func (m *MindMapTagPO) BeforeCreate(tx *gorm.DB) error {
+	now := time.Now()
+	m.CreatedAt = &now
+	return nil
+}
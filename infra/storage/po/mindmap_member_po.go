@@ -0,0 +1,38 @@
+package po
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// MindMapMemberPO 思维导图协作成员持久化对象，每个导图每个用户一条记录，同时保存该成员对导图的整理信息
+type MindMapMemberPO struct {
+	ID        uint64     `gorm:"column:id;primaryKey;autoIncrement" json:"id"`
+	MapID     string     `gorm:"column:map_id;type:varchar(64);uniqueIndex:uk_map_user,priority:1" json:"map_id"`
+	UserID    string     `gorm:"column:user_id;type:varchar(64);uniqueIndex:uk_map_user,priority:2;index" json:"user_id"`
+	Role      string     `gorm:"column:role;type:varchar(16)" json:"role"` // owner、editor、viewer
+	InvitedBy string     `gorm:"column:invited_by;type:varchar(64)" json:"invited_by"`
+	FolderID  string     `gorm:"column:folder_id;type:varchar(64);not null;default:''" json:"folder_id"` // 成员将导图归入的文件夹，顶层为空
+	StarredAt *time.Time `gorm:"column:starred_at" json:"starred_at"`                                    // 收藏时间，未收藏为NULL
+	PinnedAt  *time.Time `gorm:"column:pinned_at" json:"pinned_at"`                                      // 置顶时间，未置顶为NULL
+	CreatedAt *time.Time `gorm:"column:created_at" json:"created_at"`
+	UpdatedAt *time.Time `gorm:"column:updated_at" json:"updated_at"`
+}
+
+func (MindMapMemberPO) TableName() string {
+	return "achobeta_forge_mindmap_member"
+}
+
+func (m *MindMapMemberPO) BeforeCreate(tx *gorm.DB) error {
+	now := time.Now()
+	m.CreatedAt = &now
+	m.UpdatedAt = &now
+	return nil
+}
+
+func (m *MindMapMemberPO) BeforeUpdate(tx *gorm.DB) error {
+	now := time.Now()
+	m.UpdatedAt = &now
+	return nil
+}
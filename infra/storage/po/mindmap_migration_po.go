@@ -0,0 +1,24 @@
+package po
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// MindMapMigrationPO 已完成的一次性数据迁移，存在记录时启动时不再执行
+type MindMapMigrationPO struct {
+	ID        uint64     `gorm:"column:id;primaryKey;autoIncrement" json:"id"`
+	Name      string     `gorm:"column:name;type:varchar(64);uniqueIndex" json:"name"`
+	CreatedAt *time.Time `gorm:"column:created_at" json:"created_at"`
+}
+
+func (MindMapMigrationPO) TableName() string {
+	return "achobeta_forge_mindmap_migration"
+}
+
+func (m *MindMapMigrationPO) BeforeCreate(tx *gorm.DB) error {
+	now := time.Now()
+	m.CreatedAt = &now
+	return nil
+}
@@ -0,0 +1,30 @@
+package po
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// MindMapRevisionPO 思维导图历史版本持久化对象 - 只插入不更新
+type MindMapRevisionPO struct {
+	ID        uint64     `gorm:"column:id;primaryKey;autoIncrement" json:"id"`
+	MapID     string     `gorm:"column:map_id;type:varchar(64);uniqueIndex:uk_map_version,priority:1" json:"map_id"`
+	Version   int64      `gorm:"column:version;uniqueIndex:uk_map_version,priority:2" json:"version"`
+	UserID    string     `gorm:"column:user_id;type:varchar(64)" json:"user_id"` // 产生该版本的用户
+	Title     string     `gorm:"column:title;type:varchar(100)" json:"title"`
+	Desc      string     `gorm:"column:desc;type:varchar(500)" json:"desc"`
+	Data      string     `gorm:"column:data;type:json" json:"data"`
+	Layout    string     `gorm:"column:layout;type:varchar(50)" json:"layout"`
+	CreatedAt *time.Time `gorm:"column:created_at" json:"created_at"`
+}
+
+func (MindMapRevisionPO) TableName() string {
+	return "achobeta_forge_mindmap_revision"
+}
+
+func (m *MindMapRevisionPO) BeforeCreate(tx *gorm.DB) error {
+	now := time.Now()
+	m.CreatedAt = &now
+	return nil
+}
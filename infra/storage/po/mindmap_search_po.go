@@ -0,0 +1,15 @@
+package po
+
+// MindMapSearchIndexPO 思维导图搜索索引 - 每个标题、描述、节点文本一行，随导图写入整体重建
+type MindMapSearchIndexPO struct {
+	ID      uint64 `gorm:"column:id;primaryKey;autoIncrement" json:"id"`
+	MapID   string `gorm:"column:map_id;type:varchar(64);index" json:"map_id"`
+	Field   string `gorm:"column:field;type:varchar(16)" json:"field"`       // title、desc、node
+	NodeUID string `gorm:"column:node_uid;type:varchar(64)" json:"node_uid"` // 命中节点标识
+	Path    string `gorm:"column:path;type:varchar(1024)" json:"path"`       // 节点路径，下标以逗号分隔，根节点为空
+	Content string `gorm:"column:content;type:text" json:"content"`
+}
+
+func (MindMapSearchIndexPO) TableName() string {
+	return "achobeta_forge_mindmap_search_index"
+}
@@ -0,0 +1,29 @@
+package po
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// MindMapSharePO 思维导图分享链接持久化对象，撤销时直接删除
+type MindMapSharePO struct {
+	ID           uint64     `gorm:"column:id;primaryKey;autoIncrement" json:"id"`
+	ShareID      string     `gorm:"column:share_id;type:varchar(64);uniqueIndex" json:"share_id"`
+	Token        string     `gorm:"column:token;type:varchar(64);uniqueIndex" json:"token"`
+	MapID        string     `gorm:"column:map_id;type:varchar(64);index" json:"map_id"`
+	UserID       string     `gorm:"column:user_id;type:varchar(64);index" json:"user_id"`
+	PasswordHash string     `gorm:"column:password_hash;type:varchar(255)" json:"-"`
+	ExpiresAt    *time.Time `gorm:"column:expires_at" json:"expires_at"`
+	CreatedAt    *time.Time `gorm:"column:created_at" json:"created_at"`
+}
+
+func (MindMapSharePO) TableName() string {
+	return "achobeta_forge_mindmap_share"
+}
+
+func (m *MindMapSharePO) BeforeCreate(tx *gorm.DB) error {
+	now := time.Now()
+	m.CreatedAt = &now
+	return nil
+}
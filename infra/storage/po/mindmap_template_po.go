@@ -0,0 +1,38 @@
+package po
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// MindMapTemplatePO 思维导图模板持久化对象，系统模板的 user_id 为空
+type MindMapTemplatePO struct {
+	ID         uint64     `gorm:"column:id;primaryKey;autoIncrement" json:"id"`
+	TemplateID string     `gorm:"column:template_id;type:varchar(64);uniqueIndex" json:"template_id"`
+	UserID     string     `gorm:"column:user_id;type:varchar(64);index" json:"user_id"`
+	Name       string     `gorm:"column:name;type:varchar(100)" json:"name"`
+	Desc       string     `gorm:"column:desc;type:varchar(500)" json:"desc"`
+	Category   string     `gorm:"column:category;type:varchar(50);index" json:"category"`
+	Layout     string     `gorm:"column:layout;type:varchar(50)" json:"layout"`
+	Data       string     `gorm:"column:data;type:json" json:"data"`
+	CreatedAt  *time.Time `gorm:"column:created_at" json:"created_at"`
+	UpdatedAt  *time.Time `gorm:"column:updated_at" json:"updated_at"`
+}
+
+func (MindMapTemplatePO) TableName() string {
+	return "achobeta_forge_mindmap_template"
+}
+
+func (m *MindMapTemplatePO) BeforeCreate(tx *gorm.DB) error {
+	now := time.Now()
+	m.CreatedAt = &now
+	m.UpdatedAt = &now
+	return nil
+}
+
+func (m *MindMapTemplatePO) BeforeUpdate(tx *gorm.DB) error {
+	now := time.Now()
+	m.UpdatedAt = &now
+	return nil
+}
@@ -1,6 +1,7 @@
 package initalize
 
 import (
+	"context"
 	_ "embed"
 	"fmt"
 	"forge/biz/aichatservice"
@@ -16,11 +17,15 @@ import (
 	"forge/infra/eino"
 	"forge/infra/notification"
 	"forge/infra/oauth"
+	"forge/infra/office"
+	"forge/infra/render"
 	"forge/infra/storage"
 	"forge/interface/handler"
 	"forge/interface/router"
 	"forge/pkg/log"
 	"github.com/unidoc/unioffice/v2/common/license"
+	pdflicense "github.com/unidoc/unipdf/v4/common/license"
+	"time"
 
 	// "forge/pkg/loop"
 	"forge/util"
@@ -44,18 +49,18 @@ func Init() {
 	oauthConfig := configs.Config().GetOAuthConfig()
 	oauth.InitGoth(oauthConfig)
 
-	storage.InitUserStorage()
-	storage.InitMindMapStorage()
-	storage.InitAiChatStorage()
-	storage.InitGenerationStorage() // 初始化生成相关存储
-
-	// snowflake - 从配置文件读取节点ID
+	// snowflake - 从配置文件读取节点ID（存储初始化时补齐节点标识需要使用）
 	snowflakeConfig := configs.Config().GetSnowflakeConfig()
 	if err := util.InitSnowflake(snowflakeConfig.NodeID); err != nil {
 		// 初始化失败，直接 panic 提示原因
 		panic(fmt.Sprintf("init snowflake failed: %v", err))
 	}
 
+	storage.InitUserStorage()
+	storage.InitMindMapStorage()
+	storage.InitAiChatStorage()
+	storage.InitGenerationStorage() // 初始化生成相关存储
+
 	// 从配置文件读取JWT配置并创建JWTUtil
 	jwtConfig := configs.Config().GetJWTConfig()
 	jwtUtil := util.NewJWTUtil(jwtConfig.SecretKey, jwtConfig.ExpireHours)
@@ -66,12 +71,27 @@ func Init() {
 	cosConfig := configs.Config().GetCOSConfig()
 	cosService := cos.NewCOSService(cosConfig)
 
-	mms := mindmapservice.NewMindMapServiceImpl(storage.GetMindMapPersistence())
+	// 依赖注入：创建导图渲染器（导出图片、生成缩略图）
+	renderConfig := configs.Config().GetRenderConfig()
+	renderer := render.NewMindMapRenderer(renderConfig)
+	// 依赖注入：创建导图文档导出器（导出 PDF、DOCX、PPTX）
+	documentExporter := office.NewDocumentExporter(renderConfig)
+
+	// 依赖注入：创建限流计数器（分享链接访问限流与密码错误锁定）
+	rateLimiter := cache.NewRateLimiter()
+
+	mms := mindmapservice.NewMindMapServiceImpl(storage.GetMindMapPersistence(), storage.GetUserPersistence(), renderer, documentExporter, cosService, notification.GetNotificationService(), cache.NewCollabBroker(), rateLimiter)
 	cs := cosservice.NewCOSServiceImpl(cosService, cosConfig)
 
+	// 启动回收站清理任务
+	mindMapConfig := configs.Config().GetMindMapConfig()
+	mms.StartTrashPurger(context.Background(), time.Duration(mindMapConfig.TrashRetentionDays)*24*time.Hour, mindMapConfig.TrashPurgeInterval)
+	// 启动增量同步变更记录清理任务
+	mms.StartChangePruner(context.Background(), time.Duration(mindMapConfig.SyncRetentionDays)*24*time.Hour)
+
 	// 依赖注入: 创建ai服务实例
 	aiConfig := configs.Config().GetAiChatConfig()
-	acs := aichatservice.NewAiChatService(storage.GetAiChatPersistence(), eino.NewAiChatClient(aiConfig.ApiKey, aiConfig.ModelName))
+	acs := aichatservice.NewAiChatService(storage.GetAiChatPersistence(), eino.NewAiChatClient(aiConfig.ApiKey, aiConfig.ModelName), storage.GetMindMapPersistence())
 
 	// 依赖注入: 创建generation服务实例
 	gs := generationservice.NewGenerationService(storage.GetGenerationPersistence(), storage.GetAiChatPersistence(), storage.GetMindMapPersistence())
@@ -82,10 +102,15 @@ func Init() {
 	uniOfficeConfig := configs.Config().GetUniOfficeConfig()
 	license.SetMeteredKey(uniOfficeConfig.MeteredKey)
 	license.SetMeteredKey("uniapi") //文件解析初始化44
+	// 导出 PDF 需要 unipdf 的授权，与 unioffice 共用同一个 metered key
+	pdflicense.SetMeteredKey(uniOfficeConfig.MeteredKey)
 
 	// 初始化JWT鉴权中间件
 	router.InitJWTAuth(us)
 
+	// 初始化限流中间件使用的计数器
+	router.InitRateLimiter(rateLimiter)
+
 }
 func initPath() string {
 	return util.GetRootPath("")
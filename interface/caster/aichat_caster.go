@@ -14,7 +14,25 @@ func CastProcessUserMessageReq2Params(req *def.ProcessUserMessageRequest) *types
 	return &types.ProcessUserMessageParams{
 		ConversationID: req.ConversationID,
 		Message:        req.Content,
-		MapData:        req.MapData,
+	}
+}
+
+func CastAgentResponse2Resp(aiMsg types.AgentResponse) *def.ProcessUserMessageResponse {
+	return &def.ProcessUserMessageResponse{
+		Content:    aiMsg.Content,
+		NewMapJson: aiMsg.NewMapJson,
+		Diff:       CastMindMapDiffDO2DTO(aiMsg.Diff),
+		MapVersion: aiMsg.MapVersion,
+		Success:    true,
+	}
+}
+
+func CastAgentEvent2DTO(event types.AgentEvent) *def.AiChatStreamEvent {
+	return &def.AiChatStreamEvent{
+		Content:    event.Content,
+		ToolCallID: event.ToolCallID,
+		ToolName:   event.ToolName,
+		Arguments:  event.Arguments,
 	}
 }
 
@@ -23,9 +41,8 @@ func CastSaveNewConversationReq2Params(req *def.SaveNewConversationRequest) *typ
 		return nil
 	}
 	return &types.SaveNewConversationParams{
-		Title:   req.Title,
-		MapID:   req.MapID,
-		MapData: req.MapData,
+		Title: req.Title,
+		MapID: req.MapID,
 	}
 }
 
@@ -32,9 +32,10 @@ func CastUpdateMindMapReq2Params(req *def.UpdateMindMapReq) *types.UpdateMindMap
 	}
 
 	params := &types.UpdateMindMapParams{
-		Title:  req.Title,
-		Desc:   req.Desc,
-		Layout: req.Layout,
+		Title:   req.Title,
+		Desc:    req.Desc,
+		Layout:  req.Layout,
+		Version: req.Version,
 	}
 
 	// 处理Root字段的转换
@@ -46,6 +47,68 @@ func CastUpdateMindMapReq2Params(req *def.UpdateMindMapReq) *types.UpdateMindMap
 	return params
 }
 
+// CastImportMindMapReq2Params DTO -> Service 层参数表单转换
+func CastImportMindMapReq2Params(req *def.ImportMindMapReq) *types.ImportMindMapParams {
+	if req == nil {
+		return nil
+	}
+	return &types.ImportMindMapParams{
+		File:   req.File,
+		Format: req.Format,
+		Title:  req.Title,
+		Layout: req.Layout,
+	}
+}
+
+// CastPatchMindMapReq2Params DTO -> Service 层参数表单转换
+func CastPatchMindMapReq2Params(req *def.PatchMindMapReq) *types.PatchMindMapParams {
+	if req == nil {
+		return nil
+	}
+	return &types.PatchMindMapParams{
+		Ops:     gslice.Map(req.Ops, CastMindMapPatchOpDTO2DO),
+		Version: req.Version,
+	}
+}
+
+// CastMindMapPatchOpDTO2DO 节点补丁操作DTO转实体
+func CastMindMapPatchOpDTO2DO(op def.MindMapPatchOp) entity.MindMapPatchOp {
+	patchOp := entity.MindMapPatchOp{
+		Op:         op.Op,
+		NodeID:     op.NodeID,
+		Path:       op.Path,
+		ParentID:   op.ParentID,
+		ParentPath: op.ParentPath,
+		Index:      op.Index,
+		Text:       op.Text,
+		Order:      op.Order,
+	}
+	if op.Node != nil {
+		node := CastMindMapDataDTO2DO(*op.Node)
+		patchOp.Node = &node
+	}
+	return patchOp
+}
+
+// CastMindMapPatchOpDO2DTO 节点补丁操作实体转DTO
+func CastMindMapPatchOpDO2DTO(op entity.MindMapPatchOp) def.MindMapPatchOp {
+	dto := def.MindMapPatchOp{
+		Op:         op.Op,
+		NodeID:     op.NodeID,
+		Path:       op.Path,
+		ParentID:   op.ParentID,
+		ParentPath: op.ParentPath,
+		Index:      op.Index,
+		Text:       op.Text,
+		Order:      op.Order,
+	}
+	if op.Node != nil {
+		node := CastMindMapDataDO2DTO(*op.Node)
+		dto.Node = &node
+	}
+	return dto
+}
+
 // CastListMindMapsReq2Params DTO -> Service 层参数表单转换
 func CastListMindMapsReq2Params(req *def.ListMindMapsReq) *types.ListMindMapsParams {
 	if req == nil {
@@ -54,8 +117,91 @@ func CastListMindMapsReq2Params(req *def.ListMindMapsReq) *types.ListMindMapsPar
 	return &types.ListMindMapsParams{
 		Title:    req.Title,
 		Layout:   req.Layout,
+		Role:     req.Role,
+		FolderID: req.FolderID,
+		Tags:     req.Tags,
+		Starred:  req.Starred,
+
+		SortBy:      req.SortBy,
+		SortOrder:   req.SortOrder,
+		CreatedFrom: req.CreatedFrom,
+		CreatedTo:   req.CreatedTo,
+		UpdatedFrom: req.UpdatedFrom,
+		UpdatedTo:   req.UpdatedTo,
+
 		Page:     req.Page,
 		PageSize: req.PageSize,
+		Cursor:   req.Cursor,
+		Limit:    req.Limit,
+	}
+}
+
+// CastSyncMindMapsReq2Params DTO -> Service 层参数表单转换
+func CastSyncMindMapsReq2Params(req *def.SyncMindMapsReq) *types.SyncMindMapsParams {
+	if req == nil {
+		return nil
+	}
+	return &types.SyncMindMapsParams{
+		Cursor: req.Cursor,
+		Limit:  req.Limit,
+	}
+}
+
+// CastMindMapSyncResult2Resp 增量同步结果 -> DTO
+func CastMindMapSyncResult2Resp(result *types.MindMapSyncResult) *def.SyncMindMapsResp {
+	if result == nil {
+		return nil
+	}
+	return &def.SyncMindMapsResp{
+		Changed:    CastMindMapDOs2DTOs(result.Changed),
+		Deleted:    result.Deleted,
+		NextCursor: result.Cursor,
+		HasMore:    result.HasMore,
+	}
+}
+
+// CastListMindMapRevisionsReq2Params DTO -> Service 层参数表单转换
+func CastListMindMapRevisionsReq2Params(req *def.ListMindMapRevisionsReq) *types.ListMindMapRevisionsParams {
+	if req == nil {
+		return nil
+	}
+	return &types.ListMindMapRevisionsParams{
+		Page:     req.Page,
+		PageSize: req.PageSize,
+	}
+}
+
+// CastRestoreMindMapRevisionReq2Params DTO -> Service 层参数表单转换
+func CastRestoreMindMapRevisionReq2Params(version int64, req *def.RestoreMindMapRevisionReq) *types.RestoreMindMapRevisionParams {
+	params := &types.RestoreMindMapRevisionParams{
+		Version: version,
+	}
+	if req != nil {
+		params.ExpectedVersion = req.Version
+	}
+	return params
+}
+
+// CastDiffMindMapReq2Params DTO -> Service 层参数表单转换
+func CastDiffMindMapReq2Params(req *def.DiffMindMapReq) *types.DiffMindMapParams {
+	if req == nil {
+		return nil
+	}
+	return &types.DiffMindMapParams{
+		FromVersion: req.From,
+		ToVersion:   req.To,
+	}
+}
+
+// CastDiffMindMapProposalReq2Params DTO -> Service 层参数表单转换
+func CastDiffMindMapProposalReq2Params(req *def.DiffMindMapProposalReq) *types.DiffMindMapParams {
+	if req == nil {
+		return nil
+	}
+	data := CastMindMapDataDTO2DO(req.Root)
+	return &types.DiffMindMapParams{
+		FromVersion: req.From,
+		Data:        &data,
 	}
 }
 
@@ -66,16 +212,47 @@ func CastMindMapDO2DTO(mindmap *entity.MindMap) *def.MindMapDTO {
 	if mindmap == nil {
 		return nil
 	}
-	return &def.MindMapDTO{
+	dto := &def.MindMapDTO{
 		MapID:     mindmap.MapID,
 		UserID:    mindmap.UserID,
 		Title:     mindmap.Title,
 		Desc:      mindmap.Desc,
 		Layout:    mindmap.Layout,
 		Root:      CastMindMapDataDO2DTO(mindmap.Data),
+		Version:   mindmap.Version,
+		Thumbnail: mindmap.Thumbnail,
+		Role:      mindmap.Role,
 		CreatedAt: formatTime(mindmap.CreatedAt),
 		UpdatedAt: formatTime(mindmap.UpdatedAt),
 	}
+	if mindmap.DeletedAt != nil {
+		dto.DeletedAt = formatTime(*mindmap.DeletedAt)
+	}
+	dto.Provenance = CastMindMapProvenanceDO2DTO(mindmap.Provenance)
+	dto.FolderID = mindmap.FolderID
+	dto.Tags = mindmap.Tags
+	dto.Starred = mindmap.Starred
+	dto.Pinned = mindmap.Pinned
+	return dto
+}
+
+// CastMindMapProvenanceDO2DTO 派生来源转DTO
+func CastMindMapProvenanceDO2DTO(provenance *entity.MindMapProvenance) *def.MindMapProvenanceDTO {
+	if provenance == nil {
+		return nil
+	}
+	return &def.MindMapProvenanceDTO{
+		Type: provenance.Type,
+		Sources: gslice.Map(provenance.Sources, func(source entity.MindMapSource) *def.MindMapSourceDTO {
+			return &def.MindMapSourceDTO{
+				MapID:   source.MapID,
+				Version: source.Version,
+				Title:   source.Title,
+			}
+		}),
+		NodeID:     provenance.NodeID,
+		TemplateID: provenance.TemplateID,
+	}
 }
 
 // CastMindMapDOs2DTOs 实体列表转DTO列表
@@ -83,6 +260,103 @@ func CastMindMapDOs2DTOs(mindmaps []*entity.MindMap) []*def.MindMapDTO {
 	return gslice.Map(mindmaps, CastMindMapDO2DTO)
 }
 
+// CastSearchMindMapsReq2Params 搜索请求转服务层参数
+func CastSearchMindMapsReq2Params(req *def.SearchMindMapsReq) *types.SearchMindMapsParams {
+	if req == nil {
+		return nil
+	}
+	return &types.SearchMindMapsParams{
+		Keyword:  req.Q,
+		Page:     req.Page,
+		PageSize: req.PageSize,
+	}
+}
+
+// CastMindMapSearchResultDO2DTO 搜索结果实体转DTO
+func CastMindMapSearchResultDO2DTO(result *entity.MindMapSearchResult) *def.MindMapSearchResultDTO {
+	if result == nil || result.MindMap == nil {
+		return nil
+	}
+	return &def.MindMapSearchResultDTO{
+		MapID:     result.MindMap.MapID,
+		Title:     result.MindMap.Title,
+		Desc:      result.MindMap.Desc,
+		Layout:    result.MindMap.Layout,
+		Version:   result.MindMap.Version,
+		Thumbnail: result.MindMap.Thumbnail,
+		UpdatedAt: formatTime(result.MindMap.UpdatedAt),
+		HitCount:  result.HitCount,
+		Hits: gslice.Map(result.Hits, func(hit entity.MindMapSearchHit) def.MindMapSearchHitDTO {
+			return def.MindMapSearchHitDTO{Field: hit.Field, UID: hit.NodeUID, Path: hit.Path, Text: hit.Text}
+		}),
+	}
+}
+
+// CastMindMapSearchResultDOs2DTOs 搜索结果实体列表转DTO列表
+func CastMindMapSearchResultDOs2DTOs(results []*entity.MindMapSearchResult) []*def.MindMapSearchResultDTO {
+	return gslice.Map(results, CastMindMapSearchResultDO2DTO)
+}
+
+// CastMindMapRevisionDO2DTO 历史版本实体转DTO（包含完整数据）
+func CastMindMapRevisionDO2DTO(revision *entity.MindMapRevision) *def.MindMapRevisionDTO {
+	dto := CastMindMapRevisionDO2SummaryDTO(revision)
+	if dto == nil {
+		return nil
+	}
+	root := CastMindMapDataDO2DTO(revision.Data)
+	dto.Root = &root
+	return dto
+}
+
+// CastMindMapRevisionDO2SummaryDTO 历史版本实体转摘要DTO（不包含数据）
+func CastMindMapRevisionDO2SummaryDTO(revision *entity.MindMapRevision) *def.MindMapRevisionDTO {
+	if revision == nil {
+		return nil
+	}
+	return &def.MindMapRevisionDTO{
+		MapID:     revision.MapID,
+		Version:   revision.Version,
+		UserID:    revision.UserID,
+		Title:     revision.Title,
+		Desc:      revision.Desc,
+		Layout:    revision.Layout,
+		CreatedAt: formatTime(revision.CreatedAt),
+	}
+}
+
+// CastMindMapRevisionDOs2SummaryDTOs 历史版本列表转摘要DTO列表
+func CastMindMapRevisionDOs2SummaryDTOs(revisions []*entity.MindMapRevision) []*def.MindMapRevisionDTO {
+	return gslice.Map(revisions, CastMindMapRevisionDO2SummaryDTO)
+}
+
+// CastMindMapDiffDO2DTO 导图结构差异实体转DTO
+func CastMindMapDiffDO2DTO(diff *entity.MindMapDiff) *def.MindMapDiffDTO {
+	if diff == nil {
+		return nil
+	}
+	return &def.MindMapDiffDTO{
+		Changes: gslice.Map(diff.Changes, func(change entity.MindMapNodeChange) *def.MindMapNodeChangeDTO {
+			return &def.MindMapNodeChangeDTO{
+				Type:         change.Type,
+				UID:          change.NodeID,
+				Text:         change.Text,
+				OldText:      change.OldText,
+				ParentUID:    change.ParentID,
+				OldParentUID: change.OldParentID,
+				Path:         change.Path,
+				OldPath:      change.OldPath,
+			}
+		}),
+		Summary: def.MindMapDiffSummaryDTO{
+			Added:   diff.Summary.Added,
+			Removed: diff.Summary.Removed,
+			Renamed: diff.Summary.Renamed,
+			Moved:   diff.Summary.Moved,
+			Updated: diff.Summary.Updated,
+		},
+	}
+}
+
 // CastMindMapDataDO2DTO 思维导图数据实体转DTO
 func CastMindMapDataDO2DTO(data entity.MindMapData) def.MindMapData {
 	return def.MindMapData{
@@ -102,17 +376,457 @@ func CastMindMapDataDTO2DO(data def.MindMapData) entity.MindMapData {
 // CastNodeDataDO2DTO 节点数据实体转DTO
 func CastNodeDataDO2DTO(data entity.NodeData) def.NodeData {
 	return def.NodeData{
-		Text: data.Text,
+		UID:             data.UID,
+		Text:            data.Text,
+		Note:            data.Note,
+		Hyperlink:       data.Hyperlink,
+		HyperlinkTitle:  data.HyperlinkTitle,
+		Tags:            data.Tags,
+		Color:           data.Color,
+		BackgroundColor: data.BackgroundColor,
+		Icon:            data.Icon,
+		Priority:        data.Priority,
+		Collapsed:       data.Collapsed,
+		Attachments:     data.Attachments,
 	}
 }
 
 // CastNodeDataDTO2DO 节点数据DTO转实体
 func CastNodeDataDTO2DO(data def.NodeData) entity.NodeData {
 	return entity.NodeData{
-		Text: data.Text,
+		UID:             data.UID,
+		Text:            data.Text,
+		Note:            data.Note,
+		Hyperlink:       data.Hyperlink,
+		HyperlinkTitle:  data.HyperlinkTitle,
+		Tags:            data.Tags,
+		Color:           data.Color,
+		BackgroundColor: data.BackgroundColor,
+		Icon:            data.Icon,
+		Priority:        data.Priority,
+		Collapsed:       data.Collapsed,
+		Attachments:     data.Attachments,
+	}
+}
+
+// CastCreateMindMapShareReq2Params 创建分享请求转服务层参数
+func CastCreateMindMapShareReq2Params(req *def.CreateMindMapShareReq) *types.CreateMindMapShareParams {
+	if req == nil {
+		return nil
+	}
+	return &types.CreateMindMapShareParams{
+		ExpiresIn: time.Duration(req.ExpiresIn) * time.Second,
+		Password:  req.Password,
+	}
+}
+
+// CastMindMapShareDO2DTO 分享链接实体转DTO
+func CastMindMapShareDO2DTO(share *entity.MindMapShare) *def.MindMapShareDTO {
+	if share == nil {
+		return nil
+	}
+	dto := &def.MindMapShareDTO{
+		ShareID:     share.ShareID,
+		MapID:       share.MapID,
+		Token:       share.Token,
+		HasPassword: share.HasPassword(),
+		CreatedAt:   formatTime(share.CreatedAt),
+	}
+	if share.ExpiresAt != nil {
+		dto.ExpiresAt = formatTime(*share.ExpiresAt)
+	}
+	return dto
+}
+
+// CastMindMapShareDOs2DTOs 分享链接实体列表转DTO列表
+func CastMindMapShareDOs2DTOs(shares []*entity.MindMapShare) []*def.MindMapShareDTO {
+	return gslice.Map(shares, CastMindMapShareDO2DTO)
+}
+
+// CastInviteMindMapMemberReq2Params 邀请协作成员请求转服务层参数
+func CastInviteMindMapMemberReq2Params(req *def.InviteMindMapMemberReq) *types.InviteMindMapMemberParams {
+	if req == nil {
+		return nil
+	}
+	return &types.InviteMindMapMemberParams{
+		Email: req.Email,
+		Phone: req.Phone,
+		Role:  req.Role,
+	}
+}
+
+// CastMindMapMemberDO2DTO 协作成员实体转DTO
+func CastMindMapMemberDO2DTO(member *entity.MindMapMember) *def.MindMapMemberDTO {
+	if member == nil {
+		return nil
+	}
+	return &def.MindMapMemberDTO{
+		UserID:    member.UserID,
+		UserName:  member.UserName,
+		Avatar:    member.Avatar,
+		Role:      member.Role,
+		InvitedBy: member.InvitedBy,
+		CreatedAt: formatTime(member.CreatedAt),
+	}
+}
+
+// CastMindMapMemberDOs2DTOs 协作成员实体列表转DTO列表
+func CastMindMapMemberDOs2DTOs(members []*entity.MindMapMember) []*def.MindMapMemberDTO {
+	return gslice.Map(members, CastMindMapMemberDO2DTO)
+}
+
+// CastCollabPresenceDO2DTO 实时协作在线状态实体转DTO
+func CastCollabPresenceDO2DTO(presence *entity.CollabPresence) *def.CollabPresenceDTO {
+	if presence == nil {
+		return nil
+	}
+	return &def.CollabPresenceDTO{
+		ConnID:       presence.ConnID,
+		UserID:       presence.UserID,
+		UserName:     presence.UserName,
+		Avatar:       presence.Avatar,
+		Role:         presence.Role,
+		CursorNodeID: presence.CursorNodeID,
+		JoinedAt:     formatTime(presence.JoinedAt),
+	}
+}
+
+// CastCollabSession2InitMessage 实时协作会话转 init 消息
+func CastCollabSession2InitMessage(session *types.CollabSession) *def.CollabMessage {
+	if session == nil {
+		return nil
+	}
+	return &def.CollabMessage{
+		Type:     def.CollabMsgInit,
+		ConnID:   session.ConnID,
+		Version:  session.MindMap.Version,
+		MindMap:  CastMindMapDO2DTO(session.MindMap),
+		Presence: gslice.Map(session.Presence, CastCollabPresenceDO2DTO),
 	}
 }
 
+// CastCollabOpMessage2Params op 消息转服务层参数
+func CastCollabOpMessage2Params(req *def.CollabMessage) *types.ApplyCollabOpsParams {
+	if req == nil {
+		return nil
+	}
+	return &types.ApplyCollabOpsParams{
+		BaseVersion: req.BaseVersion,
+		Ops:         gslice.Map(req.Ops, CastMindMapPatchOpDTO2DO),
+	}
+}
+
+// CastApplyCollabOpsResult2AckMessage 协作操作结果转 ack 消息
+func CastApplyCollabOpsResult2AckMessage(clientSeq int64, result *types.ApplyCollabOpsResult) *def.CollabMessage {
+	if result == nil {
+		return nil
+	}
+	return &def.CollabMessage{
+		Type:      def.CollabMsgAck,
+		ClientSeq: clientSeq,
+		Version:   result.Version,
+		Ops:       gslice.Map(result.Ops, CastMindMapPatchOpDO2DTO),
+	}
+}
+
+// CastCollabEventDO2Message 实时协作事件转推送给客户端的消息
+func CastCollabEventDO2Message(event *entity.CollabEvent) *def.CollabMessage {
+	if event == nil {
+		return nil
+	}
+	msg := &def.CollabMessage{
+		Type:    event.Type,
+		Version: event.Version,
+		ConnID:  event.ConnID,
+		UserID:  event.UserID,
+		Ops:     gslice.Map(event.Ops, CastMindMapPatchOpDO2DTO),
+	}
+	if event.Presence != nil {
+		msg.Presence = []*def.CollabPresenceDTO{CastCollabPresenceDO2DTO(event.Presence)}
+	}
+	return msg
+}
+
+// CastListMindMapTemplatesReq2Params DTO -> Service 层参数表单转换
+func CastListMindMapTemplatesReq2Params(req *def.ListMindMapTemplatesReq) *types.ListMindMapTemplatesParams {
+	if req == nil {
+		return nil
+	}
+	return &types.ListMindMapTemplatesParams{
+		Scope:    req.Scope,
+		Category: req.Category,
+		Page:     req.Page,
+		PageSize: req.PageSize,
+	}
+}
+
+// CastSaveMindMapAsTemplateReq2Params DTO -> Service 层参数表单转换
+func CastSaveMindMapAsTemplateReq2Params(req *def.SaveMindMapAsTemplateReq) *types.SaveMindMapAsTemplateParams {
+	if req == nil {
+		return nil
+	}
+	return &types.SaveMindMapAsTemplateParams{
+		Name:     req.Name,
+		Desc:     req.Desc,
+		Category: req.Category,
+	}
+}
+
+// CastCreateMindMapFromTemplateReq2Params DTO -> Service 层参数表单转换
+func CastCreateMindMapFromTemplateReq2Params(req *def.CreateMindMapFromTemplateReq) *types.CreateMindMapFromTemplateParams {
+	if req == nil {
+		return nil
+	}
+	return &types.CreateMindMapFromTemplateParams{
+		Title:  req.Title,
+		Desc:   req.Desc,
+		Layout: req.Layout,
+	}
+}
+
+// CastMindMapTemplateDO2DTO 模板实体转DTO（包含完整数据）
+func CastMindMapTemplateDO2DTO(template *entity.MindMapTemplate) *def.MindMapTemplateDTO {
+	dto := CastMindMapTemplateDO2SummaryDTO(template)
+	if dto == nil {
+		return nil
+	}
+	root := CastMindMapDataDO2DTO(template.Data)
+	dto.Root = &root
+	return dto
+}
+
+// CastMindMapTemplateDO2SummaryDTO 模板实体转摘要DTO（不包含数据）
+func CastMindMapTemplateDO2SummaryDTO(template *entity.MindMapTemplate) *def.MindMapTemplateDTO {
+	if template == nil {
+		return nil
+	}
+	return &def.MindMapTemplateDTO{
+		TemplateID: template.TemplateID,
+		Name:       template.Name,
+		Desc:       template.Desc,
+		Category:   template.Category,
+		Layout:     template.Layout,
+		System:     template.IsSystem(),
+		CreatedAt:  formatTime(template.CreatedAt),
+	}
+}
+
+// CastMindMapTemplateDOs2SummaryDTOs 模板列表转摘要DTO列表
+func CastMindMapTemplateDOs2SummaryDTOs(templates []*entity.MindMapTemplate) []*def.MindMapTemplateDTO {
+	return gslice.Map(templates, CastMindMapTemplateDO2SummaryDTO)
+}
+
+// CastDuplicateMindMapReq2Params DTO -> Service 层参数表单转换
+func CastDuplicateMindMapReq2Params(req *def.DuplicateMindMapReq) *types.DuplicateMindMapParams {
+	if req == nil {
+		return nil
+	}
+	return &types.DuplicateMindMapParams{
+		Title: req.Title,
+	}
+}
+
+// CastExtractMindMapSubtreeReq2Params DTO -> Service 层参数表单转换
+func CastExtractMindMapSubtreeReq2Params(req *def.ExtractMindMapSubtreeReq) *types.ExtractMindMapSubtreeParams {
+	if req == nil {
+		return nil
+	}
+	return &types.ExtractMindMapSubtreeParams{
+		NodeID:           req.NodeID,
+		Title:            req.Title,
+		Layout:           req.Layout,
+		RemoveFromSource: req.RemoveFromSource,
+		Version:          req.Version,
+	}
+}
+
+// CastMergeMindMapsReq2Params DTO -> Service 层参数表单转换
+func CastMergeMindMapsReq2Params(req *def.MergeMindMapsReq) *types.MergeMindMapsParams {
+	if req == nil {
+		return nil
+	}
+	return &types.MergeMindMapsParams{
+		MapIDs:    req.MapIDs,
+		Title:     req.Title,
+		Desc:      req.Desc,
+		Layout:    req.Layout,
+		Duplicate: req.Duplicate,
+	}
+}
+
+// CastCreateMindMapFolderReq2Params DTO -> Service 层参数表单转换
+func CastCreateMindMapFolderReq2Params(req *def.CreateMindMapFolderReq) *types.CreateMindMapFolderParams {
+	if req == nil {
+		return nil
+	}
+	return &types.CreateMindMapFolderParams{
+		Name:     req.Name,
+		ParentID: req.ParentID,
+	}
+}
+
+// CastUpdateMindMapFolderReq2Params DTO -> Service 层参数表单转换
+func CastUpdateMindMapFolderReq2Params(req *def.UpdateMindMapFolderReq) *types.UpdateMindMapFolderParams {
+	if req == nil {
+		return nil
+	}
+	return &types.UpdateMindMapFolderParams{
+		Name:     req.Name,
+		ParentID: req.ParentID,
+	}
+}
+
+// CastMoveMindMapsToFolderReq2Params DTO -> Service 层参数表单转换
+func CastMoveMindMapsToFolderReq2Params(req *def.MoveMindMapsToFolderReq) *types.MoveMindMapsToFolderParams {
+	if req == nil {
+		return nil
+	}
+	return &types.MoveMindMapsToFolderParams{
+		MapIDs:   req.MapIDs,
+		FolderID: req.FolderID,
+	}
+}
+
+// CastMindMapFolderDO2DTO 文件夹实体转DTO
+func CastMindMapFolderDO2DTO(folder *entity.MindMapFolder) *def.MindMapFolderDTO {
+	if folder == nil {
+		return nil
+	}
+	return &def.MindMapFolderDTO{
+		FolderID:  folder.FolderID,
+		ParentID:  folder.ParentID,
+		Name:      folder.Name,
+		CreatedAt: formatTime(folder.CreatedAt),
+		UpdatedAt: formatTime(folder.UpdatedAt),
+	}
+}
+
+// CastMindMapFolderDOs2DTOs 文件夹列表转DTO列表
+func CastMindMapFolderDOs2DTOs(folders []*entity.MindMapFolder) []*def.MindMapFolderDTO {
+	return gslice.Map(folders, CastMindMapFolderDO2DTO)
+}
+
+// CastMindMapTagCountDOs2DTOs 标签统计列表转DTO列表
+func CastMindMapTagCountDOs2DTOs(counts []*entity.MindMapTagCount) []*def.MindMapTagCountDTO {
+	return gslice.Map(counts, func(count *entity.MindMapTagCount) *def.MindMapTagCountDTO {
+		return &def.MindMapTagCountDTO{
+			Tag:   count.Tag,
+			Count: count.Count,
+		}
+	})
+}
+
+// CastListMindMapCommentsReq2Params DTO -> Service 层参数表单转换
+func CastListMindMapCommentsReq2Params(req *def.ListMindMapCommentsReq) *types.ListMindMapCommentsParams {
+	if req == nil {
+		return nil
+	}
+	return &types.ListMindMapCommentsParams{
+		NodeID:   req.NodeID,
+		Resolved: req.Resolved,
+	}
+}
+
+// CastCreateMindMapCommentReq2Params DTO -> Service 层参数表单转换
+func CastCreateMindMapCommentReq2Params(req *def.CreateMindMapCommentReq) *types.CreateMindMapCommentParams {
+	if req == nil {
+		return nil
+	}
+	return &types.CreateMindMapCommentParams{
+		NodeID:   req.NodeID,
+		ParentID: req.ParentID,
+		Content:  req.Content,
+		Mentions: req.Mentions,
+	}
+}
+
+// CastUpdateMindMapCommentReq2Params DTO -> Service 层参数表单转换
+func CastUpdateMindMapCommentReq2Params(req *def.UpdateMindMapCommentReq) *types.UpdateMindMapCommentParams {
+	if req == nil {
+		return nil
+	}
+	return &types.UpdateMindMapCommentParams{
+		Content:  req.Content,
+		Mentions: req.Mentions,
+	}
+}
+
+// CastMindMapCommentDO2DTO 评论实体转DTO
+func CastMindMapCommentDO2DTO(comment *entity.MindMapComment) *def.MindMapCommentDTO {
+	if comment == nil {
+		return nil
+	}
+	dto := &def.MindMapCommentDTO{
+		CommentID:  comment.CommentID,
+		MapID:      comment.MapID,
+		NodeID:     comment.NodeID,
+		ParentID:   comment.ParentID,
+		UserID:     comment.UserID,
+		UserName:   comment.UserName,
+		Avatar:     comment.Avatar,
+		Content:    comment.Content,
+		Mentions:   comment.Mentions,
+		Resolved:   comment.Resolved,
+		ResolvedBy: comment.ResolvedBy,
+		CreatedAt:  formatTime(comment.CreatedAt),
+	}
+	if dto.Mentions == nil {
+		dto.Mentions = []string{}
+	}
+	if comment.ResolvedAt != nil {
+		dto.ResolvedAt = formatTime(*comment.ResolvedAt)
+	}
+	if comment.EditedAt != nil {
+		dto.EditedAt = formatTime(*comment.EditedAt)
+	}
+	return dto
+}
+
+// CastMindMapCommentThreadDOs2DTOs 评论线程列表转DTO列表
+func CastMindMapCommentThreadDOs2DTOs(threads []*entity.MindMapCommentThread) []*def.MindMapCommentThreadDTO {
+	return gslice.Map(threads, func(thread *entity.MindMapCommentThread) *def.MindMapCommentThreadDTO {
+		return &def.MindMapCommentThreadDTO{
+			MindMapCommentDTO: CastMindMapCommentDO2DTO(thread.Comment),
+			Replies:           append([]*def.MindMapCommentDTO{}, gslice.Map(thread.Replies, CastMindMapCommentDO2DTO)...),
+		}
+	})
+}
+
+// CastUploadMindMapAttachmentReq2Params DTO -> Service 层参数表单转换
+func CastUploadMindMapAttachmentReq2Params(req *def.UploadMindMapAttachmentReq) *types.UploadMindMapAttachmentParams {
+	if req == nil {
+		return nil
+	}
+	return &types.UploadMindMapAttachmentParams{
+		NodeID: req.NodeID,
+		File:   req.File,
+	}
+}
+
+// CastMindMapAttachmentDO2DTO 附件实体转DTO
+func CastMindMapAttachmentDO2DTO(attachment *entity.MindMapAttachment) *def.MindMapAttachmentDTO {
+	if attachment == nil {
+		return nil
+	}
+	return &def.MindMapAttachmentDTO{
+		AttachmentID: attachment.AttachmentID,
+		MapID:        attachment.MapID,
+		NodeID:       attachment.NodeID,
+		UserID:       attachment.UserID,
+		FileName:     attachment.FileName,
+		ContentType:  attachment.ContentType,
+		Kind:         attachment.Kind,
+		Size:         attachment.Size,
+		URL:          attachment.URL,
+		CreatedAt:    formatTime(attachment.CreatedAt),
+	}
+}
+
+// CastMindMapAttachmentDOs2DTOs 附件列表转DTO列表
+func CastMindMapAttachmentDOs2DTOs(attachments []*entity.MindMapAttachment) []*def.MindMapAttachmentDTO {
+	return gslice.Map(attachments, CastMindMapAttachmentDO2DTO)
+}
+
 // 时间格式化辅助函数
 func formatTime(t time.Time) string {
 	if t.IsZero() {
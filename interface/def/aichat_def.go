@@ -7,22 +7,32 @@ import (
 )
 
 // 请求体
+// 导图数据由服务端读取会话所属导图的已保存版本，客户端需先保存导图再发送消息
 type ProcessUserMessageRequest struct {
 	ConversationID string `json:"conversation_id" binding:"required"`
 	Content        string `json:"content" binding:"required"`
-	MapData        string `json:"map_data"`
 }
 
 type ProcessUserMessageResponse struct {
-	NewMapJson string `json:"new_map_json"`
-	Content    string `json:"content"`
-	Success    bool   `json:"success"`
+	NewMapJson string          `json:"new_map_json"`
+	Content    string          `json:"content"`
+	Diff       *MindMapDiffDTO `json:"diff,omitempty"` // new_map_json 相对修改前导图的结构差异
+	MapVersion int64           `json:"map_version"`    // 本轮回答所基于的导图版本，保存 new_map_json 时作为 version 传入
+	Success    bool            `json:"success"`
+}
+
+// 流式对话事件数据 SSE 事件名为 delta、tool_call、tool_result 时使用
+// 完成时推送 done 事件 数据为 ProcessUserMessageResponse 出错时推送 error 事件 数据为 code、message
+type AiChatStreamEvent struct {
+	Content    string `json:"content,omitempty"` // delta 为回复文本增量 tool_result 为修改后的完整导图JSON
+	ToolCallID string `json:"tool_call_id,omitempty"`
+	ToolName   string `json:"tool_name,omitempty"`
+	Arguments  string `json:"arguments,omitempty"` // tool_call 的调用参数JSON
 }
 
 type SaveNewConversationRequest struct {
-	Title   string `json:"title" binding:"required"`
-	MapID   string `json:"map_id" binding:"required"`
-	MapData string `json:"map_data"`
+	Title string `json:"title" binding:"required"`
+	MapID string `json:"map_id" binding:"required"`
 }
 
 type SaveNewConversationResponse struct {
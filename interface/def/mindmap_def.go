@@ -1,5 +1,10 @@
 package def
 
+import (
+	"mime/multipart"
+	"time"
+)
+
 // 创建请求
 type CreateMindMapReq struct {
 	Title  string      `json:"title" binding:"required,max=100"`
@@ -8,10 +13,50 @@ type CreateMindMapReq struct {
 	Root   MindMapData `json:"root" binding:"required"`
 }
 
+// 导入请求（multipart/form-data）
+type ImportMindMapReq struct {
+	File   *multipart.FileHeader `form:"-"`
+	Format string                `form:"format" binding:"omitempty,oneof=markdown opml freemind"` // 不传时根据扩展名推断
+	Title  string                `form:"title" binding:"max=100"`
+	Layout string                `form:"layout"`
+}
+
+// 导出请求
+type ExportMindMapReq struct {
+	Format string `form:"format,default=markdown"` // markdown、opml、freemind、svg、png、pdf、docx、pptx
+}
+
 // 列表查询请求
 type ListMindMapsReq struct {
-	Title    string `form:"title"`
-	Layout   string `form:"layout"`
+	Title    string   `form:"title"`
+	Layout   string   `form:"layout"`
+	Role     string   `form:"role" binding:"omitempty,oneof=owner editor viewer"` // 按当前用户在导图中的角色筛选
+	FolderID string   `form:"folder_id"`                                          // 按文件夹筛选，root 表示未归入文件夹的导图
+	Tags     []string `form:"tag" binding:"max=20"`                               // 可重复传递，筛选同时带有全部标签的导图
+	Starred  bool     `form:"starred"`                                            // 只查询收藏的导图
+
+	SortBy      string     `form:"sort_by" binding:"omitempty,oneof=title created_at updated_at"` // 默认 updated_at
+	SortOrder   string     `form:"sort_order" binding:"omitempty,oneof=asc desc"`                 // 默认 desc
+	CreatedFrom *time.Time `form:"created_from"`                                                  // RFC3339 格式，包含该时间
+	CreatedTo   *time.Time `form:"created_to"`                                                    // RFC3339 格式，不包含该时间
+	UpdatedFrom *time.Time `form:"updated_from"`
+	UpdatedTo   *time.Time `form:"updated_to"`
+
+	Page     int    `form:"page,default=1"`
+	PageSize int    `form:"page_size,default=20"`
+	Cursor   string `form:"cursor"`                                  // 传入 cursor 或 limit 时使用游标分页，忽略 page 与 page_size
+	Limit    int    `form:"limit" binding:"omitempty,min=1,max=200"` // 游标分页的单页数量，默认20
+}
+
+// 增量同步请求
+type SyncMindMapsReq struct {
+	Cursor string `form:"cursor"`                                  // 上次同步返回的 next_cursor，为空时从全量同步开始
+	Limit  int    `form:"limit" binding:"omitempty,min=1,max=500"` // 单次返回的最大数量，默认100
+}
+
+// 搜索请求
+type SearchMindMapsReq struct {
+	Q        string `form:"q" binding:"required,max=100"`
 	Page     int    `form:"page,default=1"`
 	PageSize int    `form:"page_size,default=20"`
 }
@@ -22,6 +67,27 @@ type UpdateMindMapReq struct {
 	Desc   *string      `json:"desc,omitempty" binding:"omitempty,max=500"`
 	Layout *string      `json:"layout,omitempty"`
 	Root   *MindMapData `json:"root,omitempty"`
+
+	Version *int64 `json:"version,omitempty"` // 客户端持有的版本号，用于乐观锁校验，更新 root 时必填（也可通过 If-Match 传入）
+}
+
+// 节点级补丁请求
+type PatchMindMapReq struct {
+	Ops     []MindMapPatchOp `json:"ops" binding:"required,min=1,max=500,dive"`
+	Version *int64           `json:"version,omitempty"` // 客户端持有的版本号，传入时进行乐观锁校验
+}
+
+// 节点补丁操作，节点优先通过 uid 定位，也可通过路径定位（从根节点开始逐层的子节点下标，空数组表示根节点）
+type MindMapPatchOp struct {
+	Op         string       `json:"op" binding:"required,oneof=add_child delete move rename reorder"`
+	NodeID     string       `json:"nodeId,omitempty"`     // 目标节点 uid（add_child/reorder 时为父节点），优先于 path
+	Path       []int        `json:"path"`                 // 目标节点路径（add_child/reorder 时为父节点）
+	ParentID   string       `json:"parentId,omitempty"`   // move 时的新父节点 uid，优先于 parentPath
+	ParentPath []int        `json:"parentPath,omitempty"` // move 时的新父节点路径
+	Index      *int         `json:"index,omitempty"`      // add_child/move 时的插入位置，不传表示追加到末尾
+	Node       *MindMapData `json:"node,omitempty"`       // add_child 时新增的子树
+	Text       *string      `json:"text,omitempty"`       // rename 时的新文本
+	Order      []int        `json:"order,omitempty"`      // reorder 时子节点的新顺序
 }
 
 // 思维导图DTO
@@ -32,14 +98,50 @@ type MindMapDTO struct {
 	Desc      string      `json:"desc"`
 	Layout    string      `json:"layout"`
 	Root      MindMapData `json:"root"`
+	Version   int64       `json:"version"`
+	Thumbnail string      `json:"thumbnail,omitempty"`
+	Role      string      `json:"role,omitempty"` // 当前用户在导图中的角色：owner、editor、viewer
 	CreatedAt string      `json:"createdAt,omitempty"`
 	UpdatedAt string      `json:"updatedAt,omitempty"`
+	DeletedAt string      `json:"deletedAt,omitempty"` // 移入回收站的时间，仅回收站列表返回
+	// 派生来源，复制、提取、合并或基于模板创建的导图返回
+	Provenance *MindMapProvenanceDTO `json:"provenance,omitempty"`
+	// 当前用户对导图的整理信息
+	FolderID string   `json:"folderId,omitempty"`
+	Tags     []string `json:"tags,omitempty"`
+	Starred  bool     `json:"starred"`
+	Pinned   bool     `json:"pinned"`
+}
+
+// 派生来源DTO
+type MindMapProvenanceDTO struct {
+	Type       string              `json:"type"` // duplicate、extract、merge、template
+	Sources    []*MindMapSourceDTO `json:"sources,omitempty"`
+	NodeID     string              `json:"nodeId,omitempty"`     // extract 时被提取的节点标识
+	TemplateID string              `json:"templateId,omitempty"` // template 时的模板标识
+}
+
+// 来源导图DTO
+type MindMapSourceDTO struct {
+	MapID   string `json:"mapId"`
+	Version int64  `json:"version"` // 派生时来源导图的版本
+	Title   string `json:"title"`
 }
 
 // 节点数据DTO
 type NodeData struct {
-	Text string `json:"text"`
-	// 可扩展其他节点属性，如颜色、图标等
+	UID             string   `json:"uid"` // 节点唯一标识，由服务端分配，新增节点可不传
+	Text            string   `json:"text"`
+	Note            string   `json:"note,omitempty"`            // 备注（markdown）
+	Hyperlink       string   `json:"hyperlink,omitempty"`       // 超链接地址
+	HyperlinkTitle  string   `json:"hyperlinkTitle,omitempty"`  // 超链接标题
+	Tags            []string `json:"tags,omitempty"`            // 标签
+	Color           string   `json:"color,omitempty"`           // 文字颜色，#RGB 或 #RRGGBB
+	BackgroundColor string   `json:"backgroundColor,omitempty"` // 背景颜色，#RGB 或 #RRGGBB
+	Icon            string   `json:"icon,omitempty"`            // 图标标识
+	Priority        int      `json:"priority,omitempty"`        // 优先级 0-9，0 表示未设置
+	Collapsed       bool     `json:"collapsed,omitempty"`       // 是否折叠子节点
+	Attachments     []string `json:"attachments,omitempty"`     // 附件标识，通过附件接口上传与删除
 }
 
 // 思维导图数据DTO - 递归树结构
@@ -53,6 +155,17 @@ type CreateMindMapResp struct {
 	*MindMapDTO
 }
 
+type ImportMindMapResp struct {
+	*MindMapDTO
+}
+
+// 导出响应，以文件下载形式返回，不做JSON序列化
+type ExportMindMapResp struct {
+	FileName    string `json:"fileName"`
+	ContentType string `json:"contentType"`
+	Content     []byte `json:"-"`
+}
+
 type GetMindMapResp struct {
 	*MindMapDTO
 }
@@ -62,12 +175,564 @@ type ListMindMapsResp struct {
 	Total    int64         `json:"total"`
 	Page     int           `json:"page"`
 	PageSize int           `json:"page_size"`
+
+	// 游标分页时返回，游标分页不统计总数
+	NextCursor string `json:"next_cursor,omitempty"`
+	HasMore    bool   `json:"has_more,omitempty"`
+}
+
+type SearchMindMapsResp struct {
+	List     []*MindMapSearchResultDTO `json:"list"`
+	Total    int64                     `json:"total"`
+	Page     int                       `json:"page"`
+	PageSize int                       `json:"page_size"`
+}
+
+// 搜索结果DTO，不包含导图数据
+type MindMapSearchResultDTO struct {
+	MapID     string                `json:"mapId"`
+	Title     string                `json:"title"`
+	Desc      string                `json:"desc"`
+	Layout    string                `json:"layout"`
+	Version   int64                 `json:"version"`
+	Thumbnail string                `json:"thumbnail,omitempty"`
+	UpdatedAt string                `json:"updatedAt,omitempty"`
+	HitCount  int                   `json:"hitCount"` // 命中总数，hits 最多返回前 5 项
+	Hits      []MindMapSearchHitDTO `json:"hits"`
+}
+
+// 搜索命中项DTO，text 已做 HTML 转义，关键词以 <em> 标记
+type MindMapSearchHitDTO struct {
+	Field string `json:"field"`         // title、desc、node
+	UID   string `json:"uid,omitempty"` // 命中节点的 uid
+	Path  []int  `json:"path"`          // 命中节点的路径（从根节点开始逐层的子节点下标），根节点为空数组，标题、描述命中时为 null
+	Text  string `json:"text"`
+}
+
+// 增量同步响应，has_more 为 true 时应立即使用 next_cursor 继续同步
+type SyncMindMapsResp struct {
+	Changed    []*MindMapDTO `json:"changed"` // 新增或发生变化的导图，包含完整数据
+	Deleted    []string      `json:"deleted"` // 已删除、移入回收站或失去访问权限的导图ID
+	NextCursor string        `json:"next_cursor"`
+	HasMore    bool          `json:"has_more"`
 }
 
 type UpdateMindMapResp struct {
-	Success bool `json:"success"`
+	Success bool  `json:"success"`
+	Version int64 `json:"version"`
 }
 
 type DeleteMindMapResp struct {
 	Success bool `json:"success"`
 }
+
+type RestoreMindMapResp struct {
+	Success bool `json:"success"`
+}
+
+type PurgeMindMapResp struct {
+	Success bool `json:"success"`
+}
+
+type GenerateThumbnailResp struct {
+	Thumbnail string `json:"thumbnail"`
+}
+
+type PatchMindMapResp struct {
+	Success bool  `json:"success"`
+	Version int64 `json:"version"`
+}
+
+// 历史版本列表查询请求
+type ListMindMapRevisionsReq struct {
+	Page     int `form:"page,default=1"`
+	PageSize int `form:"page_size,default=20"`
+}
+
+// 恢复历史版本请求
+type RestoreMindMapRevisionReq struct {
+	Version *int64 `json:"version,omitempty"` // 客户端持有的当前版本号，必填（也可通过 If-Match 传入），用于乐观锁校验
+}
+
+// 历史版本DTO，列表中不返回 root
+type MindMapRevisionDTO struct {
+	MapID     string       `json:"mapId"`
+	Version   int64        `json:"version"`
+	UserID    string       `json:"userId"`
+	Title     string       `json:"title"`
+	Desc      string       `json:"desc"`
+	Layout    string       `json:"layout"`
+	Root      *MindMapData `json:"root,omitempty"`
+	CreatedAt string       `json:"createdAt,omitempty"`
+}
+
+type ListMindMapRevisionsResp struct {
+	List     []*MindMapRevisionDTO `json:"list"`
+	Total    int64                 `json:"total"`
+	Page     int                   `json:"page"`
+	PageSize int                   `json:"page_size"`
+}
+
+type GetMindMapRevisionResp struct {
+	*MindMapRevisionDTO
+}
+
+type RestoreMindMapRevisionResp struct {
+	Success bool  `json:"success"`
+	Version int64 `json:"version"`
+}
+
+// 版本差异查询请求
+type DiffMindMapReq struct {
+	From int64 `form:"from" binding:"min=0"` // 基准版本，0 或不传表示当前版本
+	To   int64 `form:"to" binding:"min=0"`   // 目标版本，0 或不传表示当前版本
+}
+
+// 版本与提交的导图数据差异比较请求
+type DiffMindMapProposalReq struct {
+	From int64       `json:"from" binding:"min=0"` // 基准版本，0 或不传表示当前版本
+	Root MindMapData `json:"root" binding:"required"`
+}
+
+// 节点变更DTO
+type MindMapNodeChangeDTO struct {
+	Type         string `json:"type"`                   // added、removed、renamed、moved、updated
+	UID          string `json:"uid,omitempty"`          // 节点 uid，新增节点未分配 uid 时为空
+	Text         string `json:"text"`                   // 变更后的文本，删除的节点为原文本
+	OldText      string `json:"oldText,omitempty"`      // renamed 时的原文本
+	ParentUID    string `json:"parentUid,omitempty"`    // 变更后的父节点 uid
+	OldParentUID string `json:"oldParentUid,omitempty"` // 变更前的父节点 uid
+	Path         []int  `json:"path"`                   // 节点在新树中的路径，删除的节点为 null
+	OldPath      []int  `json:"oldPath"`                // 节点在原树中的路径，新增的节点为 null
+}
+
+type MindMapDiffSummaryDTO struct {
+	Added   int `json:"added"`
+	Removed int `json:"removed"`
+	Renamed int `json:"renamed"`
+	Moved   int `json:"moved"`
+	Updated int `json:"updated"`
+}
+
+// 导图结构差异DTO
+type MindMapDiffDTO struct {
+	Changes []*MindMapNodeChangeDTO `json:"changes"`
+	Summary MindMapDiffSummaryDTO   `json:"summary"`
+}
+
+type DiffMindMapResp struct {
+	*MindMapDiffDTO
+}
+
+// 创建分享链接请求
+type CreateMindMapShareReq struct {
+	ExpiresIn int64  `json:"expiresIn,omitempty" binding:"omitempty,min=60,max=31536000"` // 有效期（秒），不传表示永久有效
+	Password  string `json:"password,omitempty" binding:"omitempty,max=64"`               // 访问密码，不传表示无需密码
+}
+
+// 分享链接列表请求
+type ListMindMapSharesReq struct {
+	MapID string `form:"map_id"` // 不传时返回全部导图的分享
+}
+
+// 访问分享链接请求，密码通过请求头传递，避免出现在访问日志中
+type GetSharedMindMapReq struct {
+	Password string `header:"X-Share-Password" json:"-"`
+}
+
+// 获取分享导图渲染图片请求，密码同样通过请求头传递
+type ExportSharedMindMapReq struct {
+	Format   string `form:"format,default=png"` // svg、png
+	Password string `header:"X-Share-Password" json:"-"`
+}
+
+// 分享链接DTO
+type MindMapShareDTO struct {
+	ShareID     string `json:"shareId"`
+	MapID       string `json:"mapId"`
+	Token       string `json:"token"` // 访问地址为 /api/biz/v1/share/:token
+	HasPassword bool   `json:"hasPassword"`
+	ExpiresAt   string `json:"expiresAt,omitempty"` // 为空表示永久有效
+	CreatedAt   string `json:"createdAt,omitempty"`
+}
+
+type CreateMindMapShareResp struct {
+	*MindMapShareDTO
+}
+
+type ListMindMapSharesResp struct {
+	List []*MindMapShareDTO `json:"list"`
+}
+
+type RevokeMindMapShareResp struct {
+	Success bool `json:"success"`
+}
+
+type GetSharedMindMapResp struct {
+	*MindMapDTO
+}
+
+// 邀请协作成员请求，邮箱与手机号二选一
+type InviteMindMapMemberReq struct {
+	Email string `json:"email,omitempty" binding:"omitempty,email"`
+	Phone string `json:"phone,omitempty"`
+	Role  string `json:"role" binding:"required,oneof=editor viewer"`
+}
+
+// 修改协作成员角色请求
+type UpdateMindMapMemberReq struct {
+	Role string `json:"role" binding:"required,oneof=editor viewer"`
+}
+
+// 协作成员DTO
+type MindMapMemberDTO struct {
+	UserID    string `json:"userId"`
+	UserName  string `json:"userName"`
+	Avatar    string `json:"avatar,omitempty"`
+	Role      string `json:"role"`                // owner、editor、viewer
+	InvitedBy string `json:"invitedBy,omitempty"` // 邀请人用户ID，所有者为空
+	CreatedAt string `json:"createdAt,omitempty"`
+}
+
+type ListMindMapMembersResp struct {
+	List []*MindMapMemberDTO `json:"list"`
+}
+
+type InviteMindMapMemberResp struct {
+	*MindMapMemberDTO
+}
+
+type UpdateMindMapMemberResp struct {
+	Success bool `json:"success"`
+}
+
+type RemoveMindMapMemberResp struct {
+	Success bool `json:"success"`
+}
+
+// 实时协作消息类型
+const (
+	CollabMsgInit   = "init"   // 服务端：加入成功，下发导图快照与在线状态
+	CollabMsgOp     = "op"     // 客户端：提交节点操作；服务端：其他协作者的操作已生效
+	CollabMsgCursor = "cursor" // 客户端：更新光标所在节点；服务端：其他协作者的光标变化
+	CollabMsgAck    = "ack"    // 服务端：客户端提交的操作已生效
+	CollabMsgReject = "reject" // 服务端：客户端提交的操作被拒绝，需重新同步后再提交
+	CollabMsgJoin   = "join"   // 服务端：协作者加入
+	CollabMsgLeave  = "leave"  // 服务端：协作者离开
+	CollabMsgReload = "reload" // 服务端：导图通过协作以外的方式被修改，需重新加载
+	CollabMsgError  = "error"  // 服务端：其他错误
+)
+
+// 实时协作消息，客户端与服务端共用
+// 客户端的操作必须通过 nodeId/parentId 定位节点，按 version 顺序应用服务端广播的操作，发现版本不连续时重新加载
+type CollabMessage struct {
+	Type        string               `json:"type" binding:"required,oneof=op cursor"`
+	ClientSeq   int64                `json:"clientSeq,omitempty"`                  // 客户端消息序号，ack/reject 时原样返回
+	BaseVersion int64                `json:"baseVersion,omitempty"`                // op：客户端生成操作时所基于的导图版本
+	Ops         []MindMapPatchOp     `json:"ops,omitempty" binding:"max=500,dive"` // op：节点操作，服务端返回变换后实际生效的操作
+	NodeID      string               `json:"nodeId,omitempty" binding:"max=64"`    // cursor：光标所在节点，为空表示取消选中
+	Version     int64                `json:"version,omitempty"`                    // 操作生效后的导图版本
+	ConnID      string               `json:"connId,omitempty"`                     // 消息来源连接，init 时为自身连接
+	UserID      string               `json:"userId,omitempty"`                     // 消息来源用户
+	MindMap     *MindMapDTO          `json:"mindMap,omitempty"`                    // init：导图快照
+	Presence    []*CollabPresenceDTO `json:"presence,omitempty"`                   // init：全部在线状态；join/leave/cursor：来源连接的在线状态
+	Code        int                  `json:"code,omitempty"`                       // reject/error：错误码
+	Message     string               `json:"message,omitempty"`                    // reject/error：错误信息
+}
+
+// 实时协作在线状态DTO
+type CollabPresenceDTO struct {
+	ConnID       string `json:"connId"`
+	UserID       string `json:"userId"`
+	UserName     string `json:"userName"`
+	Avatar       string `json:"avatar,omitempty"`
+	Role         string `json:"role"`
+	CursorNodeID string `json:"cursorNodeId,omitempty"`
+	JoinedAt     string `json:"joinedAt,omitempty"`
+}
+
+// 模板列表请求
+type ListMindMapTemplatesReq struct {
+	Scope    string `form:"scope" binding:"omitempty,oneof=system mine"` // system 仅系统模板，mine 仅我的模板，不传时两者都返回
+	Category string `form:"category"`
+	Page     int    `form:"page,default=1"`
+	PageSize int    `form:"page_size,default=20"`
+}
+
+// 将导图保存为模板请求
+type SaveMindMapAsTemplateReq struct {
+	Name     string `json:"name,omitempty" binding:"max=100"` // 不传时使用导图标题
+	Desc     string `json:"desc,omitempty" binding:"max=500"`
+	Category string `json:"category,omitempty" binding:"max=50"`
+}
+
+// 基于模板创建导图请求
+type CreateMindMapFromTemplateReq struct {
+	Title  string `json:"title,omitempty" binding:"max=100"` // 不传时使用模板名称
+	Desc   string `json:"desc,omitempty" binding:"max=500"`
+	Layout string `json:"layout,omitempty"` // 不传时使用模板布局
+}
+
+// 模板DTO，列表中不包含 root
+type MindMapTemplateDTO struct {
+	TemplateID string       `json:"templateId"`
+	Name       string       `json:"name"`
+	Desc       string       `json:"desc"`
+	Category   string       `json:"category"`
+	Layout     string       `json:"layout"`
+	System     bool         `json:"system"` // 是否为系统模板
+	Root       *MindMapData `json:"root,omitempty"`
+	CreatedAt  string       `json:"createdAt,omitempty"`
+}
+
+type ListMindMapTemplatesResp struct {
+	List     []*MindMapTemplateDTO `json:"list"`
+	Total    int64                 `json:"total"`
+	Page     int                   `json:"page"`
+	PageSize int                   `json:"page_size"`
+}
+
+type GetMindMapTemplateResp struct {
+	*MindMapTemplateDTO
+}
+
+type SaveMindMapAsTemplateResp struct {
+	*MindMapTemplateDTO
+}
+
+type DeleteMindMapTemplateResp struct {
+	Success bool `json:"success"`
+}
+
+type CreateMindMapFromTemplateResp struct {
+	*MindMapDTO
+}
+
+// 复制导图请求
+type DuplicateMindMapReq struct {
+	Title string `json:"title,omitempty" binding:"max=100"` // 不传时使用原标题加副本后缀
+}
+
+// 提取子树为新导图请求
+type ExtractMindMapSubtreeReq struct {
+	NodeID           string `json:"nodeId" binding:"required"`
+	Title            string `json:"title,omitempty" binding:"max=100"` // 不传时使用节点文本
+	Layout           string `json:"layout,omitempty"`                  // 不传时使用原导图布局
+	RemoveFromSource bool   `json:"removeFromSource,omitempty"`        // 是否从原导图中移除该分支
+	Version          *int64 `json:"version,omitempty"`                 // 移除分支时客户端持有的原导图版本号
+}
+
+// 合并导图请求
+type MergeMindMapsReq struct {
+	MapIDs    []string `json:"mapIds" binding:"required,min=2,max=10,dive,required"`
+	Title     string   `json:"title" binding:"required,max=100"` // 新导图标题，同时作为新根节点文本
+	Desc      string   `json:"desc,omitempty" binding:"max=500"`
+	Layout    string   `json:"layout,omitempty"`                                              // 不传时使用第一张导图的布局
+	Duplicate string   `json:"duplicate,omitempty" binding:"omitempty,oneof=keep skip merge"` // 同层重复节点处理方式，默认 keep
+}
+
+type DuplicateMindMapResp struct {
+	*MindMapDTO
+}
+
+type ExtractMindMapSubtreeResp struct {
+	*MindMapDTO
+}
+
+type MergeMindMapsResp struct {
+	*MindMapDTO
+}
+
+// 文件夹DTO
+type MindMapFolderDTO struct {
+	FolderID  string `json:"folderId"`
+	ParentID  string `json:"parentId"` // 顶层文件夹为空
+	Name      string `json:"name"`
+	CreatedAt string `json:"createdAt,omitempty"`
+	UpdatedAt string `json:"updatedAt,omitempty"`
+}
+
+// 创建文件夹请求
+type CreateMindMapFolderReq struct {
+	Name     string `json:"name" binding:"required,max=50"`
+	ParentID string `json:"parentId,omitempty"` // 不传时创建在顶层
+}
+
+// 修改文件夹请求，字段不传时保持不变
+type UpdateMindMapFolderReq struct {
+	Name     *string `json:"name,omitempty" binding:"omitempty,max=50"`
+	ParentID *string `json:"parentId,omitempty"` // 传空字符串时移动到顶层
+}
+
+// 批量移动导图到文件夹请求
+type MoveMindMapsToFolderReq struct {
+	MapIDs   []string `json:"mapIds" binding:"required,min=1,max=100,dive,required"`
+	FolderID string   `json:"folderId"` // 为空或 root 时移回顶层
+}
+
+// 收藏导图请求
+type StarMindMapReq struct {
+	Starred *bool `json:"starred" binding:"required"`
+}
+
+// 置顶导图请求
+type PinMindMapReq struct {
+	Pinned *bool `json:"pinned" binding:"required"`
+}
+
+// 设置导图标签请求，整体替换，传空数组时清除全部标签
+type SetMindMapTagsReq struct {
+	Tags []string `json:"tags" binding:"max=20"`
+}
+
+// 标签统计DTO
+type MindMapTagCountDTO struct {
+	Tag   string `json:"tag"`
+	Count int64  `json:"count"` // 带有该标签的导图数量
+}
+
+type ListMindMapFoldersResp struct {
+	List []*MindMapFolderDTO `json:"list"`
+}
+
+type CreateMindMapFolderResp struct {
+	*MindMapFolderDTO
+}
+
+type UpdateMindMapFolderResp struct {
+	*MindMapFolderDTO
+}
+
+type DeleteMindMapFolderResp struct {
+	Success bool `json:"success"`
+}
+
+type MoveMindMapsToFolderResp struct {
+	Moved int64 `json:"moved"` // 实际移动的导图数量，无权访问的导图不计入
+}
+
+type StarMindMapResp struct {
+	Success bool `json:"success"`
+}
+
+type PinMindMapResp struct {
+	Success bool `json:"success"`
+}
+
+type SetMindMapTagsResp struct {
+	Tags []string `json:"tags"`
+}
+
+type ListMindMapTagsResp struct {
+	List []*MindMapTagCountDTO `json:"list"`
+}
+
+// 评论列表查询请求
+type ListMindMapCommentsReq struct {
+	NodeID   string `form:"node_id"`  // 按节点筛选
+	Resolved *bool  `form:"resolved"` // 按线程是否已解决筛选
+}
+
+// 发表评论请求，nodeId 与 parentId 二选一
+type CreateMindMapCommentReq struct {
+	NodeID   string   `json:"nodeId"`   // 在节点上发起新线程
+	ParentID string   `json:"parentId"` // 回复已有评论
+	Content  string   `json:"content" binding:"required,max=2000"`
+	Mentions []string `json:"mentions" binding:"max=20"` // 被@的用户ID，需为导图协作成员
+}
+
+// 编辑评论请求
+type UpdateMindMapCommentReq struct {
+	Content  string   `json:"content" binding:"required,max=2000"`
+	Mentions []string `json:"mentions" binding:"max=20"` // 整体替换，只通知新增的用户
+}
+
+// 解决或重新打开评论线程请求
+type ResolveMindMapCommentReq struct {
+	Resolved *bool `json:"resolved" binding:"required"`
+}
+
+type MindMapCommentDTO struct {
+	CommentID  string   `json:"commentId"`
+	MapID      string   `json:"mapId"`
+	NodeID     string   `json:"nodeId"`
+	ParentID   string   `json:"parentId,omitempty"` // 回复所属线程的首条评论
+	UserID     string   `json:"userId"`
+	UserName   string   `json:"userName"`
+	Avatar     string   `json:"avatar,omitempty"`
+	Content    string   `json:"content"`
+	Mentions   []string `json:"mentions"`
+	Resolved   bool     `json:"resolved"`
+	ResolvedBy string   `json:"resolvedBy,omitempty"`
+	ResolvedAt string   `json:"resolvedAt,omitempty"`
+	EditedAt   string   `json:"editedAt,omitempty"` // 未编辑过为空
+	CreatedAt  string   `json:"createdAt,omitempty"`
+}
+
+// 评论线程DTO
+type MindMapCommentThreadDTO struct {
+	*MindMapCommentDTO
+	Replies []*MindMapCommentDTO `json:"replies"`
+}
+
+type ListMindMapCommentsResp struct {
+	List []*MindMapCommentThreadDTO `json:"list"`
+}
+
+type CreateMindMapCommentResp struct {
+	*MindMapCommentDTO
+}
+
+type UpdateMindMapCommentResp struct {
+	*MindMapCommentDTO
+}
+
+type DeleteMindMapCommentResp struct {
+	Success bool `json:"success"`
+}
+
+type ResolveMindMapCommentResp struct {
+	Success bool `json:"success"`
+}
+
+// 附件列表查询请求
+type ListMindMapAttachmentsReq struct {
+	NodeID string `form:"node_id"` // 按上传时所属的节点筛选
+}
+
+// 上传附件请求（multipart/form-data）
+type UploadMindMapAttachmentReq struct {
+	File   *multipart.FileHeader `form:"-"`
+	NodeID string                `form:"node_id" binding:"required"`
+}
+
+type MindMapAttachmentDTO struct {
+	AttachmentID string `json:"attachmentId"`
+	MapID        string `json:"mapId"`
+	NodeID       string `json:"nodeId"`
+	UserID       string `json:"userId"` // 上传者
+	FileName     string `json:"fileName"`
+	ContentType  string `json:"contentType"`
+	Kind         string `json:"kind"` // image、pdf、document、text
+	Size         int64  `json:"size"` // 字节
+	URL          string `json:"url"`
+	CreatedAt    string `json:"createdAt,omitempty"`
+}
+
+type ListMindMapAttachmentsResp struct {
+	List []*MindMapAttachmentDTO `json:"list"`
+}
+
+type UploadMindMapAttachmentResp struct {
+	*MindMapAttachmentDTO
+}
+
+type DeleteMindMapAttachmentResp struct {
+	Success bool `json:"success"`
+}
+
+// 附件存储用量响应
+type GetAttachmentUsageResp struct {
+	Used  int64 `json:"used"`  // 已使用的字节数
+	Quota int64 `json:"quota"` // 配额字节数
+}
@@ -2,6 +2,7 @@ package handler
 
 import (
 	"context"
+	"forge/biz/types"
 	"forge/interface/caster"
 	"forge/interface/def"
 )
@@ -16,13 +17,21 @@ func (h *Handler) SendMessage(ctx context.Context, req *def.ProcessUserMessageRe
 		return nil, err
 	}
 
-	resp := &def.ProcessUserMessageResponse{
-		Content:    aiMsg.Content,
-		NewMapJson: aiMsg.NewMapJson,
-		Success:    true,
+	return caster.CastAgentResponse2Resp(aiMsg), nil
+}
+
+// SendMessageStream 流式ai对话 生成过程中的事件通过onEvent推送 返回完成后的完整结果
+func (h *Handler) SendMessageStream(ctx context.Context, req *def.ProcessUserMessageRequest, onEvent func(event string, data *def.AiChatStreamEvent)) (*def.ProcessUserMessageResponse, error) {
+	params := caster.CastProcessUserMessageReq2Params(req)
+
+	aiMsg, err := h.AiChatService.ProcessUserMessageStream(ctx, params, func(event types.AgentEvent) {
+		onEvent(event.Type, caster.CastAgentEvent2DTO(event))
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	return resp, nil
+	return caster.CastAgentResponse2Resp(aiMsg), nil
 }
 
 func (h *Handler) SaveNewConversation(ctx context.Context, req *def.SaveNewConversationRequest) (*def.SaveNewConversationResponse, error) {
@@ -29,12 +29,83 @@ type IHandler interface {
 	ListMindMaps(ctx context.Context, req *def.ListMindMapsReq) (rsp *def.ListMindMapsResp, err error)
 	UpdateMindMap(ctx context.Context, mapID string, req *def.UpdateMindMapReq) (rsp *def.UpdateMindMapResp, err error)
 	DeleteMindMap(ctx context.Context, mapID string) (rsp *def.DeleteMindMapResp, err error)
+	SearchMindMaps(ctx context.Context, req *def.SearchMindMapsReq) (rsp *def.SearchMindMapsResp, err error)
+	SyncMindMaps(ctx context.Context, req *def.SyncMindMapsReq) (rsp *def.SyncMindMapsResp, err error)
+	ListTrashMindMaps(ctx context.Context, req *def.ListMindMapsReq) (rsp *def.ListMindMapsResp, err error)
+	RestoreMindMap(ctx context.Context, mapID string) (rsp *def.RestoreMindMapResp, err error)
+	PurgeMindMap(ctx context.Context, mapID string) (rsp *def.PurgeMindMapResp, err error)
+	PatchMindMap(ctx context.Context, mapID string, req *def.PatchMindMapReq) (rsp *def.PatchMindMapResp, err error)
+	ImportMindMap(ctx context.Context, req *def.ImportMindMapReq) (rsp *def.ImportMindMapResp, err error)
+	ExportMindMap(ctx context.Context, mapID string, req *def.ExportMindMapReq) (rsp *def.ExportMindMapResp, err error)
+	GenerateThumbnail(ctx context.Context, mapID string) (rsp *def.GenerateThumbnailResp, err error)
+	// MindMapRevision: 思维导图历史版本
+	ListMindMapRevisions(ctx context.Context, mapID string, req *def.ListMindMapRevisionsReq) (rsp *def.ListMindMapRevisionsResp, err error)
+	GetMindMapRevision(ctx context.Context, mapID string, version int64) (rsp *def.GetMindMapRevisionResp, err error)
+	RestoreMindMapRevision(ctx context.Context, mapID string, version int64, req *def.RestoreMindMapRevisionReq) (rsp *def.RestoreMindMapRevisionResp, err error)
+	DiffMindMap(ctx context.Context, mapID string, req *def.DiffMindMapReq) (rsp *def.DiffMindMapResp, err error)
+	DiffMindMapProposal(ctx context.Context, mapID string, req *def.DiffMindMapProposalReq) (rsp *def.DiffMindMapResp, err error)
+
+	// MindMapShare: 思维导图分享链接
+	CreateMindMapShare(ctx context.Context, mapID string, req *def.CreateMindMapShareReq) (rsp *def.CreateMindMapShareResp, err error)
+	ListMindMapShares(ctx context.Context, req *def.ListMindMapSharesReq) (rsp *def.ListMindMapSharesResp, err error)
+	RevokeMindMapShare(ctx context.Context, shareID string) (rsp *def.RevokeMindMapShareResp, err error)
+	GetSharedMindMap(ctx context.Context, token string, req *def.GetSharedMindMapReq) (rsp *def.GetSharedMindMapResp, err error)
+	ExportSharedMindMap(ctx context.Context, token string, req *def.ExportSharedMindMapReq) (rsp *def.ExportMindMapResp, err error)
+
+	// MindMapMember: 思维导图协作成员
+	ListMindMapMembers(ctx context.Context, mapID string) (rsp *def.ListMindMapMembersResp, err error)
+	InviteMindMapMember(ctx context.Context, mapID string, req *def.InviteMindMapMemberReq) (rsp *def.InviteMindMapMemberResp, err error)
+	UpdateMindMapMember(ctx context.Context, mapID, userID string, req *def.UpdateMindMapMemberReq) (rsp *def.UpdateMindMapMemberResp, err error)
+	RemoveMindMapMember(ctx context.Context, mapID, userID string) (rsp *def.RemoveMindMapMemberResp, err error)
+
+	// MindMapCollab: 思维导图实时协作
+	JoinMindMapCollab(ctx context.Context, mapID string) (rsp *def.CollabMessage, events <-chan *def.CollabMessage, err error)
+	LeaveMindMapCollab(ctx context.Context, mapID, connID string)
+	HandleMindMapCollabMessage(ctx context.Context, mapID, connID string, req *def.CollabMessage) (rsp *def.CollabMessage, err error)
+	TouchMindMapCollab(ctx context.Context, mapID, connID string) (err error)
+
+	// MindMapTemplate: 思维导图模板
+	ListMindMapTemplates(ctx context.Context, req *def.ListMindMapTemplatesReq) (rsp *def.ListMindMapTemplatesResp, err error)
+	GetMindMapTemplate(ctx context.Context, templateID string) (rsp *def.GetMindMapTemplateResp, err error)
+	SaveMindMapAsTemplate(ctx context.Context, mapID string, req *def.SaveMindMapAsTemplateReq) (rsp *def.SaveMindMapAsTemplateResp, err error)
+	DeleteMindMapTemplate(ctx context.Context, templateID string) (rsp *def.DeleteMindMapTemplateResp, err error)
+	CreateMindMapFromTemplate(ctx context.Context, templateID string, req *def.CreateMindMapFromTemplateReq) (rsp *def.CreateMindMapFromTemplateResp, err error)
+
+	// MindMap: 复制、拆分与合并
+	DuplicateMindMap(ctx context.Context, mapID string, req *def.DuplicateMindMapReq) (rsp *def.DuplicateMindMapResp, err error)
+	ExtractMindMapSubtree(ctx context.Context, mapID string, req *def.ExtractMindMapSubtreeReq) (rsp *def.ExtractMindMapSubtreeResp, err error)
+	MergeMindMaps(ctx context.Context, req *def.MergeMindMapsReq) (rsp *def.MergeMindMapsResp, err error)
+
+	// MindMap: 文件夹、标签与收藏
+	ListMindMapFolders(ctx context.Context) (rsp *def.ListMindMapFoldersResp, err error)
+	CreateMindMapFolder(ctx context.Context, req *def.CreateMindMapFolderReq) (rsp *def.CreateMindMapFolderResp, err error)
+	UpdateMindMapFolder(ctx context.Context, folderID string, req *def.UpdateMindMapFolderReq) (rsp *def.UpdateMindMapFolderResp, err error)
+	DeleteMindMapFolder(ctx context.Context, folderID string) (rsp *def.DeleteMindMapFolderResp, err error)
+	MoveMindMapsToFolder(ctx context.Context, req *def.MoveMindMapsToFolderReq) (rsp *def.MoveMindMapsToFolderResp, err error)
+	StarMindMap(ctx context.Context, mapID string, req *def.StarMindMapReq) (rsp *def.StarMindMapResp, err error)
+	PinMindMap(ctx context.Context, mapID string, req *def.PinMindMapReq) (rsp *def.PinMindMapResp, err error)
+	SetMindMapTags(ctx context.Context, mapID string, req *def.SetMindMapTagsReq) (rsp *def.SetMindMapTagsResp, err error)
+	ListMindMapTags(ctx context.Context) (rsp *def.ListMindMapTagsResp, err error)
+
+	// MindMapComment: 思维导图节点评论
+	ListMindMapComments(ctx context.Context, mapID string, req *def.ListMindMapCommentsReq) (rsp *def.ListMindMapCommentsResp, err error)
+	CreateMindMapComment(ctx context.Context, mapID string, req *def.CreateMindMapCommentReq) (rsp *def.CreateMindMapCommentResp, err error)
+	UpdateMindMapComment(ctx context.Context, mapID, commentID string, req *def.UpdateMindMapCommentReq) (rsp *def.UpdateMindMapCommentResp, err error)
+	DeleteMindMapComment(ctx context.Context, mapID, commentID string) (rsp *def.DeleteMindMapCommentResp, err error)
+	ResolveMindMapComment(ctx context.Context, mapID, commentID string, req *def.ResolveMindMapCommentReq) (rsp *def.ResolveMindMapCommentResp, err error)
+
+	// MindMapAttachment: 思维导图节点附件
+	ListMindMapAttachments(ctx context.Context, mapID string, req *def.ListMindMapAttachmentsReq) (rsp *def.ListMindMapAttachmentsResp, err error)
+	UploadMindMapAttachment(ctx context.Context, mapID string, req *def.UploadMindMapAttachmentReq) (rsp *def.UploadMindMapAttachmentResp, err error)
+	DeleteMindMapAttachment(ctx context.Context, mapID, attachmentID string) (rsp *def.DeleteMindMapAttachmentResp, err error)
+	GetAttachmentUsage(ctx context.Context) (rsp *def.GetAttachmentUsageResp, err error)
 
 	// COS: OSS凭证相关接口
 	GetOSSCredentials(ctx context.Context, req *def.GetOSSCredentialsReq) (rsp *def.GetOSSCredentialsResp, err error)
 
 	//AiChat: ai对话相关
 	SendMessage(ctx context.Context, req *def.ProcessUserMessageRequest) (*def.ProcessUserMessageResponse, error)
+	SendMessageStream(ctx context.Context, req *def.ProcessUserMessageRequest, onEvent func(event string, data *def.AiChatStreamEvent)) (*def.ProcessUserMessageResponse, error)
 	SaveNewConversation(ctx context.Context, req *def.SaveNewConversationRequest) (*def.SaveNewConversationResponse, error)
 	GetConversationList(ctx context.Context, req *def.GetConversationListRequest) (*def.GetConversationListResponse, error)
 	DelConversation(ctx context.Context, req *def.DelConversationRequest) (*def.DelConversationResponse, error)
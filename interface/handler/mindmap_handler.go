@@ -66,6 +66,20 @@ func (h *Handler) ListMindMaps(ctx context.Context, req *def.ListMindMapsReq) (r
 	// DTO -> Service 层参数转换
 	params := caster.CastListMindMapsReq2Params(req)
 
+	// 传入游标或单页数量时使用游标分页
+	if req.Cursor != "" || req.Limit > 0 {
+		mindmaps, nextCursor, err := h.MindMapService.ListMindMapsByCursor(ctx, params)
+		if err != nil {
+			return nil, err
+		}
+		rsp = &def.ListMindMapsResp{
+			List:       caster.CastMindMapDOs2DTOs(mindmaps),
+			NextCursor: nextCursor,
+			HasMore:    nextCursor != "",
+		}
+		return rsp, nil
+	}
+
 	// 调用服务层获取思维导图列表
 	mindmaps, total, err := h.MindMapService.ListMindMaps(ctx, params)
 	if err != nil {
@@ -82,6 +96,19 @@ func (h *Handler) ListMindMaps(ctx context.Context, req *def.ListMindMapsReq) (r
 	return rsp, nil
 }
 
+func (h *Handler) SyncMindMaps(ctx context.Context, req *def.SyncMindMapsReq) (rsp *def.SyncMindMapsResp, err error) {
+	defer func() {
+		zlog.CtxAllInOne(ctx, "handler.sync_mindmaps", req, rsp, err)
+	}()
+
+	// 调用服务层增量同步
+	result, err := h.MindMapService.SyncMindMaps(ctx, caster.CastSyncMindMapsReq2Params(req))
+	if err != nil {
+		return nil, err
+	}
+	return caster.CastMindMapSyncResult2Resp(result), nil
+}
+
 func (h *Handler) UpdateMindMap(ctx context.Context, mapID string, req *def.UpdateMindMapReq) (rsp *def.UpdateMindMapResp, err error) {
 	// 链路追踪 - TODO: cozeloop配置好后启用
 	// ctx, sp := loop.GetNewSpan(ctx, "handler.update_mindmap", constant.LoopSpanType_Handle)
@@ -94,7 +121,7 @@ func (h *Handler) UpdateMindMap(ctx context.Context, mapID string, req *def.Upda
 	params := caster.CastUpdateMindMapReq2Params(req)
 
 	// 调用服务层更新思维导图
-	err = h.MindMapService.UpdateMindMap(ctx, mapID, params)
+	version, err := h.MindMapService.UpdateMindMap(ctx, mapID, params)
 	if err != nil {
 		return nil, err
 	}
@@ -102,6 +129,7 @@ func (h *Handler) UpdateMindMap(ctx context.Context, mapID string, req *def.Upda
 	// 组装响应
 	rsp = &def.UpdateMindMapResp{
 		Success: true,
+		Version: version,
 	}
 	return rsp, nil
 }
@@ -126,3 +154,1010 @@ func (h *Handler) DeleteMindMap(ctx context.Context, mapID string) (rsp *def.Del
 	}
 	return rsp, nil
 }
+
+func (h *Handler) SearchMindMaps(ctx context.Context, req *def.SearchMindMapsReq) (rsp *def.SearchMindMapsResp, err error) {
+	defer func() {
+		zlog.CtxAllInOne(ctx, "handler.search_mindmaps", req, rsp, err)
+	}()
+
+	// DTO -> Service 层参数转换
+	params := caster.CastSearchMindMapsReq2Params(req)
+
+	// 调用服务层搜索
+	results, total, err := h.MindMapService.SearchMindMaps(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	// 组装响应
+	rsp = &def.SearchMindMapsResp{
+		List:     caster.CastMindMapSearchResultDOs2DTOs(results),
+		Total:    total,
+		Page:     req.Page,
+		PageSize: req.PageSize,
+	}
+	return rsp, nil
+}
+
+func (h *Handler) ListTrashMindMaps(ctx context.Context, req *def.ListMindMapsReq) (rsp *def.ListMindMapsResp, err error) {
+	defer func() {
+		zlog.CtxAllInOne(ctx, "handler.list_trash_mindmaps", req, rsp, err)
+	}()
+
+	// DTO -> Service 层参数转换
+	params := caster.CastListMindMapsReq2Params(req)
+
+	// 调用服务层获取回收站列表
+	mindmaps, total, err := h.MindMapService.ListTrashMindMaps(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	// 组装响应
+	rsp = &def.ListMindMapsResp{
+		List:     caster.CastMindMapDOs2DTOs(mindmaps),
+		Total:    total,
+		Page:     req.Page,
+		PageSize: req.PageSize,
+	}
+	return rsp, nil
+}
+
+func (h *Handler) RestoreMindMap(ctx context.Context, mapID string) (rsp *def.RestoreMindMapResp, err error) {
+	defer func() {
+		zlog.CtxAllInOne(ctx, "handler.restore_mindmap", mapID, rsp, err)
+	}()
+
+	// 调用服务层从回收站恢复思维导图
+	err = h.MindMapService.RestoreMindMap(ctx, mapID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 组装响应
+	rsp = &def.RestoreMindMapResp{
+		Success: true,
+	}
+	return rsp, nil
+}
+
+func (h *Handler) PurgeMindMap(ctx context.Context, mapID string) (rsp *def.PurgeMindMapResp, err error) {
+	defer func() {
+		zlog.CtxAllInOne(ctx, "handler.purge_mindmap", mapID, rsp, err)
+	}()
+
+	// 调用服务层彻底删除思维导图
+	err = h.MindMapService.PurgeMindMap(ctx, mapID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 组装响应
+	rsp = &def.PurgeMindMapResp{
+		Success: true,
+	}
+	return rsp, nil
+}
+
+func (h *Handler) PatchMindMap(ctx context.Context, mapID string, req *def.PatchMindMapReq) (rsp *def.PatchMindMapResp, err error) {
+	defer func() {
+		zlog.CtxAllInOne(ctx, "handler.patch_mindmap", map[string]interface{}{"mapID": mapID, "req": req}, rsp, err)
+	}()
+
+	// DTO -> Service 层参数转换
+	params := caster.CastPatchMindMapReq2Params(req)
+
+	// 调用服务层执行节点级更新
+	version, err := h.MindMapService.PatchMindMap(ctx, mapID, params)
+	if err != nil {
+		return nil, err
+	}
+
+	// 组装响应
+	rsp = &def.PatchMindMapResp{
+		Success: true,
+		Version: version,
+	}
+	return rsp, nil
+}
+
+func (h *Handler) ImportMindMap(ctx context.Context, req *def.ImportMindMapReq) (rsp *def.ImportMindMapResp, err error) {
+	defer func() {
+		zlog.CtxAllInOne(ctx, "handler.import_mindmap", req, rsp, err)
+	}()
+
+	// DTO -> Service 层参数转换
+	params := caster.CastImportMindMapReq2Params(req)
+
+	// 调用服务层导入思维导图
+	mindmap, err := h.MindMapService.ImportMindMap(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	// 组装响应
+	rsp = &def.ImportMindMapResp{
+		MindMapDTO: caster.CastMindMapDO2DTO(mindmap),
+	}
+	return rsp, nil
+}
+
+func (h *Handler) ExportMindMap(ctx context.Context, mapID string, req *def.ExportMindMapReq) (rsp *def.ExportMindMapResp, err error) {
+	defer func() {
+		zlog.CtxAllInOne(ctx, "handler.export_mindmap", map[string]interface{}{"mapID": mapID, "req": req}, rsp, err)
+	}()
+
+	// 调用服务层导出思维导图
+	result, err := h.MindMapService.ExportMindMap(ctx, mapID, req.Format)
+	if err != nil {
+		return nil, err
+	}
+
+	// 组装响应
+	rsp = &def.ExportMindMapResp{
+		FileName:    result.FileName,
+		ContentType: result.ContentType,
+		Content:     result.Content,
+	}
+	return rsp, nil
+}
+
+func (h *Handler) GenerateThumbnail(ctx context.Context, mapID string) (rsp *def.GenerateThumbnailResp, err error) {
+	defer func() {
+		zlog.CtxAllInOne(ctx, "handler.generate_thumbnail", mapID, rsp, err)
+	}()
+
+	// 调用服务层生成缩略图
+	thumbnail, err := h.MindMapService.GenerateThumbnail(ctx, mapID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 组装响应
+	rsp = &def.GenerateThumbnailResp{
+		Thumbnail: thumbnail,
+	}
+	return rsp, nil
+}
+
+func (h *Handler) ListMindMapRevisions(ctx context.Context, mapID string, req *def.ListMindMapRevisionsReq) (rsp *def.ListMindMapRevisionsResp, err error) {
+	defer func() {
+		zlog.CtxAllInOne(ctx, "handler.list_mindmap_revisions", map[string]interface{}{"mapID": mapID, "req": req}, rsp, err)
+	}()
+
+	// DTO -> Service 层参数转换
+	params := caster.CastListMindMapRevisionsReq2Params(req)
+
+	// 调用服务层获取历史版本列表
+	revisions, total, err := h.MindMapService.ListMindMapRevisions(ctx, mapID, params)
+	if err != nil {
+		return nil, err
+	}
+
+	// 组装响应
+	rsp = &def.ListMindMapRevisionsResp{
+		List:     caster.CastMindMapRevisionDOs2SummaryDTOs(revisions),
+		Total:    total,
+		Page:     req.Page,
+		PageSize: req.PageSize,
+	}
+	return rsp, nil
+}
+
+func (h *Handler) GetMindMapRevision(ctx context.Context, mapID string, version int64) (rsp *def.GetMindMapRevisionResp, err error) {
+	defer func() {
+		zlog.CtxAllInOne(ctx, "handler.get_mindmap_revision", map[string]interface{}{"mapID": mapID, "version": version}, rsp, err)
+	}()
+
+	// 调用服务层获取历史版本
+	revision, err := h.MindMapService.GetMindMapRevision(ctx, mapID, version)
+	if err != nil {
+		return nil, err
+	}
+
+	// 组装响应
+	rsp = &def.GetMindMapRevisionResp{
+		MindMapRevisionDTO: caster.CastMindMapRevisionDO2DTO(revision),
+	}
+	return rsp, nil
+}
+
+func (h *Handler) RestoreMindMapRevision(ctx context.Context, mapID string, version int64, req *def.RestoreMindMapRevisionReq) (rsp *def.RestoreMindMapRevisionResp, err error) {
+	defer func() {
+		zlog.CtxAllInOne(ctx, "handler.restore_mindmap_revision", map[string]interface{}{"mapID": mapID, "version": version, "req": req}, rsp, err)
+	}()
+
+	// DTO -> Service 层参数转换
+	params := caster.CastRestoreMindMapRevisionReq2Params(version, req)
+
+	// 调用服务层恢复历史版本
+	newVersion, err := h.MindMapService.RestoreMindMapRevision(ctx, mapID, params)
+	if err != nil {
+		return nil, err
+	}
+
+	// 组装响应
+	rsp = &def.RestoreMindMapRevisionResp{
+		Success: true,
+		Version: newVersion,
+	}
+	return rsp, nil
+}
+
+func (h *Handler) DiffMindMap(ctx context.Context, mapID string, req *def.DiffMindMapReq) (rsp *def.DiffMindMapResp, err error) {
+	defer func() {
+		zlog.CtxAllInOne(ctx, "handler.diff_mindmap", map[string]interface{}{"mapID": mapID, "req": req}, rsp, err)
+	}()
+
+	// DTO -> Service 层参数转换
+	params := caster.CastDiffMindMapReq2Params(req)
+
+	// 调用服务层比较两个版本
+	diff, err := h.MindMapService.DiffMindMap(ctx, mapID, params)
+	if err != nil {
+		return nil, err
+	}
+
+	// 组装响应
+	rsp = &def.DiffMindMapResp{
+		MindMapDiffDTO: caster.CastMindMapDiffDO2DTO(diff),
+	}
+	return rsp, nil
+}
+
+func (h *Handler) DiffMindMapProposal(ctx context.Context, mapID string, req *def.DiffMindMapProposalReq) (rsp *def.DiffMindMapResp, err error) {
+	defer func() {
+		zlog.CtxAllInOne(ctx, "handler.diff_mindmap_proposal", map[string]interface{}{"mapID": mapID, "from": req.From}, rsp, err)
+	}()
+
+	// DTO -> Service 层参数转换
+	params := caster.CastDiffMindMapProposalReq2Params(req)
+
+	// 调用服务层比较版本与提交的导图数据
+	diff, err := h.MindMapService.DiffMindMap(ctx, mapID, params)
+	if err != nil {
+		return nil, err
+	}
+
+	// 组装响应
+	rsp = &def.DiffMindMapResp{
+		MindMapDiffDTO: caster.CastMindMapDiffDO2DTO(diff),
+	}
+	return rsp, nil
+}
+
+func (h *Handler) CreateMindMapShare(ctx context.Context, mapID string, req *def.CreateMindMapShareReq) (rsp *def.CreateMindMapShareResp, err error) {
+	defer func() {
+		zlog.CtxAllInOne(ctx, "handler.create_mindmap_share", map[string]interface{}{"mapID": mapID, "expiresIn": req.ExpiresIn}, rsp, err)
+	}()
+
+	// DTO -> Service 层参数转换
+	params := caster.CastCreateMindMapShareReq2Params(req)
+
+	// 调用服务层创建分享链接
+	share, err := h.MindMapService.CreateMindMapShare(ctx, mapID, params)
+	if err != nil {
+		return nil, err
+	}
+
+	// 组装响应
+	rsp = &def.CreateMindMapShareResp{
+		MindMapShareDTO: caster.CastMindMapShareDO2DTO(share),
+	}
+	return rsp, nil
+}
+
+func (h *Handler) ListMindMapShares(ctx context.Context, req *def.ListMindMapSharesReq) (rsp *def.ListMindMapSharesResp, err error) {
+	defer func() {
+		zlog.CtxAllInOne(ctx, "handler.list_mindmap_shares", req, rsp, err)
+	}()
+
+	// 调用服务层获取分享链接列表
+	shares, err := h.MindMapService.ListMindMapShares(ctx, req.MapID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 组装响应
+	rsp = &def.ListMindMapSharesResp{
+		List: caster.CastMindMapShareDOs2DTOs(shares),
+	}
+	return rsp, nil
+}
+
+func (h *Handler) RevokeMindMapShare(ctx context.Context, shareID string) (rsp *def.RevokeMindMapShareResp, err error) {
+	defer func() {
+		zlog.CtxAllInOne(ctx, "handler.revoke_mindmap_share", shareID, rsp, err)
+	}()
+
+	// 调用服务层撤销分享链接
+	err = h.MindMapService.RevokeMindMapShare(ctx, shareID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 组装响应
+	rsp = &def.RevokeMindMapShareResp{
+		Success: true,
+	}
+	return rsp, nil
+}
+
+func (h *Handler) GetSharedMindMap(ctx context.Context, token string, req *def.GetSharedMindMapReq) (rsp *def.GetSharedMindMapResp, err error) {
+	defer func() {
+		zlog.CtxAllInOne(ctx, "handler.get_shared_mindmap", req, rsp, err)
+	}()
+
+	// 调用服务层通过分享链接获取思维导图
+	mindmap, err := h.MindMapService.GetSharedMindMap(ctx, token, req.Password)
+	if err != nil {
+		return nil, err
+	}
+
+	// 组装响应
+	rsp = &def.GetSharedMindMapResp{
+		MindMapDTO: caster.CastMindMapDO2DTO(mindmap),
+	}
+	return rsp, nil
+}
+
+func (h *Handler) ExportSharedMindMap(ctx context.Context, token string, req *def.ExportSharedMindMapReq) (rsp *def.ExportMindMapResp, err error) {
+	defer func() {
+		zlog.CtxAllInOne(ctx, "handler.export_shared_mindmap", req, rsp, err)
+	}()
+
+	// 调用服务层获取分享导图的渲染图片
+	result, err := h.MindMapService.ExportSharedMindMap(ctx, token, req.Password, req.Format)
+	if err != nil {
+		return nil, err
+	}
+
+	// 组装响应
+	rsp = &def.ExportMindMapResp{
+		FileName:    result.FileName,
+		ContentType: result.ContentType,
+		Content:     result.Content,
+	}
+	return rsp, nil
+}
+
+func (h *Handler) ListMindMapMembers(ctx context.Context, mapID string) (rsp *def.ListMindMapMembersResp, err error) {
+	defer func() {
+		zlog.CtxAllInOne(ctx, "handler.list_mindmap_members", mapID, rsp, err)
+	}()
+
+	// 调用服务层获取协作成员列表
+	members, err := h.MindMapService.ListMindMapMembers(ctx, mapID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 组装响应
+	rsp = &def.ListMindMapMembersResp{
+		List: caster.CastMindMapMemberDOs2DTOs(members),
+	}
+	return rsp, nil
+}
+
+func (h *Handler) InviteMindMapMember(ctx context.Context, mapID string, req *def.InviteMindMapMemberReq) (rsp *def.InviteMindMapMemberResp, err error) {
+	defer func() {
+		zlog.CtxAllInOne(ctx, "handler.invite_mindmap_member", map[string]interface{}{"mapID": mapID, "req": req}, rsp, err)
+	}()
+
+	// DTO -> Service 层参数转换
+	params := caster.CastInviteMindMapMemberReq2Params(req)
+
+	// 调用服务层邀请协作成员
+	member, err := h.MindMapService.InviteMindMapMember(ctx, mapID, params)
+	if err != nil {
+		return nil, err
+	}
+
+	// 组装响应
+	rsp = &def.InviteMindMapMemberResp{
+		MindMapMemberDTO: caster.CastMindMapMemberDO2DTO(member),
+	}
+	return rsp, nil
+}
+
+func (h *Handler) UpdateMindMapMember(ctx context.Context, mapID, userID string, req *def.UpdateMindMapMemberReq) (rsp *def.UpdateMindMapMemberResp, err error) {
+	defer func() {
+		zlog.CtxAllInOne(ctx, "handler.update_mindmap_member", map[string]interface{}{"mapID": mapID, "userID": userID, "role": req.Role}, rsp, err)
+	}()
+
+	// 调用服务层修改成员角色
+	err = h.MindMapService.UpdateMindMapMemberRole(ctx, mapID, userID, req.Role)
+	if err != nil {
+		return nil, err
+	}
+
+	// 组装响应
+	rsp = &def.UpdateMindMapMemberResp{
+		Success: true,
+	}
+	return rsp, nil
+}
+
+func (h *Handler) RemoveMindMapMember(ctx context.Context, mapID, userID string) (rsp *def.RemoveMindMapMemberResp, err error) {
+	defer func() {
+		zlog.CtxAllInOne(ctx, "handler.remove_mindmap_member", map[string]interface{}{"mapID": mapID, "userID": userID}, rsp, err)
+	}()
+
+	// 调用服务层移除协作成员
+	err = h.MindMapService.RemoveMindMapMember(ctx, mapID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 组装响应
+	rsp = &def.RemoveMindMapMemberResp{
+		Success: true,
+	}
+	return rsp, nil
+}
+
+// JoinMindMapCollab 加入实时协作，返回 init 消息与推送给该连接的协作消息
+func (h *Handler) JoinMindMapCollab(ctx context.Context, mapID string) (rsp *def.CollabMessage, events <-chan *def.CollabMessage, err error) {
+	defer func() {
+		zlog.CtxAllInOne(ctx, "handler.join_mindmap_collab", mapID, rsp, err)
+	}()
+
+	// 调用服务层加入协作
+	session, err := h.MindMapService.JoinCollab(ctx, mapID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// 协作事件转换为消息，会话结束时关闭
+	messages := make(chan *def.CollabMessage)
+	go func() {
+		defer close(messages)
+		for event := range session.Events {
+			messages <- caster.CastCollabEventDO2Message(event)
+		}
+	}()
+
+	return caster.CastCollabSession2InitMessage(session), messages, nil
+}
+
+func (h *Handler) LeaveMindMapCollab(ctx context.Context, mapID, connID string) {
+	zlog.CtxAllInOne(ctx, "handler.leave_mindmap_collab", map[string]interface{}{"mapID": mapID, "connID": connID}, nil, nil)
+
+	h.MindMapService.LeaveCollab(ctx, mapID, connID)
+}
+
+// HandleMindMapCollabMessage 处理客户端发送的协作消息（op、cursor），op 返回 ack，cursor 无需响应
+func (h *Handler) HandleMindMapCollabMessage(ctx context.Context, mapID, connID string, req *def.CollabMessage) (rsp *def.CollabMessage, err error) {
+	defer func() {
+		zlog.CtxAllInOne(ctx, "handler.handle_mindmap_collab_message", map[string]interface{}{"mapID": mapID, "connID": connID, "req": req}, rsp, err)
+	}()
+
+	switch req.Type {
+	case def.CollabMsgOp:
+		// DTO -> Service 层参数转换
+		params := caster.CastCollabOpMessage2Params(req)
+
+		// 调用服务层变换并保存操作
+		result, err := h.MindMapService.ApplyCollabOps(ctx, mapID, connID, params)
+		if err != nil {
+			return nil, err
+		}
+		return caster.CastApplyCollabOpsResult2AckMessage(req.ClientSeq, result), nil
+
+	default:
+		// 调用服务层更新光标
+		return nil, h.MindMapService.UpdateCollabCursor(ctx, mapID, connID, req.NodeID)
+	}
+}
+
+func (h *Handler) TouchMindMapCollab(ctx context.Context, mapID, connID string) (err error) {
+	return h.MindMapService.TouchCollab(ctx, mapID, connID)
+}
+
+func (h *Handler) ListMindMapTemplates(ctx context.Context, req *def.ListMindMapTemplatesReq) (rsp *def.ListMindMapTemplatesResp, err error) {
+	defer func() {
+		zlog.CtxAllInOne(ctx, "handler.list_mindmap_templates", req, rsp, err)
+	}()
+
+	// DTO -> Service 层参数转换
+	params := caster.CastListMindMapTemplatesReq2Params(req)
+
+	// 调用服务层获取模板列表
+	templates, total, err := h.MindMapService.ListMindMapTemplates(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	// 组装响应
+	rsp = &def.ListMindMapTemplatesResp{
+		List:     caster.CastMindMapTemplateDOs2SummaryDTOs(templates),
+		Total:    total,
+		Page:     req.Page,
+		PageSize: req.PageSize,
+	}
+	return rsp, nil
+}
+
+func (h *Handler) GetMindMapTemplate(ctx context.Context, templateID string) (rsp *def.GetMindMapTemplateResp, err error) {
+	defer func() {
+		zlog.CtxAllInOne(ctx, "handler.get_mindmap_template", templateID, rsp, err)
+	}()
+
+	// 调用服务层获取模板详情
+	template, err := h.MindMapService.GetMindMapTemplate(ctx, templateID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 组装响应
+	rsp = &def.GetMindMapTemplateResp{
+		MindMapTemplateDTO: caster.CastMindMapTemplateDO2DTO(template),
+	}
+	return rsp, nil
+}
+
+func (h *Handler) SaveMindMapAsTemplate(ctx context.Context, mapID string, req *def.SaveMindMapAsTemplateReq) (rsp *def.SaveMindMapAsTemplateResp, err error) {
+	defer func() {
+		zlog.CtxAllInOne(ctx, "handler.save_mindmap_as_template", map[string]interface{}{"mapID": mapID, "req": req}, rsp, err)
+	}()
+
+	// DTO -> Service 层参数转换
+	params := caster.CastSaveMindMapAsTemplateReq2Params(req)
+
+	// 调用服务层保存模板
+	template, err := h.MindMapService.SaveMindMapAsTemplate(ctx, mapID, params)
+	if err != nil {
+		return nil, err
+	}
+
+	// 组装响应
+	rsp = &def.SaveMindMapAsTemplateResp{
+		MindMapTemplateDTO: caster.CastMindMapTemplateDO2SummaryDTO(template),
+	}
+	return rsp, nil
+}
+
+func (h *Handler) DeleteMindMapTemplate(ctx context.Context, templateID string) (rsp *def.DeleteMindMapTemplateResp, err error) {
+	defer func() {
+		zlog.CtxAllInOne(ctx, "handler.delete_mindmap_template", templateID, rsp, err)
+	}()
+
+	// 调用服务层删除模板
+	err = h.MindMapService.DeleteMindMapTemplate(ctx, templateID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 组装响应
+	rsp = &def.DeleteMindMapTemplateResp{
+		Success: true,
+	}
+	return rsp, nil
+}
+
+func (h *Handler) CreateMindMapFromTemplate(ctx context.Context, templateID string, req *def.CreateMindMapFromTemplateReq) (rsp *def.CreateMindMapFromTemplateResp, err error) {
+	defer func() {
+		zlog.CtxAllInOne(ctx, "handler.create_mindmap_from_template", map[string]interface{}{"templateID": templateID, "req": req}, rsp, err)
+	}()
+
+	// DTO -> Service 层参数转换
+	params := caster.CastCreateMindMapFromTemplateReq2Params(req)
+
+	// 调用服务层基于模板创建导图
+	mindMap, err := h.MindMapService.CreateMindMapFromTemplate(ctx, templateID, params)
+	if err != nil {
+		return nil, err
+	}
+
+	// 组装响应
+	rsp = &def.CreateMindMapFromTemplateResp{
+		MindMapDTO: caster.CastMindMapDO2DTO(mindMap),
+	}
+	return rsp, nil
+}
+
+func (h *Handler) DuplicateMindMap(ctx context.Context, mapID string, req *def.DuplicateMindMapReq) (rsp *def.DuplicateMindMapResp, err error) {
+	defer func() {
+		zlog.CtxAllInOne(ctx, "handler.duplicate_mindmap", map[string]interface{}{"mapID": mapID, "req": req}, rsp, err)
+	}()
+
+	// DTO -> Service 层参数转换
+	params := caster.CastDuplicateMindMapReq2Params(req)
+
+	// 调用服务层复制导图
+	mindMap, err := h.MindMapService.DuplicateMindMap(ctx, mapID, params)
+	if err != nil {
+		return nil, err
+	}
+
+	// 组装响应
+	rsp = &def.DuplicateMindMapResp{
+		MindMapDTO: caster.CastMindMapDO2DTO(mindMap),
+	}
+	return rsp, nil
+}
+
+func (h *Handler) ExtractMindMapSubtree(ctx context.Context, mapID string, req *def.ExtractMindMapSubtreeReq) (rsp *def.ExtractMindMapSubtreeResp, err error) {
+	defer func() {
+		zlog.CtxAllInOne(ctx, "handler.extract_mindmap_subtree", map[string]interface{}{"mapID": mapID, "req": req}, rsp, err)
+	}()
+
+	// DTO -> Service 层参数转换
+	params := caster.CastExtractMindMapSubtreeReq2Params(req)
+
+	// 调用服务层提取子树为新导图
+	mindMap, err := h.MindMapService.ExtractMindMapSubtree(ctx, mapID, params)
+	if err != nil {
+		return nil, err
+	}
+
+	// 组装响应
+	rsp = &def.ExtractMindMapSubtreeResp{
+		MindMapDTO: caster.CastMindMapDO2DTO(mindMap),
+	}
+	return rsp, nil
+}
+
+func (h *Handler) MergeMindMaps(ctx context.Context, req *def.MergeMindMapsReq) (rsp *def.MergeMindMapsResp, err error) {
+	defer func() {
+		zlog.CtxAllInOne(ctx, "handler.merge_mindmaps", req, rsp, err)
+	}()
+
+	// DTO -> Service 层参数转换
+	params := caster.CastMergeMindMapsReq2Params(req)
+
+	// 调用服务层合并导图
+	mindMap, err := h.MindMapService.MergeMindMaps(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	// 组装响应
+	rsp = &def.MergeMindMapsResp{
+		MindMapDTO: caster.CastMindMapDO2DTO(mindMap),
+	}
+	return rsp, nil
+}
+
+func (h *Handler) ListMindMapFolders(ctx context.Context) (rsp *def.ListMindMapFoldersResp, err error) {
+	defer func() {
+		zlog.CtxAllInOne(ctx, "handler.list_mindmap_folders", nil, rsp, err)
+	}()
+
+	// 调用服务层获取文件夹列表
+	folders, err := h.MindMapService.ListMindMapFolders(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// 组装响应
+	rsp = &def.ListMindMapFoldersResp{
+		List: caster.CastMindMapFolderDOs2DTOs(folders),
+	}
+	return rsp, nil
+}
+
+func (h *Handler) CreateMindMapFolder(ctx context.Context, req *def.CreateMindMapFolderReq) (rsp *def.CreateMindMapFolderResp, err error) {
+	defer func() {
+		zlog.CtxAllInOne(ctx, "handler.create_mindmap_folder", req, rsp, err)
+	}()
+
+	// DTO -> Service 层参数转换
+	params := caster.CastCreateMindMapFolderReq2Params(req)
+
+	// 调用服务层创建文件夹
+	folder, err := h.MindMapService.CreateMindMapFolder(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	// 组装响应
+	rsp = &def.CreateMindMapFolderResp{
+		MindMapFolderDTO: caster.CastMindMapFolderDO2DTO(folder),
+	}
+	return rsp, nil
+}
+
+func (h *Handler) UpdateMindMapFolder(ctx context.Context, folderID string, req *def.UpdateMindMapFolderReq) (rsp *def.UpdateMindMapFolderResp, err error) {
+	defer func() {
+		zlog.CtxAllInOne(ctx, "handler.update_mindmap_folder", map[string]interface{}{"folderID": folderID, "req": req}, rsp, err)
+	}()
+
+	// DTO -> Service 层参数转换
+	params := caster.CastUpdateMindMapFolderReq2Params(req)
+
+	// 调用服务层修改文件夹
+	folder, err := h.MindMapService.UpdateMindMapFolder(ctx, folderID, params)
+	if err != nil {
+		return nil, err
+	}
+
+	// 组装响应
+	rsp = &def.UpdateMindMapFolderResp{
+		MindMapFolderDTO: caster.CastMindMapFolderDO2DTO(folder),
+	}
+	return rsp, nil
+}
+
+func (h *Handler) DeleteMindMapFolder(ctx context.Context, folderID string) (rsp *def.DeleteMindMapFolderResp, err error) {
+	defer func() {
+		zlog.CtxAllInOne(ctx, "handler.delete_mindmap_folder", folderID, rsp, err)
+	}()
+
+	// 调用服务层删除文件夹
+	err = h.MindMapService.DeleteMindMapFolder(ctx, folderID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 组装响应
+	rsp = &def.DeleteMindMapFolderResp{
+		Success: true,
+	}
+	return rsp, nil
+}
+
+func (h *Handler) MoveMindMapsToFolder(ctx context.Context, req *def.MoveMindMapsToFolderReq) (rsp *def.MoveMindMapsToFolderResp, err error) {
+	defer func() {
+		zlog.CtxAllInOne(ctx, "handler.move_mindmaps_to_folder", req, rsp, err)
+	}()
+
+	// DTO -> Service 层参数转换
+	params := caster.CastMoveMindMapsToFolderReq2Params(req)
+
+	// 调用服务层批量移动导图
+	moved, err := h.MindMapService.MoveMindMapsToFolder(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	// 组装响应
+	rsp = &def.MoveMindMapsToFolderResp{
+		Moved: moved,
+	}
+	return rsp, nil
+}
+
+func (h *Handler) StarMindMap(ctx context.Context, mapID string, req *def.StarMindMapReq) (rsp *def.StarMindMapResp, err error) {
+	defer func() {
+		zlog.CtxAllInOne(ctx, "handler.star_mindmap", map[string]interface{}{"mapID": mapID, "req": req}, rsp, err)
+	}()
+
+	// 调用服务层收藏或取消收藏
+	err = h.MindMapService.StarMindMap(ctx, mapID, *req.Starred)
+	if err != nil {
+		return nil, err
+	}
+
+	// 组装响应
+	rsp = &def.StarMindMapResp{
+		Success: true,
+	}
+	return rsp, nil
+}
+
+func (h *Handler) PinMindMap(ctx context.Context, mapID string, req *def.PinMindMapReq) (rsp *def.PinMindMapResp, err error) {
+	defer func() {
+		zlog.CtxAllInOne(ctx, "handler.pin_mindmap", map[string]interface{}{"mapID": mapID, "req": req}, rsp, err)
+	}()
+
+	// 调用服务层置顶或取消置顶
+	err = h.MindMapService.PinMindMap(ctx, mapID, *req.Pinned)
+	if err != nil {
+		return nil, err
+	}
+
+	// 组装响应
+	rsp = &def.PinMindMapResp{
+		Success: true,
+	}
+	return rsp, nil
+}
+
+func (h *Handler) SetMindMapTags(ctx context.Context, mapID string, req *def.SetMindMapTagsReq) (rsp *def.SetMindMapTagsResp, err error) {
+	defer func() {
+		zlog.CtxAllInOne(ctx, "handler.set_mindmap_tags", map[string]interface{}{"mapID": mapID, "req": req}, rsp, err)
+	}()
+
+	// 调用服务层设置标签
+	tags, err := h.MindMapService.SetMindMapTags(ctx, mapID, req.Tags)
+	if err != nil {
+		return nil, err
+	}
+
+	// 组装响应
+	rsp = &def.SetMindMapTagsResp{
+		Tags: tags,
+	}
+	return rsp, nil
+}
+
+func (h *Handler) ListMindMapTags(ctx context.Context) (rsp *def.ListMindMapTagsResp, err error) {
+	defer func() {
+		zlog.CtxAllInOne(ctx, "handler.list_mindmap_tags", nil, rsp, err)
+	}()
+
+	// 调用服务层获取标签统计
+	counts, err := h.MindMapService.ListMindMapTags(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// 组装响应
+	rsp = &def.ListMindMapTagsResp{
+		List: caster.CastMindMapTagCountDOs2DTOs(counts),
+	}
+	return rsp, nil
+}
+
+func (h *Handler) ListMindMapComments(ctx context.Context, mapID string, req *def.ListMindMapCommentsReq) (rsp *def.ListMindMapCommentsResp, err error) {
+	defer func() {
+		zlog.CtxAllInOne(ctx, "handler.list_mindmap_comments", map[string]interface{}{"mapID": mapID, "req": req}, rsp, err)
+	}()
+
+	// DTO -> Service 层参数转换
+	params := caster.CastListMindMapCommentsReq2Params(req)
+
+	// 调用服务层获取评论线程
+	threads, err := h.MindMapService.ListMindMapComments(ctx, mapID, params)
+	if err != nil {
+		return nil, err
+	}
+
+	// 组装响应
+	rsp = &def.ListMindMapCommentsResp{
+		List: caster.CastMindMapCommentThreadDOs2DTOs(threads),
+	}
+	return rsp, nil
+}
+
+func (h *Handler) CreateMindMapComment(ctx context.Context, mapID string, req *def.CreateMindMapCommentReq) (rsp *def.CreateMindMapCommentResp, err error) {
+	defer func() {
+		zlog.CtxAllInOne(ctx, "handler.create_mindmap_comment", map[string]interface{}{"mapID": mapID, "req": req}, rsp, err)
+	}()
+
+	// DTO -> Service 层参数转换
+	params := caster.CastCreateMindMapCommentReq2Params(req)
+
+	// 调用服务层发表评论
+	comment, err := h.MindMapService.CreateMindMapComment(ctx, mapID, params)
+	if err != nil {
+		return nil, err
+	}
+
+	// 组装响应
+	rsp = &def.CreateMindMapCommentResp{
+		MindMapCommentDTO: caster.CastMindMapCommentDO2DTO(comment),
+	}
+	return rsp, nil
+}
+
+func (h *Handler) UpdateMindMapComment(ctx context.Context, mapID, commentID string, req *def.UpdateMindMapCommentReq) (rsp *def.UpdateMindMapCommentResp, err error) {
+	defer func() {
+		zlog.CtxAllInOne(ctx, "handler.update_mindmap_comment", map[string]interface{}{"mapID": mapID, "commentID": commentID, "req": req}, rsp, err)
+	}()
+
+	// DTO -> Service 层参数转换
+	params := caster.CastUpdateMindMapCommentReq2Params(req)
+
+	// 调用服务层编辑评论
+	comment, err := h.MindMapService.UpdateMindMapComment(ctx, mapID, commentID, params)
+	if err != nil {
+		return nil, err
+	}
+
+	// 组装响应
+	rsp = &def.UpdateMindMapCommentResp{
+		MindMapCommentDTO: caster.CastMindMapCommentDO2DTO(comment),
+	}
+	return rsp, nil
+}
+
+func (h *Handler) DeleteMindMapComment(ctx context.Context, mapID, commentID string) (rsp *def.DeleteMindMapCommentResp, err error) {
+	defer func() {
+		zlog.CtxAllInOne(ctx, "handler.delete_mindmap_comment", map[string]interface{}{"mapID": mapID, "commentID": commentID}, rsp, err)
+	}()
+
+	// 调用服务层删除评论
+	if err = h.MindMapService.DeleteMindMapComment(ctx, mapID, commentID); err != nil {
+		return nil, err
+	}
+
+	// 组装响应
+	rsp = &def.DeleteMindMapCommentResp{
+		Success: true,
+	}
+	return rsp, nil
+}
+
+func (h *Handler) ResolveMindMapComment(ctx context.Context, mapID, commentID string, req *def.ResolveMindMapCommentReq) (rsp *def.ResolveMindMapCommentResp, err error) {
+	defer func() {
+		zlog.CtxAllInOne(ctx, "handler.resolve_mindmap_comment", map[string]interface{}{"mapID": mapID, "commentID": commentID, "req": req}, rsp, err)
+	}()
+
+	// 调用服务层解决或重新打开评论线程
+	if err = h.MindMapService.ResolveMindMapComment(ctx, mapID, commentID, *req.Resolved); err != nil {
+		return nil, err
+	}
+
+	// 组装响应
+	rsp = &def.ResolveMindMapCommentResp{
+		Success: true,
+	}
+	return rsp, nil
+}
+
+func (h *Handler) ListMindMapAttachments(ctx context.Context, mapID string, req *def.ListMindMapAttachmentsReq) (rsp *def.ListMindMapAttachmentsResp, err error) {
+	defer func() {
+		zlog.CtxAllInOne(ctx, "handler.list_mindmap_attachments", map[string]interface{}{"mapID": mapID, "req": req}, rsp, err)
+	}()
+
+	// 调用服务层获取附件列表
+	attachments, err := h.MindMapService.ListMindMapAttachments(ctx, mapID, req.NodeID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 组装响应
+	rsp = &def.ListMindMapAttachmentsResp{
+		List: caster.CastMindMapAttachmentDOs2DTOs(attachments),
+	}
+	return rsp, nil
+}
+
+func (h *Handler) UploadMindMapAttachment(ctx context.Context, mapID string, req *def.UploadMindMapAttachmentReq) (rsp *def.UploadMindMapAttachmentResp, err error) {
+	defer func() {
+		zlog.CtxAllInOne(ctx, "handler.upload_mindmap_attachment", map[string]interface{}{"mapID": mapID, "nodeID": req.NodeID}, rsp, err)
+	}()
+
+	// DTO -> Service 层参数转换
+	params := caster.CastUploadMindMapAttachmentReq2Params(req)
+
+	// 调用服务层上传附件
+	attachment, err := h.MindMapService.UploadMindMapAttachment(ctx, mapID, params)
+	if err != nil {
+		return nil, err
+	}
+
+	// 组装响应
+	rsp = &def.UploadMindMapAttachmentResp{
+		MindMapAttachmentDTO: caster.CastMindMapAttachmentDO2DTO(attachment),
+	}
+	return rsp, nil
+}
+
+func (h *Handler) DeleteMindMapAttachment(ctx context.Context, mapID, attachmentID string) (rsp *def.DeleteMindMapAttachmentResp, err error) {
+	defer func() {
+		zlog.CtxAllInOne(ctx, "handler.delete_mindmap_attachment", map[string]interface{}{"mapID": mapID, "attachmentID": attachmentID}, rsp, err)
+	}()
+
+	// 调用服务层删除附件
+	if err = h.MindMapService.DeleteMindMapAttachment(ctx, mapID, attachmentID); err != nil {
+		return nil, err
+	}
+
+	// 组装响应
+	rsp = &def.DeleteMindMapAttachmentResp{
+		Success: true,
+	}
+	return rsp, nil
+}
+
+func (h *Handler) GetAttachmentUsage(ctx context.Context) (rsp *def.GetAttachmentUsageResp, err error) {
+	defer func() {
+		zlog.CtxAllInOne(ctx, "handler.get_attachment_usage", nil, rsp, err)
+	}()
+
+	// 调用服务层获取附件存储用量
+	usage, err := h.MindMapService.GetAttachmentUsage(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// 组装响应
+	rsp = &def.GetAttachmentUsageResp{
+		Used:  usage.Used,
+		Quota: usage.Quota,
+	}
+	return rsp, nil
+}
@@ -11,7 +11,7 @@ func CorsMiddleware() gin.HandlerFunc {
 
 		AllowOrigins:  []string{configs.Config().GetAppConfig().YourFrontendDomain},
 		AllowMethods:  []string{"GET", "POST", "PUT", "PATCH", "DELETE", "HEAD", "OPTIONS"},
-		AllowHeaders:  []string{"Origin", "Content-Length", "Content-Type", "Authorization"},
+		AllowHeaders:  []string{"Origin", "Content-Length", "Content-Type", "Authorization", "X-Share-Password"},
 		ExposeHeaders: []string{"Content-Length", "Authorization"},
 	})
 }
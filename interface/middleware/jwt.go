@@ -14,14 +14,39 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
+// queryTokenKey StripQueryToken 取出的 token 查询参数在 gin context 中的键
+const queryTokenKey = "query_token"
+
+// StripQueryToken 将WebSocket握手的token查询参数移出请求URL，避免JWT出现在访问日志等记录请求地址的日志中
+// 需注册在日志中间件之前，取出的token由JWTAuth读取
+func StripQueryToken() gin.HandlerFunc {
+	return func(gCtx *gin.Context) {
+		if gCtx.IsWebsocket() {
+			if query := gCtx.Request.URL.Query(); query.Has("token") {
+				gCtx.Set(queryTokenKey, query.Get("token"))
+				query.Del("token")
+				gCtx.Request.URL.RawQuery = query.Encode()
+				gCtx.Request.RequestURI = gCtx.Request.URL.RequestURI()
+			}
+		}
+		gCtx.Next()
+	}
+}
+
 // JWTAuth JWT鉴权中间件
 // 从请求头获取token，验证token，提取用户信息并注入到context中
+// 浏览器发起WebSocket握手时无法设置请求头，此时使用 StripQueryToken 从token查询参数取出的token
 func JWTAuth(jwtUtil *util.JWTUtil, userService types.IUserService) gin.HandlerFunc {
 	return func(gCtx *gin.Context) {
 		ctx := gCtx.Request.Context()
 
 		// 从请求头获取token
 		authHeader := gCtx.GetHeader("Authorization")
+		if authHeader == "" && gCtx.IsWebsocket() {
+			if token := gCtx.GetString(queryTokenKey); token != "" {
+				authHeader = "Bearer " + token
+			}
+		}
 		if authHeader == "" {
 			zlog.CtxWarnf(ctx, "missing authorization header")
 			gCtx.JSON(http.StatusUnauthorized, response.JsonMsgResult{
@@ -0,0 +1,45 @@
+package middleware
+
+import (
+	"fmt"
+	"forge/biz/adapter"
+	"forge/pkg/log/zlog"
+	"forge/pkg/response"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RateLimit 限流中间件
+// 按 key 返回的维度在 window 内最多放行 limit 次请求，key 为空时不限流；计数器不可用时放行，避免影响正常访问
+func RateLimit(limiter adapter.RateLimiter, name string, limit int64, window time.Duration, key func(gCtx *gin.Context) string) gin.HandlerFunc {
+	return func(gCtx *gin.Context) {
+		ctx := gCtx.Request.Context()
+
+		k := key(gCtx)
+		if k == "" {
+			gCtx.Next()
+			return
+		}
+
+		allowed, err := limiter.Allow(ctx, fmt.Sprintf("%s:%s", name, k), limit, window)
+		if err != nil {
+			zlog.CtxErrorf(ctx, "rate limiter %s failed: %v", name, err)
+			gCtx.Next()
+			return
+		}
+		if !allowed {
+			zlog.CtxWarnf(ctx, "rate limit %s exceeded, key: %s", name, k)
+			gCtx.Header("Retry-After", fmt.Sprintf("%d", int(window.Seconds())))
+			gCtx.JSON(http.StatusTooManyRequests, response.JsonMsgResult{
+				Code:    response.TOO_MANY_REQUESTS.Code,
+				Message: response.TOO_MANY_REQUESTS.Msg,
+				Data:    nil,
+			})
+			gCtx.Abort()
+			return
+		}
+		gCtx.Next()
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"forge/pkg/response"
 	"github.com/gin-gonic/gin"
 	"net/http"
+	"sync"
 )
 
 func aiChatServiceErrorToMsgCode(err error) response.MsgCode {
@@ -78,6 +79,57 @@ func SendMessage() gin.HandlerFunc {
 	}
 }
 
+// SendMessageStream 流式ai对话 以 Server-Sent Events 推送回复文本增量、工具调用与结果 完成后推送 done 事件
+func SendMessageStream() gin.HandlerFunc {
+	return func(gCtx *gin.Context) {
+		var req def.ProcessUserMessageRequest
+		ctx := gCtx.Request.Context()
+
+		if err := gCtx.ShouldBindJSON(&req); err != nil {
+			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
+				Code:    response.PARAM_NOT_COMPLETE.Code,
+				Message: response.PARAM_NOT_COMPLETE.Msg,
+				Data:    def.ProcessUserMessageResponse{Success: false},
+			})
+			return
+		}
+
+		gCtx.Header("Content-Type", "text/event-stream")
+		gCtx.Header("Cache-Control", "no-cache")
+		gCtx.Header("Connection", "keep-alive")
+		gCtx.Header("X-Accel-Buffering", "no")
+
+		// 多个工具可能并发回调 写入需串行
+		var mu sync.Mutex
+		send := func(event string, data interface{}) {
+			mu.Lock()
+			defer mu.Unlock()
+			gCtx.SSEvent(event, data)
+			gCtx.Writer.Flush()
+		}
+
+		resp, err := handler.GetHandler().SendMessageStream(ctx, &req, func(event string, data *def.AiChatStreamEvent) {
+			send(event, data)
+		})
+
+		zlog.CtxAllInOne(ctx, "send_message_stream", map[string]interface{}{"req": req}, resp, err)
+
+		if err != nil {
+			msgCode := aiChatServiceErrorToMsgCode(err)
+			if msgCode == response.COMMON_FAIL {
+				msgCode.Msg = err.Error()
+			}
+			send("error", response.JsonMsgResult{
+				Code:    msgCode.Code,
+				Message: msgCode.Msg,
+				Data:    def.ProcessUserMessageResponse{Success: false},
+			})
+			return
+		}
+		send("done", resp)
+	}
+}
+
 // SaveNewConversation 保存新的会话
 func SaveNewConversation() gin.HandlerFunc {
 	return func(gCtx *gin.Context) {
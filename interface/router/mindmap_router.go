@@ -1,12 +1,25 @@
 package router
 
 import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"mime"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/gorilla/websocket"
 
 	"forge/biz/mindmapservice"
+	"forge/infra/configs"
 	// "forge/constant"
 	"forge/interface/def"
 	"forge/interface/handler"
@@ -38,6 +51,100 @@ func mapMindMapServiceErrorToMsgCode(err error) response.MsgCode {
 		return response.MINDMAP_PERMISSION_DENIED
 	}
 
+	if errors.Is(err, mindmapservice.ErrVersionConflict) {
+		return response.MINDMAP_VERSION_CONFLICT
+	}
+
+	if errors.Is(err, mindmapservice.ErrRevisionNotFound) {
+		return response.MINDMAP_REVISION_NOT_FOUND
+	}
+
+	if errors.Is(err, mindmapservice.ErrInvalidPatch) {
+		return response.MINDMAP_INVALID_PATCH
+	}
+
+	if errors.Is(err, mindmapservice.ErrInvalidMindMapData) {
+		// 返回具体的校验失败原因（包含出错节点路径）
+		return response.MsgCode{Code: response.MINDMAP_INVALID_DATA.Code, Msg: err.Error()}
+	}
+
+	if errors.Is(err, mindmapservice.ErrUnsupportedFormat) {
+		return response.MINDMAP_UNSUPPORTED_FORMAT
+	}
+
+	if errors.Is(err, mindmapservice.ErrImportFailed) {
+		// 返回具体的解析失败原因
+		return response.MsgCode{Code: response.MINDMAP_IMPORT_FAILED.Code, Msg: err.Error()}
+	}
+
+	if errors.Is(err, mindmapservice.ErrShareNotFound) {
+		return response.MINDMAP_SHARE_NOT_FOUND
+	}
+
+	if errors.Is(err, mindmapservice.ErrSharePasswordNeeded) {
+		return response.MINDMAP_SHARE_NEED_PWD
+	}
+
+	if errors.Is(err, mindmapservice.ErrSharePasswordWrong) {
+		return response.MINDMAP_SHARE_WRONG_PWD
+	}
+
+	if errors.Is(err, mindmapservice.ErrShareLocked) {
+		return response.MINDMAP_SHARE_LOCKED
+	}
+
+	if errors.Is(err, mindmapservice.ErrMemberNotFound) {
+		return response.MINDMAP_MEMBER_NOT_FOUND
+	}
+
+	if errors.Is(err, mindmapservice.ErrMemberAlreadyExists) {
+		return response.MINDMAP_MEMBER_EXISTS
+	}
+
+	if errors.Is(err, mindmapservice.ErrInviteeNotFound) {
+		return response.MINDMAP_INVITEE_NOT_FOUND
+	}
+
+	if errors.Is(err, mindmapservice.ErrCollabConflict) {
+		return response.MINDMAP_COLLAB_CONFLICT
+	}
+
+	if errors.Is(err, mindmapservice.ErrTemplateNotFound) {
+		return response.MINDMAP_TEMPLATE_NOT_FOUND
+	}
+
+	if errors.Is(err, mindmapservice.ErrFolderNotFound) {
+		return response.MINDMAP_FOLDER_NOT_FOUND
+	}
+
+	if errors.Is(err, mindmapservice.ErrFolderNameExists) {
+		return response.MINDMAP_FOLDER_NAME_EXISTS
+	}
+
+	if errors.Is(err, mindmapservice.ErrCommentNotFound) {
+		return response.MINDMAP_COMMENT_NOT_FOUND
+	}
+
+	if errors.Is(err, mindmapservice.ErrAttachmentNotFound) {
+		return response.MINDMAP_ATTACHMENT_NOT_FOUND
+	}
+
+	if errors.Is(err, mindmapservice.ErrUnsupportedAttachment) {
+		return response.MINDMAP_ATTACHMENT_UNSUPPORTED
+	}
+
+	if errors.Is(err, mindmapservice.ErrAttachmentTooLarge) {
+		return response.PARAM_FILE_SIZE_TOO_BIG
+	}
+
+	if errors.Is(err, mindmapservice.ErrAttachmentQuotaExceeded) {
+		return response.MINDMAP_ATTACHMENT_QUOTA_EXCEEDED
+	}
+
+	if errors.Is(err, mindmapservice.ErrSyncCursorExpired) {
+		return response.MINDMAP_SYNC_CURSOR_EXPIRED
+	}
+
 	if errors.Is(err, mindmapservice.ErrInternalError) {
 		return response.INTERNAL_ERROR
 	}
@@ -46,6 +153,69 @@ func mapMindMapServiceErrorToMsgCode(err error) response.MsgCode {
 	return response.COMMON_FAIL
 }
 
+// mindMapETag 导图的实体标签，由内容版本号与响应数据的摘要组成
+// 版本号用于 If-Match 的乐观锁校验，摘要覆盖角色、标签等不改变版本号的用户整理信息
+func mindMapETag(rsp *def.GetMindMapResp) string {
+	data, _ := json.Marshal(rsp)
+	sum := sha256.Sum256(data)
+	return fmt.Sprintf(`"%d-%s"`, rsp.Version, hex.EncodeToString(sum[:8]))
+}
+
+// etagMatches 判断 If-None-Match 中是否包含 etag，按弱比较忽略 W/ 前缀
+func etagMatches(ifNoneMatch, etag string) bool {
+	for _, tag := range strings.Split(ifNoneMatch, ",") {
+		tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+		if tag == "*" || tag == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// parseIfMatchVersion 解析 If-Match 中导图实体标签的版本号，"*" 表示导图存在即可，不限制版本
+// 仅支持单个强实体标签，无法解析时 ok 为 false
+func parseIfMatchVersion(ifMatch string) (version *int64, ok bool) {
+	tag := strings.TrimSpace(ifMatch)
+	if tag == "*" {
+		return nil, true
+	}
+	if len(tag) < 2 || tag[0] != '"' || tag[len(tag)-1] != '"' {
+		return nil, false
+	}
+	versionPart, _, _ := strings.Cut(tag[1:len(tag)-1], "-")
+	v, err := strconv.ParseInt(versionPart, 10, 64)
+	if err != nil {
+		return nil, false
+	}
+	return &v, true
+}
+
+// mergeIfMatchVersion 合并 If-Match 与请求体中的版本号，If-Match 携带版本号时优先使用
+// If-Match 无法解析时 status 为 200，与请求体中的版本号不一致时 status 为 412，此时 ok 为 false
+func mergeIfMatchVersion(ifMatch string, bodyVersion *int64) (version *int64, status int, ok bool) {
+	if ifMatch == "" {
+		return bodyVersion, http.StatusOK, true
+	}
+	version, ok = parseIfMatchVersion(ifMatch)
+	if !ok {
+		return nil, http.StatusOK, false
+	}
+	if version == nil {
+		return bodyVersion, http.StatusOK, true
+	}
+	if bodyVersion != nil && *version != *bodyVersion {
+		return nil, http.StatusPreconditionFailed, false
+	}
+	return version, http.StatusOK, true
+}
+
+// isPreconditionFailure 条件请求中 If-Match 不成立的错误：版本不一致，或导图不存在、无权访问（包括 "*"）
+func isPreconditionFailure(err error) bool {
+	return errors.Is(err, mindmapservice.ErrVersionConflict) ||
+		errors.Is(err, mindmapservice.ErrMindMapNotFound) ||
+		errors.Is(err, mindmapservice.ErrPermissionDenied)
+}
+
 // CreateMindMap
 //
 //	@Description:[POST] /api/biz/v1/mindmap
@@ -120,9 +290,17 @@ func GetMindMap() gin.HandlerFunc {
 				Data:    def.GetMindMapResp{},
 			})
 			return
-		} else {
-			r.Success(rsp)
 		}
+
+		// 条件请求：客户端缓存的版本仍是最新时返回 304，不返回导图数据
+		etag := mindMapETag(rsp)
+		gCtx.Header("ETag", etag)
+		gCtx.Header("Cache-Control", "private, no-cache")
+		if ifNoneMatch := gCtx.GetHeader("If-None-Match"); ifNoneMatch != "" && etagMatches(ifNoneMatch, etag) {
+			gCtx.Status(http.StatusNotModified)
+			return
+		}
+		r.Success(rsp)
 	}
 }
 
@@ -196,6 +374,23 @@ func UpdateMindMap() gin.HandlerFunc {
 			return
 		}
 
+		// 条件请求：If-Match 中的版本号作为乐观锁校验的版本，与请求体中的版本号不一致时直接返回 412
+		ifMatch := gCtx.GetHeader("If-Match")
+		version, status, ok := mergeIfMatchVersion(ifMatch, req.Version)
+		if !ok {
+			msgCode := response.INVALID_PARAMS
+			if status == http.StatusPreconditionFailed {
+				msgCode = response.MINDMAP_VERSION_CONFLICT
+			}
+			gCtx.JSON(status, response.JsonMsgResult{
+				Code:    msgCode.Code,
+				Message: msgCode.Msg,
+				Data:    def.UpdateMindMapResp{Success: false},
+			})
+			return
+		}
+		req.Version = version
+
 		// TODO: cozeloop配置好后启用
 		// ctx, sp := loop.GetNewSpan(ctx, "update_mindmap", constant.LoopSpanType_Root)
 		rsp, err := handler.GetHandler().UpdateMindMap(ctx, mapID, req)
@@ -205,7 +400,11 @@ func UpdateMindMap() gin.HandlerFunc {
 		r := response.NewResponse(gCtx)
 		if err != nil {
 			msgCode := mapMindMapServiceErrorToMsgCode(err)
-			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
+			status := http.StatusOK
+			if ifMatch != "" && isPreconditionFailure(err) {
+				status = http.StatusPreconditionFailed
+			}
+			gCtx.JSON(status, response.JsonMsgResult{
 				Code:    msgCode.Code,
 				Message: msgCode.Msg,
 				Data:    def.UpdateMindMapResp{Success: false},
@@ -256,3 +455,2313 @@ func DeleteMindMap() gin.HandlerFunc {
 		}
 	}
 }
+
+// SyncMindMaps
+//
+//	@Description:[GET] /api/biz/v1/mindmap/sync?cursor=上次同步的游标
+//	@return gin.HandlerFunc
+func SyncMindMaps() gin.HandlerFunc {
+	return func(gCtx *gin.Context) {
+		req := &def.SyncMindMapsReq{}
+		ctx := gCtx.Request.Context()
+
+		// 绑定查询参数
+		if err := gCtx.ShouldBindQuery(req); err != nil {
+			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
+				Code:    response.INVALID_PARAMS.Code,
+				Message: response.INVALID_PARAMS.Msg,
+				Data:    def.SyncMindMapsResp{},
+			})
+			return
+		}
+
+		rsp, err := handler.GetHandler().SyncMindMaps(ctx, req)
+		zlog.CtxAllInOne(ctx, "sync_mindmaps", req, rsp, err)
+
+		r := response.NewResponse(gCtx)
+		if err != nil {
+			msgCode := mapMindMapServiceErrorToMsgCode(err)
+			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
+				Code:    msgCode.Code,
+				Message: msgCode.Msg,
+				Data:    def.SyncMindMapsResp{},
+			})
+			return
+		} else {
+			r.Success(rsp)
+		}
+	}
+}
+
+// SearchMindMaps
+//
+//	@Description:[GET] /api/biz/v1/mindmap/search?q=关键词
+//	@return gin.HandlerFunc
+func SearchMindMaps() gin.HandlerFunc {
+	return func(gCtx *gin.Context) {
+		req := &def.SearchMindMapsReq{}
+		ctx := gCtx.Request.Context()
+
+		// 绑定查询参数
+		if err := gCtx.ShouldBindQuery(req); err != nil {
+			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
+				Code:    response.INVALID_PARAMS.Code,
+				Message: response.INVALID_PARAMS.Msg,
+				Data:    def.SearchMindMapsResp{},
+			})
+			return
+		}
+
+		rsp, err := handler.GetHandler().SearchMindMaps(ctx, req)
+		zlog.CtxAllInOne(ctx, "search_mindmaps", req, rsp, err)
+
+		r := response.NewResponse(gCtx)
+		if err != nil {
+			msgCode := mapMindMapServiceErrorToMsgCode(err)
+			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
+				Code:    msgCode.Code,
+				Message: msgCode.Msg,
+				Data:    def.SearchMindMapsResp{},
+			})
+			return
+		}
+		r.Success(rsp)
+	}
+}
+
+// ListTrashMindMaps
+//
+//	@Description:[GET] /api/biz/v1/mindmap/trash
+//	@return gin.HandlerFunc
+func ListTrashMindMaps() gin.HandlerFunc {
+	return func(gCtx *gin.Context) {
+		req := &def.ListMindMapsReq{}
+		ctx := gCtx.Request.Context()
+
+		// 绑定查询参数
+		if err := gCtx.ShouldBindQuery(req); err != nil {
+			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
+				Code:    response.INVALID_PARAMS.Code,
+				Message: response.INVALID_PARAMS.Msg,
+				Data:    def.ListMindMapsResp{},
+			})
+			return
+		}
+
+		rsp, err := handler.GetHandler().ListTrashMindMaps(ctx, req)
+		zlog.CtxAllInOne(ctx, "list_trash_mindmaps", req, rsp, err)
+
+		r := response.NewResponse(gCtx)
+		if err != nil {
+			msgCode := mapMindMapServiceErrorToMsgCode(err)
+			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
+				Code:    msgCode.Code,
+				Message: msgCode.Msg,
+				Data:    def.ListMindMapsResp{},
+			})
+			return
+		}
+		r.Success(rsp)
+	}
+}
+
+// RestoreMindMap
+//
+//	@Description:[POST] /api/biz/v1/mindmap/:id/restore
+//	@return gin.HandlerFunc
+func RestoreMindMap() gin.HandlerFunc {
+	return func(gCtx *gin.Context) {
+		mapID := gCtx.Param("id")
+		ctx := gCtx.Request.Context()
+
+		// 参数校验
+		if mapID == "" {
+			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
+				Code:    response.PARAM_NOT_VALID.Code,
+				Message: response.PARAM_NOT_VALID.Msg,
+				Data:    def.RestoreMindMapResp{Success: false},
+			})
+			return
+		}
+
+		rsp, err := handler.GetHandler().RestoreMindMap(ctx, mapID)
+		zlog.CtxAllInOne(ctx, "restore_mindmap", mapID, rsp, err)
+
+		r := response.NewResponse(gCtx)
+		if err != nil {
+			msgCode := mapMindMapServiceErrorToMsgCode(err)
+			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
+				Code:    msgCode.Code,
+				Message: msgCode.Msg,
+				Data:    def.RestoreMindMapResp{Success: false},
+			})
+			return
+		}
+		r.Success(rsp)
+	}
+}
+
+// PurgeMindMap
+//
+//	@Description:[DELETE] /api/biz/v1/mindmap/:id/permanent
+//	@return gin.HandlerFunc
+func PurgeMindMap() gin.HandlerFunc {
+	return func(gCtx *gin.Context) {
+		mapID := gCtx.Param("id")
+		ctx := gCtx.Request.Context()
+
+		// 参数校验
+		if mapID == "" {
+			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
+				Code:    response.PARAM_NOT_VALID.Code,
+				Message: response.PARAM_NOT_VALID.Msg,
+				Data:    def.PurgeMindMapResp{Success: false},
+			})
+			return
+		}
+
+		rsp, err := handler.GetHandler().PurgeMindMap(ctx, mapID)
+		zlog.CtxAllInOne(ctx, "purge_mindmap", mapID, rsp, err)
+
+		r := response.NewResponse(gCtx)
+		if err != nil {
+			msgCode := mapMindMapServiceErrorToMsgCode(err)
+			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
+				Code:    msgCode.Code,
+				Message: msgCode.Msg,
+				Data:    def.PurgeMindMapResp{Success: false},
+			})
+			return
+		}
+		r.Success(rsp)
+	}
+}
+
+// PatchMindMap
+//
+//	@Description:[PATCH] /api/biz/v1/mindmap/:id
+//	@return gin.HandlerFunc
+func PatchMindMap() gin.HandlerFunc {
+	return func(gCtx *gin.Context) {
+		mapID := gCtx.Param("id")
+		req := &def.PatchMindMapReq{}
+		ctx := gCtx.Request.Context()
+
+		// 参数校验
+		if mapID == "" {
+			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
+				Code:    response.PARAM_NOT_VALID.Code,
+				Message: response.PARAM_NOT_VALID.Msg,
+				Data:    def.PatchMindMapResp{Success: false},
+			})
+			return
+		}
+
+		// 绑定JSON请求体
+		if err := gCtx.ShouldBindJSON(req); err != nil {
+			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
+				Code:    response.INVALID_PARAMS.Code,
+				Message: response.INVALID_PARAMS.Msg,
+				Data:    def.PatchMindMapResp{Success: false},
+			})
+			return
+		}
+
+		rsp, err := handler.GetHandler().PatchMindMap(ctx, mapID, req)
+		zlog.CtxAllInOne(ctx, "patch_mindmap", map[string]interface{}{"mapID": mapID, "req": req}, rsp, err)
+
+		r := response.NewResponse(gCtx)
+		if err != nil {
+			msgCode := mapMindMapServiceErrorToMsgCode(err)
+			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
+				Code:    msgCode.Code,
+				Message: msgCode.Msg,
+				Data:    def.PatchMindMapResp{Success: false},
+			})
+			return
+		} else {
+			r.Success(rsp)
+		}
+	}
+}
+
+// ImportMindMap
+//
+//	@Description:[POST] /api/biz/v1/mindmap/import
+//	@return gin.HandlerFunc
+func ImportMindMap() gin.HandlerFunc {
+	return func(gCtx *gin.Context) {
+		req := &def.ImportMindMapReq{}
+		ctx := gCtx.Request.Context()
+
+		if gCtx.ContentType() != "multipart/form-data" {
+			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
+				Code:    response.INVALID_CONTENT_TYPE.Code,
+				Message: response.INVALID_CONTENT_TYPE.Msg,
+				Data:    def.ImportMindMapResp{},
+			})
+			return
+		}
+
+		// 接收文件
+		file, err := gCtx.FormFile("file")
+		if err != nil {
+			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
+				Code:    response.INTERNAL_FILE_UPLOAD_ERROR.Code,
+				Message: response.INTERNAL_FILE_UPLOAD_ERROR.Msg + err.Error(),
+				Data:    def.ImportMindMapResp{},
+			})
+			return
+		}
+
+		// 绑定其他表单参数
+		if err := gCtx.ShouldBind(req); err != nil {
+			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
+				Code:    response.INVALID_PARAMS.Code,
+				Message: response.INVALID_PARAMS.Msg,
+				Data:    def.ImportMindMapResp{},
+			})
+			return
+		}
+		req.File = file
+
+		rsp, err := handler.GetHandler().ImportMindMap(ctx, req)
+		zlog.CtxAllInOne(ctx, "import_mindmap", req, rsp, err)
+
+		r := response.NewResponse(gCtx)
+		if err != nil {
+			msgCode := mapMindMapServiceErrorToMsgCode(err)
+			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
+				Code:    msgCode.Code,
+				Message: msgCode.Msg,
+				Data:    def.ImportMindMapResp{},
+			})
+			return
+		} else {
+			r.Success(rsp)
+		}
+	}
+}
+
+// ExportMindMap
+//
+//	@Description:[GET] /api/biz/v1/mindmap/:id/export?format=markdown|opml|freemind|svg|png|pdf|docx|pptx
+//	@return gin.HandlerFunc
+func ExportMindMap() gin.HandlerFunc {
+	return func(gCtx *gin.Context) {
+		mapID := gCtx.Param("id")
+		req := &def.ExportMindMapReq{}
+		ctx := gCtx.Request.Context()
+
+		// 参数校验
+		if mapID == "" {
+			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
+				Code:    response.PARAM_NOT_VALID.Code,
+				Message: response.PARAM_NOT_VALID.Msg,
+				Data:    def.ExportMindMapResp{},
+			})
+			return
+		}
+
+		// 绑定查询参数
+		if err := gCtx.ShouldBindQuery(req); err != nil {
+			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
+				Code:    response.INVALID_PARAMS.Code,
+				Message: response.INVALID_PARAMS.Msg,
+				Data:    def.ExportMindMapResp{},
+			})
+			return
+		}
+
+		rsp, err := handler.GetHandler().ExportMindMap(ctx, mapID, req)
+		zlog.CtxAllInOne(ctx, "export_mindmap", map[string]interface{}{"mapID": mapID, "req": req}, rsp, err)
+
+		if err != nil {
+			msgCode := mapMindMapServiceErrorToMsgCode(err)
+			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
+				Code:    msgCode.Code,
+				Message: msgCode.Msg,
+				Data:    def.ExportMindMapResp{},
+			})
+			return
+		}
+
+		// 以附件形式下载，文件名按 RFC 2231 编码以支持中文标题
+		gCtx.Header("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": rsp.FileName}))
+		gCtx.Data(http.StatusOK, rsp.ContentType, rsp.Content)
+	}
+}
+
+// GenerateThumbnail
+//
+//	@Description:[POST] /api/biz/v1/mindmap/:id/thumbnail
+//	@return gin.HandlerFunc
+func GenerateThumbnail() gin.HandlerFunc {
+	return func(gCtx *gin.Context) {
+		mapID := gCtx.Param("id")
+		ctx := gCtx.Request.Context()
+
+		// 参数校验
+		if mapID == "" {
+			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
+				Code:    response.PARAM_NOT_VALID.Code,
+				Message: response.PARAM_NOT_VALID.Msg,
+				Data:    def.GenerateThumbnailResp{},
+			})
+			return
+		}
+
+		rsp, err := handler.GetHandler().GenerateThumbnail(ctx, mapID)
+		zlog.CtxAllInOne(ctx, "generate_thumbnail", mapID, rsp, err)
+
+		r := response.NewResponse(gCtx)
+		if err != nil {
+			msgCode := mapMindMapServiceErrorToMsgCode(err)
+			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
+				Code:    msgCode.Code,
+				Message: msgCode.Msg,
+				Data:    def.GenerateThumbnailResp{},
+			})
+			return
+		}
+		r.Success(rsp)
+	}
+}
+
+// ListMindMapRevisions
+//
+//	@Description:[GET] /api/biz/v1/mindmap/:id/versions
+//	@return gin.HandlerFunc
+func ListMindMapRevisions() gin.HandlerFunc {
+	return func(gCtx *gin.Context) {
+		mapID := gCtx.Param("id")
+		req := &def.ListMindMapRevisionsReq{}
+		ctx := gCtx.Request.Context()
+
+		// 参数校验
+		if mapID == "" {
+			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
+				Code:    response.PARAM_NOT_VALID.Code,
+				Message: response.PARAM_NOT_VALID.Msg,
+				Data:    def.ListMindMapRevisionsResp{},
+			})
+			return
+		}
+
+		// 绑定查询参数
+		if err := gCtx.ShouldBindQuery(req); err != nil {
+			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
+				Code:    response.INVALID_PARAMS.Code,
+				Message: response.INVALID_PARAMS.Msg,
+				Data:    def.ListMindMapRevisionsResp{},
+			})
+			return
+		}
+
+		rsp, err := handler.GetHandler().ListMindMapRevisions(ctx, mapID, req)
+		zlog.CtxAllInOne(ctx, "list_mindmap_revisions", map[string]interface{}{"mapID": mapID, "req": req}, rsp, err)
+
+		r := response.NewResponse(gCtx)
+		if err != nil {
+			msgCode := mapMindMapServiceErrorToMsgCode(err)
+			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
+				Code:    msgCode.Code,
+				Message: msgCode.Msg,
+				Data:    def.ListMindMapRevisionsResp{},
+			})
+			return
+		} else {
+			r.Success(rsp)
+		}
+	}
+}
+
+// GetMindMapRevision
+//
+//	@Description:[GET] /api/biz/v1/mindmap/:id/versions/:version
+//	@return gin.HandlerFunc
+func GetMindMapRevision() gin.HandlerFunc {
+	return func(gCtx *gin.Context) {
+		mapID := gCtx.Param("id")
+		ctx := gCtx.Request.Context()
+
+		// 参数校验
+		version, parseErr := strconv.ParseInt(gCtx.Param("version"), 10, 64)
+		if mapID == "" || parseErr != nil || version <= 0 {
+			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
+				Code:    response.PARAM_NOT_VALID.Code,
+				Message: response.PARAM_NOT_VALID.Msg,
+				Data:    def.GetMindMapRevisionResp{},
+			})
+			return
+		}
+
+		rsp, err := handler.GetHandler().GetMindMapRevision(ctx, mapID, version)
+		zlog.CtxAllInOne(ctx, "get_mindmap_revision", map[string]interface{}{"mapID": mapID, "version": version}, rsp, err)
+
+		r := response.NewResponse(gCtx)
+		if err != nil {
+			msgCode := mapMindMapServiceErrorToMsgCode(err)
+			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
+				Code:    msgCode.Code,
+				Message: msgCode.Msg,
+				Data:    def.GetMindMapRevisionResp{},
+			})
+			return
+		} else {
+			r.Success(rsp)
+		}
+	}
+}
+
+// RestoreMindMapRevision
+//
+//	@Description:[POST] /api/biz/v1/mindmap/:id/versions/:version/restore
+//	@return gin.HandlerFunc
+func RestoreMindMapRevision() gin.HandlerFunc {
+	return func(gCtx *gin.Context) {
+		mapID := gCtx.Param("id")
+		req := &def.RestoreMindMapRevisionReq{}
+		ctx := gCtx.Request.Context()
+
+		// 参数校验
+		version, parseErr := strconv.ParseInt(gCtx.Param("version"), 10, 64)
+		if mapID == "" || parseErr != nil || version <= 0 {
+			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
+				Code:    response.PARAM_NOT_VALID.Code,
+				Message: response.PARAM_NOT_VALID.Msg,
+				Data:    def.RestoreMindMapRevisionResp{Success: false},
+			})
+			return
+		}
+
+		// 请求体仅用于携带乐观锁版本号，通过 If-Match 传入版本号时可省略
+		if gCtx.Request.ContentLength > 0 {
+			if err := gCtx.ShouldBindJSON(req); err != nil {
+				gCtx.JSON(http.StatusOK, response.JsonMsgResult{
+					Code:    response.INVALID_PARAMS.Code,
+					Message: response.INVALID_PARAMS.Msg,
+					Data:    def.RestoreMindMapRevisionResp{Success: false},
+				})
+				return
+			}
+		}
+
+		// 条件请求：与更新导图一致，If-Match 中的版本号作为乐观锁校验的版本
+		ifMatch := gCtx.GetHeader("If-Match")
+		expectedVersion, status, ok := mergeIfMatchVersion(ifMatch, req.Version)
+		if !ok {
+			msgCode := response.INVALID_PARAMS
+			if status == http.StatusPreconditionFailed {
+				msgCode = response.MINDMAP_VERSION_CONFLICT
+			}
+			gCtx.JSON(status, response.JsonMsgResult{
+				Code:    msgCode.Code,
+				Message: msgCode.Msg,
+				Data:    def.RestoreMindMapRevisionResp{Success: false},
+			})
+			return
+		}
+		req.Version = expectedVersion
+
+		rsp, err := handler.GetHandler().RestoreMindMapRevision(ctx, mapID, version, req)
+		zlog.CtxAllInOne(ctx, "restore_mindmap_revision", map[string]interface{}{"mapID": mapID, "version": version, "req": req}, rsp, err)
+
+		r := response.NewResponse(gCtx)
+		if err != nil {
+			msgCode := mapMindMapServiceErrorToMsgCode(err)
+			status := http.StatusOK
+			if ifMatch != "" && isPreconditionFailure(err) {
+				status = http.StatusPreconditionFailed
+			}
+			gCtx.JSON(status, response.JsonMsgResult{
+				Code:    msgCode.Code,
+				Message: msgCode.Msg,
+				Data:    def.RestoreMindMapRevisionResp{Success: false},
+			})
+			return
+		} else {
+			r.Success(rsp)
+		}
+	}
+}
+
+// DiffMindMap
+//
+//	@Description:[GET] /api/biz/v1/mindmap/:id/diff
+//	@return gin.HandlerFunc
+func DiffMindMap() gin.HandlerFunc {
+	return func(gCtx *gin.Context) {
+		mapID := gCtx.Param("id")
+		req := &def.DiffMindMapReq{}
+		ctx := gCtx.Request.Context()
+
+		// 参数校验
+		if mapID == "" {
+			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
+				Code:    response.PARAM_NOT_VALID.Code,
+				Message: response.PARAM_NOT_VALID.Msg,
+				Data:    def.DiffMindMapResp{},
+			})
+			return
+		}
+
+		// 绑定查询参数
+		if err := gCtx.ShouldBindQuery(req); err != nil {
+			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
+				Code:    response.INVALID_PARAMS.Code,
+				Message: response.INVALID_PARAMS.Msg,
+				Data:    def.DiffMindMapResp{},
+			})
+			return
+		}
+
+		rsp, err := handler.GetHandler().DiffMindMap(ctx, mapID, req)
+		zlog.CtxAllInOne(ctx, "diff_mindmap", map[string]interface{}{"mapID": mapID, "req": req}, rsp, err)
+
+		r := response.NewResponse(gCtx)
+		if err != nil {
+			msgCode := mapMindMapServiceErrorToMsgCode(err)
+			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
+				Code:    msgCode.Code,
+				Message: msgCode.Msg,
+				Data:    def.DiffMindMapResp{},
+			})
+			return
+		} else {
+			r.Success(rsp)
+		}
+	}
+}
+
+// DiffMindMapProposal
+//
+//	@Description:[POST] /api/biz/v1/mindmap/:id/diff
+//	@return gin.HandlerFunc
+func DiffMindMapProposal() gin.HandlerFunc {
+	return func(gCtx *gin.Context) {
+		mapID := gCtx.Param("id")
+		req := &def.DiffMindMapProposalReq{}
+		ctx := gCtx.Request.Context()
+
+		// 参数校验
+		if mapID == "" {
+			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
+				Code:    response.PARAM_NOT_VALID.Code,
+				Message: response.PARAM_NOT_VALID.Msg,
+				Data:    def.DiffMindMapResp{},
+			})
+			return
+		}
+
+		// 绑定JSON请求体
+		if err := gCtx.ShouldBindJSON(req); err != nil {
+			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
+				Code:    response.INVALID_PARAMS.Code,
+				Message: response.INVALID_PARAMS.Msg,
+				Data:    def.DiffMindMapResp{},
+			})
+			return
+		}
+
+		rsp, err := handler.GetHandler().DiffMindMapProposal(ctx, mapID, req)
+		zlog.CtxAllInOne(ctx, "diff_mindmap_proposal", map[string]interface{}{"mapID": mapID, "from": req.From}, rsp, err)
+
+		r := response.NewResponse(gCtx)
+		if err != nil {
+			msgCode := mapMindMapServiceErrorToMsgCode(err)
+			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
+				Code:    msgCode.Code,
+				Message: msgCode.Msg,
+				Data:    def.DiffMindMapResp{},
+			})
+			return
+		} else {
+			r.Success(rsp)
+		}
+	}
+}
+
+// CreateMindMapShare
+//
+//	@Description:[POST] /api/biz/v1/mindmap/:id/shares
+//	@return gin.HandlerFunc
+func CreateMindMapShare() gin.HandlerFunc {
+	return func(gCtx *gin.Context) {
+		mapID := gCtx.Param("id")
+		req := &def.CreateMindMapShareReq{}
+		ctx := gCtx.Request.Context()
+
+		// 参数校验
+		if mapID == "" {
+			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
+				Code:    response.PARAM_NOT_VALID.Code,
+				Message: response.PARAM_NOT_VALID.Msg,
+				Data:    def.CreateMindMapShareResp{},
+			})
+			return
+		}
+
+		// 绑定JSON请求体，请求体可为空
+		if gCtx.Request.ContentLength != 0 {
+			if err := gCtx.ShouldBindJSON(req); err != nil {
+				gCtx.JSON(http.StatusOK, response.JsonMsgResult{
+					Code:    response.INVALID_PARAMS.Code,
+					Message: response.INVALID_PARAMS.Msg,
+					Data:    def.CreateMindMapShareResp{},
+				})
+				return
+			}
+		}
+
+		rsp, err := handler.GetHandler().CreateMindMapShare(ctx, mapID, req)
+		// 不记录访问密码
+		zlog.CtxAllInOne(ctx, "create_mindmap_share", map[string]interface{}{"mapID": mapID, "expiresIn": req.ExpiresIn}, rsp, err)
+
+		r := response.NewResponse(gCtx)
+		if err != nil {
+			msgCode := mapMindMapServiceErrorToMsgCode(err)
+			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
+				Code:    msgCode.Code,
+				Message: msgCode.Msg,
+				Data:    def.CreateMindMapShareResp{},
+			})
+			return
+		}
+		r.Success(rsp)
+	}
+}
+
+// ListMindMapShares
+//
+//	@Description:[GET] /api/biz/v1/mindmap/shares
+//	@return gin.HandlerFunc
+func ListMindMapShares() gin.HandlerFunc {
+	return func(gCtx *gin.Context) {
+		req := &def.ListMindMapSharesReq{}
+		ctx := gCtx.Request.Context()
+
+		// 绑定查询参数
+		if err := gCtx.ShouldBindQuery(req); err != nil {
+			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
+				Code:    response.INVALID_PARAMS.Code,
+				Message: response.INVALID_PARAMS.Msg,
+				Data:    def.ListMindMapSharesResp{},
+			})
+			return
+		}
+
+		rsp, err := handler.GetHandler().ListMindMapShares(ctx, req)
+		zlog.CtxAllInOne(ctx, "list_mindmap_shares", req, rsp, err)
+
+		r := response.NewResponse(gCtx)
+		if err != nil {
+			msgCode := mapMindMapServiceErrorToMsgCode(err)
+			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
+				Code:    msgCode.Code,
+				Message: msgCode.Msg,
+				Data:    def.ListMindMapSharesResp{},
+			})
+			return
+		}
+		r.Success(rsp)
+	}
+}
+
+// RevokeMindMapShare
+//
+//	@Description:[DELETE] /api/biz/v1/mindmap/shares/:share_id
+//	@return gin.HandlerFunc
+func RevokeMindMapShare() gin.HandlerFunc {
+	return func(gCtx *gin.Context) {
+		shareID := gCtx.Param("share_id")
+		ctx := gCtx.Request.Context()
+
+		// 参数校验
+		if shareID == "" {
+			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
+				Code:    response.PARAM_NOT_VALID.Code,
+				Message: response.PARAM_NOT_VALID.Msg,
+				Data:    def.RevokeMindMapShareResp{Success: false},
+			})
+			return
+		}
+
+		rsp, err := handler.GetHandler().RevokeMindMapShare(ctx, shareID)
+		zlog.CtxAllInOne(ctx, "revoke_mindmap_share", shareID, rsp, err)
+
+		r := response.NewResponse(gCtx)
+		if err != nil {
+			msgCode := mapMindMapServiceErrorToMsgCode(err)
+			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
+				Code:    msgCode.Code,
+				Message: msgCode.Msg,
+				Data:    def.RevokeMindMapShareResp{Success: false},
+			})
+			return
+		}
+		r.Success(rsp)
+	}
+}
+
+// GetSharedMindMap
+//
+//	@Description:[GET] /api/biz/v1/share/:token 无需登录，设置了密码时通过 X-Share-Password 请求头传递
+//	@return gin.HandlerFunc
+func GetSharedMindMap() gin.HandlerFunc {
+	return func(gCtx *gin.Context) {
+		token := gCtx.Param("token")
+		req := &def.GetSharedMindMapReq{}
+		ctx := gCtx.Request.Context()
+
+		// 绑定请求头
+		if err := gCtx.ShouldBindHeader(req); err != nil {
+			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
+				Code:    response.INVALID_PARAMS.Code,
+				Message: response.INVALID_PARAMS.Msg,
+				Data:    def.GetSharedMindMapResp{},
+			})
+			return
+		}
+
+		rsp, err := handler.GetHandler().GetSharedMindMap(ctx, token, req)
+		zlog.CtxAllInOne(ctx, "get_shared_mindmap", req, rsp, err)
+
+		r := response.NewResponse(gCtx)
+		if err != nil {
+			msgCode := mapMindMapServiceErrorToMsgCode(err)
+			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
+				Code:    msgCode.Code,
+				Message: msgCode.Msg,
+				Data:    def.GetSharedMindMapResp{},
+			})
+			return
+		}
+		r.Success(rsp)
+	}
+}
+
+// ExportSharedMindMap
+//
+//	@Description:[GET] /api/biz/v1/share/:token/image?format=png 无需登录，成功时直接返回图片
+//	@return gin.HandlerFunc
+func ExportSharedMindMap() gin.HandlerFunc {
+	return func(gCtx *gin.Context) {
+		token := gCtx.Param("token")
+		req := &def.ExportSharedMindMapReq{}
+		ctx := gCtx.Request.Context()
+
+		// 绑定查询参数与请求头
+		if err := gCtx.ShouldBindQuery(req); err != nil {
+			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
+				Code:    response.INVALID_PARAMS.Code,
+				Message: response.INVALID_PARAMS.Msg,
+				Data:    def.ExportMindMapResp{},
+			})
+			return
+		}
+		if err := gCtx.ShouldBindHeader(req); err != nil {
+			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
+				Code:    response.INVALID_PARAMS.Code,
+				Message: response.INVALID_PARAMS.Msg,
+				Data:    def.ExportMindMapResp{},
+			})
+			return
+		}
+
+		rsp, err := handler.GetHandler().ExportSharedMindMap(ctx, token, req)
+		zlog.CtxAllInOne(ctx, "export_shared_mindmap", req, rsp, err)
+
+		if err != nil {
+			msgCode := mapMindMapServiceErrorToMsgCode(err)
+			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
+				Code:    msgCode.Code,
+				Message: msgCode.Msg,
+				Data:    def.ExportMindMapResp{},
+			})
+			return
+		}
+
+		// 以内联方式返回，便于直接在页面中展示
+		gCtx.Header("Content-Disposition", mime.FormatMediaType("inline", map[string]string{"filename": rsp.FileName}))
+		gCtx.Data(http.StatusOK, rsp.ContentType, rsp.Content)
+	}
+}
+
+// ListMindMapMembers
+//
+//	@Description:[GET] /api/biz/v1/mindmap/:id/members
+//	@return gin.HandlerFunc
+func ListMindMapMembers() gin.HandlerFunc {
+	return func(gCtx *gin.Context) {
+		mapID := gCtx.Param("id")
+		ctx := gCtx.Request.Context()
+
+		// 参数校验
+		if mapID == "" {
+			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
+				Code:    response.PARAM_NOT_VALID.Code,
+				Message: response.PARAM_NOT_VALID.Msg,
+				Data:    def.ListMindMapMembersResp{},
+			})
+			return
+		}
+
+		rsp, err := handler.GetHandler().ListMindMapMembers(ctx, mapID)
+		zlog.CtxAllInOne(ctx, "list_mindmap_members", mapID, rsp, err)
+
+		r := response.NewResponse(gCtx)
+		if err != nil {
+			msgCode := mapMindMapServiceErrorToMsgCode(err)
+			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
+				Code:    msgCode.Code,
+				Message: msgCode.Msg,
+				Data:    def.ListMindMapMembersResp{},
+			})
+			return
+		}
+		r.Success(rsp)
+	}
+}
+
+// InviteMindMapMember
+//
+//	@Description:[POST] /api/biz/v1/mindmap/:id/members
+//	@return gin.HandlerFunc
+func InviteMindMapMember() gin.HandlerFunc {
+	return func(gCtx *gin.Context) {
+		mapID := gCtx.Param("id")
+		req := &def.InviteMindMapMemberReq{}
+		ctx := gCtx.Request.Context()
+
+		// 参数校验
+		if mapID == "" {
+			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
+				Code:    response.PARAM_NOT_VALID.Code,
+				Message: response.PARAM_NOT_VALID.Msg,
+				Data:    def.InviteMindMapMemberResp{},
+			})
+			return
+		}
+
+		// 绑定JSON请求体
+		if err := gCtx.ShouldBindJSON(req); err != nil {
+			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
+				Code:    response.INVALID_PARAMS.Code,
+				Message: response.INVALID_PARAMS.Msg,
+				Data:    def.InviteMindMapMemberResp{},
+			})
+			return
+		}
+
+		rsp, err := handler.GetHandler().InviteMindMapMember(ctx, mapID, req)
+		zlog.CtxAllInOne(ctx, "invite_mindmap_member", map[string]interface{}{"mapID": mapID, "req": req}, rsp, err)
+
+		r := response.NewResponse(gCtx)
+		if err != nil {
+			msgCode := mapMindMapServiceErrorToMsgCode(err)
+			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
+				Code:    msgCode.Code,
+				Message: msgCode.Msg,
+				Data:    def.InviteMindMapMemberResp{},
+			})
+			return
+		}
+		r.Success(rsp)
+	}
+}
+
+// UpdateMindMapMember
+//
+//	@Description:[PUT] /api/biz/v1/mindmap/:id/members/:user_id
+//	@return gin.HandlerFunc
+func UpdateMindMapMember() gin.HandlerFunc {
+	return func(gCtx *gin.Context) {
+		mapID := gCtx.Param("id")
+		userID := gCtx.Param("user_id")
+		req := &def.UpdateMindMapMemberReq{}
+		ctx := gCtx.Request.Context()
+
+		// 参数校验
+		if mapID == "" || userID == "" {
+			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
+				Code:    response.PARAM_NOT_VALID.Code,
+				Message: response.PARAM_NOT_VALID.Msg,
+				Data:    def.UpdateMindMapMemberResp{Success: false},
+			})
+			return
+		}
+
+		// 绑定JSON请求体
+		if err := gCtx.ShouldBindJSON(req); err != nil {
+			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
+				Code:    response.INVALID_PARAMS.Code,
+				Message: response.INVALID_PARAMS.Msg,
+				Data:    def.UpdateMindMapMemberResp{Success: false},
+			})
+			return
+		}
+
+		rsp, err := handler.GetHandler().UpdateMindMapMember(ctx, mapID, userID, req)
+		zlog.CtxAllInOne(ctx, "update_mindmap_member", map[string]interface{}{"mapID": mapID, "userID": userID, "role": req.Role}, rsp, err)
+
+		r := response.NewResponse(gCtx)
+		if err != nil {
+			msgCode := mapMindMapServiceErrorToMsgCode(err)
+			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
+				Code:    msgCode.Code,
+				Message: msgCode.Msg,
+				Data:    def.UpdateMindMapMemberResp{Success: false},
+			})
+			return
+		}
+		r.Success(rsp)
+	}
+}
+
+// RemoveMindMapMember
+//
+//	@Description:[DELETE] /api/biz/v1/mindmap/:id/members/:user_id
+//	@return gin.HandlerFunc
+func RemoveMindMapMember() gin.HandlerFunc {
+	return func(gCtx *gin.Context) {
+		mapID := gCtx.Param("id")
+		userID := gCtx.Param("user_id")
+		ctx := gCtx.Request.Context()
+
+		// 参数校验
+		if mapID == "" || userID == "" {
+			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
+				Code:    response.PARAM_NOT_VALID.Code,
+				Message: response.PARAM_NOT_VALID.Msg,
+				Data:    def.RemoveMindMapMemberResp{Success: false},
+			})
+			return
+		}
+
+		rsp, err := handler.GetHandler().RemoveMindMapMember(ctx, mapID, userID)
+		zlog.CtxAllInOne(ctx, "remove_mindmap_member", map[string]interface{}{"mapID": mapID, "userID": userID}, rsp, err)
+
+		r := response.NewResponse(gCtx)
+		if err != nil {
+			msgCode := mapMindMapServiceErrorToMsgCode(err)
+			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
+				Code:    msgCode.Code,
+				Message: msgCode.Msg,
+				Data:    def.RemoveMindMapMemberResp{Success: false},
+			})
+			return
+		}
+		r.Success(rsp)
+	}
+}
+
+const (
+	collabWriteWait      = 10 * time.Second
+	collabPongWait       = 60 * time.Second
+	collabPingPeriod     = 25 * time.Second // 同时用于刷新在线状态，需小于在线状态有效期
+	collabMaxMessageSize = 1 << 20
+	collabReplyBuffer    = 16
+)
+
+var collabUpgrader = websocket.Upgrader{
+	ReadBufferSize:  4096,
+	WriteBufferSize: 4096,
+	CheckOrigin:     checkCollabOrigin,
+}
+
+// checkCollabOrigin 仅允许同源或配置的前端域名发起WebSocket握手
+func checkCollabOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" || origin == configs.Config().GetAppConfig().YourFrontendDomain {
+		return true
+	}
+	u, err := url.Parse(origin)
+	return err == nil && strings.EqualFold(u.Host, r.Host)
+}
+
+// MindMapCollab
+//
+//	@Description:[GET] /api/biz/v1/mindmap/:id/collab
+//	@return gin.HandlerFunc
+func MindMapCollab() gin.HandlerFunc {
+	return func(gCtx *gin.Context) {
+		mapID := gCtx.Param("id")
+		ctx := gCtx.Request.Context()
+
+		// 参数校验
+		if mapID == "" || !gCtx.IsWebsocket() {
+			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
+				Code:    response.PARAM_NOT_VALID.Code,
+				Message: response.PARAM_NOT_VALID.Msg,
+				Data:    nil,
+			})
+			return
+		}
+
+		// 握手前加入协作，权限不足等错误按普通接口返回
+		initMsg, events, err := handler.GetHandler().JoinMindMapCollab(ctx, mapID)
+		if err != nil {
+			msgCode := mapMindMapServiceErrorToMsgCode(err)
+			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
+				Code:    msgCode.Code,
+				Message: msgCode.Msg,
+				Data:    nil,
+			})
+			return
+		}
+		connID := initMsg.ConnID
+		defer func() {
+			handler.GetHandler().LeaveMindMapCollab(ctx, mapID, connID)
+			// 等待事件 channel 关闭，避免转换协程阻塞
+			for range events {
+			}
+		}()
+
+		conn, err := collabUpgrader.Upgrade(gCtx.Writer, gCtx.Request, nil)
+		if err != nil {
+			// Upgrade 失败时已向客户端返回 HTTP 错误
+			zlog.CtxWarnf(ctx, "failed to upgrade collab connection, mapID: %s: %v", mapID, err)
+			return
+		}
+		defer conn.Close()
+
+		replies := make(chan *def.CollabMessage, collabReplyBuffer)
+		writerDone := make(chan struct{})
+		go func() {
+			defer close(writerDone)
+			// 写协程退出时关闭连接，使读循环随之结束
+			defer conn.Close()
+			writeCollabMessages(ctx, conn, mapID, connID, initMsg, events, replies)
+		}()
+
+		readCollabMessages(ctx, conn, mapID, connID, replies, writerDone)
+		close(replies)
+		<-writerDone
+	}
+}
+
+// readCollabMessages 读取并处理客户端消息，连接断开或写协程退出时返回
+func readCollabMessages(ctx context.Context, conn *websocket.Conn, mapID, connID string, replies chan<- *def.CollabMessage, writerDone <-chan struct{}) {
+	conn.SetReadLimit(collabMaxMessageSize)
+	_ = conn.SetReadDeadline(time.Now().Add(collabPongWait))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(collabPongWait))
+	})
+
+	for {
+		req := &def.CollabMessage{}
+		if err := conn.ReadJSON(req); err != nil {
+			if websocket.IsUnexpectedCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway) {
+				zlog.CtxWarnf(ctx, "collab connection closed unexpectedly, mapID: %s, connID: %s: %v", mapID, connID, err)
+			}
+			return
+		}
+
+		var reply *def.CollabMessage
+		if err := binding.Validator.ValidateStruct(req); err != nil {
+			reply = newCollabErrorMessage(req, response.INVALID_PARAMS)
+		} else {
+			rsp, err := handler.GetHandler().HandleMindMapCollabMessage(ctx, mapID, connID, req)
+			switch {
+			case err != nil:
+				reply = newCollabErrorMessage(req, mapMindMapServiceErrorToMsgCode(err))
+			case rsp != nil:
+				reply = rsp
+			default:
+				continue
+			}
+		}
+
+		select {
+		case replies <- reply:
+		case <-writerDone:
+			return
+		}
+	}
+}
+
+// writeCollabMessages 依次写出 init 消息、处理结果与协作事件，并定期发送心跳、刷新在线状态
+func writeCollabMessages(ctx context.Context, conn *websocket.Conn, mapID, connID string, initMsg *def.CollabMessage, events <-chan *def.CollabMessage, replies <-chan *def.CollabMessage) {
+	ticker := time.NewTicker(collabPingPeriod)
+	defer ticker.Stop()
+
+	write := func(msg *def.CollabMessage) bool {
+		_ = conn.SetWriteDeadline(time.Now().Add(collabWriteWait))
+		if err := conn.WriteJSON(msg); err != nil {
+			zlog.CtxWarnf(ctx, "failed to write collab message, mapID: %s, connID: %s: %v", mapID, connID, err)
+			return false
+		}
+		return true
+	}
+	closeWith := func(code int, text string) {
+		_ = conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(code, text), time.Now().Add(collabWriteWait))
+	}
+
+	if !write(initMsg) {
+		return
+	}
+	for {
+		select {
+		case msg, ok := <-replies:
+			if !ok {
+				closeWith(websocket.CloseNormalClosure, "")
+				return
+			}
+			if !write(msg) {
+				return
+			}
+
+		case msg, ok := <-events:
+			if !ok {
+				// 消费过慢被移除，客户端需重新连接并同步
+				closeWith(websocket.CloseTryAgainLater, "resync required")
+				return
+			}
+			if !write(msg) {
+				return
+			}
+
+		case <-ticker.C:
+			// 定期校验协作权限并刷新在线状态，成员被移除后断开连接
+			if err := handler.GetHandler().TouchMindMapCollab(ctx, mapID, connID); err != nil {
+				msgCode := mapMindMapServiceErrorToMsgCode(err)
+				write(&def.CollabMessage{Type: def.CollabMsgError, Code: msgCode.Code, Message: msgCode.Msg})
+				closeWith(websocket.ClosePolicyViolation, "")
+				return
+			}
+			_ = conn.SetWriteDeadline(time.Now().Add(collabWriteWait))
+			if err := conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// newCollabErrorMessage 构建错误消息，op 消息被拒绝时返回 reject
+func newCollabErrorMessage(req *def.CollabMessage, msgCode response.MsgCode) *def.CollabMessage {
+	msg := &def.CollabMessage{
+		Type:      def.CollabMsgError,
+		ClientSeq: req.ClientSeq,
+		Code:      msgCode.Code,
+		Message:   msgCode.Msg,
+	}
+	if req.Type == def.CollabMsgOp {
+		msg.Type = def.CollabMsgReject
+	}
+	return msg
+}
+
+// ListMindMapTemplates
+//
+//	@Description:[GET] /api/biz/v1/mindmap/templates
+//	@return gin.HandlerFunc
+func ListMindMapTemplates() gin.HandlerFunc {
+	return func(gCtx *gin.Context) {
+		req := &def.ListMindMapTemplatesReq{}
+		ctx := gCtx.Request.Context()
+
+		// 绑定查询参数
+		if err := gCtx.ShouldBindQuery(req); err != nil {
+			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
+				Code:    response.INVALID_PARAMS.Code,
+				Message: response.INVALID_PARAMS.Msg,
+				Data:    def.ListMindMapTemplatesResp{},
+			})
+			return
+		}
+
+		rsp, err := handler.GetHandler().ListMindMapTemplates(ctx, req)
+		zlog.CtxAllInOne(ctx, "list_mindmap_templates", req, rsp, err)
+
+		r := response.NewResponse(gCtx)
+		if err != nil {
+			msgCode := mapMindMapServiceErrorToMsgCode(err)
+			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
+				Code:    msgCode.Code,
+				Message: msgCode.Msg,
+				Data:    def.ListMindMapTemplatesResp{},
+			})
+			return
+		} else {
+			r.Success(rsp)
+		}
+	}
+}
+
+// GetMindMapTemplate
+//
+//	@Description:[GET] /api/biz/v1/mindmap/templates/:template_id
+//	@return gin.HandlerFunc
+func GetMindMapTemplate() gin.HandlerFunc {
+	return func(gCtx *gin.Context) {
+		templateID := gCtx.Param("template_id")
+		ctx := gCtx.Request.Context()
+
+		// 参数校验
+		if templateID == "" {
+			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
+				Code:    response.PARAM_NOT_VALID.Code,
+				Message: response.PARAM_NOT_VALID.Msg,
+				Data:    def.GetMindMapTemplateResp{},
+			})
+			return
+		}
+
+		rsp, err := handler.GetHandler().GetMindMapTemplate(ctx, templateID)
+		zlog.CtxAllInOne(ctx, "get_mindmap_template", templateID, rsp, err)
+
+		r := response.NewResponse(gCtx)
+		if err != nil {
+			msgCode := mapMindMapServiceErrorToMsgCode(err)
+			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
+				Code:    msgCode.Code,
+				Message: msgCode.Msg,
+				Data:    def.GetMindMapTemplateResp{},
+			})
+			return
+		} else {
+			r.Success(rsp)
+		}
+	}
+}
+
+// SaveMindMapAsTemplate
+//
+//	@Description:[POST] /api/biz/v1/mindmap/:id/template
+//	@return gin.HandlerFunc
+func SaveMindMapAsTemplate() gin.HandlerFunc {
+	return func(gCtx *gin.Context) {
+		mapID := gCtx.Param("id")
+		req := &def.SaveMindMapAsTemplateReq{}
+		ctx := gCtx.Request.Context()
+
+		// 参数校验
+		if mapID == "" {
+			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
+				Code:    response.PARAM_NOT_VALID.Code,
+				Message: response.PARAM_NOT_VALID.Msg,
+				Data:    def.SaveMindMapAsTemplateResp{},
+			})
+			return
+		}
+
+		// 绑定JSON请求体，请求体可为空
+		if gCtx.Request.ContentLength != 0 {
+			if err := gCtx.ShouldBindJSON(req); err != nil {
+				gCtx.JSON(http.StatusOK, response.JsonMsgResult{
+					Code:    response.INVALID_PARAMS.Code,
+					Message: response.INVALID_PARAMS.Msg,
+					Data:    def.SaveMindMapAsTemplateResp{},
+				})
+				return
+			}
+		}
+
+		rsp, err := handler.GetHandler().SaveMindMapAsTemplate(ctx, mapID, req)
+		zlog.CtxAllInOne(ctx, "save_mindmap_as_template", map[string]interface{}{"mapID": mapID, "req": req}, rsp, err)
+
+		r := response.NewResponse(gCtx)
+		if err != nil {
+			msgCode := mapMindMapServiceErrorToMsgCode(err)
+			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
+				Code:    msgCode.Code,
+				Message: msgCode.Msg,
+				Data:    def.SaveMindMapAsTemplateResp{},
+			})
+			return
+		} else {
+			r.Success(rsp)
+		}
+	}
+}
+
+// DeleteMindMapTemplate
+//
+//	@Description:[DELETE] /api/biz/v1/mindmap/templates/:template_id
+//	@return gin.HandlerFunc
+func DeleteMindMapTemplate() gin.HandlerFunc {
+	return func(gCtx *gin.Context) {
+		templateID := gCtx.Param("template_id")
+		ctx := gCtx.Request.Context()
+
+		// 参数校验
+		if templateID == "" {
+			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
+				Code:    response.PARAM_NOT_VALID.Code,
+				Message: response.PARAM_NOT_VALID.Msg,
+				Data:    def.DeleteMindMapTemplateResp{},
+			})
+			return
+		}
+
+		rsp, err := handler.GetHandler().DeleteMindMapTemplate(ctx, templateID)
+		zlog.CtxAllInOne(ctx, "delete_mindmap_template", templateID, rsp, err)
+
+		r := response.NewResponse(gCtx)
+		if err != nil {
+			msgCode := mapMindMapServiceErrorToMsgCode(err)
+			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
+				Code:    msgCode.Code,
+				Message: msgCode.Msg,
+				Data:    def.DeleteMindMapTemplateResp{},
+			})
+			return
+		} else {
+			r.Success(rsp)
+		}
+	}
+}
+
+// CreateMindMapFromTemplate
+//
+//	@Description:[POST] /api/biz/v1/mindmap/templates/:template_id/mindmaps
+//	@return gin.HandlerFunc
+func CreateMindMapFromTemplate() gin.HandlerFunc {
+	return func(gCtx *gin.Context) {
+		templateID := gCtx.Param("template_id")
+		req := &def.CreateMindMapFromTemplateReq{}
+		ctx := gCtx.Request.Context()
+
+		// 参数校验
+		if templateID == "" {
+			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
+				Code:    response.PARAM_NOT_VALID.Code,
+				Message: response.PARAM_NOT_VALID.Msg,
+				Data:    def.CreateMindMapFromTemplateResp{},
+			})
+			return
+		}
+
+		// 绑定JSON请求体，请求体可为空
+		if gCtx.Request.ContentLength != 0 {
+			if err := gCtx.ShouldBindJSON(req); err != nil {
+				gCtx.JSON(http.StatusOK, response.JsonMsgResult{
+					Code:    response.INVALID_PARAMS.Code,
+					Message: response.INVALID_PARAMS.Msg,
+					Data:    def.CreateMindMapFromTemplateResp{},
+				})
+				return
+			}
+		}
+
+		rsp, err := handler.GetHandler().CreateMindMapFromTemplate(ctx, templateID, req)
+		zlog.CtxAllInOne(ctx, "create_mindmap_from_template", map[string]interface{}{"templateID": templateID, "req": req}, rsp, err)
+
+		r := response.NewResponse(gCtx)
+		if err != nil {
+			msgCode := mapMindMapServiceErrorToMsgCode(err)
+			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
+				Code:    msgCode.Code,
+				Message: msgCode.Msg,
+				Data:    def.CreateMindMapFromTemplateResp{},
+			})
+			return
+		} else {
+			r.Success(rsp)
+		}
+	}
+}
+
+// DuplicateMindMap
+//
+//	@Description:[POST] /api/biz/v1/mindmap/:id/duplicate
+//	@return gin.HandlerFunc
+func DuplicateMindMap() gin.HandlerFunc {
+	return func(gCtx *gin.Context) {
+		mapID := gCtx.Param("id")
+		req := &def.DuplicateMindMapReq{}
+		ctx := gCtx.Request.Context()
+
+		// 参数校验
+		if mapID == "" {
+			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
+				Code:    response.PARAM_NOT_VALID.Code,
+				Message: response.PARAM_NOT_VALID.Msg,
+				Data:    def.DuplicateMindMapResp{},
+			})
+			return
+		}
+
+		// 绑定JSON请求体，请求体可为空
+		if gCtx.Request.ContentLength != 0 {
+			if err := gCtx.ShouldBindJSON(req); err != nil {
+				gCtx.JSON(http.StatusOK, response.JsonMsgResult{
+					Code:    response.INVALID_PARAMS.Code,
+					Message: response.INVALID_PARAMS.Msg,
+					Data:    def.DuplicateMindMapResp{},
+				})
+				return
+			}
+		}
+
+		rsp, err := handler.GetHandler().DuplicateMindMap(ctx, mapID, req)
+		zlog.CtxAllInOne(ctx, "duplicate_mindmap", map[string]interface{}{"mapID": mapID, "req": req}, rsp, err)
+
+		r := response.NewResponse(gCtx)
+		if err != nil {
+			msgCode := mapMindMapServiceErrorToMsgCode(err)
+			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
+				Code:    msgCode.Code,
+				Message: msgCode.Msg,
+				Data:    def.DuplicateMindMapResp{},
+			})
+			return
+		} else {
+			r.Success(rsp)
+		}
+	}
+}
+
+// ExtractMindMapSubtree
+//
+//	@Description:[POST] /api/biz/v1/mindmap/:id/extract
+//	@return gin.HandlerFunc
+func ExtractMindMapSubtree() gin.HandlerFunc {
+	return func(gCtx *gin.Context) {
+		mapID := gCtx.Param("id")
+		req := &def.ExtractMindMapSubtreeReq{}
+		ctx := gCtx.Request.Context()
+
+		// 参数校验
+		if mapID == "" {
+			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
+				Code:    response.PARAM_NOT_VALID.Code,
+				Message: response.PARAM_NOT_VALID.Msg,
+				Data:    def.ExtractMindMapSubtreeResp{},
+			})
+			return
+		}
+
+		// 绑定JSON请求体
+		if err := gCtx.ShouldBindJSON(req); err != nil {
+			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
+				Code:    response.INVALID_PARAMS.Code,
+				Message: response.INVALID_PARAMS.Msg,
+				Data:    def.ExtractMindMapSubtreeResp{},
+			})
+			return
+		}
+
+		rsp, err := handler.GetHandler().ExtractMindMapSubtree(ctx, mapID, req)
+		zlog.CtxAllInOne(ctx, "extract_mindmap_subtree", map[string]interface{}{"mapID": mapID, "req": req}, rsp, err)
+
+		r := response.NewResponse(gCtx)
+		if err != nil {
+			msgCode := mapMindMapServiceErrorToMsgCode(err)
+			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
+				Code:    msgCode.Code,
+				Message: msgCode.Msg,
+				Data:    def.ExtractMindMapSubtreeResp{},
+			})
+			return
+		} else {
+			r.Success(rsp)
+		}
+	}
+}
+
+// MergeMindMaps
+//
+//	@Description:[POST] /api/biz/v1/mindmap/merge
+//	@return gin.HandlerFunc
+func MergeMindMaps() gin.HandlerFunc {
+	return func(gCtx *gin.Context) {
+		req := &def.MergeMindMapsReq{}
+		ctx := gCtx.Request.Context()
+
+		// 绑定JSON请求体
+		if err := gCtx.ShouldBindJSON(req); err != nil {
+			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
+				Code:    response.INVALID_PARAMS.Code,
+				Message: response.INVALID_PARAMS.Msg,
+				Data:    def.MergeMindMapsResp{},
+			})
+			return
+		}
+
+		rsp, err := handler.GetHandler().MergeMindMaps(ctx, req)
+		zlog.CtxAllInOne(ctx, "merge_mindmaps", req, rsp, err)
+
+		r := response.NewResponse(gCtx)
+		if err != nil {
+			msgCode := mapMindMapServiceErrorToMsgCode(err)
+			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
+				Code:    msgCode.Code,
+				Message: msgCode.Msg,
+				Data:    def.MergeMindMapsResp{},
+			})
+			return
+		} else {
+			r.Success(rsp)
+		}
+	}
+}
+
+// ListMindMapFolders
+//
+//	@Description:[GET] /api/biz/v1/mindmap/folders
+//	@return gin.HandlerFunc
+func ListMindMapFolders() gin.HandlerFunc {
+	return func(gCtx *gin.Context) {
+		ctx := gCtx.Request.Context()
+
+		rsp, err := handler.GetHandler().ListMindMapFolders(ctx)
+		zlog.CtxAllInOne(ctx, "list_mindmap_folders", nil, rsp, err)
+
+		r := response.NewResponse(gCtx)
+		if err != nil {
+			msgCode := mapMindMapServiceErrorToMsgCode(err)
+			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
+				Code:    msgCode.Code,
+				Message: msgCode.Msg,
+				Data:    def.ListMindMapFoldersResp{},
+			})
+			return
+		} else {
+			r.Success(rsp)
+		}
+	}
+}
+
+// CreateMindMapFolder
+//
+//	@Description:[POST] /api/biz/v1/mindmap/folders
+//	@return gin.HandlerFunc
+func CreateMindMapFolder() gin.HandlerFunc {
+	return func(gCtx *gin.Context) {
+		req := &def.CreateMindMapFolderReq{}
+		ctx := gCtx.Request.Context()
+
+		// 绑定JSON请求体
+		if err := gCtx.ShouldBindJSON(req); err != nil {
+			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
+				Code:    response.INVALID_PARAMS.Code,
+				Message: response.INVALID_PARAMS.Msg,
+				Data:    def.CreateMindMapFolderResp{},
+			})
+			return
+		}
+
+		rsp, err := handler.GetHandler().CreateMindMapFolder(ctx, req)
+		zlog.CtxAllInOne(ctx, "create_mindmap_folder", req, rsp, err)
+
+		r := response.NewResponse(gCtx)
+		if err != nil {
+			msgCode := mapMindMapServiceErrorToMsgCode(err)
+			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
+				Code:    msgCode.Code,
+				Message: msgCode.Msg,
+				Data:    def.CreateMindMapFolderResp{},
+			})
+			return
+		} else {
+			r.Success(rsp)
+		}
+	}
+}
+
+// UpdateMindMapFolder
+//
+//	@Description:[PUT] /api/biz/v1/mindmap/folders/:folder_id
+//	@return gin.HandlerFunc
+func UpdateMindMapFolder() gin.HandlerFunc {
+	return func(gCtx *gin.Context) {
+		folderID := gCtx.Param("folder_id")
+		req := &def.UpdateMindMapFolderReq{}
+		ctx := gCtx.Request.Context()
+
+		// 参数校验
+		if folderID == "" {
+			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
+				Code:    response.PARAM_NOT_VALID.Code,
+				Message: response.PARAM_NOT_VALID.Msg,
+				Data:    def.UpdateMindMapFolderResp{},
+			})
+			return
+		}
+
+		// 绑定JSON请求体
+		if err := gCtx.ShouldBindJSON(req); err != nil {
+			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
+				Code:    response.INVALID_PARAMS.Code,
+				Message: response.INVALID_PARAMS.Msg,
+				Data:    def.UpdateMindMapFolderResp{},
+			})
+			return
+		}
+
+		rsp, err := handler.GetHandler().UpdateMindMapFolder(ctx, folderID, req)
+		zlog.CtxAllInOne(ctx, "update_mindmap_folder", map[string]interface{}{"folderID": folderID, "req": req}, rsp, err)
+
+		r := response.NewResponse(gCtx)
+		if err != nil {
+			msgCode := mapMindMapServiceErrorToMsgCode(err)
+			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
+				Code:    msgCode.Code,
+				Message: msgCode.Msg,
+				Data:    def.UpdateMindMapFolderResp{},
+			})
+			return
+		} else {
+			r.Success(rsp)
+		}
+	}
+}
+
+// DeleteMindMapFolder
+//
+//	@Description:[DELETE] /api/biz/v1/mindmap/folders/:folder_id
+//	@return gin.HandlerFunc
+func DeleteMindMapFolder() gin.HandlerFunc {
+	return func(gCtx *gin.Context) {
+		folderID := gCtx.Param("folder_id")
+		ctx := gCtx.Request.Context()
+
+		// 参数校验
+		if folderID == "" {
+			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
+				Code:    response.PARAM_NOT_VALID.Code,
+				Message: response.PARAM_NOT_VALID.Msg,
+				Data:    def.DeleteMindMapFolderResp{Success: false},
+			})
+			return
+		}
+
+		rsp, err := handler.GetHandler().DeleteMindMapFolder(ctx, folderID)
+		zlog.CtxAllInOne(ctx, "delete_mindmap_folder", folderID, rsp, err)
+
+		r := response.NewResponse(gCtx)
+		if err != nil {
+			msgCode := mapMindMapServiceErrorToMsgCode(err)
+			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
+				Code:    msgCode.Code,
+				Message: msgCode.Msg,
+				Data:    def.DeleteMindMapFolderResp{Success: false},
+			})
+			return
+		} else {
+			r.Success(rsp)
+		}
+	}
+}
+
+// MoveMindMapsToFolder
+//
+//	@Description:[POST] /api/biz/v1/mindmap/move
+//	@return gin.HandlerFunc
+func MoveMindMapsToFolder() gin.HandlerFunc {
+	return func(gCtx *gin.Context) {
+		req := &def.MoveMindMapsToFolderReq{}
+		ctx := gCtx.Request.Context()
+
+		// 绑定JSON请求体
+		if err := gCtx.ShouldBindJSON(req); err != nil {
+			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
+				Code:    response.INVALID_PARAMS.Code,
+				Message: response.INVALID_PARAMS.Msg,
+				Data:    def.MoveMindMapsToFolderResp{},
+			})
+			return
+		}
+
+		rsp, err := handler.GetHandler().MoveMindMapsToFolder(ctx, req)
+		zlog.CtxAllInOne(ctx, "move_mindmaps_to_folder", req, rsp, err)
+
+		r := response.NewResponse(gCtx)
+		if err != nil {
+			msgCode := mapMindMapServiceErrorToMsgCode(err)
+			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
+				Code:    msgCode.Code,
+				Message: msgCode.Msg,
+				Data:    def.MoveMindMapsToFolderResp{},
+			})
+			return
+		} else {
+			r.Success(rsp)
+		}
+	}
+}
+
+// StarMindMap
+//
+//	@Description:[PUT] /api/biz/v1/mindmap/:id/star
+//	@return gin.HandlerFunc
+func StarMindMap() gin.HandlerFunc {
+	return func(gCtx *gin.Context) {
+		mapID := gCtx.Param("id")
+		req := &def.StarMindMapReq{}
+		ctx := gCtx.Request.Context()
+
+		// 参数校验
+		if mapID == "" {
+			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
+				Code:    response.PARAM_NOT_VALID.Code,
+				Message: response.PARAM_NOT_VALID.Msg,
+				Data:    def.StarMindMapResp{Success: false},
+			})
+			return
+		}
+
+		// 绑定JSON请求体
+		if err := gCtx.ShouldBindJSON(req); err != nil {
+			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
+				Code:    response.INVALID_PARAMS.Code,
+				Message: response.INVALID_PARAMS.Msg,
+				Data:    def.StarMindMapResp{Success: false},
+			})
+			return
+		}
+
+		rsp, err := handler.GetHandler().StarMindMap(ctx, mapID, req)
+		zlog.CtxAllInOne(ctx, "star_mindmap", map[string]interface{}{"mapID": mapID, "req": req}, rsp, err)
+
+		r := response.NewResponse(gCtx)
+		if err != nil {
+			msgCode := mapMindMapServiceErrorToMsgCode(err)
+			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
+				Code:    msgCode.Code,
+				Message: msgCode.Msg,
+				Data:    def.StarMindMapResp{Success: false},
+			})
+			return
+		} else {
+			r.Success(rsp)
+		}
+	}
+}
+
+// PinMindMap
+//
+//	@Description:[PUT] /api/biz/v1/mindmap/:id/pin
+//	@return gin.HandlerFunc
+func PinMindMap() gin.HandlerFunc {
+	return func(gCtx *gin.Context) {
+		mapID := gCtx.Param("id")
+		req := &def.PinMindMapReq{}
+		ctx := gCtx.Request.Context()
+
+		// 参数校验
+		if mapID == "" {
+			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
+				Code:    response.PARAM_NOT_VALID.Code,
+				Message: response.PARAM_NOT_VALID.Msg,
+				Data:    def.PinMindMapResp{Success: false},
+			})
+			return
+		}
+
+		// 绑定JSON请求体
+		if err := gCtx.ShouldBindJSON(req); err != nil {
+			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
+				Code:    response.INVALID_PARAMS.Code,
+				Message: response.INVALID_PARAMS.Msg,
+				Data:    def.PinMindMapResp{Success: false},
+			})
+			return
+		}
+
+		rsp, err := handler.GetHandler().PinMindMap(ctx, mapID, req)
+		zlog.CtxAllInOne(ctx, "pin_mindmap", map[string]interface{}{"mapID": mapID, "req": req}, rsp, err)
+
+		r := response.NewResponse(gCtx)
+		if err != nil {
+			msgCode := mapMindMapServiceErrorToMsgCode(err)
+			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
+				Code:    msgCode.Code,
+				Message: msgCode.Msg,
+				Data:    def.PinMindMapResp{Success: false},
+			})
+			return
+		} else {
+			r.Success(rsp)
+		}
+	}
+}
+
+// SetMindMapTags
+//
+//	@Description:[PUT] /api/biz/v1/mindmap/:id/tags
+//	@return gin.HandlerFunc
+func SetMindMapTags() gin.HandlerFunc {
+	return func(gCtx *gin.Context) {
+		mapID := gCtx.Param("id")
+		req := &def.SetMindMapTagsReq{}
+		ctx := gCtx.Request.Context()
+
+		// 参数校验
+		if mapID == "" {
+			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
+				Code:    response.PARAM_NOT_VALID.Code,
+				Message: response.PARAM_NOT_VALID.Msg,
+				Data:    def.SetMindMapTagsResp{},
+			})
+			return
+		}
+
+		// 绑定JSON请求体
+		if err := gCtx.ShouldBindJSON(req); err != nil {
+			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
+				Code:    response.INVALID_PARAMS.Code,
+				Message: response.INVALID_PARAMS.Msg,
+				Data:    def.SetMindMapTagsResp{},
+			})
+			return
+		}
+
+		rsp, err := handler.GetHandler().SetMindMapTags(ctx, mapID, req)
+		zlog.CtxAllInOne(ctx, "set_mindmap_tags", map[string]interface{}{"mapID": mapID, "req": req}, rsp, err)
+
+		r := response.NewResponse(gCtx)
+		if err != nil {
+			msgCode := mapMindMapServiceErrorToMsgCode(err)
+			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
+				Code:    msgCode.Code,
+				Message: msgCode.Msg,
+				Data:    def.SetMindMapTagsResp{},
+			})
+			return
+		} else {
+			r.Success(rsp)
+		}
+	}
+}
+
+// ListMindMapTags
+//
+//	@Description:[GET] /api/biz/v1/mindmap/tags
+//	@return gin.HandlerFunc
+func ListMindMapTags() gin.HandlerFunc {
+	return func(gCtx *gin.Context) {
+		ctx := gCtx.Request.Context()
+
+		rsp, err := handler.GetHandler().ListMindMapTags(ctx)
+		zlog.CtxAllInOne(ctx, "list_mindmap_tags", nil, rsp, err)
+
+		r := response.NewResponse(gCtx)
+		if err != nil {
+			msgCode := mapMindMapServiceErrorToMsgCode(err)
+			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
+				Code:    msgCode.Code,
+				Message: msgCode.Msg,
+				Data:    def.ListMindMapTagsResp{},
+			})
+			return
+		} else {
+			r.Success(rsp)
+		}
+	}
+}
+
+// ListMindMapComments
+//
+//	@Description:[GET] /api/biz/v1/mindmap/:id/comments
+//	@return gin.HandlerFunc
+func ListMindMapComments() gin.HandlerFunc {
+	return func(gCtx *gin.Context) {
+		mapID := gCtx.Param("id")
+		req := &def.ListMindMapCommentsReq{}
+		ctx := gCtx.Request.Context()
+
+		// 参数校验
+		if mapID == "" {
+			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
+				Code:    response.PARAM_NOT_VALID.Code,
+				Message: response.PARAM_NOT_VALID.Msg,
+				Data:    def.ListMindMapCommentsResp{},
+			})
+			return
+		}
+
+		// 绑定查询参数
+		if err := gCtx.ShouldBindQuery(req); err != nil {
+			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
+				Code:    response.INVALID_PARAMS.Code,
+				Message: response.INVALID_PARAMS.Msg,
+				Data:    def.ListMindMapCommentsResp{},
+			})
+			return
+		}
+
+		rsp, err := handler.GetHandler().ListMindMapComments(ctx, mapID, req)
+		zlog.CtxAllInOne(ctx, "list_mindmap_comments", map[string]interface{}{"mapID": mapID, "req": req}, rsp, err)
+
+		r := response.NewResponse(gCtx)
+		if err != nil {
+			msgCode := mapMindMapServiceErrorToMsgCode(err)
+			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
+				Code:    msgCode.Code,
+				Message: msgCode.Msg,
+				Data:    def.ListMindMapCommentsResp{},
+			})
+			return
+		} else {
+			r.Success(rsp)
+		}
+	}
+}
+
+// CreateMindMapComment
+//
+//	@Description:[POST] /api/biz/v1/mindmap/:id/comments
+//	@return gin.HandlerFunc
+func CreateMindMapComment() gin.HandlerFunc {
+	return func(gCtx *gin.Context) {
+		mapID := gCtx.Param("id")
+		req := &def.CreateMindMapCommentReq{}
+		ctx := gCtx.Request.Context()
+
+		// 参数校验
+		if mapID == "" {
+			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
+				Code:    response.PARAM_NOT_VALID.Code,
+				Message: response.PARAM_NOT_VALID.Msg,
+				Data:    def.CreateMindMapCommentResp{},
+			})
+			return
+		}
+
+		// 绑定JSON请求体
+		if err := gCtx.ShouldBindJSON(req); err != nil {
+			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
+				Code:    response.INVALID_PARAMS.Code,
+				Message: response.INVALID_PARAMS.Msg,
+				Data:    def.CreateMindMapCommentResp{},
+			})
+			return
+		}
+
+		rsp, err := handler.GetHandler().CreateMindMapComment(ctx, mapID, req)
+		zlog.CtxAllInOne(ctx, "create_mindmap_comment", map[string]interface{}{"mapID": mapID, "req": req}, rsp, err)
+
+		r := response.NewResponse(gCtx)
+		if err != nil {
+			msgCode := mapMindMapServiceErrorToMsgCode(err)
+			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
+				Code:    msgCode.Code,
+				Message: msgCode.Msg,
+				Data:    def.CreateMindMapCommentResp{},
+			})
+			return
+		} else {
+			r.Success(rsp)
+		}
+	}
+}
+
+// UpdateMindMapComment
+//
+//	@Description:[PUT] /api/biz/v1/mindmap/:id/comments/:comment_id
+//	@return gin.HandlerFunc
+func UpdateMindMapComment() gin.HandlerFunc {
+	return func(gCtx *gin.Context) {
+		mapID := gCtx.Param("id")
+		commentID := gCtx.Param("comment_id")
+		req := &def.UpdateMindMapCommentReq{}
+		ctx := gCtx.Request.Context()
+
+		// 参数校验
+		if mapID == "" || commentID == "" {
+			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
+				Code:    response.PARAM_NOT_VALID.Code,
+				Message: response.PARAM_NOT_VALID.Msg,
+				Data:    def.UpdateMindMapCommentResp{},
+			})
+			return
+		}
+
+		// 绑定JSON请求体
+		if err := gCtx.ShouldBindJSON(req); err != nil {
+			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
+				Code:    response.INVALID_PARAMS.Code,
+				Message: response.INVALID_PARAMS.Msg,
+				Data:    def.UpdateMindMapCommentResp{},
+			})
+			return
+		}
+
+		rsp, err := handler.GetHandler().UpdateMindMapComment(ctx, mapID, commentID, req)
+		zlog.CtxAllInOne(ctx, "update_mindmap_comment", map[string]interface{}{"mapID": mapID, "commentID": commentID, "req": req}, rsp, err)
+
+		r := response.NewResponse(gCtx)
+		if err != nil {
+			msgCode := mapMindMapServiceErrorToMsgCode(err)
+			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
+				Code:    msgCode.Code,
+				Message: msgCode.Msg,
+				Data:    def.UpdateMindMapCommentResp{},
+			})
+			return
+		} else {
+			r.Success(rsp)
+		}
+	}
+}
+
+// DeleteMindMapComment
+//
+//	@Description:[DELETE] /api/biz/v1/mindmap/:id/comments/:comment_id
+//	@return gin.HandlerFunc
+func DeleteMindMapComment() gin.HandlerFunc {
+	return func(gCtx *gin.Context) {
+		mapID := gCtx.Param("id")
+		commentID := gCtx.Param("comment_id")
+		ctx := gCtx.Request.Context()
+
+		// 参数校验
+		if mapID == "" || commentID == "" {
+			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
+				Code:    response.PARAM_NOT_VALID.Code,
+				Message: response.PARAM_NOT_VALID.Msg,
+				Data:    def.DeleteMindMapCommentResp{Success: false},
+			})
+			return
+		}
+
+		rsp, err := handler.GetHandler().DeleteMindMapComment(ctx, mapID, commentID)
+		zlog.CtxAllInOne(ctx, "delete_mindmap_comment", map[string]interface{}{"mapID": mapID, "commentID": commentID}, rsp, err)
+
+		r := response.NewResponse(gCtx)
+		if err != nil {
+			msgCode := mapMindMapServiceErrorToMsgCode(err)
+			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
+				Code:    msgCode.Code,
+				Message: msgCode.Msg,
+				Data:    def.DeleteMindMapCommentResp{Success: false},
+			})
+			return
+		} else {
+			r.Success(rsp)
+		}
+	}
+}
+
+// ResolveMindMapComment
+//
+//	@Description:[PUT] /api/biz/v1/mindmap/:id/comments/:comment_id/resolve
+//	@return gin.HandlerFunc
+func ResolveMindMapComment() gin.HandlerFunc {
+	return func(gCtx *gin.Context) {
+		mapID := gCtx.Param("id")
+		commentID := gCtx.Param("comment_id")
+		req := &def.ResolveMindMapCommentReq{}
+		ctx := gCtx.Request.Context()
+
+		// 参数校验
+		if mapID == "" || commentID == "" {
+			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
+				Code:    response.PARAM_NOT_VALID.Code,
+				Message: response.PARAM_NOT_VALID.Msg,
+				Data:    def.ResolveMindMapCommentResp{Success: false},
+			})
+			return
+		}
+
+		// 绑定JSON请求体
+		if err := gCtx.ShouldBindJSON(req); err != nil {
+			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
+				Code:    response.INVALID_PARAMS.Code,
+				Message: response.INVALID_PARAMS.Msg,
+				Data:    def.ResolveMindMapCommentResp{Success: false},
+			})
+			return
+		}
+
+		rsp, err := handler.GetHandler().ResolveMindMapComment(ctx, mapID, commentID, req)
+		zlog.CtxAllInOne(ctx, "resolve_mindmap_comment", map[string]interface{}{"mapID": mapID, "commentID": commentID, "req": req}, rsp, err)
+
+		r := response.NewResponse(gCtx)
+		if err != nil {
+			msgCode := mapMindMapServiceErrorToMsgCode(err)
+			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
+				Code:    msgCode.Code,
+				Message: msgCode.Msg,
+				Data:    def.ResolveMindMapCommentResp{Success: false},
+			})
+			return
+		} else {
+			r.Success(rsp)
+		}
+	}
+}
+
+// ListMindMapAttachments
+//
+//	@Description:[GET] /api/biz/v1/mindmap/:id/attachments
+//	@return gin.HandlerFunc
+func ListMindMapAttachments() gin.HandlerFunc {
+	return func(gCtx *gin.Context) {
+		mapID := gCtx.Param("id")
+		req := &def.ListMindMapAttachmentsReq{}
+		ctx := gCtx.Request.Context()
+
+		// 参数校验
+		if mapID == "" {
+			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
+				Code:    response.PARAM_NOT_VALID.Code,
+				Message: response.PARAM_NOT_VALID.Msg,
+				Data:    def.ListMindMapAttachmentsResp{},
+			})
+			return
+		}
+
+		// 绑定查询参数
+		if err := gCtx.ShouldBindQuery(req); err != nil {
+			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
+				Code:    response.INVALID_PARAMS.Code,
+				Message: response.INVALID_PARAMS.Msg,
+				Data:    def.ListMindMapAttachmentsResp{},
+			})
+			return
+		}
+
+		rsp, err := handler.GetHandler().ListMindMapAttachments(ctx, mapID, req)
+		zlog.CtxAllInOne(ctx, "list_mindmap_attachments", map[string]interface{}{"mapID": mapID, "req": req}, rsp, err)
+
+		r := response.NewResponse(gCtx)
+		if err != nil {
+			msgCode := mapMindMapServiceErrorToMsgCode(err)
+			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
+				Code:    msgCode.Code,
+				Message: msgCode.Msg,
+				Data:    def.ListMindMapAttachmentsResp{},
+			})
+			return
+		} else {
+			r.Success(rsp)
+		}
+	}
+}
+
+// UploadMindMapAttachment
+//
+//	@Description:[POST] /api/biz/v1/mindmap/:id/attachments
+//	@return gin.HandlerFunc
+func UploadMindMapAttachment() gin.HandlerFunc {
+	return func(gCtx *gin.Context) {
+		mapID := gCtx.Param("id")
+		req := &def.UploadMindMapAttachmentReq{}
+		ctx := gCtx.Request.Context()
+
+		// 参数校验
+		if mapID == "" {
+			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
+				Code:    response.PARAM_NOT_VALID.Code,
+				Message: response.PARAM_NOT_VALID.Msg,
+				Data:    def.UploadMindMapAttachmentResp{},
+			})
+			return
+		}
+
+		if gCtx.ContentType() != "multipart/form-data" {
+			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
+				Code:    response.INVALID_CONTENT_TYPE.Code,
+				Message: response.INVALID_CONTENT_TYPE.Msg,
+				Data:    def.UploadMindMapAttachmentResp{},
+			})
+			return
+		}
+
+		// 接收文件
+		file, err := gCtx.FormFile("file")
+		if err != nil {
+			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
+				Code:    response.INTERNAL_FILE_UPLOAD_ERROR.Code,
+				Message: response.INTERNAL_FILE_UPLOAD_ERROR.Msg + err.Error(),
+				Data:    def.UploadMindMapAttachmentResp{},
+			})
+			return
+		}
+
+		// 绑定其他表单参数
+		if err := gCtx.ShouldBind(req); err != nil {
+			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
+				Code:    response.INVALID_PARAMS.Code,
+				Message: response.INVALID_PARAMS.Msg,
+				Data:    def.UploadMindMapAttachmentResp{},
+			})
+			return
+		}
+		req.File = file
+
+		rsp, err := handler.GetHandler().UploadMindMapAttachment(ctx, mapID, req)
+		zlog.CtxAllInOne(ctx, "upload_mindmap_attachment", map[string]interface{}{"mapID": mapID, "nodeID": req.NodeID, "fileName": file.Filename}, rsp, err)
+
+		r := response.NewResponse(gCtx)
+		if err != nil {
+			msgCode := mapMindMapServiceErrorToMsgCode(err)
+			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
+				Code:    msgCode.Code,
+				Message: msgCode.Msg,
+				Data:    def.UploadMindMapAttachmentResp{},
+			})
+			return
+		} else {
+			r.Success(rsp)
+		}
+	}
+}
+
+// DeleteMindMapAttachment
+//
+//	@Description:[DELETE] /api/biz/v1/mindmap/:id/attachments/:attachment_id
+//	@return gin.HandlerFunc
+func DeleteMindMapAttachment() gin.HandlerFunc {
+	return func(gCtx *gin.Context) {
+		mapID := gCtx.Param("id")
+		attachmentID := gCtx.Param("attachment_id")
+		ctx := gCtx.Request.Context()
+
+		// 参数校验
+		if mapID == "" || attachmentID == "" {
+			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
+				Code:    response.PARAM_NOT_VALID.Code,
+				Message: response.PARAM_NOT_VALID.Msg,
+				Data:    def.DeleteMindMapAttachmentResp{Success: false},
+			})
+			return
+		}
+
+		rsp, err := handler.GetHandler().DeleteMindMapAttachment(ctx, mapID, attachmentID)
+		zlog.CtxAllInOne(ctx, "delete_mindmap_attachment", map[string]interface{}{"mapID": mapID, "attachmentID": attachmentID}, rsp, err)
+
+		r := response.NewResponse(gCtx)
+		if err != nil {
+			msgCode := mapMindMapServiceErrorToMsgCode(err)
+			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
+				Code:    msgCode.Code,
+				Message: msgCode.Msg,
+				Data:    def.DeleteMindMapAttachmentResp{Success: false},
+			})
+			return
+		} else {
+			r.Success(rsp)
+		}
+	}
+}
+
+// GetAttachmentUsage
+//
+//	@Description:[GET] /api/biz/v1/mindmap/attachments/usage
+//	@return gin.HandlerFunc
+func GetAttachmentUsage() gin.HandlerFunc {
+	return func(gCtx *gin.Context) {
+		ctx := gCtx.Request.Context()
+
+		rsp, err := handler.GetHandler().GetAttachmentUsage(ctx)
+		zlog.CtxAllInOne(ctx, "get_attachment_usage", nil, rsp, err)
+
+		r := response.NewResponse(gCtx)
+		if err != nil {
+			msgCode := mapMindMapServiceErrorToMsgCode(err)
+			gCtx.JSON(http.StatusOK, response.JsonMsgResult{
+				Code:    msgCode.Code,
+				Message: msgCode.Msg,
+				Data:    def.GetAttachmentUsageResp{},
+			})
+			return
+		} else {
+			r.Success(rsp)
+		}
+	}
+}
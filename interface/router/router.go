@@ -2,11 +2,13 @@ package router
 
 import (
 	"fmt"
+	"forge/biz/adapter"
 	"forge/biz/types"
 	"forge/infra/configs"
 	"forge/interface/middleware"
 	"forge/pkg/log/zlog"
 	"forge/util"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/spf13/cast"
@@ -14,6 +16,15 @@ import (
 
 var (
 	jwtAuthMiddleware gin.HandlerFunc
+	rateLimiter       adapter.RateLimiter
+)
+
+// 分享链接（无需登录）的访问限流，按客户端IP与分享Token分别计数
+const (
+	shareRateWindow     = time.Minute
+	shareIPRateLimit    = 60  // 单个IP每分钟的访问次数
+	shareTokenRateLimit = 300 // 单个分享链接每分钟的访问次数
+	shareImageRateLimit = 10  // 单个IP每分钟获取渲染图片的次数
 )
 
 // InitJWTAuth 初始化JWT鉴权中间件
@@ -31,6 +42,11 @@ func InitJWTAuth(userService types.IUserService) {
 	jwtAuthMiddleware = middleware.JWTAuth(jwtUtil, userService)
 }
 
+// InitRateLimiter 初始化限流中间件使用的计数器
+func InitRateLimiter(limiter adapter.RateLimiter) {
+	rateLimiter = limiter
+}
+
 func RunServer() {
 	r := register()
 	run(r)
@@ -38,7 +54,9 @@ func RunServer() {
 
 func register() (router *gin.Engine) {
 	gin.SetMode(gin.DebugMode)
-	r := gin.Default()
+	r := gin.New()
+	// 先移除WebSocket握手URL中的token，访问日志不记录JWT
+	r.Use(middleware.StripQueryToken(), gin.Logger(), gin.Recovery())
 	r.Use(middleware.CorsMiddleware())
 	r.RouterGroup = *r.Group("/api/biz/v1", middleware.AddTracer())
 
@@ -59,6 +77,12 @@ func register() (router *gin.Engine) {
 	loadMindMapService(mindMapGroup)
 	loadGenerationService(mindMapGroup) // 添加生成相关路由
 
+	// 分享链接路由（不需要JWT，通过分享Token访问），按IP与Token限流
+	shareGroup := r.Group("share",
+		middleware.RateLimit(rateLimiter, "share:ip", shareIPRateLimit, shareRateWindow, clientIPKey),
+		middleware.RateLimit(rateLimiter, "share:token", shareTokenRateLimit, shareRateWindow, shareTokenKey))
+	loadShareService(shareGroup)
+
 	// cos路由组需要JWT鉴权
 	cosGroup := r.Group("cos", jwtAuthMiddleware)
 	loadCOSService(cosGroup)
@@ -83,6 +107,7 @@ const (
 	POST   = "POST"
 	GET    = "GET"
 	PUT    = "PUT"
+	PATCH  = "PATCH"
 	DELETE = "DELETE"
 )
 
@@ -129,6 +154,10 @@ func loadMindMapService(r *gin.RouterGroup) {
 	// [POST] /api/biz/v1/mindmap
 	r.Handle(POST, "", CreateMindMap())
 
+	// 从 Markdown、OPML、FreeMind 文件导入思维导图
+	// [POST] /api/biz/v1/mindmap/import
+	r.Handle(POST, "import", ImportMindMap())
+
 	// 获取思维导图详情
 	// [GET] /api/biz/v1/mindmap/:id
 	r.Handle(GET, ":id", GetMindMap())
@@ -137,13 +166,221 @@ func loadMindMapService(r *gin.RouterGroup) {
 	// [GET] /api/biz/v1/mindmap/list
 	r.Handle(GET, "list", ListMindMaps())
 
+	// 搜索思维导图（标题、描述、节点文本）
+	// [GET] /api/biz/v1/mindmap/search
+	r.Handle(GET, "search", SearchMindMaps())
+
+	// 增量同步思维导图（返回游标之后变化与删除的导图，游标为空时全量同步）
+	// [GET] /api/biz/v1/mindmap/sync
+	r.Handle(GET, "sync", SyncMindMaps())
+
 	// 更新思维导图
 	// [PUT] /api/biz/v1/mindmap/:id
 	r.Handle(PUT, ":id", UpdateMindMap())
 
-	// 删除思维导图
+	// 节点级增量更新思维导图
+	// [PATCH] /api/biz/v1/mindmap/:id
+	r.Handle(PATCH, ":id", PatchMindMap())
+
+	// 删除思维导图（移入回收站）
 	// [DELETE] /api/biz/v1/mindmap/:id
 	r.Handle(DELETE, ":id", DeleteMindMap())
+
+	// 获取回收站中的思维导图列表
+	// [GET] /api/biz/v1/mindmap/trash
+	r.Handle(GET, "trash", ListTrashMindMaps())
+
+	// 从回收站恢复思维导图
+	// [POST] /api/biz/v1/mindmap/:id/restore
+	r.Handle(POST, ":id/restore", RestoreMindMap())
+
+	// 彻底删除回收站中的思维导图
+	// [DELETE] /api/biz/v1/mindmap/:id/permanent
+	r.Handle(DELETE, ":id/permanent", PurgeMindMap())
+
+	// 导出思维导图为 Markdown、OPML、FreeMind 文件，SVG、PNG 图片或 PDF、Word、PPT 文档
+	// [GET] /api/biz/v1/mindmap/:id/export
+	r.Handle(GET, ":id/export", ExportMindMap())
+
+	// 生成思维导图缩略图
+	// [POST] /api/biz/v1/mindmap/:id/thumbnail
+	r.Handle(POST, ":id/thumbnail", GenerateThumbnail())
+
+	// 获取思维导图历史版本列表
+	// [GET] /api/biz/v1/mindmap/:id/versions
+	r.Handle(GET, ":id/versions", ListMindMapRevisions())
+
+	// 获取思维导图指定历史版本
+	// [GET] /api/biz/v1/mindmap/:id/versions/:version
+	r.Handle(GET, ":id/versions/:version", GetMindMapRevision())
+
+	// 恢复思维导图到指定历史版本
+	// [POST] /api/biz/v1/mindmap/:id/versions/:version/restore
+	r.Handle(POST, ":id/versions/:version/restore", RestoreMindMapRevision())
+
+	// 比较思维导图两个版本之间的结构差异
+	// [GET] /api/biz/v1/mindmap/:id/diff
+	r.Handle(GET, ":id/diff", DiffMindMap())
+
+	// 比较思维导图指定版本与提交的导图数据之间的结构差异
+	// [POST] /api/biz/v1/mindmap/:id/diff
+	r.Handle(POST, ":id/diff", DiffMindMapProposal())
+
+	// 创建思维导图只读分享链接
+	// [POST] /api/biz/v1/mindmap/:id/shares
+	r.Handle(POST, ":id/shares", CreateMindMapShare())
+
+	// 获取当前用户未过期的分享链接
+	// [GET] /api/biz/v1/mindmap/shares?map_id=
+	r.Handle(GET, "shares", ListMindMapShares())
+
+	// 撤销分享链接
+	// [DELETE] /api/biz/v1/mindmap/shares/:share_id
+	r.Handle(DELETE, "shares/:share_id", RevokeMindMapShare())
+
+	// 获取思维导图协作成员列表
+	// [GET] /api/biz/v1/mindmap/:id/members
+	r.Handle(GET, ":id/members", ListMindMapMembers())
+
+	// 通过邮箱或手机号邀请协作成员
+	// [POST] /api/biz/v1/mindmap/:id/members
+	r.Handle(POST, ":id/members", InviteMindMapMember())
+
+	// 修改协作成员角色
+	// [PUT] /api/biz/v1/mindmap/:id/members/:user_id
+	r.Handle(PUT, ":id/members/:user_id", UpdateMindMapMember())
+
+	// 移除协作成员或退出协作
+	// [DELETE] /api/biz/v1/mindmap/:id/members/:user_id
+	r.Handle(DELETE, ":id/members/:user_id", RemoveMindMapMember())
+
+	// 实时协作（WebSocket），浏览器可通过 token 查询参数传递登录凭证
+	// [GET] /api/biz/v1/mindmap/:id/collab
+	r.Handle(GET, ":id/collab", MindMapCollab())
+
+	// 获取系统模板与我的模板列表
+	// [GET] /api/biz/v1/mindmap/templates
+	r.Handle(GET, "templates", ListMindMapTemplates())
+
+	// 预览模板
+	// [GET] /api/biz/v1/mindmap/templates/:template_id
+	r.Handle(GET, "templates/:template_id", GetMindMapTemplate())
+
+	// 基于模板创建思维导图
+	// [POST] /api/biz/v1/mindmap/templates/:template_id/mindmaps
+	r.Handle(POST, "templates/:template_id/mindmaps", CreateMindMapFromTemplate())
+
+	// 删除我的模板
+	// [DELETE] /api/biz/v1/mindmap/templates/:template_id
+	r.Handle(DELETE, "templates/:template_id", DeleteMindMapTemplate())
+
+	// 将思维导图保存为模板
+	// [POST] /api/biz/v1/mindmap/:id/template
+	r.Handle(POST, ":id/template", SaveMindMapAsTemplate())
+
+	// 复制思维导图
+	// [POST] /api/biz/v1/mindmap/:id/duplicate
+	r.Handle(POST, ":id/duplicate", DuplicateMindMap())
+
+	// 将思维导图的一个分支提取为新导图，可选从原导图中移除
+	// [POST] /api/biz/v1/mindmap/:id/extract
+	r.Handle(POST, ":id/extract", ExtractMindMapSubtree())
+
+	// 将多张思维导图合并到新根节点下生成新导图
+	// [POST] /api/biz/v1/mindmap/merge
+	r.Handle(POST, "merge", MergeMindMaps())
+
+	// 获取当前用户的全部文件夹
+	// [GET] /api/biz/v1/mindmap/folders
+	r.Handle(GET, "folders", ListMindMapFolders())
+
+	// 创建文件夹
+	// [POST] /api/biz/v1/mindmap/folders
+	r.Handle(POST, "folders", CreateMindMapFolder())
+
+	// 重命名文件夹或移动到其他文件夹下
+	// [PUT] /api/biz/v1/mindmap/folders/:folder_id
+	r.Handle(PUT, "folders/:folder_id", UpdateMindMapFolder())
+
+	// 删除文件夹及其子文件夹，其中的导图移回顶层
+	// [DELETE] /api/biz/v1/mindmap/folders/:folder_id
+	r.Handle(DELETE, "folders/:folder_id", DeleteMindMapFolder())
+
+	// 批量将思维导图移入文件夹
+	// [POST] /api/biz/v1/mindmap/move
+	r.Handle(POST, "move", MoveMindMapsToFolder())
+
+	// 收藏或取消收藏思维导图
+	// [PUT] /api/biz/v1/mindmap/:id/star
+	r.Handle(PUT, ":id/star", StarMindMap())
+
+	// 置顶或取消置顶思维导图
+	// [PUT] /api/biz/v1/mindmap/:id/pin
+	r.Handle(PUT, ":id/pin", PinMindMap())
+
+	// 设置思维导图标签
+	// [PUT] /api/biz/v1/mindmap/:id/tags
+	r.Handle(PUT, ":id/tags", SetMindMapTags())
+
+	// 获取当前用户使用过的标签及导图数量
+	// [GET] /api/biz/v1/mindmap/tags
+	r.Handle(GET, "tags", ListMindMapTags())
+
+	// 获取思维导图的评论线程
+	// [GET] /api/biz/v1/mindmap/:id/comments
+	r.Handle(GET, ":id/comments", ListMindMapComments())
+
+	// 在节点上发表评论或回复评论
+	// [POST] /api/biz/v1/mindmap/:id/comments
+	r.Handle(POST, ":id/comments", CreateMindMapComment())
+
+	// 编辑评论
+	// [PUT] /api/biz/v1/mindmap/:id/comments/:comment_id
+	r.Handle(PUT, ":id/comments/:comment_id", UpdateMindMapComment())
+
+	// 删除评论
+	// [DELETE] /api/biz/v1/mindmap/:id/comments/:comment_id
+	r.Handle(DELETE, ":id/comments/:comment_id", DeleteMindMapComment())
+
+	// 解决或重新打开评论线程
+	// [PUT] /api/biz/v1/mindmap/:id/comments/:comment_id/resolve
+	r.Handle(PUT, ":id/comments/:comment_id/resolve", ResolveMindMapComment())
+
+	// 获取思维导图的附件列表
+	// [GET] /api/biz/v1/mindmap/:id/attachments
+	r.Handle(GET, ":id/attachments", ListMindMapAttachments())
+
+	// 上传附件到节点
+	// [POST] /api/biz/v1/mindmap/:id/attachments
+	r.Handle(POST, ":id/attachments", UploadMindMapAttachment())
+
+	// 删除附件
+	// [DELETE] /api/biz/v1/mindmap/:id/attachments/:attachment_id
+	r.Handle(DELETE, ":id/attachments/:attachment_id", DeleteMindMapAttachment())
+
+	// 获取当前用户的附件存储用量
+	// [GET] /api/biz/v1/mindmap/attachments/usage
+	r.Handle(GET, "attachments/usage", GetAttachmentUsage())
+}
+
+func loadShareService(r *gin.RouterGroup) {
+	// 通过分享链接查看思维导图
+	// [GET] /api/biz/v1/share/:token
+	r.Handle(GET, ":token", GetSharedMindMap())
+
+	// 通过分享链接获取思维导图的渲染图片
+	// [GET] /api/biz/v1/share/:token/image
+	r.Handle(GET, ":token/image",
+		middleware.RateLimit(rateLimiter, "share:image:ip", shareImageRateLimit, shareRateWindow, clientIPKey),
+		ExportSharedMindMap())
+}
+
+func clientIPKey(gCtx *gin.Context) string {
+	return gCtx.ClientIP()
+}
+
+func shareTokenKey(gCtx *gin.Context) string {
+	return gCtx.Param("token")
 }
 
 func loadCOSService(r *gin.RouterGroup) {
@@ -157,6 +394,10 @@ func loadAiChat(r *gin.RouterGroup) {
 	// [POST] /api/biz/v1/aichat/send_message
 	r.Handle(POST, "send_message", SendMessage())
 
+	// 流式ai对话（Server-Sent Events）
+	// [POST] /api/biz/v1/aichat/send_message/stream
+	r.Handle(POST, "send_message/stream", SendMessageStream())
+
 	//新增会话
 	// [POST] /api/biz/v1/aichat/save_conversation
 	r.Handle(POST, "save_conversation", SaveNewConversation())
@@ -32,6 +32,7 @@ var (
 	INVALID_PARAMS     = MsgCode{Code: 1005, Msg: "请求体无效"}
 
 	PARAM_FILE_SIZE_TOO_BIG = MsgCode{Code: 1010, Msg: "文件过大"}
+	TOO_MANY_REQUESTS       = MsgCode{Code: 1020, Msg: "请求过于频繁，请稍后再试"}
 
 	/* 用户错误 2000 ~ 2999 */
 	USER_NOT_LOGIN             = MsgCode{Code: 2001, Msg: "用户未登录"}
@@ -48,9 +49,31 @@ var (
 	INSUFFICENT_PERMISSIONS = MsgCode{Code: 2200, Msg: "权限不足"}
 
 	/* 思维导图错误 3000 ~ 3999 */
-	MINDMAP_NOT_FOUND         = MsgCode{Code: 3001, Msg: "思维导图不存在"}
-	MINDMAP_ALREADY_EXISTS    = MsgCode{Code: 3002, Msg: "思维导图已存在"}
-	MINDMAP_PERMISSION_DENIED = MsgCode{Code: 3003, Msg: "思维导图权限不足"}
+	MINDMAP_NOT_FOUND                 = MsgCode{Code: 3001, Msg: "思维导图不存在"}
+	MINDMAP_ALREADY_EXISTS            = MsgCode{Code: 3002, Msg: "思维导图已存在"}
+	MINDMAP_PERMISSION_DENIED         = MsgCode{Code: 3003, Msg: "思维导图权限不足"}
+	MINDMAP_VERSION_CONFLICT          = MsgCode{Code: 3004, Msg: "思维导图已被修改，请刷新后重试"}
+	MINDMAP_REVISION_NOT_FOUND        = MsgCode{Code: 3005, Msg: "历史版本不存在"}
+	MINDMAP_INVALID_PATCH             = MsgCode{Code: 3006, Msg: "无效的节点操作"}
+	MINDMAP_INVALID_DATA              = MsgCode{Code: 3007, Msg: "思维导图数据无效"}
+	MINDMAP_UNSUPPORTED_FORMAT        = MsgCode{Code: 3008, Msg: "不支持的文件格式"}
+	MINDMAP_IMPORT_FAILED             = MsgCode{Code: 3009, Msg: "导入文件解析失败"}
+	MINDMAP_SHARE_NOT_FOUND           = MsgCode{Code: 3010, Msg: "分享链接不存在或已失效"}
+	MINDMAP_SHARE_NEED_PWD            = MsgCode{Code: 3011, Msg: "需要输入分享密码"}
+	MINDMAP_SHARE_WRONG_PWD           = MsgCode{Code: 3012, Msg: "分享密码错误"}
+	MINDMAP_MEMBER_NOT_FOUND          = MsgCode{Code: 3013, Msg: "协作成员不存在"}
+	MINDMAP_MEMBER_EXISTS             = MsgCode{Code: 3014, Msg: "该用户已是协作成员"}
+	MINDMAP_INVITEE_NOT_FOUND         = MsgCode{Code: 3015, Msg: "被邀请的用户不存在"}
+	MINDMAP_COLLAB_CONFLICT           = MsgCode{Code: 3016, Msg: "节点已被其他协作者修改，请同步后重试"}
+	MINDMAP_TEMPLATE_NOT_FOUND        = MsgCode{Code: 3017, Msg: "模板不存在"}
+	MINDMAP_FOLDER_NOT_FOUND          = MsgCode{Code: 3018, Msg: "文件夹不存在"}
+	MINDMAP_FOLDER_NAME_EXISTS        = MsgCode{Code: 3019, Msg: "同一位置已存在同名文件夹"}
+	MINDMAP_COMMENT_NOT_FOUND         = MsgCode{Code: 3020, Msg: "评论不存在"}
+	MINDMAP_ATTACHMENT_NOT_FOUND      = MsgCode{Code: 3021, Msg: "附件不存在"}
+	MINDMAP_ATTACHMENT_UNSUPPORTED    = MsgCode{Code: 3022, Msg: "不支持的附件类型"}
+	MINDMAP_ATTACHMENT_QUOTA_EXCEEDED = MsgCode{Code: 3023, Msg: "附件存储空间不足"}
+	MINDMAP_SYNC_CURSOR_EXPIRED       = MsgCode{Code: 3024, Msg: "同步游标已过期，请重新全量同步"}
+	MINDMAP_SHARE_LOCKED              = MsgCode{Code: 3025, Msg: "分享密码错误次数过多，请稍后再试"}
 
 	/* COS错误 4000 ~ 4999 */
 	COS_INVALID_RESOURCE_PATH  = MsgCode{Code: 4001, Msg: "无效的资源路径"}
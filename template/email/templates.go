@@ -4,3 +4,9 @@ import _ "embed"
 
 //go:embed verification_code.html
 var VerificationCodeTemplate string
+
+//go:embed mindmap_invite.html
+var MindMapInviteTemplate string
+
+//go:embed mindmap_mention.html
+var MindMapMentionTemplate string
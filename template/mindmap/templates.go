@@ -0,0 +1,8 @@
+package mindmap
+
+import "embed"
+
+// SystemTemplates 内置的系统模板，每个文件一个模板
+//
+//go:embed *.json
+var SystemTemplates embed.FS